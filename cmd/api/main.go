@@ -32,6 +32,12 @@ import (
 //	@description				Type "Bearer" followed by a space and JWT token.
 
 func main() {
+	// Schema changes run instead of the server, see runMigrate
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
 	// Initialize application
 	application, err := app.New()
 	if err != nil {
@@ -39,6 +45,11 @@ func main() {
 	}
 	defer application.Close()
 
+	// Differences between the schema and the models are only reported
+	if config.Load().Database.CheckSchemaOnStartup {
+		application.WarnSchemaDrift(context.Background())
+	}
+
 	// Create HTTP server
 	server := &http.Server{
 		Addr:    config.Load().Server.Host + ":" + config.Load().Server.Port,
@@ -60,9 +71,10 @@ func main() {
 
 	log.Println("Shutting down server...")
 
-	// Graceful shutdown
+	// Graceful shutdown - the deferred Close releases the hasher and database once
+	// in-flight requests are done
 	if err := server.Shutdown(context.Background()); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
+		log.Println("Server forced to shutdown:", err)
 	}
 
 	log.Println("Server exited")
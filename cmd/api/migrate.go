@@ -0,0 +1,151 @@
+package main
+
+import (
+	"app/internal/app"
+	"app/internal/shared/infrastructure/database"
+	"app/internal/shared/infrastructure/migration"
+	"app/migrations"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+)
+
+const migrateUsage = `Usage: api migrate [-dir DIR] <command> [args]
+
+Commands:
+  up [N]           apply all pending migrations, or the next N
+  down [N]         roll back the last migration, or the last N
+  status           show the applied version and pending migrations
+  force VERSION    record VERSION as applied without running anything, 0 for none
+  create NAME      write empty up and down files for a new migration
+  check            compare the schema with the models of the features, exit 1 on differences
+
+Flags:
+`
+
+// runMigrate runs the migrate command with the arguments following it. The
+// migrations are embedded in the binary, create writes to -dir
+func runMigrate(args []string) {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dir := flags.String("dir", "migrations", "directory create writes new migrations to")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), migrateUsage)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	command, arg := flags.Arg(0), flags.Arg(1)
+
+	if command == "create" {
+		up, down, err := migration.Create(*dir, arg)
+		if err != nil {
+			log.Fatal("Failed to create migration:", err)
+		}
+		fmt.Println(up)
+		fmt.Println(down)
+		return
+	}
+
+	if command == "check" {
+		checkSchema()
+		return
+	}
+
+	// Migrations only touch the schema, the field encryption keys are not needed
+	db, err := database.NewPlainPostgresDB()
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+	migrator, err := migration.New(db.GetDB(), migrations.FS)
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+	ctx := context.Background()
+
+	switch command {
+	case "up", "down":
+		limit := 0
+		if command == "down" {
+			limit = 1
+		}
+		if arg != "" {
+			if limit, err = strconv.Atoi(arg); err != nil || limit <= 0 {
+				log.Fatalf("Invalid number of migrations %q", arg)
+			}
+		}
+
+		run := migrator.Up
+		if command == "down" {
+			run = migrator.Down
+		}
+		done, err := run(ctx, limit)
+		for _, m := range done {
+			fmt.Printf("%s %s\n", command, m)
+		}
+		if err != nil {
+			db.Close()
+			log.Fatal("Migration failed:", err)
+		}
+		if len(done) == 0 {
+			fmt.Println("no change")
+		}
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			db.Close()
+			log.Fatal("Failed to read migration status:", err)
+		}
+		state := ""
+		if status.Dirty {
+			state = " (dirty)"
+		}
+		fmt.Printf("version %d%s, %d applied, %d pending\n", status.Version, state, len(status.Applied), len(status.Pending))
+		for _, m := range status.Pending {
+			fmt.Printf("pending %s\n", m)
+		}
+	case "force":
+		version, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid version %q", arg)
+		}
+		if err := migrator.Force(ctx, version); err != nil {
+			db.Close()
+			log.Fatal("Failed to force version:", err)
+		}
+		fmt.Printf("forced version %d\n", version)
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+}
+
+// checkSchema prints the differences between the database schema and the models
+// registered by the features
+func checkSchema() {
+	application, err := app.New()
+	if err != nil {
+		log.Fatal("Failed to initialize application:", err)
+	}
+	defer application.Close()
+
+	drifts, err := application.CheckSchema(context.Background())
+	if err != nil {
+		application.Close()
+		log.Fatal("Failed to check the schema:", err)
+	}
+	for _, drift := range drifts {
+		fmt.Println(drift)
+	}
+	if len(drifts) > 0 {
+		application.Close()
+		log.Fatalf("%d differences between the schema and the models", len(drifts))
+	}
+	fmt.Println("schema matches the models")
+}
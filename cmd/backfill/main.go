@@ -0,0 +1,45 @@
+// Command backfill recomputes the canonical email and username keys of existing
+// users and reports keys claimed by several users. Collisions must be resolved
+// by hand, e.g. by renaming one of the accounts, before the unique indexes on
+// the keys can be created
+package main
+
+import (
+	"app/internal/app"
+	"app/internal/core/config"
+	"app/internal/shared/infrastructure/backfill"
+	"app/internal/shared/infrastructure/database"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report without writing any keys")
+	batchSize := flag.Int("batch", 1000, "rows read and written per batch")
+	flag.Parse()
+	app.ConfigureIdentity(config.Load().Identity)
+
+	db, err := database.NewPostgresDB()
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	report, err := backfill.IdentityKeys(context.Background(), db.GetDB(), *batchSize, *dryRun)
+	if err != nil {
+		log.Fatal("Backfill failed:", err)
+	}
+
+	fmt.Printf("scanned %d users, updated %d, skipped %d\n", report.Scanned, report.Updated, report.Skipped)
+	for _, c := range report.Collisions {
+		fmt.Printf("collision on %s %q: %s\n", c.Field, c.Key, strings.Join(c.UserIDs, ", "))
+	}
+	if len(report.Collisions) > 0 {
+		db.Close()
+		os.Exit(1)
+	}
+}
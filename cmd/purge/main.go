@@ -0,0 +1,85 @@
+// Command purge anonymizes or permanently deletes, depending on ACCOUNT_PURGE_MODE,
+// the accounts deleted longer ago than ACCOUNT_DELETION_GRACE_PERIOD, releasing
+// their email and username, and removes the archives of expired data exports.
+// Every purged account is reported as a JSON line on stdout. Run it once from a
+// scheduler such as cron, or keep it running with -every
+package main
+
+import (
+	"app/internal/app"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	batchSize := flag.Int("batch", 100, "accounts and exports read and purged per batch")
+	every := flag.Duration("every", 0, "repeat at this interval instead of running once (e.g. 1h)")
+	dryRun := flag.Bool("dry-run", false, "only report the accounts and records that would be purged")
+	flag.Parse()
+	if err := checkBatchSize(*batchSize); err != nil {
+		log.Fatal("Invalid flags:", err)
+	}
+
+	application, err := app.New()
+	if err != nil {
+		log.Fatal("Failed to initialize application:", err)
+	}
+	defer application.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report := json.NewEncoder(os.Stdout)
+	for {
+		err := purge(ctx, application, *batchSize, *dryRun, report)
+		if err != nil {
+			log.Println("Purge failed:", err)
+			if *every == 0 {
+				stop()
+				application.Close()
+				os.Exit(1)
+			}
+		}
+		if *every == 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(*every):
+		}
+	}
+}
+
+// checkBatchSize rejects a batch size no batch would ever fill, the purge
+// would list nothing and never end
+func checkBatchSize(batchSize int) error {
+	if batchSize < 1 {
+		return fmt.Errorf("-batch must be at least 1, got %d", batchSize)
+	}
+	return nil
+}
+
+// purge runs one pass over the deleted accounts and expired exports
+func purge(ctx context.Context, application *app.App, batchSize int, dryRun bool, report *json.Encoder) error {
+	reports, err := application.Privacy.PurgeDeletedAccounts(ctx, batchSize, dryRun)
+	for _, r := range reports {
+		_ = report.Encode(r)
+	}
+	log.Printf("purged %d accounts (dry run: %t)", len(reports), dryRun)
+	if err != nil || dryRun {
+		return err
+	}
+
+	purged, err := application.Exports.PurgeExpiredExports(ctx, batchSize)
+	log.Printf("purged %d data exports", purged)
+	return err
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckBatchSize(t *testing.T) {
+	assert.NoError(t, checkBatchSize(1))
+	assert.NoError(t, checkBatchSize(100))
+	assert.ErrorContains(t, checkBatchSize(0), "-batch")
+	assert.ErrorContains(t, checkBatchSize(-5), "-batch")
+}
@@ -0,0 +1,53 @@
+// Command reencrypt encrypts the phone numbers and birth dates of existing users
+// under the primary key of FIELD_ENCRYPTION_KEYS and refills their blind indexes.
+// Run it after migration 011 and after every key rotation, the former key can be
+// removed once it reports no stale users. With -decrypt it writes the values
+// back as plaintext, which migration 011 must be rolled back after
+package main
+
+import (
+	"app/internal/core/config"
+	"app/internal/shared/infrastructure/backfill"
+	"app/internal/shared/infrastructure/database"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report without writing any values")
+	batchSize := flag.Int("batch", 1000, "rows read and written per batch")
+	all := flag.Bool("all", false, "re-encrypt every value, e.g. after FIELD_ENCRYPTION_INDEX_KEY changed")
+	decrypt := flag.Bool("decrypt", false, "write every value back as plaintext")
+	flag.Parse()
+
+	mode := backfill.EncryptStale
+	switch {
+	case *all && *decrypt:
+		log.Fatal("-all and -decrypt are exclusive")
+	case *all:
+		mode = backfill.EncryptAll
+	case *decrypt:
+		mode = backfill.Decrypt
+	}
+
+	keyring, err := database.NewKeyring(config.Load().Encryption)
+	if err != nil {
+		log.Fatal("Invalid field encryption config:", err)
+	}
+	db, err := database.NewPostgresDB()
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	report, err := backfill.EncryptedFields(context.Background(), db.GetDB(), keyring, *batchSize, mode, *dryRun)
+	if report != nil {
+		fmt.Printf("scanned %d users, stale %d, updated %d\n", report.Scanned, report.Stale, report.Updated)
+	}
+	if err != nil {
+		db.Close()
+		log.Fatal("Re-encryption failed:", err)
+	}
+}
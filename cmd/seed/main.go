@@ -0,0 +1,86 @@
+// Command seed fills the database with deterministic fixtures described by a YAML
+// spec: one admin account and generated users with realistic names, genders,
+// roles, providers and birth dates. Users are derived from the spec seed, so
+// re-running it inserts nothing new. With -bulk it adds that many more users in
+// batches, to exercise pagination and filters on a large table
+package main
+
+import (
+	"app/internal/app"
+	"app/internal/core/config"
+	"app/internal/shared/infrastructure/database"
+	"app/internal/shared/infrastructure/seed"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func main() {
+	specPath := flag.String("spec", "seeds/dev.yaml", "YAML spec of the fixtures")
+	seedValue := flag.Uint64("seed", 0, "override the seed of the spec")
+	users := flag.Int("users", -1, "override the number of generated users of the spec")
+	bulk := flag.Int("bulk", -1, "override the number of load testing users of the spec")
+	flag.Parse()
+
+	spec, err := seed.LoadSpec(*specPath)
+	if err != nil {
+		log.Fatal("Invalid seed spec:", err)
+	}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "seed":
+			spec.Seed = *seedValue
+		case "users":
+			spec.Users.Count = *users
+		case "bulk":
+			spec.Bulk.Count = *bulk
+		}
+	})
+	cfg := config.Load()
+	app.ConfigureIdentity(cfg.Identity)
+	if err := spec.CheckGenders(cfg.Profile.Genders); err != nil {
+		log.Fatal("Invalid seed spec:", err)
+	}
+	if err := spec.CheckAdmin(cfg.Username.Reserved); err != nil {
+		log.Fatal("Invalid seed spec:", err)
+	}
+
+	db, err := database.NewPostgresDB()
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+	// Batches of a thousand rows are not worth logging
+	quiet := db.GetDB().Session(&gorm.Session{Logger: db.GetDB().Logger.LogMode(logger.Error)})
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	progress := func(done, total int) {
+		log.Printf("bulk users: %d/%d", done, total)
+	}
+	report, err := seed.Run(ctx, quiet, spec, app.PasswordParams(cfg.Password), progress)
+	if report != nil {
+		fmt.Printf("admin created: %t\n", report.AdminCreated)
+		fmt.Printf("users inserted %d, skipped %d\n", report.Users.Inserted, report.Users.Skipped)
+		fmt.Printf("bulk users inserted %d, skipped %d\n", report.Bulk.Inserted, report.Bulk.Skipped)
+		// Drawn passwords are shown once, they are not stored anywhere else
+		if report.AdminPassword != "" {
+			fmt.Printf("admin password: %s\n", report.AdminPassword)
+		}
+		if report.Password != "" {
+			fmt.Printf("password of the inserted users: %s\n", report.Password)
+		}
+	}
+	if err != nil {
+		stop()
+		db.Close()
+		log.Fatal("Seeding failed:", err)
+	}
+}
@@ -1,12 +1,26 @@
 package app
 
 import (
+	"app/internal/core/config"
 	"app/internal/features/auth"
+	"app/internal/features/export"
+	"app/internal/features/metrics"
+	"app/internal/features/privacy"
 	"app/internal/features/user"
 	"app/internal/shared/delivery/http/middleware"
+	"app/internal/shared/domain/service"
 	"app/internal/shared/infrastructure/database"
+	"app/internal/shared/infrastructure/email"
+	"app/internal/shared/infrastructure/migration"
 	sharedRepo "app/internal/shared/infrastructure/repository"
+	"app/internal/shared/infrastructure/sms"
+	"app/internal/shared/infrastructure/storage"
+	"app/migrations"
+	"app/pkg/canonical"
+	"app/pkg/crypto"
 	"app/pkg/logger"
+	"context"
+	"fmt"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -14,7 +28,10 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-// Feature defines the interface that each feature module must implement
+// Feature defines the interface that each feature module must implement. A feature
+// holding personal data of its own also implements service.DataExporter, its
+// section is then added to every user data export, and service.DataAnonymizer,
+// so the data is erased along with the account
 type Feature interface {
 	// Name returns the feature name for logging/debugging
 	Name() string
@@ -23,11 +40,27 @@ type Feature interface {
 	RegisterRoutes(rg *gin.RouterGroup)
 }
 
+// ModelOwner is implemented by feature modules storing entities, whose GORM models
+// are then checked against the database schema, see App.CheckSchema
+type ModelOwner interface {
+	Models() []any
+}
+
 // App holds the application and its dependencies
 type App struct {
 	DB     *database.PostgresDB
 	Engine *gin.Engine
 	Logger *logrus.Logger
+
+	// Password hashing pool shared by every feature
+	Hasher *crypto.PasswordHasher
+
+	// Modules running the scheduled jobs of cmd/purge
+	Privacy *privacy.Module
+	Exports *export.Module
+
+	// Models stored by the features, see ModelOwner
+	Models []any
 }
 
 // New creates and initializes the application
@@ -35,6 +68,7 @@ func New() (*App, error) {
 	app := &App{}
 
 	app.Logger = logger.NewLogger()
+	ConfigureIdentity(config.Load().Identity)
 
 	// Initialize database
 	db, err := database.NewPostgresDB()
@@ -43,6 +77,15 @@ func New() (*App, error) {
 	}
 	app.DB = db
 
+	// Bring the schema up to date before serving, replicas starting at once
+	// wait for each other on the migration lock
+	if config.Load().Database.MigrateOnStartup {
+		if err := app.migrate(); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
 	// Setup router with features
 	app.Engine = app.setupRouter()
 
@@ -59,6 +102,11 @@ func (a *App) setupRouter() *gin.Engine {
 	router.Use(gin.Recovery())
 	router.Use(middleware.CORSMiddleware())
 	router.Use(middleware.LanguageMiddleware())
+	router.Use(middleware.ErrorMiddleware())
+
+	// Password hashing runs on a bounded pool shared by every feature
+	hasher := newPasswordHasher(config.Load().Password)
+	a.Hasher = hasher
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -71,17 +119,49 @@ func (a *App) setupRouter() *gin.Engine {
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 
-	// Initialize shared repository
+	// Initialize shared repository and unit of work
 	userRepo := sharedRepo.NewUserRepository(a.DB.GetDB())
+	otpRepo := sharedRepo.NewPhoneOTPRepository(a.DB.GetDB())
+	historyRepo := sharedRepo.NewUsernameHistoryRepository(a.DB.GetDB())
+	emailChangeRepo := sharedRepo.NewEmailChangeRepository(a.DB.GetDB())
+	exportRepo := sharedRepo.NewDataExportRepository(a.DB.GetDB())
+	transactor := database.NewTransactor(a.DB.GetDB())
+	smsSender := sms.NewSender(config.Load().SMS.Provider, a.Logger)
+	mailer := email.NewSender(config.Load().Email.Provider, a.Logger)
+	exportStorage := storage.NewStorage(config.Load().Export.Storage, config.Load().Export.Dir)
 
 	// Register all features - just add one line per new feature!
 	features := []Feature{
-		auth.NewModule(userRepo, a.Logger),
-		user.NewModule(userRepo, a.Logger),
+		auth.NewModule(userRepo, otpRepo, emailChangeRepo, transactor, smsSender, mailer, hasher, a.Logger),
+		user.NewModule(userRepo, historyRepo, transactor, hasher, a.Logger),
+		metrics.NewModule(userRepo, hasher),
 	}
 
+	// Data exports collect the sections of the features above
+	var exporters []service.DataExporter
+	for _, f := range features {
+		if e, ok := f.(service.DataExporter); ok {
+			exporters = append(exporters, e)
+		}
+	}
+	a.Exports = export.NewModule(exportRepo, userRepo, exportStorage, exporters, a.Logger)
+	features = append(features, a.Exports)
+
+	// Anonymization erases the data of every feature above
+	var anonymizers []service.DataAnonymizer
+	for _, f := range features {
+		if an, ok := f.(service.DataAnonymizer); ok {
+			anonymizers = append(anonymizers, an)
+		}
+	}
+	a.Privacy = privacy.NewModule(userRepo, transactor, anonymizers, a.Logger)
+	features = append(features, a.Privacy)
+
 	for _, f := range features {
 		f.RegisterRoutes(v1)
+		if owner, ok := f.(ModelOwner); ok {
+			a.Models = append(a.Models, owner.Models()...)
+		}
 	}
 
 	// Swagger documentation
@@ -90,8 +170,77 @@ func (a *App) setupRouter() *gin.Engine {
 	return router
 }
 
-// Close releases all resources held by the application
+// migrate applies the pending embedded migrations
+func (a *App) migrate() error {
+	migrator, err := migration.New(a.DB.GetDB(), migrations.FS)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+	applied, err := migrator.Up(context.Background(), 0)
+	for _, m := range applied {
+		a.Logger.WithField("migration", m.String()).Info("Applied migration")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	return nil
+}
+
+// CheckSchema compares the database schema with the models of the features
+func (a *App) CheckSchema(ctx context.Context) ([]migration.Drift, error) {
+	return migration.CheckDrift(ctx, a.DB.GetDB(), a.Models...)
+}
+
+// WarnSchemaDrift logs the differences between the database schema and the models
+// of the features, a migration is probably missing. Serving goes on regardless
+func (a *App) WarnSchemaDrift(ctx context.Context) {
+	drifts, err := a.CheckSchema(ctx)
+	if err != nil {
+		a.Logger.WithError(err).Warn("Failed to check the database schema")
+		return
+	}
+	for _, drift := range drifts {
+		a.Logger.WithFields(logrus.Fields{
+			"kind":   drift.Kind,
+			"table":  drift.Table,
+			"column": drift.Column,
+			"detail": drift.Detail,
+		}).Warn("Database schema differs from the models")
+	}
+}
+
+// ConfigureIdentity sets the rules emails are canonicalized by, once before any
+// user is read or written
+func ConfigureIdentity(cfg config.IdentityConfig) {
+	canonical.Configure(canonical.Options{IgnoreGmailDots: cfg.IgnoreGmailDots})
+}
+
+// PasswordParams returns the parameters new passwords are hashed with
+func PasswordParams(cfg config.PasswordConfig) crypto.PasswordParams {
+	params := crypto.DefaultPasswordParams
+	params.Algorithm = cfg.Algorithm
+	params.Memory = uint32(cfg.Argon2Memory)
+	params.Iterations = uint32(cfg.Argon2Iterations)
+	params.Parallelism = uint8(cfg.Argon2Parallelism)
+	params.BcryptCost = cfg.BcryptCost
+	return params
+}
+
+// newPasswordHasher creates the password hashing pool from config
+func newPasswordHasher(cfg config.PasswordConfig) *crypto.PasswordHasher {
+	return crypto.NewPasswordHasher(PasswordParams(cfg), crypto.HasherConfig{
+		Workers:      cfg.Workers,
+		QueueSize:    cfg.QueueSize,
+		QueueTimeout: cfg.QueueTimeout,
+	})
+}
+
+// Close releases all resources held by the application, once the HTTP server
+// has drained so no request is still hashing a password
 func (a *App) Close() error {
+	if a.Hasher != nil {
+		a.Hasher.Close()
+	}
 	if a.DB != nil {
 		return a.DB.Close()
 	}
@@ -2,13 +2,26 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all configuration for our application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	JWT        JWTConfig
+	Profile    ProfileConfig
+	OTP        OTPConfig
+	SMS        SMSConfig
+	Email      EmailConfig
+	Identity   IdentityConfig
+	Username   UsernameConfig
+	Account    AccountConfig
+	Export     ExportConfig
+	Encryption EncryptionConfig
+	Password   PasswordConfig
 }
 
 // ServerConfig holds server configuration
@@ -25,6 +38,9 @@ type DatabaseConfig struct {
 	Pass    string
 	Name    string
 	SSLMode string
+
+	MigrateOnStartup     bool // Apply pending migrations before serving
+	CheckSchemaOnStartup bool // Log differences between the schema and the models before serving
 }
 
 // JWTConfig holds JWT configuration
@@ -32,6 +48,80 @@ type JWTConfig struct {
 	Secret string
 }
 
+// ProfileConfig holds user profile configuration
+type ProfileConfig struct {
+	Genders []string // Accepted gender values
+}
+
+// OTPConfig holds one-time password configuration
+type OTPConfig struct {
+	TTL            time.Duration // How long a code stays valid
+	MaxAttempts    int           // Wrong guesses allowed before a code is discarded
+	ResendInterval time.Duration // Minimum delay before a new code can be requested
+}
+
+// SMSConfig holds SMS delivery configuration
+type SMSConfig struct {
+	Provider string // log or memory
+}
+
+// EmailConfig holds email delivery and email change configuration
+type EmailConfig struct {
+	Provider   string        // log or memory
+	ChangeTTL  time.Duration // How long an email change link stays valid
+	ConfirmURL string        // Page the confirmation link opens, the token is appended as a query parameter
+	CancelURL  string        // Page the cancel link opens, the token is appended as a query parameter
+}
+
+// IdentityConfig holds email and username canonicalization configuration
+type IdentityConfig struct {
+	IgnoreGmailDots bool // Treat j.doe@gmail.com and jdoe@gmail.com as the same address
+}
+
+// UsernameConfig holds username policy configuration
+type UsernameConfig struct {
+	Reserved       []string      // Usernames nobody can take, lookalikes included
+	ChangeCooldown time.Duration // Minimum time between username changes
+	HoldPeriod     time.Duration // How long a former username stays held for its owner
+}
+
+// AccountConfig holds account deletion configuration
+type AccountConfig struct {
+	DeletionGracePeriod time.Duration // How long a deleted account can still be reactivated by logging in
+	PurgeMode           string        // anonymize or delete, what happens to the account once the grace period is over
+	RetentionPeriod     time.Duration // How long anonymized accounts are kept before being deleted for good, 0 keeps them
+}
+
+// EncryptionConfig holds field-level encryption configuration
+type EncryptionConfig struct {
+	Keys     string // Comma-separated id:base64 AES-256 keys, old keys stay listed until re-encrypted
+	KeyID    string // ID of the key new values are encrypted with, the last listed one by default
+	IndexKey string // Base64 HMAC key of the blind indexes, changing it requires re-indexing every row
+}
+
+// PasswordConfig holds password hashing configuration. Hashes made with another
+// algorithm or cost are replaced on the next login
+type PasswordConfig struct {
+	Algorithm         string // argon2id or bcrypt, what new hashes are made with
+	Argon2Memory      int    // Argon2id memory in KiB
+	Argon2Iterations  int    // Argon2id passes over the memory
+	Argon2Parallelism int    // Argon2id lanes
+	BcryptCost        int
+	Workers           int           // Passwords hashed at once, one per CPU when 0
+	QueueSize         int           // Requests waiting for a hashing worker, further ones get 503
+	QueueTimeout      time.Duration // How long a request waits for a hashing worker before getting 503
+}
+
+// ExportConfig holds user data export configuration
+type ExportConfig struct {
+	Storage     string        // local or memory, where archives are kept
+	Dir         string        // Directory of the local storage
+	TTL         time.Duration // How long an archive is kept once produced
+	LinkTTL     time.Duration // How long a download link stays valid
+	DownloadURL string        // Download endpoint, the signed link parameters are appended
+	LinkSecret  string        // Signs download links, kept apart from the JWT signing key
+}
+
 // Load loads configuration from environment variables
 func Load() Config {
 	config := Config{
@@ -46,10 +136,66 @@ func Load() Config {
 			Pass:    getEnv("DB_PASS", "password"),
 			Name:    getEnv("DB_NAME", "app"),
 			SSLMode: getEnv("DB_SSLMODE", "disable"),
+
+			MigrateOnStartup:     getEnvBool("DB_MIGRATE_ON_STARTUP", false),
+			CheckSchemaOnStartup: getEnvBool("DB_CHECK_SCHEMA_ON_STARTUP", true),
 		},
 		JWT: JWTConfig{
 			Secret: getEnv("JWT_SECRET", "your-secret-key"),
 		},
+		Profile: ProfileConfig{
+			Genders: getEnvList("PROFILE_GENDERS", "male,female,other"),
+		},
+		OTP: OTPConfig{
+			TTL:            getEnvDuration("OTP_TTL", 5*time.Minute),
+			MaxAttempts:    getEnvInt("OTP_MAX_ATTEMPTS", 5),
+			ResendInterval: getEnvDuration("OTP_RESEND_INTERVAL", time.Minute),
+		},
+		SMS: SMSConfig{
+			Provider: getEnv("SMS_PROVIDER", "log"),
+		},
+		Email: EmailConfig{
+			Provider:   getEnv("EMAIL_PROVIDER", "log"),
+			ChangeTTL:  getEnvDuration("EMAIL_CHANGE_TTL", 24*time.Hour),
+			ConfirmURL: getEnv("EMAIL_CHANGE_CONFIRM_URL", "http://localhost:3000/email/confirm"),
+			CancelURL:  getEnv("EMAIL_CHANGE_CANCEL_URL", "http://localhost:3000/email/cancel"),
+		},
+		Identity: IdentityConfig{
+			IgnoreGmailDots: getEnvBool("EMAIL_IGNORE_GMAIL_DOTS", false),
+		},
+		Username: UsernameConfig{
+			Reserved:       getEnvList("USERNAME_RESERVED", "admin,administrator,api,auth,help,login,logout,me,moderator,null,official,register,root,security,settings,staff,support,system,undefined,users"),
+			ChangeCooldown: getEnvDuration("USERNAME_CHANGE_COOLDOWN", 30*24*time.Hour),
+			HoldPeriod:     getEnvDuration("USERNAME_HOLD_PERIOD", 90*24*time.Hour),
+		},
+		Account: AccountConfig{
+			DeletionGracePeriod: getEnvDuration("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour),
+			PurgeMode:           getEnv("ACCOUNT_PURGE_MODE", "anonymize"),
+			RetentionPeriod:     getEnvDuration("ACCOUNT_RETENTION_PERIOD", 0),
+		},
+		Export: ExportConfig{
+			Storage:     getEnv("EXPORT_STORAGE", "local"),
+			Dir:         getEnv("EXPORT_DIR", "./storage/exports"),
+			TTL:         getEnvDuration("EXPORT_TTL", 7*24*time.Hour),
+			LinkTTL:     getEnvDuration("EXPORT_LINK_TTL", 15*time.Minute),
+			DownloadURL: getEnv("EXPORT_DOWNLOAD_URL", "http://localhost:8080/api/v1/exports/download"),
+			LinkSecret:  getEnv("EXPORT_LINK_SECRET", "your-export-link-secret"),
+		},
+		Encryption: EncryptionConfig{
+			Keys:     getEnv("FIELD_ENCRYPTION_KEYS", ""),
+			KeyID:    getEnv("FIELD_ENCRYPTION_KEY_ID", ""),
+			IndexKey: getEnv("FIELD_ENCRYPTION_INDEX_KEY", ""),
+		},
+		Password: PasswordConfig{
+			Algorithm:         getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+			Argon2Memory:      getEnvInt("PASSWORD_ARGON2_MEMORY", 19*1024),
+			Argon2Iterations:  getEnvInt("PASSWORD_ARGON2_ITERATIONS", 2),
+			Argon2Parallelism: getEnvInt("PASSWORD_ARGON2_PARALLELISM", 1),
+			BcryptCost:        getEnvInt("PASSWORD_BCRYPT_COST", 10),
+			Workers:           getEnvInt("PASSWORD_HASH_WORKERS", 0),
+			QueueSize:         getEnvInt("PASSWORD_HASH_QUEUE_SIZE", 64),
+			QueueTimeout:      getEnvDuration("PASSWORD_HASH_QUEUE_TIMEOUT", 2*time.Second),
+		},
 	}
 
 	return config
@@ -62,3 +208,38 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getEnvList gets a comma-separated environment variable as a list with a fallback value
+func getEnvList(key, fallback string) []string {
+	var items []string
+	for _, item := range strings.Split(getEnv(key, fallback), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvInt gets an integer environment variable with a fallback value
+func getEnvInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// getEnvDuration gets a duration environment variable (e.g. 90s) with a fallback value
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// getEnvBool gets a boolean environment variable with a fallback value
+func getEnvBool(key string, fallback bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
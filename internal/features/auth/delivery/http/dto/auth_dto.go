@@ -4,6 +4,7 @@ import (
 	"app/internal/shared/constants"
 	"app/internal/shared/domain/entity"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -14,10 +15,14 @@ type RegisterRequest struct {
 	Password  string `json:"password"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
+	Phone     string `json:"phone,omitempty"`
+	BirthDate string `json:"birth_date,omitempty" example:"1990-01-31"`
+	Gender    string `json:"gender,omitempty"`
 }
 
-// Validate validates RegisterRequest fields
-func (r *RegisterRequest) Validate(lang constants.Lang) map[string][]string {
+// Validate validates RegisterRequest fields, gender against the accepted values.
+// reserved lists the usernames nobody can take
+func (r *RegisterRequest) Validate(lang constants.Lang, genders, reserved []string) map[string][]string {
 	errors := make(map[string][]string)
 
 	// Email validation
@@ -37,6 +42,11 @@ func (r *RegisterRequest) Validate(lang constants.Lang) map[string][]string {
 		if !constants.MaxLength(r.Username, 20) {
 			errors["username"] = append(errors["username"], fmt.Sprintf(constants.GetValidationMessage(constants.UsernameTooLong, lang), 20))
 		}
+		if !constants.IsValidUsername(r.Username) {
+			errors["username"] = append(errors["username"], constants.GetValidationMessage(constants.InvalidUsername, lang))
+		} else if constants.IsReservedUsername(r.Username, reserved) {
+			errors["username"] = append(errors["username"], constants.GetValidationMessage(constants.ReservedUsername, lang))
+		}
 	}
 
 	// Password validation
@@ -56,24 +66,73 @@ func (r *RegisterRequest) Validate(lang constants.Lang) map[string][]string {
 		errors["last_name"] = append(errors["last_name"], fmt.Sprintf(constants.GetValidationMessage(constants.Required, lang), "last_name"))
 	}
 
+	// Optional profile fields
+	if r.Phone != "" {
+		if _, ok := constants.NormalizePhone(r.Phone); !ok {
+			errors["phone"] = append(errors["phone"], constants.GetValidationMessage(constants.InvalidPhone, lang))
+		}
+	}
+	if r.BirthDate != "" {
+		if _, ok := constants.ParseBirthDate(r.BirthDate); !ok {
+			errors["birth_date"] = append(errors["birth_date"], constants.GetValidationMessage(constants.InvalidBirthDate, lang))
+		}
+	}
+	if r.Gender != "" && !constants.IsOneOf(r.Gender, genders) {
+		errors["gender"] = append(errors["gender"], fmt.Sprintf(constants.GetValidationMessage(constants.OneOf, lang), "gender", strings.Join(genders, ", ")))
+	}
+
 	return errors
 }
 
-// LoginRequest represents the request for user login
+// ApplyProfile sets the optional profile fields on user, with the phone normalized to E.164
+func (r *RegisterRequest) ApplyProfile(user *entity.User) {
+	if phone, ok := constants.NormalizePhone(r.Phone); ok {
+		user.Phone = &phone
+	}
+	if birthDate, ok := constants.ParseBirthDate(r.BirthDate); ok {
+		user.BirthDate = &birthDate
+	}
+	user.Gender = r.Gender
+}
+
+// LoginRequest represents the request for user login. Identifier is an email or
+// username, Email is still accepted in its place for older clients. OTP is the
+// SMS code, required when the account has two-factor authentication enabled.
+// Reactivate restores an account scheduled for deletion
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Identifier string `json:"identifier,omitempty" example:"johndoe"`
+	Email      string `json:"email,omitempty"` // Deprecated: use Identifier
+	Password   string `json:"password"`
+	OTP        string `json:"otp,omitempty" example:"123456"`
+	Reactivate bool   `json:"reactivate,omitempty"`
+}
+
+// LoginIdentifier returns the identifier, falling back to the deprecated email field
+func (r *LoginRequest) LoginIdentifier() string {
+	if r.Identifier != "" {
+		return strings.TrimSpace(r.Identifier)
+	}
+	return strings.TrimSpace(r.Email)
+}
+
+// IsEmail reports whether the identifier is an email address rather than a username
+func (r *LoginRequest) IsEmail() bool {
+	return strings.Contains(r.LoginIdentifier(), "@")
 }
 
 // Validate validates LoginRequest fields
 func (r *LoginRequest) Validate(lang constants.Lang) map[string][]string {
 	errors := make(map[string][]string)
 
-	// Email validation
-	if r.Email == "" {
-		errors["email"] = append(errors["email"], fmt.Sprintf(constants.GetValidationMessage(constants.Required, lang), "email"))
-	} else if !constants.IsValidEmail(r.Email) {
-		errors["email"] = append(errors["email"], constants.GetValidationMessage(constants.InvalidEmail, lang))
+	// Identifier validation - reported under the field the client sent
+	field := "identifier"
+	if r.Identifier == "" && r.Email != "" {
+		field = "email"
+	}
+	if r.LoginIdentifier() == "" {
+		errors[field] = append(errors[field], fmt.Sprintf(constants.GetValidationMessage(constants.Required, lang), field))
+	} else if r.IsEmail() && !constants.IsValidEmail(r.LoginIdentifier()) {
+		errors[field] = append(errors[field], constants.GetValidationMessage(constants.InvalidEmail, lang))
 	}
 
 	// Password validation
@@ -81,35 +140,177 @@ func (r *LoginRequest) Validate(lang constants.Lang) map[string][]string {
 		errors["password"] = append(errors["password"], fmt.Sprintf(constants.GetValidationMessage(constants.Required, lang), "password"))
 	}
 
+	// OTP validation
+	if r.OTP != "" && !constants.IsOTPCode(r.OTP) {
+		errors["otp"] = append(errors["otp"], fmt.Sprintf(constants.GetValidationMessage(constants.InvalidOTPCode, lang), "otp"))
+	}
+
 	return errors
 }
 
+// ConfirmPhoneRequest represents the request confirming the profile phone with an SMS code
+type ConfirmPhoneRequest struct {
+	Code string `json:"code" example:"123456"`
+}
+
+// Validate validates ConfirmPhoneRequest fields
+func (r *ConfirmPhoneRequest) Validate(lang constants.Lang) map[string][]string {
+	errors := make(map[string][]string)
+	validateOTPCode(errors, "code", r.Code, lang)
+	return errors
+}
+
+// PhoneOTPRequest represents the request for an SMS login code
+type PhoneOTPRequest struct {
+	Phone string `json:"phone" example:"+6281234567890"`
+}
+
+// Validate validates PhoneOTPRequest fields
+func (r *PhoneOTPRequest) Validate(lang constants.Lang) map[string][]string {
+	errors := make(map[string][]string)
+	validatePhone(errors, r.Phone, lang)
+	return errors
+}
+
+// NormalizedPhone returns the phone in E.164 format
+func (r *PhoneOTPRequest) NormalizedPhone() string {
+	phone, _ := constants.NormalizePhone(r.Phone)
+	return phone
+}
+
+// PhoneLoginRequest represents the request for logging in with a verified phone and SMS code
+type PhoneLoginRequest struct {
+	Phone string `json:"phone" example:"+6281234567890"`
+	Code  string `json:"code" example:"123456"`
+}
+
+// Validate validates PhoneLoginRequest fields
+func (r *PhoneLoginRequest) Validate(lang constants.Lang) map[string][]string {
+	errors := make(map[string][]string)
+	validatePhone(errors, r.Phone, lang)
+	validateOTPCode(errors, "code", r.Code, lang)
+	return errors
+}
+
+// NormalizedPhone returns the phone in E.164 format
+func (r *PhoneLoginRequest) NormalizedPhone() string {
+	phone, _ := constants.NormalizePhone(r.Phone)
+	return phone
+}
+
+// TwoFactorRequest represents the request turning SMS two-factor authentication on or off
+type TwoFactorRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// Validate validates TwoFactorRequest fields
+func (r *TwoFactorRequest) Validate(lang constants.Lang) map[string][]string {
+	errors := make(map[string][]string)
+	if r.Enabled == nil {
+		errors["enabled"] = append(errors["enabled"], fmt.Sprintf(constants.GetValidationMessage(constants.Required, lang), "enabled"))
+	}
+	return errors
+}
+
+// EmailChangeRequest represents the request for changing the email, the current
+// password is asked again since the email is a login identifier
+type EmailChangeRequest struct {
+	NewEmail string `json:"new_email" example:"john.new@example.com"`
+	Password string `json:"password"`
+}
+
+// Validate validates EmailChangeRequest fields
+func (r *EmailChangeRequest) Validate(lang constants.Lang) map[string][]string {
+	errors := make(map[string][]string)
+
+	if r.NewEmail == "" {
+		errors["new_email"] = append(errors["new_email"], fmt.Sprintf(constants.GetValidationMessage(constants.Required, lang), "new_email"))
+	} else if !constants.IsValidEmail(strings.TrimSpace(r.NewEmail)) {
+		errors["new_email"] = append(errors["new_email"], constants.GetValidationMessage(constants.InvalidEmail, lang))
+	}
+	if r.Password == "" {
+		errors["password"] = append(errors["password"], fmt.Sprintf(constants.GetValidationMessage(constants.Required, lang), "password"))
+	}
+
+	return errors
+}
+
+// EmailChangeTokenRequest represents the request confirming or cancelling an
+// email change with the token from the emailed link
+type EmailChangeTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// Validate validates EmailChangeTokenRequest fields
+func (r *EmailChangeTokenRequest) Validate(lang constants.Lang) map[string][]string {
+	errors := make(map[string][]string)
+	if r.Token == "" {
+		errors["token"] = append(errors["token"], fmt.Sprintf(constants.GetValidationMessage(constants.Required, lang), "token"))
+	}
+	return errors
+}
+
+// validatePhone adds the errors for a required phone number
+func validatePhone(errors map[string][]string, phone string, lang constants.Lang) {
+	if phone == "" {
+		errors["phone"] = append(errors["phone"], fmt.Sprintf(constants.GetValidationMessage(constants.Required, lang), "phone"))
+	} else if _, ok := constants.NormalizePhone(phone); !ok {
+		errors["phone"] = append(errors["phone"], constants.GetValidationMessage(constants.InvalidPhone, lang))
+	}
+}
+
+// validateOTPCode adds the errors for a required SMS code
+func validateOTPCode(errors map[string][]string, field, code string, lang constants.Lang) {
+	if code == "" {
+		errors[field] = append(errors[field], fmt.Sprintf(constants.GetValidationMessage(constants.Required, lang), field))
+	} else if !constants.IsOTPCode(code) {
+		errors[field] = append(errors[field], fmt.Sprintf(constants.GetValidationMessage(constants.InvalidOTPCode, lang), field))
+	}
+}
+
 // RegisterResponse represents the response for user registration
 type RegisterResponse struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Username  string    `json:"username"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Status    string    `json:"status"`
-	Role      string    `json:"role"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
+	ID               string     `json:"id"`
+	Email            string     `json:"email"`
+	Username         string     `json:"username"`
+	FirstName        string     `json:"first_name"`
+	LastName         string     `json:"last_name"`
+	Phone            *string    `json:"phone,omitempty"`
+	PhoneVerifiedAt  *time.Time `json:"phone_verified_at,omitempty"`
+	BirthDate        *string    `json:"birth_date,omitempty"`
+	Gender           string     `json:"gender,omitempty"`
+	Status           string     `json:"status"`
+	Role             string     `json:"role"`
+	IsActive         bool       `json:"is_active"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	CreatedAt        time.Time  `json:"created_at"`
 }
 
 // ToRegisterResponse converts entity.User to RegisterResponse
 func ToRegisterResponse(user *entity.User) *RegisterResponse {
-	return &RegisterResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		Username:  user.Username,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Status:    user.Status,
-		Role:      user.Role,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt,
+	response := &RegisterResponse{
+		ID:               user.ID,
+		Email:            user.Email,
+		Username:         user.Username,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Phone:            user.Phone,
+		PhoneVerifiedAt:  user.PhoneVerifiedAt,
+		Gender:           user.Gender,
+		Status:           user.Status,
+		Role:             user.Role,
+		IsActive:         user.IsActive,
+		TwoFactorEnabled: user.TwoFactorEnabled,
+		CreatedAt:        user.CreatedAt,
 	}
+
+	// Format birth date if exists
+	if user.BirthDate != nil {
+		birthDate := user.BirthDate.Format(time.DateOnly)
+		response.BirthDate = &birthDate
+	}
+
+	return response
 }
 
 // LoginResponse represents the response for user login
@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"app/internal/core/config"
 	"app/internal/features/auth/delivery/http/dto"
 	"app/internal/features/auth/usecase"
 	"app/internal/shared/constants"
 	"app/internal/shared/delivery/http/middleware"
 	"app/internal/shared/delivery/http/response"
+	"app/pkg/jwt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -14,12 +16,17 @@ import (
 // AuthHandler handles HTTP requests for authentication operations
 type AuthHandler struct {
 	authUsecase usecase.AuthUsecase
+	profile     config.ProfileConfig
+	username    config.UsernameConfig
 }
 
 // NewAuthHandler creates a new auth handler
 func NewAuthHandler(authUsecase usecase.AuthUsecase) *AuthHandler {
+	cfg := config.Load()
 	return &AuthHandler{
 		authUsecase: authUsecase,
+		profile:     cfg.Profile,
+		username:    cfg.Username,
 	}
 }
 
@@ -33,38 +40,40 @@ func NewAuthHandler(authUsecase usecase.AuthUsecase) *AuthHandler {
 //	@Param			request	body		dto.RegisterRequest	true	"User registration data"
 //	@Success		201		{object}	response.Response{data=dto.RegisterResponse}
 //	@Failure		400		{object}	response.Response
+//	@Failure		409		{object}	response.Response
 //	@Failure		500		{object}	response.Response
+//	@Failure		503		{object}	response.Response
 //	@Router			/api/v1/auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
 	lang := middleware.GetLangFromGin(c)
 
 	var req dto.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.NewResponse(c, http.StatusBadRequest, nil, constants.GetErrorMessage(constants.ValidationFailed, lang), map[string][]string{
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
 			"body": {err.Error()},
 		})
 		return
 	}
 
 	// Validate request
-	if errors := req.Validate(lang); len(errors) > 0 {
-		response.NewResponse(c, http.StatusBadRequest, nil, constants.GetErrorMessage(constants.ValidationFailed, lang), errors)
+	if errors := req.Validate(lang, h.profile.Genders, h.username.Reserved); len(errors) > 0 {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), errors)
 		return
 	}
 
-	user, status, err := h.authUsecase.Register(c.Request.Context(), req)
+	user, err := h.authUsecase.Register(c.Request.Context(), req)
 	if err != nil {
-		response.NewResponse(c, status, nil, err.Error(), nil)
+		_ = c.Error(err)
 		return
 	}
 
-	response.NewResponse(c, status, user, "User registered successfully", nil)
+	response.NewResponse(c, http.StatusCreated, user, "User registered successfully", nil)
 }
 
 // Login handles user login
 //
 //	@Summary		Login user
-//	@Description	Authenticate user with email and password
+//	@Description	Authenticate user with an email or username and password. email is still accepted in place of identifier. An account scheduled for deletion answers 403 until reactivate is set
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
@@ -72,14 +81,16 @@ func (h *AuthHandler) Register(c *gin.Context) {
 //	@Success		200		{object}	response.Response{data=dto.LoginResponse}
 //	@Failure		400		{object}	response.Response
 //	@Failure		401		{object}	response.Response
+//	@Failure		403		{object}	response.Response
 //	@Failure		500		{object}	response.Response
+//	@Failure		503		{object}	response.Response
 //	@Router			/api/v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	lang := middleware.GetLangFromGin(c)
 
 	var req dto.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.NewResponse(c, http.StatusBadRequest, nil, constants.GetErrorMessage(constants.ValidationFailed, lang), map[string][]string{
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
 			"body": {err.Error()},
 		})
 		return
@@ -87,15 +98,353 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	// Validate request
 	if errors := req.Validate(lang); len(errors) > 0 {
-		response.NewResponse(c, http.StatusBadRequest, nil, constants.GetErrorMessage(constants.ValidationFailed, lang), errors)
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), errors)
 		return
 	}
 
-	loginResp, status, err := h.authUsecase.Login(c.Request.Context(), req)
+	loginResp, err := h.authUsecase.Login(c.Request.Context(), req)
 	if err != nil {
-		response.NewResponse(c, status, nil, err.Error(), nil)
+		_ = c.Error(err)
+		return
+	}
+
+	response.NewResponse(c, http.StatusOK, loginResp, "Login successful", nil)
+}
+
+// RequestPhoneVerification handles sending a verification code to the profile phone
+//
+//	@Summary		Request phone verification code
+//	@Description	Send an SMS code to the phone on the authenticated user's profile
+//	@Tags			auth
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		202	{object}	response.Response
+//	@Failure		400	{object}	response.Response
+//	@Failure		401	{object}	response.Response
+//	@Failure		429	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Failure		503	{object}	response.Response
+//	@Router			/api/v1/auth/phone/verification [post]
+func (h *AuthHandler) RequestPhoneVerification(c *gin.Context) {
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authUsecase.RequestPhoneVerification(c.Request.Context(), userID); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.NewResponse(c, http.StatusAccepted, nil, "Verification code sent", nil)
+}
+
+// ConfirmPhoneVerification handles confirming the profile phone with an SMS code
+//
+//	@Summary		Confirm phone verification
+//	@Description	Mark the authenticated user's phone verified with the SMS code sent to it
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.ConfirmPhoneRequest	true	"SMS code"
+//	@Success		200		{object}	response.Response{data=dto.RegisterResponse}
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		409		{object}	response.Response
+//	@Failure		429		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Failure		503		{object}	response.Response
+//	@Router			/api/v1/auth/phone/verification/confirm [post]
+func (h *AuthHandler) ConfirmPhoneVerification(c *gin.Context) {
+	lang := middleware.GetLangFromGin(c)
+
+	userID, ok := authenticatedUserID(c)
+	if !ok {
 		return
 	}
 
-	response.NewResponse(c, status, loginResp, "Login successful", nil)
+	var req dto.ConfirmPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
+			"body": {err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if errors := req.Validate(lang); len(errors) > 0 {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), errors)
+		return
+	}
+
+	user, err := h.authUsecase.ConfirmPhoneVerification(c.Request.Context(), userID, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.NewResponse(c, http.StatusOK, user, "Phone verified successfully", nil)
+}
+
+// SetTwoFactor handles turning SMS two-factor authentication on or off
+//
+//	@Summary		Set two-factor authentication
+//	@Description	Require an SMS code on password login, turning it on needs a verified phone
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.TwoFactorRequest	true	"Two-factor setting"
+//	@Success		200		{object}	response.Response{data=dto.RegisterResponse}
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		409		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Failure		503		{object}	response.Response
+//	@Router			/api/v1/auth/two-factor [put]
+func (h *AuthHandler) SetTwoFactor(c *gin.Context) {
+	lang := middleware.GetLangFromGin(c)
+
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.TwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
+			"body": {err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if errors := req.Validate(lang); len(errors) > 0 {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), errors)
+		return
+	}
+
+	user, err := h.authUsecase.SetTwoFactor(c.Request.Context(), userID, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.NewResponse(c, http.StatusOK, user, "Two-factor authentication updated", nil)
+}
+
+// RequestLoginOTP handles sending a login code to a verified phone
+//
+//	@Summary		Request login code
+//	@Description	Send an SMS login code to a verified phone. The response is the same whether or not the number belongs to an account
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.PhoneOTPRequest	true	"Verified phone"
+//	@Success		202		{object}	response.Response
+//	@Failure		400		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Failure		503		{object}	response.Response
+//	@Router			/api/v1/auth/otp [post]
+func (h *AuthHandler) RequestLoginOTP(c *gin.Context) {
+	lang := middleware.GetLangFromGin(c)
+
+	var req dto.PhoneOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
+			"body": {err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if errors := req.Validate(lang); len(errors) > 0 {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), errors)
+		return
+	}
+
+	if err := h.authUsecase.RequestLoginOTP(c.Request.Context(), req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.NewResponse(c, http.StatusAccepted, nil, "If the phone is verified, a login code was sent", nil)
+}
+
+// LoginWithPhone handles login with a verified phone and SMS code
+//
+//	@Summary		Login with phone
+//	@Description	Authenticate user with a verified phone and the SMS code sent to it
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.PhoneLoginRequest	true	"Phone and SMS code"
+//	@Success		200		{object}	response.Response{data=dto.LoginResponse}
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		429		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Failure		503		{object}	response.Response
+//	@Router			/api/v1/auth/otp/login [post]
+func (h *AuthHandler) LoginWithPhone(c *gin.Context) {
+	lang := middleware.GetLangFromGin(c)
+
+	var req dto.PhoneLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
+			"body": {err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if errors := req.Validate(lang); len(errors) > 0 {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), errors)
+		return
+	}
+
+	loginResp, err := h.authUsecase.LoginWithPhone(c.Request.Context(), req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.NewResponse(c, http.StatusOK, loginResp, "Login successful", nil)
+}
+
+// RequestEmailChange handles starting an email change
+//
+//	@Summary		Request email change
+//	@Description	Send a confirmation link to the new address and a notice with a cancel link to the current one. The email only changes once confirmed
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.EmailChangeRequest	true	"New email and current password"
+//	@Success		202		{object}	response.Response
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		409		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Failure		503		{object}	response.Response
+//	@Router			/api/v1/auth/email/change [post]
+func (h *AuthHandler) RequestEmailChange(c *gin.Context) {
+	lang := middleware.GetLangFromGin(c)
+
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.EmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
+			"body": {err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if errors := req.Validate(lang); len(errors) > 0 {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), errors)
+		return
+	}
+
+	if err := h.authUsecase.RequestEmailChange(c.Request.Context(), userID, req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.NewResponse(c, http.StatusAccepted, nil, "Confirmation link sent to the new email", nil)
+}
+
+// ConfirmEmailChange handles confirming an email change
+//
+//	@Summary		Confirm email change
+//	@Description	Apply an email change with the token from the link sent to the new address. Every issued access token is revoked, sign in again afterwards
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.EmailChangeTokenRequest	true	"Confirmation token"
+//	@Success		200		{object}	response.Response{data=dto.RegisterResponse}
+//	@Failure		400		{object}	response.Response
+//	@Failure		409		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Failure		503		{object}	response.Response
+//	@Router			/api/v1/auth/email/change/confirm [post]
+func (h *AuthHandler) ConfirmEmailChange(c *gin.Context) {
+	lang := middleware.GetLangFromGin(c)
+
+	var req dto.EmailChangeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
+			"body": {err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if errors := req.Validate(lang); len(errors) > 0 {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), errors)
+		return
+	}
+
+	user, err := h.authUsecase.ConfirmEmailChange(c.Request.Context(), req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.NewResponse(c, http.StatusOK, user, "Email changed successfully", nil)
+}
+
+// CancelEmailChange handles cancelling an email change
+//
+//	@Summary		Cancel email change
+//	@Description	Drop a pending email change with the token from the link sent to the current address
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.EmailChangeTokenRequest	true	"Cancel token"
+//	@Success		200		{object}	response.Response
+//	@Failure		400		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Failure		503		{object}	response.Response
+//	@Router			/api/v1/auth/email/change/cancel [post]
+func (h *AuthHandler) CancelEmailChange(c *gin.Context) {
+	lang := middleware.GetLangFromGin(c)
+
+	var req dto.EmailChangeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
+			"body": {err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if errors := req.Validate(lang); len(errors) > 0 {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), errors)
+		return
+	}
+
+	if err := h.authUsecase.CancelEmailChange(c.Request.Context(), req); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.NewResponse(c, http.StatusOK, nil, "Email change cancelled", nil)
+}
+
+// authenticatedUserID returns the user ID from the session claims, responding
+// 401 when the request carries none
+func authenticatedUserID(c *gin.Context) (string, bool) {
+	claimsVal, _ := c.Get("sess")
+	claims, ok := claimsVal.(*jwt.Claims)
+	if !ok {
+		response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, middleware.GetLangFromGin(c)), nil)
+		return "", false
+	}
+	return claims.UserID, true
 }
@@ -5,9 +5,10 @@ import (
 	mocks "app/internal/mocks/usecase"
 	"app/internal/shared/constants"
 	"app/internal/shared/delivery/http/middleware"
+	domainerror "app/internal/shared/domain/error"
+	"app/pkg/jwt"
 	"bytes"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -20,7 +21,9 @@ import (
 
 func setupTestRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
-	return gin.New()
+	router := gin.New()
+	router.Use(middleware.ErrorMiddleware())
+	return router
 }
 
 func setupGinContext(router *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
@@ -58,7 +61,7 @@ func TestRegister_Success(t *testing.T) {
 
 	mockUsecase.EXPECT().
 		Register(mock.Anything, reqBody).
-		Return(expectedUser, http.StatusCreated, nil)
+		Return(expectedUser, nil)
 
 	body, _ := json.Marshal(reqBody)
 	req, _ := http.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(body))
@@ -117,6 +120,84 @@ func TestRegister_ValidationError(t *testing.T) {
 	assert.NotNil(t, response["errors"])
 }
 
+func TestRegister_ProfileValidationError(t *testing.T) {
+	mockUsecase := mocks.NewMockAuthUsecase(t)
+	handler := NewAuthHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/register", setLanguageMiddleware, handler.Register)
+
+	reqBody := authdto.RegisterRequest{
+		Email:     "test@example.com",
+		Username:  "testuser",
+		Password:  "password123",
+		FirstName: "Test",
+		LastName:  "User",
+		Phone:     "0812", // Not an international number
+		BirthDate: "2999-01-01",
+		Gender:    "unknown",
+	}
+
+	body, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response map[string]any
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	errs := response["errors"].(map[string]any)
+	assert.Equal(t, []any{constants.GetValidationMessage(constants.InvalidPhone, constants.LangEN)}, errs["phone"])
+	assert.Equal(t, []any{constants.GetValidationMessage(constants.InvalidBirthDate, constants.LangEN)}, errs["birth_date"])
+	assert.Equal(t, []any{"gender must be one of: male, female, other"}, errs["gender"])
+}
+
+func TestRegister_UsernameRules(t *testing.T) {
+	tests := []struct {
+		username string
+		code     constants.ValidationCode
+	}{
+		{"test user", constants.InvalidUsername},
+		{"_testuser", constants.InvalidUsername},
+		{"admin", constants.ReservedUsername},
+		{"\u0430dm\u0456n", constants.ReservedUsername}, // Cyrillic а and і
+	}
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			mockUsecase := mocks.NewMockAuthUsecase(t)
+			handler := NewAuthHandler(mockUsecase)
+
+			router := setupTestRouter()
+			router.POST("/register", setLanguageMiddleware, handler.Register)
+
+			reqBody := authdto.RegisterRequest{
+				Email:     "test@example.com",
+				Username:  tt.username,
+				Password:  "password123",
+				FirstName: "Test",
+				LastName:  "User",
+			}
+
+			body, _ := json.Marshal(reqBody)
+			req, _ := http.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := setupGinContext(router, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response map[string]any
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			require.NoError(t, err)
+			errs := response["errors"].(map[string]any)
+			assert.Equal(t, []any{constants.GetValidationMessage(tt.code, constants.LangEN)}, errs["username"])
+		})
+	}
+}
+
 func TestRegister_UsecaseError(t *testing.T) {
 	mockUsecase := mocks.NewMockAuthUsecase(t)
 	handler := NewAuthHandler(mockUsecase)
@@ -134,7 +215,7 @@ func TestRegister_UsecaseError(t *testing.T) {
 
 	mockUsecase.EXPECT().
 		Register(mock.Anything, reqBody).
-		Return(nil, http.StatusBadRequest, errors.New("user already exists"))
+		Return(nil, domainerror.New(domainerror.KindConflict, constants.UserAlreadyExists, nil).WithField("email"))
 
 	body, _ := json.Marshal(reqBody)
 	req, _ := http.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(body))
@@ -142,12 +223,14 @@ func TestRegister_UsecaseError(t *testing.T) {
 
 	w := setupGinContext(router, req)
 
-	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, http.StatusConflict, w.Code)
 
 	var response map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	require.NoError(t, err)
 	assert.True(t, response["error"].(bool))
+	assert.Equal(t, "AUTH_USER_ALREADY_EXISTS", response["code"])
+	assert.Equal(t, map[string]interface{}{"email": []interface{}{"user already exists"}}, response["errors"])
 }
 
 func TestLogin_Success(t *testing.T) {
@@ -173,7 +256,7 @@ func TestLogin_Success(t *testing.T) {
 
 	mockUsecase.EXPECT().
 		Login(mock.Anything, reqBody).
-		Return(expectedResponse, http.StatusOK, nil)
+		Return(expectedResponse, nil)
 
 	body, _ := json.Marshal(reqBody)
 	req, _ := http.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(body))
@@ -246,7 +329,7 @@ func TestLogin_UsecaseError(t *testing.T) {
 
 	mockUsecase.EXPECT().
 		Login(mock.Anything, reqBody).
-		Return(nil, http.StatusUnauthorized, errors.New("invalid credentials"))
+		Return(nil, domainerror.New(domainerror.KindUnauthorized, constants.InvalidCredentials, nil))
 
 	body, _ := json.Marshal(reqBody)
 	req, _ := http.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(body))
@@ -261,3 +344,327 @@ func TestLogin_UsecaseError(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, response["error"].(bool))
 }
+
+func TestLogin_UsecaseErrorCode(t *testing.T) {
+	mockUsecase := mocks.NewMockAuthUsecase(t)
+	handler := NewAuthHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/login", setLanguageMiddleware, handler.Login)
+
+	reqBody := authdto.LoginRequest{
+		Email:    "test@example.com",
+		Password: "wrongpassword",
+	}
+
+	mockUsecase.EXPECT().
+		Login(mock.Anything, reqBody).
+		Return(nil, domainerror.New(domainerror.KindUnauthorized, constants.InvalidCredentials, nil))
+
+	body, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Equal(t, "AUTH_INVALID_CREDENTIALS", response["code"])
+	assert.Equal(t, "invalid email or password", response["message"])
+}
+
+func TestLogin_UsecaseErrorProblemJSON(t *testing.T) {
+	mockUsecase := mocks.NewMockAuthUsecase(t)
+	handler := NewAuthHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/login", setLanguageMiddleware, handler.Login)
+
+	reqBody := authdto.LoginRequest{
+		Email:    "test@example.com",
+		Password: "wrongpassword",
+	}
+
+	mockUsecase.EXPECT().
+		Login(mock.Anything, reqBody).
+		Return(nil, domainerror.New(domainerror.KindUnauthorized, constants.InvalidCredentials, nil))
+
+	body, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/problem+json")
+
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+	var problem map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &problem)
+	require.NoError(t, err)
+	assert.Equal(t, "/problems/auth-invalid-credentials", problem["type"])
+	assert.Equal(t, "Unauthorized", problem["title"])
+	assert.Equal(t, "invalid email or password", problem["detail"])
+	assert.Equal(t, "/login", problem["instance"])
+	assert.Equal(t, "AUTH_INVALID_CREDENTIALS", problem["code"])
+}
+
+func TestRegister_ValidationErrorProblemJSON(t *testing.T) {
+	mockUsecase := mocks.NewMockAuthUsecase(t)
+	handler := NewAuthHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/register", setLanguageMiddleware, handler.Register)
+
+	reqBody := authdto.RegisterRequest{
+		Email:     "not-an-email",
+		Username:  "testuser",
+		Password:  "password123",
+		FirstName: "Test",
+		LastName:  "User",
+	}
+
+	body, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/problem+json")
+
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var problem map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &problem)
+	require.NoError(t, err)
+	assert.Equal(t, "VALIDATION_FAILED", problem["code"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "email", "reason": "invalid email format"},
+	}, problem["invalid_params"])
+}
+
+func setSession(c *gin.Context) {
+	c.Set("sess", &jwt.Claims{UserID: "user-123"})
+}
+
+func TestRequestPhoneVerification_Accepted(t *testing.T) {
+	mockUsecase := mocks.NewMockAuthUsecase(t)
+	handler := NewAuthHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/phone/verification", setLanguageMiddleware, setSession, handler.RequestPhoneVerification)
+
+	mockUsecase.EXPECT().RequestPhoneVerification(mock.Anything, "user-123").Return(nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/phone/verification", nil)
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+}
+
+func TestRequestPhoneVerification_NoSession(t *testing.T) {
+	mockUsecase := mocks.NewMockAuthUsecase(t)
+	handler := NewAuthHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/phone/verification", setLanguageMiddleware, handler.RequestPhoneVerification)
+
+	req, _ := http.NewRequest(http.MethodPost, "/phone/verification", nil)
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestConfirmPhoneVerification_ValidationError(t *testing.T) {
+	mockUsecase := mocks.NewMockAuthUsecase(t)
+	handler := NewAuthHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/phone/verification/confirm", setLanguageMiddleware, setSession, handler.ConfirmPhoneVerification)
+
+	req, _ := http.NewRequest(http.MethodPost, "/phone/verification/confirm", bytes.NewBufferString(`{"code":"12ab"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Contains(t, response["errors"], "code")
+}
+
+func TestConfirmPhoneVerification_AttemptsExceeded(t *testing.T) {
+	mockUsecase := mocks.NewMockAuthUsecase(t)
+	handler := NewAuthHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/phone/verification/confirm", setLanguageMiddleware, setSession, handler.ConfirmPhoneVerification)
+
+	mockUsecase.EXPECT().
+		ConfirmPhoneVerification(mock.Anything, "user-123", authdto.ConfirmPhoneRequest{Code: "123456"}).
+		Return(nil, domainerror.New(domainerror.KindTooManyRequests, constants.OTPAttemptsExceeded, nil))
+
+	req, _ := http.NewRequest(http.MethodPost, "/phone/verification/confirm", bytes.NewBufferString(`{"code":"123456"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestRequestLoginOTP_Accepted(t *testing.T) {
+	mockUsecase := mocks.NewMockAuthUsecase(t)
+	handler := NewAuthHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/otp", setLanguageMiddleware, handler.RequestLoginOTP)
+
+	mockUsecase.EXPECT().RequestLoginOTP(mock.Anything, authdto.PhoneOTPRequest{Phone: "+6281234567890"}).Return(nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/otp", bytes.NewBufferString(`{"phone":"+6281234567890"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+}
+
+func TestLoginWithPhone_Success(t *testing.T) {
+	mockUsecase := mocks.NewMockAuthUsecase(t)
+	handler := NewAuthHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/otp/login", setLanguageMiddleware, handler.LoginWithPhone)
+
+	reqBody := authdto.PhoneLoginRequest{Phone: "+6281234567890", Code: "123456"}
+	mockUsecase.EXPECT().
+		LoginWithPhone(mock.Anything, reqBody).
+		Return(&authdto.LoginResponse{User: &authdto.RegisterResponse{ID: "user-123"}, Token: "token"}, nil)
+
+	body, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest(http.MethodPost, "/otp/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestLogin_IdentifierValidationError(t *testing.T) {
+	mockUsecase := mocks.NewMockAuthUsecase(t)
+	handler := NewAuthHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/login", setLanguageMiddleware, handler.Login)
+
+	req, _ := http.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(`{"identifier":"john@","password":"password123"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Contains(t, response["errors"], "identifier")
+}
+
+func TestLogin_WithUsername(t *testing.T) {
+	mockUsecase := mocks.NewMockAuthUsecase(t)
+	handler := NewAuthHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/login", setLanguageMiddleware, handler.Login)
+
+	reqBody := authdto.LoginRequest{
+		Identifier: "testuser",
+		Password:   "password123",
+	}
+
+	mockUsecase.EXPECT().
+		Login(mock.Anything, reqBody).
+		Return(&authdto.LoginResponse{User: &authdto.RegisterResponse{ID: "user-123"}, Token: "jwt-token-here"}, nil)
+
+	body, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequestEmailChange_Accepted(t *testing.T) {
+	mockUsecase := mocks.NewMockAuthUsecase(t)
+	handler := NewAuthHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/email/change", setLanguageMiddleware, setSession, handler.RequestEmailChange)
+
+	mockUsecase.EXPECT().
+		RequestEmailChange(mock.Anything, "user-123", authdto.EmailChangeRequest{NewEmail: "new@example.com", Password: "password123"}).
+		Return(nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/email/change", bytes.NewBufferString(`{"new_email":"new@example.com","password":"password123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+}
+
+func TestRequestEmailChange_ValidationError(t *testing.T) {
+	mockUsecase := mocks.NewMockAuthUsecase(t)
+	handler := NewAuthHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/email/change", setLanguageMiddleware, setSession, handler.RequestEmailChange)
+
+	req, _ := http.NewRequest(http.MethodPost, "/email/change", bytes.NewBufferString(`{"new_email":"not-an-email"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response map[string]any
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	errs := response["errors"].(map[string]any)
+	assert.Contains(t, errs, "new_email")
+	assert.Contains(t, errs, "password")
+}
+
+func TestConfirmEmailChange_Success(t *testing.T) {
+	mockUsecase := mocks.NewMockAuthUsecase(t)
+	handler := NewAuthHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/email/change/confirm", setLanguageMiddleware, handler.ConfirmEmailChange)
+
+	mockUsecase.EXPECT().
+		ConfirmEmailChange(mock.Anything, authdto.EmailChangeTokenRequest{Token: "token"}).
+		Return(&authdto.RegisterResponse{ID: "user-123", Email: "new@example.com"}, nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/email/change/confirm", bytes.NewBufferString(`{"token":"token"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCancelEmailChange_InvalidToken(t *testing.T) {
+	mockUsecase := mocks.NewMockAuthUsecase(t)
+	handler := NewAuthHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/email/change/cancel", setLanguageMiddleware, handler.CancelEmailChange)
+
+	mockUsecase.EXPECT().
+		CancelEmailChange(mock.Anything, authdto.EmailChangeTokenRequest{Token: "stale"}).
+		Return(domainerror.New(domainerror.KindInvalidInput, constants.InvalidEmailChangeToken, nil).WithField("token"))
+
+	req, _ := http.NewRequest(http.MethodPost, "/email/change/cancel", bytes.NewBufferString(`{"token":"stale"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
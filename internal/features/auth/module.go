@@ -3,7 +3,12 @@ package auth
 import (
 	"app/internal/features/auth/delivery/http/handler"
 	"app/internal/features/auth/usecase"
+	"app/internal/shared/delivery/http/middleware"
+	"app/internal/shared/domain/entity"
 	"app/internal/shared/domain/repository"
+	"app/internal/shared/domain/service"
+	"app/pkg/crypto"
+	"context"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -11,16 +16,18 @@ import (
 
 // Module is the auth feature module that combines DI and route registration
 type Module struct {
+	usecase usecase.AuthUsecase
 	handler *handler.AuthHandler
+	auth    gin.HandlerFunc
 }
 
 // NewModule creates and wires all auth feature dependencies
-func NewModule(userRepo repository.UserRepository, logger *logrus.Logger) *Module {
+func NewModule(userRepo repository.UserRepository, otpRepo repository.PhoneOTPRepository, emailChangeRepo repository.EmailChangeRepository, transactor repository.Transactor, sms service.SMSSender, mailer service.EmailSender, hasher *crypto.PasswordHasher, logger *logrus.Logger) *Module {
 	// Wire dependencies
-	uc := usecase.NewAuthUsecase(userRepo, logger)
+	uc := usecase.NewAuthUsecase(userRepo, otpRepo, emailChangeRepo, transactor, sms, mailer, hasher, logger)
 	h := handler.NewAuthHandler(uc)
 
-	return &Module{handler: h}
+	return &Module{usecase: uc, handler: h, auth: middleware.AuthMiddleware(userRepo)}
 }
 
 // Name returns the feature name
@@ -28,12 +35,38 @@ func (m *Module) Name() string {
 	return "auth"
 }
 
+// ExportUserData contributes the pending email change to data exports
+func (m *Module) ExportUserData(ctx context.Context, userID string) (map[string]any, error) {
+	return m.usecase.ExportUserData(ctx, userID)
+}
+
+// AnonymizeUserData erases the pending email change and SMS codes when the
+// account is anonymized
+func (m *Module) AnonymizeUserData(ctx context.Context, userID string, dryRun bool) ([]service.AnonymizedRecords, error) {
+	return m.usecase.AnonymizeUserData(ctx, userID, dryRun)
+}
+
+// Models returns the entities the auth feature stores, checked against the schema
+func (m *Module) Models() []any {
+	return []any{&entity.PhoneOTP{}, &entity.EmailChange{}}
+}
+
 // RegisterRoutes registers all auth routes
 func (m *Module) RegisterRoutes(rg *gin.RouterGroup) {
-	// Auth routes - all public (no auth required)
 	authGroup := rg.Group("/auth")
 	{
+		// Public routes
 		authGroup.POST("/register", m.handler.Register)
 		authGroup.POST("/login", m.handler.Login)
+		authGroup.POST("/otp", m.handler.RequestLoginOTP)
+		authGroup.POST("/otp/login", m.handler.LoginWithPhone)
+		authGroup.POST("/email/change/confirm", m.handler.ConfirmEmailChange)
+		authGroup.POST("/email/change/cancel", m.handler.CancelEmailChange)
+
+		// Protected routes - auth middleware applied inline
+		authGroup.POST("/phone/verification", m.auth, m.handler.RequestPhoneVerification)
+		authGroup.POST("/phone/verification/confirm", m.auth, m.handler.ConfirmPhoneVerification)
+		authGroup.PUT("/two-factor", m.auth, m.handler.SetTwoFactor)
+		authGroup.POST("/email/change", m.auth, m.handler.RequestEmailChange)
 	}
 }
@@ -1,108 +1,256 @@
 package usecase
 
 import (
+	"app/internal/core/config"
 	"app/internal/features/auth/delivery/http/dto"
 	"app/internal/shared/constants"
-	"app/internal/shared/delivery/http/middleware"
 	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
 	"app/internal/shared/domain/repository"
+	"app/internal/shared/domain/service"
 	"app/pkg/crypto"
 	"app/pkg/jwt"
 	"context"
-	"net/http"
+	"errors"
 
 	"github.com/sirupsen/logrus"
 )
 
 // AuthUsecase defines the interface for authentication use cases
 type AuthUsecase interface {
-	Register(ctx context.Context, req dto.RegisterRequest) (*dto.RegisterResponse, int, error)
-	Login(ctx context.Context, req dto.LoginRequest) (*dto.LoginResponse, int, error)
+	Register(ctx context.Context, req dto.RegisterRequest) (*dto.RegisterResponse, error)
+	Login(ctx context.Context, req dto.LoginRequest) (*dto.LoginResponse, error)
+	RequestPhoneVerification(ctx context.Context, userID string) error
+	ConfirmPhoneVerification(ctx context.Context, userID string, req dto.ConfirmPhoneRequest) (*dto.RegisterResponse, error)
+	SetTwoFactor(ctx context.Context, userID string, req dto.TwoFactorRequest) (*dto.RegisterResponse, error)
+	RequestLoginOTP(ctx context.Context, req dto.PhoneOTPRequest) error
+	LoginWithPhone(ctx context.Context, req dto.PhoneLoginRequest) (*dto.LoginResponse, error)
+	RequestEmailChange(ctx context.Context, userID string, req dto.EmailChangeRequest) error
+	ConfirmEmailChange(ctx context.Context, req dto.EmailChangeTokenRequest) (*dto.RegisterResponse, error)
+	CancelEmailChange(ctx context.Context, req dto.EmailChangeTokenRequest) error
+	ExportUserData(ctx context.Context, userID string) (map[string]any, error)
+	AnonymizeUserData(ctx context.Context, userID string, dryRun bool) ([]service.AnonymizedRecords, error)
 }
 
 // authUsecase implements AuthUsecase interface
 type authUsecase struct {
-	userRepo repository.UserRepository
-	logger   *logrus.Logger
+	userRepo        repository.UserRepository
+	otpRepo         repository.PhoneOTPRepository
+	emailChangeRepo repository.EmailChangeRepository
+	transactor      repository.Transactor
+	sms             service.SMSSender
+	mailer          service.EmailSender
+	otp             config.OTPConfig
+	email           config.EmailConfig
+	account         config.AccountConfig
+	hasher          *crypto.PasswordHasher
+	logger          *logrus.Logger
 }
 
 // NewAuthUsecase creates a new auth usecase
-func NewAuthUsecase(userRepo repository.UserRepository, logger *logrus.Logger) AuthUsecase {
+func NewAuthUsecase(userRepo repository.UserRepository, otpRepo repository.PhoneOTPRepository, emailChangeRepo repository.EmailChangeRepository, transactor repository.Transactor, sms service.SMSSender, mailer service.EmailSender, hasher *crypto.PasswordHasher, logger *logrus.Logger) AuthUsecase {
+	cfg := config.Load()
 	return &authUsecase{
-		userRepo: userRepo,
-		logger:   logger,
+		userRepo:        userRepo,
+		otpRepo:         otpRepo,
+		emailChangeRepo: emailChangeRepo,
+		transactor:      transactor,
+		sms:             sms,
+		mailer:          mailer,
+		otp:             cfg.OTP,
+		email:           cfg.Email,
+		account:         cfg.Account,
+		hasher:          hasher,
+		logger:          logger,
 	}
 }
 
 // Register creates a new user
-func (a *authUsecase) Register(ctx context.Context, req dto.RegisterRequest) (*dto.RegisterResponse, int, error) {
-	lang := middleware.GetLangFromContext(ctx)
-
-	// Check if user already exists by email
-	existingUser, _ := a.userRepo.GetByEmail(ctx, req.Email)
-	if existingUser != nil {
-		a.logger.Error("a.userRepo.GetByEmail: user already exists")
-		return nil, http.StatusBadRequest, constants.GetError(constants.UserAlreadyExists, lang)
-	}
-
-	// Check if username is taken
-	existingUser, _ = a.userRepo.GetByUsername(ctx, req.Username)
-	if existingUser != nil {
-		a.logger.Error("a.userRepo.GetByUsername: username already taken")
-		return nil, http.StatusBadRequest, constants.GetError(constants.UsernameAlreadyTaken, lang)
-	}
-
+func (a *authUsecase) Register(ctx context.Context, req dto.RegisterRequest) (*dto.RegisterResponse, error) {
 	// Hash password
-	hashedPassword, err := crypto.HashPassword(req.Password)
+	hashedPassword, err := a.hasher.Hash(ctx, req.Password)
 	if err != nil {
-		a.logger.Error("crypto.HashPassword ", err)
-		return nil, http.StatusInternalServerError, constants.GetError(constants.FailedToHashPassword, lang)
+		a.logger.Error("a.hasher.Hash ", err)
+		if busy := hasherBusyError(err); busy != nil {
+			return nil, busy
+		}
+		return nil, domainerror.New(domainerror.KindInternal, constants.FailedToHashPassword, err)
 	}
 
 	// Create user entity using shared entity
 	user := entity.NewUser(req.Email, req.Username, hashedPassword, req.FirstName, req.LastName)
+	req.ApplyProfile(user)
 
-	// Save user
-	if err := a.userRepo.Create(ctx, user); err != nil {
+	// Save user - exact uniqueness is enforced by the database indexes rather than
+	// a lookup beforehand, so concurrent registrations cannot both succeed. Lookalike
+	// and held usernames have no index and are checked first
+	err = a.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		taken, err := a.userRepo.IsUsernameTaken(ctx, user.Username, user.ID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return &domainerror.ConflictError{Field: "username"}
+		}
+		return a.userRepo.Create(ctx, user)
+	})
+	if err != nil {
 		a.logger.Error("a.userRepo.Create ", err)
-		return nil, http.StatusInternalServerError, constants.GetError(constants.FailedToCreateUser, lang)
+		var conflict *domainerror.ConflictError
+		if errors.As(err, &conflict) {
+			return nil, conflictError(conflict.Field)
+		}
+		return nil, domainerror.Internal(constants.FailedToCreateUser, err)
 	}
 
 	// Convert to DTO response
-	return dto.ToRegisterResponse(user), http.StatusCreated, nil
+	return dto.ToRegisterResponse(user), nil
 }
 
-// Login authenticates a user
-func (a *authUsecase) Login(ctx context.Context, req dto.LoginRequest) (*dto.LoginResponse, int, error) {
-	lang := middleware.GetLangFromContext(ctx)
-
-	// Get user by email
-	user, err := a.userRepo.GetByEmail(ctx, req.Email)
+// Login authenticates a user by email or username and password
+func (a *authUsecase) Login(ctx context.Context, req dto.LoginRequest) (*dto.LoginResponse, error) {
+	// Get user by email or username - both lookups ignore case
+	user, err := a.findByIdentifier(ctx, req)
+	if errors.Is(err, domainerror.ErrUserNotFound) {
+		// An account scheduled for deletion can still log in to be reactivated
+		user, err = a.findDeletedByIdentifier(ctx, req)
+	}
 	if err != nil {
-		a.logger.Error("a.userRepo.GetByEmail ", err)
-		return nil, http.StatusUnauthorized, constants.GetError(constants.InvalidCredentials, lang)
+		if errors.Is(err, domainerror.ErrUserNotFound) {
+			// Burn a password verification so the response time does not reveal the account is missing
+			if busy := hasherBusyError(a.hasher.Verify(ctx, a.dummyPasswordHash(), req.Password)); busy != nil {
+				return nil, busy
+			}
+			return nil, domainerror.New(domainerror.KindUnauthorized, constants.InvalidCredentials, err)
+		}
+		return nil, domainerror.Internal(constants.SomethingWentWrong, err)
 	}
 
 	// Verify password
-	if err := crypto.VerifyPassword(user.Password, req.Password); err != nil {
-		a.logger.Error("crypto.VerifyPassword ", err)
-		return nil, http.StatusUnauthorized, constants.GetError(constants.InvalidCredentials, lang)
+	if err := a.hasher.Verify(ctx, user.Password, req.Password); err != nil {
+		a.logger.Error("a.hasher.Verify ", err)
+		if busy := hasherBusyError(err); busy != nil {
+			return nil, busy
+		}
+		return nil, domainerror.New(domainerror.KindUnauthorized, constants.InvalidCredentials, err)
 	}
 
+	// Second factor - the password alone is not enough
+	if user.TwoFactorEnabled {
+		if err := a.secondFactor(ctx, user, req.OTP); err != nil {
+			return nil, err
+		}
+	}
+
+	if user.DeletedAt.Valid {
+		if err := a.reactivate(ctx, user, req.Reactivate); err != nil {
+			return nil, err
+		}
+	}
+
+	a.rehashPassword(ctx, user, req.Password)
+	return a.issueToken(user)
+}
+
+// findDeletedByIdentifier looks up a deleted account still within its grace period,
+// returning ErrUserNotFound for one past it
+func (a *authUsecase) findDeletedByIdentifier(ctx context.Context, req dto.LoginRequest) (*entity.User, error) {
+	var user *entity.User
+	var err error
+	if req.IsEmail() {
+		user, err = a.userRepo.GetDeletedByEmail(ctx, req.LoginIdentifier())
+	} else {
+		user, err = a.userRepo.GetDeletedByUsername(ctx, req.LoginIdentifier())
+	}
+	if err != nil {
+		if !errors.Is(err, domainerror.ErrUserNotFound) {
+			a.logger.Error("a.userRepo.GetDeleted ", err)
+		}
+		return nil, err
+	}
+	if !user.Reactivatable(a.account.DeletionGracePeriod) {
+		return nil, domainerror.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// reactivate restores an account scheduled for deletion once its owner confirmed
+// it, the password and second factor being verified already
+func (a *authUsecase) reactivate(ctx context.Context, user *entity.User, confirmed bool) error {
+	if !confirmed {
+		return domainerror.New(domainerror.KindForbidden, constants.AccountPendingDeletion, nil).WithField("reactivate")
+	}
+
+	// Exact identities are guarded by the unique indexes, lookalikes of the username
+	// registered or held by someone else during the grace period are checked first
+	err := a.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		taken, err := a.userRepo.IsUsernameTaken(ctx, user.Username, user.ID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return &domainerror.ConflictError{Field: "username"}
+		}
+		return a.userRepo.Restore(ctx, user.ID)
+	})
+	if err != nil {
+		a.logger.Error("a.userRepo.Restore ", err)
+		var conflict *domainerror.ConflictError
+		if errors.As(err, &conflict) {
+			return conflictError(conflict.Field)
+		}
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+	user.DeletedAt.Valid = false
+	return nil
+}
+
+// findByIdentifier looks the user up by email when the identifier is one, by username otherwise
+func (a *authUsecase) findByIdentifier(ctx context.Context, req dto.LoginRequest) (*entity.User, error) {
+	if req.IsEmail() {
+		user, err := a.userRepo.GetByEmail(ctx, req.LoginIdentifier())
+		if err != nil {
+			a.logger.Error("a.userRepo.GetByEmail ", err)
+		}
+		return user, err
+	}
+
+	user, err := a.userRepo.GetByUsername(ctx, req.LoginIdentifier())
+	if err != nil {
+		a.logger.Error("a.userRepo.GetByUsername ", err)
+	}
+	return user, err
+}
+
+// issueToken generates the access token for an authenticated user
+func (a *authUsecase) issueToken(user *entity.User) (*dto.LoginResponse, error) {
 	// Generate token with string UUID
 	token, err := jwt.GenerateToken(jwt.UserPayload{
-		ID:       user.ID,
-		Email:    user.Email,
-		Username: user.Username,
+		ID:           user.ID,
+		Email:        user.Email,
+		Username:     user.Username,
+		TokenVersion: user.TokenVersion,
 	})
 	if err != nil {
 		a.logger.Error("jwt.GenerateToken ", err)
-		return nil, http.StatusInternalServerError, constants.GetError(constants.FailedToGenerateToken, lang)
+		return nil, domainerror.New(domainerror.KindInternal, constants.FailedToGenerateToken, err)
 	}
 
 	// Convert to DTO response
 	return &dto.LoginResponse{
 		User:  dto.ToRegisterResponse(user),
 		Token: token,
-	}, http.StatusOK, nil
+	}, nil
+}
+
+// conflictError maps a uniqueness conflict on field to its domain error
+func conflictError(field string) *domainerror.Error {
+	switch field {
+	case "email":
+		return domainerror.New(domainerror.KindConflict, constants.EmailAlreadyRegistered, nil).WithField(field)
+	case "username":
+		return domainerror.New(domainerror.KindConflict, constants.UsernameAlreadyTaken, nil).WithField(field)
+	default:
+		return domainerror.New(domainerror.KindConflict, constants.UserAlreadyExists, nil).WithField(field)
+	}
 }
@@ -6,17 +6,23 @@ import (
 	"app/internal/shared/constants"
 	"app/internal/shared/delivery/http/middleware"
 	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/testutil"
 	"app/pkg/crypto"
 	"context"
 	"errors"
-	"net/http"
+	"fmt"
+	"io"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 func TestMain(m *testing.M) {
@@ -26,14 +32,22 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
+// testPasswordParams are cheap Argon2id parameters, hashes made with them are current
+var testPasswordParams = crypto.PasswordParams{Algorithm: crypto.AlgorithmArgon2id, Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+// testHasher hashes with testPasswordParams
+var testHasher = crypto.NewPasswordHasher(testPasswordParams, crypto.HasherConfig{QueueSize: 64})
+
 func setupTest(t *testing.T) (*authUsecase, *mocks.MockUserRepository) {
 	mockRepo := mocks.NewMockUserRepository(t)
 	logger := logrus.New()
 	logger.SetOutput(os.Stderr)
 
 	uc := &authUsecase{
-		userRepo: mockRepo,
-		logger:   logger,
+		userRepo:   mockRepo,
+		transactor: testutil.InlineTransactor{},
+		hasher:     testHasher,
+		logger:     logger,
 	}
 
 	return uc, mockRepo
@@ -43,59 +57,120 @@ func createTestContext() context.Context {
 	return context.WithValue(context.Background(), middleware.LangKey, constants.LangEN)
 }
 
-func TestRegister_Success(t *testing.T) {
-	uc, mockRepo := setupTest(t)
-	ctx := createTestContext()
-
-	req := dto.RegisterRequest{
+func newRegisterRequest() dto.RegisterRequest {
+	return dto.RegisterRequest{
 		Email:     "test@example.com",
 		Username:  "testuser",
 		Password:  "password123",
 		FirstName: "Test",
 		LastName:  "User",
 	}
+}
 
-	// Mock: email not found
-	mockRepo.EXPECT().GetByEmail(ctx, req.Email).Return(nil, errors.New("not found"))
-	// Mock: username not found
-	mockRepo.EXPECT().GetByUsername(ctx, req.Username).Return(nil, errors.New("not found"))
+func TestRegister_Success(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+	req := newRegisterRequest()
+
+	mockRepo.EXPECT().IsUsernameTaken(ctx, "testuser", mock.Anything).Return(false, nil)
 	// Mock: create user success
 	mockRepo.EXPECT().Create(ctx, mock.AnythingOfType("*entity.User")).Return(nil)
 
-	user, status, err := uc.Register(ctx, req)
+	user, err := uc.Register(ctx, req)
 
 	require.NoError(t, err)
-	assert.Equal(t, http.StatusCreated, status)
 	assert.NotNil(t, user)
 	assert.Equal(t, req.Email, user.Email)
 	assert.Equal(t, req.Username, user.Username)
 	// Password is not in the RegisterResponse DTO
 }
 
-func TestRegister_UserAlreadyExists(t *testing.T) {
+func TestRegister_HashesWithConfiguredParams(t *testing.T) {
 	uc, mockRepo := setupTest(t)
 	ctx := createTestContext()
 
-	req := dto.RegisterRequest{
-		Email:     "existing@example.com",
-		Username:  "testuser",
-		Password:  "password123",
-		FirstName: "Test",
-		LastName:  "User",
-	}
+	mockRepo.EXPECT().IsUsernameTaken(ctx, "testuser", mock.Anything).Return(false, nil)
+	mockRepo.EXPECT().Create(ctx, mock.MatchedBy(func(user *entity.User) bool {
+		return strings.HasPrefix(user.Password, "$argon2id$v=19$m=64,t=1,p=1$") &&
+			crypto.VerifyPassword(user.Password, "password123") == nil
+	})).Return(nil)
 
-	existingUser := &entity.User{
-		ID:    "existing-id",
-		Email: req.Email,
-	}
+	_, err := uc.Register(ctx, newRegisterRequest())
 
-	// Mock: email already exists
-	mockRepo.EXPECT().GetByEmail(ctx, req.Email).Return(existingUser, nil)
+	require.NoError(t, err)
+}
 
-	user, status, err := uc.Register(ctx, req)
+func TestRegister_UnknownAlgorithm(t *testing.T) {
+	uc, _ := setupTest(t)
+	uc.hasher = crypto.NewPasswordHasher(crypto.PasswordParams{Algorithm: "md5"}, crypto.HasherConfig{})
 
-	assert.Error(t, err)
-	assert.Equal(t, http.StatusBadRequest, status)
+	_, err := uc.Register(createTestContext(), newRegisterRequest())
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, constants.FailedToHashPassword, domainErr.Code)
+}
+
+func TestRegister_NormalizesProfile(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	req := newRegisterRequest()
+	req.Phone = "0062 812-3456-7890"
+	req.BirthDate = "1990-01-31"
+	req.Gender = "female"
+
+	mockRepo.EXPECT().IsUsernameTaken(ctx, "testuser", mock.Anything).Return(false, nil)
+	mockRepo.EXPECT().Create(ctx, mock.MatchedBy(func(user *entity.User) bool {
+		return user.Phone != nil && *user.Phone == "+6281234567890" &&
+			user.BirthDate != nil && user.BirthDate.Format(time.DateOnly) == "1990-01-31" &&
+			user.Gender == "female"
+	})).Return(nil)
+
+	user, err := uc.Register(ctx, req)
+
+	require.NoError(t, err)
+	require.NotNil(t, user.Phone)
+	assert.Equal(t, "+6281234567890", *user.Phone)
+	require.NotNil(t, user.BirthDate)
+	assert.Equal(t, "1990-01-31", *user.BirthDate)
+}
+
+func TestRegister_RunsInTransaction(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	mockTx := mocks.NewMockTransactor(t)
+	uc.transactor = mockTx
+	ctx := createTestContext()
+	txCtx := context.WithValue(ctx, struct{}{}, "tx")
+
+	mockTx.EXPECT().WithinTransaction(ctx, mock.Anything).RunAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(txCtx)
+	})
+	// Mock: create must use the transaction context
+	mockRepo.EXPECT().IsUsernameTaken(txCtx, "testuser", mock.Anything).Return(false, nil)
+	mockRepo.EXPECT().Create(txCtx, mock.AnythingOfType("*entity.User")).Return(nil)
+
+	user, err := uc.Register(ctx, newRegisterRequest())
+
+	require.NoError(t, err)
+	assert.NotNil(t, user)
+}
+
+func TestRegister_EmailAlreadyRegistered(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().IsUsernameTaken(ctx, "testuser", mock.Anything).Return(false, nil)
+	// Mock: unique index rejects the email
+	mockRepo.EXPECT().Create(ctx, mock.AnythingOfType("*entity.User")).Return(&domainerror.ConflictError{Field: "email"})
+
+	user, err := uc.Register(ctx, newRegisterRequest())
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerror.KindConflict, domainErr.Kind)
+	assert.Equal(t, constants.EmailAlreadyRegistered, domainErr.Code)
+	assert.Equal(t, "email", domainErr.Field)
 	assert.Nil(t, user)
 }
 
@@ -103,28 +178,33 @@ func TestRegister_UsernameAlreadyTaken(t *testing.T) {
 	uc, mockRepo := setupTest(t)
 	ctx := createTestContext()
 
-	req := dto.RegisterRequest{
-		Email:     "test@example.com",
-		Username:  "existinguser",
-		Password:  "password123",
-		FirstName: "Test",
-		LastName:  "User",
-	}
+	mockRepo.EXPECT().IsUsernameTaken(ctx, "testuser", mock.Anything).Return(false, nil)
+	// Mock: unique index rejects the username
+	mockRepo.EXPECT().Create(ctx, mock.AnythingOfType("*entity.User")).Return(&domainerror.ConflictError{Field: "username"})
 
-	existingUser := &entity.User{
-		ID:       "existing-id",
-		Username: req.Username,
-	}
+	user, err := uc.Register(ctx, newRegisterRequest())
 
-	// Mock: email not found
-	mockRepo.EXPECT().GetByEmail(ctx, req.Email).Return(nil, errors.New("not found"))
-	// Mock: username already exists
-	mockRepo.EXPECT().GetByUsername(ctx, req.Username).Return(existingUser, nil)
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerror.KindConflict, domainErr.Kind)
+	assert.Equal(t, constants.UsernameAlreadyTaken, domainErr.Code)
+	assert.Equal(t, "username", domainErr.Field)
+	assert.Nil(t, user)
+}
 
-	user, status, err := uc.Register(ctx, req)
+func TestRegister_UsernameLooksTaken(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
 
-	assert.Error(t, err)
-	assert.Equal(t, http.StatusBadRequest, status)
+	// Mock: another user has a lookalike or held username, nothing is created
+	mockRepo.EXPECT().IsUsernameTaken(ctx, "testuser", mock.Anything).Return(true, nil)
+
+	user, err := uc.Register(ctx, newRegisterRequest())
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, constants.UsernameAlreadyTaken, domainErr.Code)
+	assert.Equal(t, "username", domainErr.Field)
 	assert.Nil(t, user)
 }
 
@@ -132,25 +212,29 @@ func TestRegister_CreateUserError(t *testing.T) {
 	uc, mockRepo := setupTest(t)
 	ctx := createTestContext()
 
-	req := dto.RegisterRequest{
-		Email:     "test@example.com",
-		Username:  "testuser",
-		Password:  "password123",
-		FirstName: "Test",
-		LastName:  "User",
-	}
-
-	// Mock: email not found
-	mockRepo.EXPECT().GetByEmail(ctx, req.Email).Return(nil, errors.New("not found"))
-	// Mock: username not found
-	mockRepo.EXPECT().GetByUsername(ctx, req.Username).Return(nil, errors.New("not found"))
+	mockRepo.EXPECT().IsUsernameTaken(ctx, "testuser", mock.Anything).Return(false, nil)
 	// Mock: create user fails
 	mockRepo.EXPECT().Create(ctx, mock.AnythingOfType("*entity.User")).Return(errors.New("database error"))
 
-	user, status, err := uc.Register(ctx, req)
+	user, err := uc.Register(ctx, newRegisterRequest())
+
+	assert.Error(t, err)
+	assert.Equal(t, domainerror.KindInternal, domainerror.KindOf(err))
+	assert.Nil(t, user)
+}
+
+func TestRegister_DatabaseUnavailable(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().IsUsernameTaken(ctx, "testuser", mock.Anything).Return(false, nil)
+	// Mock: database outage
+	mockRepo.EXPECT().Create(ctx, mock.AnythingOfType("*entity.User")).Return(fmt.Errorf("%w: connection refused", domainerror.ErrServiceUnavailable))
+
+	user, err := uc.Register(ctx, newRegisterRequest())
 
 	assert.Error(t, err)
-	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, domainerror.KindUnavailable, domainerror.KindOf(err))
 	assert.Nil(t, user)
 }
 
@@ -159,7 +243,7 @@ func TestLogin_Success(t *testing.T) {
 	ctx := createTestContext()
 
 	password := "password123"
-	hashedPassword, err := crypto.HashPassword(password)
+	hashedPassword, err := crypto.HashPasswordWithParams(password, testPasswordParams)
 	require.NoError(t, err)
 
 	req := dto.LoginRequest{
@@ -177,10 +261,9 @@ func TestLogin_Success(t *testing.T) {
 	// Mock: get user by email success
 	mockRepo.EXPECT().GetByEmail(ctx, req.Email).Return(existingUser, nil)
 
-	loginResp, status, err := uc.Login(ctx, req)
+	loginResp, err := uc.Login(ctx, req)
 
 	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, status)
 	assert.NotNil(t, loginResp)
 	assert.NotEmpty(t, loginResp.Token)
 	// Password is not in the RegisterResponse DTO
@@ -196,12 +279,13 @@ func TestLogin_UserNotFound(t *testing.T) {
 	}
 
 	// Mock: user not found
-	mockRepo.EXPECT().GetByEmail(ctx, req.Email).Return(nil, errors.New("not found"))
+	mockRepo.EXPECT().GetByEmail(ctx, req.Email).Return(nil, domainerror.ErrUserNotFound)
+	mockRepo.EXPECT().GetDeletedByEmail(ctx, req.Email).Return(nil, domainerror.ErrUserNotFound)
 
-	loginResp, status, err := uc.Login(ctx, req)
+	loginResp, err := uc.Login(ctx, req)
 
 	assert.Error(t, err)
-	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, domainerror.KindUnauthorized, domainerror.KindOf(err))
 	assert.Nil(t, loginResp)
 }
 
@@ -210,7 +294,7 @@ func TestLogin_InvalidPassword(t *testing.T) {
 	ctx := createTestContext()
 
 	correctPassword := "correctpassword"
-	hashedPassword, err := crypto.HashPassword(correctPassword)
+	hashedPassword, err := crypto.HashPasswordWithParams(correctPassword, testPasswordParams)
 	require.NoError(t, err)
 
 	req := dto.LoginRequest{
@@ -228,9 +312,287 @@ func TestLogin_InvalidPassword(t *testing.T) {
 	// Mock: get user by email success
 	mockRepo.EXPECT().GetByEmail(ctx, req.Email).Return(existingUser, nil)
 
-	loginResp, status, err := uc.Login(ctx, req)
+	loginResp, err := uc.Login(ctx, req)
 
 	assert.Error(t, err)
-	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, domainerror.KindUnauthorized, domainerror.KindOf(err))
+	assert.Nil(t, loginResp)
+}
+
+func TestLogin_ByUsername(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	hashedPassword, err := crypto.HashPasswordWithParams("password123", testPasswordParams)
+	require.NoError(t, err)
+
+	existingUser := &entity.User{
+		ID:       "user-123",
+		Email:    "test@example.com",
+		Username: "testuser",
+		Password: hashedPassword,
+	}
+
+	// Mock: identifier without @ is looked up as a username
+	mockRepo.EXPECT().GetByUsername(ctx, "TestUser").Return(existingUser, nil)
+
+	loginResp, err := uc.Login(ctx, dto.LoginRequest{Identifier: " TestUser ", Password: "password123"})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, loginResp.Token)
+}
+
+func TestLogin_ByEmailIdentifier(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	hashedPassword, err := crypto.HashPasswordWithParams("password123", testPasswordParams)
+	require.NoError(t, err)
+
+	existingUser := &entity.User{
+		ID:       "user-123",
+		Email:    "test@example.com",
+		Username: "testuser",
+		Password: hashedPassword,
+	}
+
+	// Mock: identifier takes precedence over the deprecated email field
+	mockRepo.EXPECT().GetByEmail(ctx, "Test@Example.com").Return(existingUser, nil)
+
+	loginResp, err := uc.Login(ctx, dto.LoginRequest{Identifier: "Test@Example.com", Email: "other@example.com", Password: "password123"})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, loginResp.Token)
+}
+
+func TestLogin_RehashesOutdatedHash(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	bcryptHash, err := crypto.HashPasswordWithCost("password123", 4)
+	require.NoError(t, err)
+	existingUser := &entity.User{ID: "user-123", Email: "test@example.com", Username: "testuser", Password: bcryptHash}
+
+	mockRepo.EXPECT().GetByUsername(ctx, "testuser").Return(existingUser, nil)
+	// Swapped for an Argon2id hash, conditional on the bcrypt one
+	mockRepo.EXPECT().UpdatePassword(ctx, "user-123", bcryptHash, mock.MatchedBy(func(hash string) bool {
+		return !crypto.NeedsRehash(hash, testPasswordParams) && crypto.VerifyPassword(hash, "password123") == nil
+	})).Return(nil)
+
+	loginResp, err := uc.Login(ctx, dto.LoginRequest{Identifier: "testuser", Password: "password123"})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, loginResp.Token)
+	assert.False(t, crypto.NeedsRehash(existingUser.Password, testPasswordParams))
+}
+
+func TestLogin_RehashesOutdatedParams(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	weaker := testPasswordParams
+	weaker.Iterations = 1
+	weaker.Memory = 32
+	hash, err := crypto.HashPasswordWithParams("password123", weaker)
+	require.NoError(t, err)
+
+	mockRepo.EXPECT().GetByUsername(ctx, "testuser").Return(&entity.User{ID: "user-123", Password: hash}, nil)
+	mockRepo.EXPECT().UpdatePassword(ctx, "user-123", hash, mock.AnythingOfType("string")).Return(nil)
+
+	_, err = uc.Login(ctx, dto.LoginRequest{Identifier: "testuser", Password: "password123"})
+
+	require.NoError(t, err)
+}
+
+func TestLogin_RehashFailureStillLogsIn(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	uc.logger.SetOutput(io.Discard)
+	ctx := createTestContext()
+
+	bcryptHash, err := crypto.HashPasswordWithCost("password123", 4)
+	require.NoError(t, err)
+
+	mockRepo.EXPECT().GetByUsername(ctx, "testuser").Return(&entity.User{ID: "user-123", Password: bcryptHash}, nil)
+	// The password was changed meanwhile
+	mockRepo.EXPECT().UpdatePassword(ctx, "user-123", bcryptHash, mock.AnythingOfType("string")).Return(domainerror.ErrUserNotFound)
+
+	loginResp, err := uc.Login(ctx, dto.LoginRequest{Identifier: "testuser", Password: "password123"})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, loginResp.Token)
+}
+
+func TestLogin_WrongPasswordIsNotRehashed(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	bcryptHash, err := crypto.HashPasswordWithCost("password123", 4)
+	require.NoError(t, err)
+
+	mockRepo.EXPECT().GetByUsername(ctx, "testuser").Return(&entity.User{ID: "user-123", Password: bcryptHash}, nil)
+
+	_, err = uc.Login(ctx, dto.LoginRequest{Identifier: "testuser", Password: "wrong-password"})
+
+	assert.Equal(t, domainerror.KindUnauthorized, domainerror.KindOf(err))
+}
+
+// busyHasher returns a hasher whose only worker is taken and which queues nothing,
+// until the returned func is called
+func busyHasher(t *testing.T) (*crypto.PasswordHasher, func()) {
+	slow := crypto.DefaultPasswordParams
+	slow.Iterations = 10
+	hasher := crypto.NewPasswordHasher(slow, crypto.HasherConfig{Workers: 1})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = hasher.Hash(context.Background(), "password123")
+	}()
+	require.Eventually(t, func() bool { return hasher.Stats().Running == 1 }, time.Second, time.Millisecond)
+	return hasher, func() { <-done }
+}
+
+func TestLogin_HasherBusy(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+	hashedPassword, err := crypto.HashPasswordWithParams("password123", testPasswordParams)
+	require.NoError(t, err)
+	mockRepo.EXPECT().GetByUsername(ctx, "testuser").Return(&entity.User{ID: "user-123", Password: hashedPassword}, nil)
+
+	var wait func()
+	uc.hasher, wait = busyHasher(t)
+	defer wait()
+	loginResp, err := uc.Login(ctx, dto.LoginRequest{Identifier: "testuser", Password: "password123"})
+
+	assert.Nil(t, loginResp)
+	assert.Equal(t, domainerror.KindUnavailable, domainerror.KindOf(err))
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, constants.PasswordHasherBusy, domainErr.Code)
+}
+
+func TestRegister_HasherBusy(t *testing.T) {
+	uc, _ := setupTest(t)
+
+	var wait func()
+	uc.hasher, wait = busyHasher(t)
+	defer wait()
+	_, err := uc.Register(createTestContext(), newRegisterRequest())
+
+	assert.Equal(t, domainerror.KindUnavailable, domainerror.KindOf(err))
+}
+
+// newDeletedUser returns a user with password123 deleted the given time ago
+func newDeletedUser(t *testing.T, ago time.Duration) *entity.User {
+	hashedPassword, err := crypto.HashPasswordWithParams("password123", testPasswordParams)
+	require.NoError(t, err)
+
+	return &entity.User{
+		ID:        "user-123",
+		Email:     "test@example.com",
+		Username:  "testuser",
+		Password:  hashedPassword,
+		DeletedAt: gorm.DeletedAt{Time: time.Now().Add(-ago), Valid: true},
+	}
+}
+
+func TestLogin_PendingDeletionOffersReactivation(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	uc.account.DeletionGracePeriod = 30 * 24 * time.Hour
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByUsername(ctx, "testuser").Return(nil, domainerror.ErrUserNotFound)
+	mockRepo.EXPECT().GetDeletedByUsername(ctx, "testuser").Return(newDeletedUser(t, 24*time.Hour), nil)
+
+	loginResp, err := uc.Login(ctx, dto.LoginRequest{Identifier: "testuser", Password: "password123"})
+
+	assert.Nil(t, loginResp)
+	assert.Equal(t, domainerror.KindForbidden, domainerror.KindOf(err))
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, constants.AccountPendingDeletion, domainErr.Code)
+}
+
+func TestLogin_ReactivatesPendingDeletion(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	uc.account.DeletionGracePeriod = 30 * 24 * time.Hour
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByUsername(ctx, "testuser").Return(nil, domainerror.ErrUserNotFound)
+	mockRepo.EXPECT().GetDeletedByUsername(ctx, "testuser").Return(newDeletedUser(t, 24*time.Hour), nil)
+	mockRepo.EXPECT().IsUsernameTaken(ctx, "testuser", "user-123").Return(false, nil)
+	mockRepo.EXPECT().Restore(ctx, "user-123").Return(nil)
+
+	loginResp, err := uc.Login(ctx, dto.LoginRequest{Identifier: "testuser", Password: "password123", Reactivate: true})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, loginResp.Token)
+}
+
+func TestLogin_ReactivateLookalikeTaken(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	uc.account.DeletionGracePeriod = 30 * 24 * time.Hour
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByUsername(ctx, "testuser").Return(nil, domainerror.ErrUserNotFound)
+	mockRepo.EXPECT().GetDeletedByUsername(ctx, "testuser").Return(newDeletedUser(t, 24*time.Hour), nil)
+	// Someone registered a lookalike of the username during the grace period
+	mockRepo.EXPECT().IsUsernameTaken(ctx, "testuser", "user-123").Return(true, nil)
+
+	loginResp, err := uc.Login(ctx, dto.LoginRequest{Identifier: "testuser", Password: "password123", Reactivate: true})
+
+	assert.Nil(t, loginResp)
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerror.KindConflict, domainErr.Kind)
+	assert.Equal(t, "username", domainErr.Field)
+}
+
+func TestLogin_PendingDeletionWrongPassword(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	uc.account.DeletionGracePeriod = 30 * 24 * time.Hour
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByUsername(ctx, "testuser").Return(nil, domainerror.ErrUserNotFound)
+	mockRepo.EXPECT().GetDeletedByUsername(ctx, "testuser").Return(newDeletedUser(t, 24*time.Hour), nil)
+
+	// Nothing reveals the account is pending deletion without the password
+	loginResp, err := uc.Login(ctx, dto.LoginRequest{Identifier: "testuser", Password: "wrong-password", Reactivate: true})
+
+	assert.Nil(t, loginResp)
+	assert.Equal(t, domainerror.KindUnauthorized, domainerror.KindOf(err))
+}
+
+func TestLogin_DeletedPastGracePeriod(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	uc.account.DeletionGracePeriod = 30 * 24 * time.Hour
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByEmail(ctx, "test@example.com").Return(nil, domainerror.ErrUserNotFound)
+	mockRepo.EXPECT().GetDeletedByEmail(ctx, "test@example.com").Return(newDeletedUser(t, 31*24*time.Hour), nil)
+
+	loginResp, err := uc.Login(ctx, dto.LoginRequest{Identifier: "test@example.com", Password: "password123", Reactivate: true})
+
+	assert.Nil(t, loginResp)
+	assert.Equal(t, domainerror.KindUnauthorized, domainerror.KindOf(err))
+}
+
+func TestLogin_UnknownIdentifierVerifiesPassword(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	uc.hasher = crypto.NewPasswordHasher(crypto.DefaultPasswordParams, crypto.HasherConfig{})
+	ctx := createTestContext()
+	uc.dummyPasswordHash() // hash once up front, outside the measured login
+
+	mockRepo.EXPECT().GetByUsername(ctx, "nobody").Return(nil, domainerror.ErrUserNotFound)
+	mockRepo.EXPECT().GetDeletedByUsername(ctx, "nobody").Return(nil, domainerror.ErrUserNotFound)
+
+	start := time.Now()
+	loginResp, err := uc.Login(ctx, dto.LoginRequest{Identifier: "nobody", Password: "password123"})
+	elapsed := time.Since(start)
+
+	assert.Equal(t, domainerror.KindUnauthorized, domainerror.KindOf(err))
 	assert.Nil(t, loginResp)
+	// An Argon2id verification at the default parameters takes tens of milliseconds,
+	// a bare lookup miss a few microseconds
+	assert.Greater(t, elapsed, 10*time.Millisecond)
 }
@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"app/internal/features/auth/delivery/http/dto"
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/domain/service"
+	"app/pkg/crypto"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Emails of the email change flow
+const (
+	emailChangeConfirmSubject = "Confirm your new email address"
+	emailChangeConfirmMessage = "Follow this link to make %s the email of your account: %s\n\nThe link expires in %d hours."
+	emailChangeNoticeSubject  = "Your email address is being changed"
+	emailChangeNoticeMessage  = "A change of your account email to %s was requested. If it was not you, cancel it with this link: %s"
+)
+
+// RequestEmailChange starts a change of the user's email. The new address gets a
+// confirmation link and the current one a notice with a cancel link, the email
+// itself only changes once confirmed
+func (a *authUsecase) RequestEmailChange(ctx context.Context, userID string, req dto.EmailChangeRequest) error {
+	user, err := a.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := a.hasher.Verify(ctx, user.Password, req.Password); err != nil {
+		if busy := hasherBusyError(err); busy != nil {
+			return busy
+		}
+		return domainerror.New(domainerror.KindUnauthorized, constants.InvalidCredentials, err).WithField("password")
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if entity.NormalizeEmail(newEmail) == user.EmailNormalized {
+		return domainerror.New(domainerror.KindInvalidInput, constants.EmailUnchanged, nil).WithField("new_email")
+	}
+
+	// Fail early when the address is taken, confirming checks again
+	_, err = a.userRepo.GetByEmail(ctx, newEmail)
+	switch {
+	case err == nil:
+		return domainerror.New(domainerror.KindConflict, constants.EmailAlreadyRegistered, nil).WithField("new_email")
+	case !errors.Is(err, domainerror.ErrUserNotFound):
+		a.logger.Error("a.userRepo.GetByEmail ", err)
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+
+	token, err := crypto.GenerateToken()
+	if err != nil {
+		a.logger.Error("crypto.GenerateToken ", err)
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+	cancelToken, err := crypto.GenerateToken()
+	if err != nil {
+		a.logger.Error("crypto.GenerateToken ", err)
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+
+	change := entity.NewEmailChange(user, newEmail, a.email.ChangeTTL)
+	change.TokenHash = crypto.HashToken(token)
+	change.CancelTokenHash = crypto.HashToken(cancelToken)
+	if err := a.emailChangeRepo.Create(ctx, change); err != nil {
+		a.logger.Error("a.emailChangeRepo.Create ", err)
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+
+	hours := max(1, int(a.email.ChangeTTL.Hours()))
+	confirm := fmt.Sprintf(emailChangeConfirmMessage, newEmail, tokenLink(a.email.ConfirmURL, token), hours)
+	notice := fmt.Sprintf(emailChangeNoticeMessage, newEmail, tokenLink(a.email.CancelURL, cancelToken))
+	if err := a.mailer.Send(ctx, newEmail, emailChangeConfirmSubject, confirm); err != nil {
+		a.logger.Error("a.mailer.Send ", err)
+		a.discardEmailChange(ctx, change.ID)
+		return domainerror.New(domainerror.KindUnavailable, constants.FailedToSendEmail, err)
+	}
+	// The owner of the current address must be able to stop a takeover
+	if err := a.mailer.Send(ctx, user.Email, emailChangeNoticeSubject, notice); err != nil {
+		a.logger.Error("a.mailer.Send ", err)
+		a.discardEmailChange(ctx, change.ID)
+		return domainerror.New(domainerror.KindUnavailable, constants.FailedToSendEmail, err)
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange applies the change confirmed by token. The new email is
+// written conditioned on the user version and guarded by the unique indexes, so
+// an address taken meanwhile is rejected. Every issued token is revoked since
+// access tokens embed the email
+func (a *authUsecase) ConfirmEmailChange(ctx context.Context, req dto.EmailChangeTokenRequest) (*dto.RegisterResponse, error) {
+	change, err := a.pendingEmailChange(ctx, a.emailChangeRepo.GetByToken, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *entity.User
+	err = a.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = a.getUser(ctx, change.UserID)
+		if err != nil {
+			return err
+		}
+		// The email was changed another way since the link was sent
+		if user.Email != change.OldEmail {
+			return invalidEmailChangeToken(nil)
+		}
+
+		user.SetEmail(change.NewEmail)
+		user.TokenVersion++
+		if err := a.updateUser(ctx, user, "email", "email_normalized", "token_version"); err != nil {
+			return err
+		}
+
+		if err := a.emailChangeRepo.Delete(ctx, change.ID); err != nil {
+			a.logger.Error("a.emailChangeRepo.Delete ", err)
+			return domainerror.Internal(constants.SomethingWentWrong, err)
+		}
+		return nil
+	})
+	if err != nil {
+		// A stale change or a taken address can never succeed
+		var domainErr *domainerror.Error
+		if errors.As(err, &domainErr) && (domainErr.Code == constants.InvalidEmailChangeToken || domainErr.Code == constants.EmailAlreadyRegistered) {
+			a.discardEmailChange(ctx, change.ID)
+		}
+		return nil, err
+	}
+
+	return dto.ToRegisterResponse(user), nil
+}
+
+// CancelEmailChange drops the change cancelled by token
+func (a *authUsecase) CancelEmailChange(ctx context.Context, req dto.EmailChangeTokenRequest) error {
+	change, err := a.pendingEmailChange(ctx, a.emailChangeRepo.GetByCancelToken, req.Token)
+	if err != nil {
+		return err
+	}
+
+	if err := a.emailChangeRepo.Delete(ctx, change.ID); err != nil {
+		a.logger.Error("a.emailChangeRepo.Delete ", err)
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+	return nil
+}
+
+// pendingEmailChange looks the change up by the hash of token, an expired change is discarded
+func (a *authUsecase) pendingEmailChange(ctx context.Context, lookup func(context.Context, string) (*entity.EmailChange, error), token string) (*entity.EmailChange, error) {
+	change, err := lookup(ctx, crypto.HashToken(token))
+	if err != nil {
+		if errors.Is(err, domainerror.ErrEmailChangeNotFound) {
+			return nil, invalidEmailChangeToken(err)
+		}
+		a.logger.Error("a.emailChangeRepo.GetByToken ", err)
+		return nil, domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+	if change.Expired() {
+		a.discardEmailChange(ctx, change.ID)
+		return nil, invalidEmailChangeToken(nil)
+	}
+	return change, nil
+}
+
+// discardEmailChange deletes a change that can no longer be used, a failure only leaves it to expire
+func (a *authUsecase) discardEmailChange(ctx context.Context, id string) {
+	if err := a.emailChangeRepo.Delete(ctx, id); err != nil {
+		a.logger.Error("a.emailChangeRepo.Delete ", err)
+	}
+}
+
+// invalidEmailChangeToken reports an unknown, expired or stale email change link
+func invalidEmailChangeToken(cause error) *domainerror.Error {
+	return domainerror.New(domainerror.KindInvalidInput, constants.InvalidEmailChangeToken, cause).WithField("token")
+}
+
+// tokenLink appends token to base as the token query parameter
+func tokenLink(base, token string) string {
+	separator := "?"
+	if strings.Contains(base, "?") {
+		separator = "&"
+	}
+	return base + separator + "token=" + url.QueryEscape(token)
+}
+
+// ExportUserData returns the email change pending for the user, if any, for data
+// exports. Token hashes are left out
+func (a *authUsecase) ExportUserData(ctx context.Context, userID string) (map[string]any, error) {
+	change, err := a.emailChangeRepo.GetByUser(ctx, userID)
+	if errors.Is(err, domainerror.ErrEmailChangeNotFound) {
+		return map[string]any{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"pending_email_change": change}, nil
+}
+
+// AnonymizeUserData deletes the pending email change and SMS codes of the user,
+// both hold addresses or phone numbers
+func (a *authUsecase) AnonymizeUserData(ctx context.Context, userID string, dryRun bool) ([]service.AnonymizedRecords, error) {
+	changes, err := a.emailChangeRepo.DeleteByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	otps, err := a.otpRepo.DeleteByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return []service.AnonymizedRecords{
+		{Table: "email_changes", Action: service.AnonymizeDelete, Count: changes},
+		{Table: "phone_otps", Action: service.AnonymizeDelete, Count: otps},
+	}, nil
+}
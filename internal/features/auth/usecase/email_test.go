@@ -0,0 +1,277 @@
+package usecase
+
+import (
+	"app/internal/core/config"
+	"app/internal/features/auth/delivery/http/dto"
+	mocks "app/internal/mocks/repository"
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/infrastructure/email"
+	"app/internal/testutil"
+	"app/pkg/crypto"
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupEmailTest(t *testing.T) (*authUsecase, *mocks.MockUserRepository, *mocks.MockEmailChangeRepository, *email.MemorySender) {
+	mockRepo := mocks.NewMockUserRepository(t)
+	mockChangeRepo := mocks.NewMockEmailChangeRepository(t)
+	mailer := email.NewMemorySender()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	uc := &authUsecase{
+		userRepo:        mockRepo,
+		emailChangeRepo: mockChangeRepo,
+		transactor:      testutil.InlineTransactor{},
+		mailer:          mailer,
+		hasher:          testHasher,
+		email: config.EmailConfig{
+			ChangeTTL:  24 * time.Hour,
+			ConfirmURL: "https://app.example.com/email/confirm",
+			CancelURL:  "https://app.example.com/email/cancel",
+		},
+		logger: logger,
+	}
+
+	return uc, mockRepo, mockChangeRepo, mailer
+}
+
+func newEmailUser(t *testing.T) *entity.User {
+	hash, err := crypto.HashPassword("password123")
+	require.NoError(t, err)
+
+	user := &entity.User{ID: "user-123", Username: "testuser", Password: hash, Version: 3, TokenVersion: 1}
+	user.SetEmail("old@example.com")
+	return user
+}
+
+// sentToken extracts the token from the link in the latest email sent to address
+func sentToken(t *testing.T, mailer *email.MemorySender, address string) string {
+	msg, ok := mailer.Last(address)
+	require.True(t, ok, "no email sent to %s", address)
+	return regexp.MustCompile(`token=([A-Za-z0-9_-]+)`).FindStringSubmatch(msg.Body)[1]
+}
+
+func TestRequestEmailChange_SendsLinks(t *testing.T) {
+	uc, mockRepo, mockChangeRepo, mailer := setupEmailTest(t)
+	ctx := createTestContext()
+	user := newEmailUser(t)
+
+	var stored *entity.EmailChange
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+	mockRepo.EXPECT().GetByEmail(ctx, "new@example.com").Return(nil, domainerror.ErrUserNotFound)
+	mockChangeRepo.EXPECT().Create(ctx, mock.AnythingOfType("*entity.EmailChange")).RunAndReturn(func(ctx context.Context, change *entity.EmailChange) error {
+		stored = change
+		return nil
+	})
+
+	err := uc.RequestEmailChange(ctx, user.ID, dto.EmailChangeRequest{NewEmail: " new@example.com ", Password: "password123"})
+
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, "old@example.com", stored.OldEmail)
+	assert.Equal(t, "new@example.com", stored.NewEmail)
+	assert.Equal(t, crypto.HashToken(sentToken(t, mailer, "new@example.com")), stored.TokenHash)
+	assert.Equal(t, crypto.HashToken(sentToken(t, mailer, "old@example.com")), stored.CancelTokenHash)
+	assert.Contains(t, mailer.Messages()[0].Body, "https://app.example.com/email/confirm?token=")
+	assert.Contains(t, mailer.Messages()[1].Body, "https://app.example.com/email/cancel?token=")
+}
+
+func TestRequestEmailChange_WrongPassword(t *testing.T) {
+	uc, mockRepo, _, mailer := setupEmailTest(t)
+	ctx := createTestContext()
+	user := newEmailUser(t)
+
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+
+	err := uc.RequestEmailChange(ctx, user.ID, dto.EmailChangeRequest{NewEmail: "new@example.com", Password: "wrong"})
+
+	assert.Equal(t, domainerror.KindUnauthorized, domainerror.KindOf(err))
+	assert.Empty(t, mailer.Messages())
+}
+
+func TestRequestEmailChange_Unchanged(t *testing.T) {
+	uc, mockRepo, _, _ := setupEmailTest(t)
+	ctx := createTestContext()
+	user := newEmailUser(t)
+
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+
+	err := uc.RequestEmailChange(ctx, user.ID, dto.EmailChangeRequest{NewEmail: "OLD@example.com", Password: "password123"})
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, constants.EmailUnchanged, domainErr.Code)
+}
+
+func TestRequestEmailChange_EmailTaken(t *testing.T) {
+	uc, mockRepo, _, mailer := setupEmailTest(t)
+	ctx := createTestContext()
+	user := newEmailUser(t)
+
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+	mockRepo.EXPECT().GetByEmail(ctx, "new@example.com").Return(&entity.User{ID: "user-456"}, nil)
+
+	err := uc.RequestEmailChange(ctx, user.ID, dto.EmailChangeRequest{NewEmail: "new@example.com", Password: "password123"})
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerror.KindConflict, domainErr.Kind)
+	assert.Equal(t, constants.EmailAlreadyRegistered, domainErr.Code)
+	assert.Equal(t, "new_email", domainErr.Field)
+	assert.Empty(t, mailer.Messages())
+}
+
+func TestRequestEmailChange_SendFails(t *testing.T) {
+	uc, mockRepo, mockChangeRepo, mailer := setupEmailTest(t)
+	ctx := createTestContext()
+	user := newEmailUser(t)
+	mailer.FailWith(errors.New("smtp down"))
+
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+	mockRepo.EXPECT().GetByEmail(ctx, "new@example.com").Return(nil, domainerror.ErrUserNotFound)
+	mockChangeRepo.EXPECT().Create(ctx, mock.Anything).Return(nil)
+	// Mock: the undelivered change is dropped
+	mockChangeRepo.EXPECT().Delete(ctx, mock.Anything).Return(nil)
+
+	err := uc.RequestEmailChange(ctx, user.ID, dto.EmailChangeRequest{NewEmail: "new@example.com", Password: "password123"})
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerror.KindUnavailable, domainErr.Kind)
+	assert.Equal(t, constants.FailedToSendEmail, domainErr.Code)
+}
+
+// newPendingChange returns a stored change of the test user confirmed by token
+func newPendingChange(token string) *entity.EmailChange {
+	change := entity.NewEmailChange(&entity.User{ID: "user-123", Email: "old@example.com"}, "new@example.com", time.Hour)
+	change.TokenHash = crypto.HashToken(token)
+	return change
+}
+
+func TestConfirmEmailChange_SwapsEmailAndRevokesTokens(t *testing.T) {
+	uc, mockRepo, mockChangeRepo, _ := setupEmailTest(t)
+	ctx := createTestContext()
+	change := newPendingChange("token")
+
+	mockChangeRepo.EXPECT().GetByToken(ctx, crypto.HashToken("token")).Return(change, nil)
+	mockRepo.EXPECT().GetByID(ctx, "user-123").Return(newEmailUser(t), nil)
+	mockRepo.EXPECT().Update(ctx, entity.FilterUser{ID: "user-123", Version: 3}, mock.MatchedBy(func(user *entity.User) bool {
+		return user.Email == "new@example.com" && user.EmailNormalized == "new@example.com" && user.TokenVersion == 2
+	}), "email", "email_normalized", "token_version").Return(nil)
+	mockChangeRepo.EXPECT().Delete(ctx, change.ID).Return(nil)
+
+	user, err := uc.ConfirmEmailChange(ctx, dto.EmailChangeTokenRequest{Token: "token"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", user.Email)
+}
+
+func TestConfirmEmailChange_EmailTakenMeanwhile(t *testing.T) {
+	uc, mockRepo, mockChangeRepo, _ := setupEmailTest(t)
+	ctx := createTestContext()
+	change := newPendingChange("token")
+
+	mockChangeRepo.EXPECT().GetByToken(ctx, crypto.HashToken("token")).Return(change, nil)
+	mockRepo.EXPECT().GetByID(ctx, "user-123").Return(newEmailUser(t), nil)
+	// Mock: unique index rejects the email
+	mockRepo.EXPECT().Update(ctx, mock.Anything, mock.Anything, "email", "email_normalized", "token_version").Return(&domainerror.ConflictError{Field: "email"})
+	mockChangeRepo.EXPECT().Delete(ctx, change.ID).Return(nil)
+
+	user, err := uc.ConfirmEmailChange(ctx, dto.EmailChangeTokenRequest{Token: "token"})
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerror.KindConflict, domainErr.Kind)
+	assert.Equal(t, constants.EmailAlreadyRegistered, domainErr.Code)
+	assert.Nil(t, user)
+}
+
+func TestConfirmEmailChange_StaleChange(t *testing.T) {
+	uc, mockRepo, mockChangeRepo, _ := setupEmailTest(t)
+	ctx := createTestContext()
+	change := newPendingChange("token")
+	user := newEmailUser(t)
+	user.SetEmail("other@example.com")
+
+	mockChangeRepo.EXPECT().GetByToken(ctx, crypto.HashToken("token")).Return(change, nil)
+	mockRepo.EXPECT().GetByID(ctx, "user-123").Return(user, nil)
+	mockChangeRepo.EXPECT().Delete(ctx, change.ID).Return(nil)
+
+	_, err := uc.ConfirmEmailChange(ctx, dto.EmailChangeTokenRequest{Token: "token"})
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, constants.InvalidEmailChangeToken, domainErr.Code)
+}
+
+func TestConfirmEmailChange_Expired(t *testing.T) {
+	uc, _, mockChangeRepo, _ := setupEmailTest(t)
+	ctx := createTestContext()
+	change := newPendingChange("token")
+	change.ExpiresAt = time.Now().Add(-time.Minute)
+
+	mockChangeRepo.EXPECT().GetByToken(ctx, crypto.HashToken("token")).Return(change, nil)
+	mockChangeRepo.EXPECT().Delete(ctx, change.ID).Return(nil)
+
+	_, err := uc.ConfirmEmailChange(ctx, dto.EmailChangeTokenRequest{Token: "token"})
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerror.KindInvalidInput, domainErr.Kind)
+	assert.Equal(t, constants.InvalidEmailChangeToken, domainErr.Code)
+}
+
+func TestCancelEmailChange_DeletesChange(t *testing.T) {
+	uc, _, mockChangeRepo, _ := setupEmailTest(t)
+	ctx := createTestContext()
+	change := newPendingChange("token")
+
+	mockChangeRepo.EXPECT().GetByCancelToken(ctx, crypto.HashToken("cancel")).Return(change, nil)
+	mockChangeRepo.EXPECT().Delete(ctx, change.ID).Return(nil)
+
+	err := uc.CancelEmailChange(ctx, dto.EmailChangeTokenRequest{Token: "cancel"})
+
+	assert.NoError(t, err)
+}
+
+func TestCancelEmailChange_UnknownToken(t *testing.T) {
+	uc, _, mockChangeRepo, _ := setupEmailTest(t)
+	ctx := createTestContext()
+
+	mockChangeRepo.EXPECT().GetByCancelToken(ctx, crypto.HashToken("cancel")).Return(nil, domainerror.ErrEmailChangeNotFound)
+
+	err := uc.CancelEmailChange(ctx, dto.EmailChangeTokenRequest{Token: "cancel"})
+
+	assert.Equal(t, domainerror.KindInvalidInput, domainerror.KindOf(err))
+}
+
+func TestAnonymizeUserData_DeletesChangesAndCodes(t *testing.T) {
+	uc, _, mockChangeRepo, _ := setupEmailTest(t)
+	mockOTPRepo := mocks.NewMockPhoneOTPRepository(t)
+	uc.otpRepo = mockOTPRepo
+	ctx := context.Background()
+
+	mockChangeRepo.EXPECT().DeleteByUser(ctx, "user-123").Return(1, nil)
+	mockOTPRepo.EXPECT().DeleteByUser(ctx, "user-123").Return(2, nil)
+
+	records, err := uc.AnonymizeUserData(ctx, "user-123", false)
+
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "email_changes", records[0].Table)
+	assert.Equal(t, 1, records[0].Count)
+	assert.Equal(t, "phone_otps", records[1].Table)
+	assert.Equal(t, 2, records[1].Count)
+}
@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/pkg/crypto"
+	"context"
+	"errors"
+	"sync"
+)
+
+// dummyPasswordHashes caches a hash per parameter set, see dummyPasswordHash
+var dummyPasswordHashes sync.Map
+
+// dummyPasswordHash is verified against when no user matches, so a login with an
+// unknown identifier takes as long as one with a wrong password
+func (a *authUsecase) dummyPasswordHash() string {
+	params := a.hasher.Params()
+	if hash, ok := dummyPasswordHashes.Load(params); ok {
+		return hash.(string)
+	}
+	hash, _ := crypto.HashPasswordWithParams("dummy-password-for-timing", params)
+	dummyPasswordHashes.Store(params, hash)
+	return hash
+}
+
+// hasherBusyError maps an overloaded hashing pool to 503, returning nil for other errors
+func hasherBusyError(err error) error {
+	if errors.Is(err, crypto.ErrHasherBusy) || errors.Is(err, crypto.ErrHasherTimeout) {
+		return domainerror.New(domainerror.KindUnavailable, constants.PasswordHasherBusy, err)
+	}
+	return nil
+}
+
+// rehashPassword replaces the hash of a user who just logged in with password when
+// it was made with an outdated algorithm or cost. Failures are only logged, the
+// old hash keeps working until the next login
+func (a *authUsecase) rehashPassword(ctx context.Context, user *entity.User, password string) {
+	if !a.hasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hash, err := a.hasher.Hash(ctx, password)
+	if err != nil {
+		a.logger.Error("a.hasher.Hash ", err)
+		return
+	}
+	// Conditional on the old hash, a password changed meanwhile is not overwritten
+	if err := a.userRepo.UpdatePassword(ctx, user.ID, user.Password, hash); err != nil {
+		a.logger.Error("a.userRepo.UpdatePassword ", err)
+		return
+	}
+	user.Password = hash
+}
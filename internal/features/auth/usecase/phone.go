@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"app/internal/features/auth/delivery/http/dto"
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/pkg/crypto"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// otpMessage is the SMS text carrying a one-time password and its lifetime in minutes
+const otpMessage = "%s is your verification code. It expires in %d minutes."
+
+// RequestPhoneVerification sends an SMS code to the phone on the user's profile
+func (a *authUsecase) RequestPhoneVerification(ctx context.Context, userID string) error {
+	user, err := a.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Phone == nil {
+		return domainerror.New(domainerror.KindInvalidInput, constants.PhoneRequired, nil).WithField("phone")
+	}
+
+	return a.sendOTP(ctx, user, entity.OTPPurposeVerifyPhone)
+}
+
+// ConfirmPhoneVerification marks the profile phone verified once the SMS code matches
+func (a *authUsecase) ConfirmPhoneVerification(ctx context.Context, userID string, req dto.ConfirmPhoneRequest) (*dto.RegisterResponse, error) {
+	user, err := a.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Phone == nil {
+		return nil, domainerror.New(domainerror.KindInvalidInput, constants.PhoneRequired, nil).WithField("phone")
+	}
+
+	if err := a.checkOTP(ctx, user, entity.OTPPurposeVerifyPhone, req.Code); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.PhoneVerifiedAt = &now
+	if err := a.updateUser(ctx, user, "phone_verified_at"); err != nil {
+		return nil, err
+	}
+
+	return dto.ToRegisterResponse(user), nil
+}
+
+// SetTwoFactor turns SMS two-factor authentication on or off, turning it on
+// requires a verified phone
+func (a *authUsecase) SetTwoFactor(ctx context.Context, userID string, req dto.TwoFactorRequest) (*dto.RegisterResponse, error) {
+	user, err := a.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := *req.Enabled
+	if enabled && user.PhoneVerifiedAt == nil {
+		return nil, domainerror.New(domainerror.KindInvalidInput, constants.PhoneNotVerified, nil).WithField("phone")
+	}
+	if user.TwoFactorEnabled == enabled {
+		return dto.ToRegisterResponse(user), nil
+	}
+
+	user.TwoFactorEnabled = enabled
+	if err := a.updateUser(ctx, user, "two_factor_enabled"); err != nil {
+		return nil, err
+	}
+
+	return dto.ToRegisterResponse(user), nil
+}
+
+// RequestLoginOTP sends a login code to a verified phone. Unknown numbers and
+// throttled requests succeed silently so the response does not reveal accounts
+func (a *authUsecase) RequestLoginOTP(ctx context.Context, req dto.PhoneOTPRequest) error {
+	user, err := a.userRepo.GetByPhone(ctx, req.NormalizedPhone())
+	if err != nil {
+		if errors.Is(err, domainerror.ErrUserNotFound) {
+			return nil
+		}
+		a.logger.Error("a.userRepo.GetByPhone ", err)
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+
+	err = a.sendOTP(ctx, user, entity.OTPPurposeLogin)
+	if domainerror.KindOf(err) == domainerror.KindTooManyRequests {
+		return nil
+	}
+	return err
+}
+
+// LoginWithPhone authenticates a user by verified phone and SMS code
+func (a *authUsecase) LoginWithPhone(ctx context.Context, req dto.PhoneLoginRequest) (*dto.LoginResponse, error) {
+	user, err := a.userRepo.GetByPhone(ctx, req.NormalizedPhone())
+	if err != nil {
+		a.logger.Error("a.userRepo.GetByPhone ", err)
+		if errors.Is(err, domainerror.ErrUserNotFound) {
+			return nil, domainerror.New(domainerror.KindUnauthorized, constants.InvalidOTP, err)
+		}
+		return nil, domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+
+	if err := a.checkOTP(ctx, user, entity.OTPPurposeLogin, req.Code); err != nil {
+		return nil, err
+	}
+
+	return a.issueToken(user)
+}
+
+// secondFactor checks the SMS code of a password login, sending one when none was given
+func (a *authUsecase) secondFactor(ctx context.Context, user *entity.User, code string) error {
+	if code != "" {
+		return a.checkOTP(ctx, user, entity.OTPPurposeLogin, code)
+	}
+
+	// A code sent moments ago is still valid, ask for it again instead of failing
+	if err := a.sendOTP(ctx, user, entity.OTPPurposeLogin); err != nil && domainerror.KindOf(err) != domainerror.KindTooManyRequests {
+		return err
+	}
+	return domainerror.New(domainerror.KindUnauthorized, constants.OTPRequired, nil).WithField("otp")
+}
+
+// sendOTP issues a new code for purpose and texts it to the user's phone,
+// replacing any pending one unless it was sent less than ResendInterval ago
+func (a *authUsecase) sendOTP(ctx context.Context, user *entity.User, purpose entity.OTPPurpose) error {
+	pending, err := a.otpRepo.GetPending(ctx, user.ID, purpose)
+	if err != nil && !errors.Is(err, domainerror.ErrOTPNotFound) {
+		a.logger.Error("a.otpRepo.GetPending ", err)
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+	if pending != nil && time.Since(pending.CreatedAt) < a.otp.ResendInterval {
+		return domainerror.New(domainerror.KindTooManyRequests, constants.OTPResendTooSoon, nil)
+	}
+
+	code, err := crypto.GenerateOTP(crypto.OTPLength)
+	if err != nil {
+		a.logger.Error("crypto.GenerateOTP ", err)
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+
+	// The code is bound to the number through its blind index, which is missing
+	// only for a phone not re-encrypted since migration 011
+	if user.PhoneIndex == nil {
+		err := errors.New("phone has no blind index")
+		a.logger.Error("a.sendOTP ", err)
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+
+	otp := entity.NewPhoneOTP(user.ID, *user.PhoneIndex, purpose, a.otp.TTL)
+	otp.CodeHash = crypto.HashOTP(code, otp.ID)
+	if err := a.otpRepo.Create(ctx, otp); err != nil {
+		a.logger.Error("a.otpRepo.Create ", err)
+		var conflict *domainerror.ConflictError
+		if errors.As(err, &conflict) {
+			// A concurrent request issued a code first
+			return domainerror.New(domainerror.KindTooManyRequests, constants.OTPResendTooSoon, err)
+		}
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+
+	minutes := max(1, int(a.otp.TTL.Minutes()))
+	if err := a.sms.Send(ctx, *user.Phone, fmt.Sprintf(otpMessage, code, minutes)); err != nil {
+		a.logger.Error("a.sms.Send ", err)
+		// Drop the undelivered code so the user can ask again right away
+		a.discardOTP(ctx, otp.ID)
+		return domainerror.New(domainerror.KindUnavailable, constants.FailedToSendOTP, err)
+	}
+
+	return nil
+}
+
+// checkOTP verifies code against the code pending for purpose. Every guess counts
+// against OTPConfig.MaxAttempts and a matching code is consumed
+func (a *authUsecase) checkOTP(ctx context.Context, user *entity.User, purpose entity.OTPPurpose, code string) error {
+	otp, err := a.otpRepo.GetPending(ctx, user.ID, purpose)
+	if err != nil {
+		a.logger.Error("a.otpRepo.GetPending ", err)
+		if errors.Is(err, domainerror.ErrOTPNotFound) {
+			return domainerror.New(domainerror.KindUnauthorized, constants.InvalidOTP, err)
+		}
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+
+	// The code was sent to a number the user has since replaced
+	if user.PhoneIndex == nil || otp.PhoneIndex != *user.PhoneIndex {
+		a.discardOTP(ctx, otp.ID)
+		return domainerror.New(domainerror.KindUnauthorized, constants.InvalidOTP, nil)
+	}
+	if time.Now().After(otp.ExpiresAt) {
+		a.discardOTP(ctx, otp.ID)
+		return domainerror.New(domainerror.KindUnauthorized, constants.OTPExpired, nil)
+	}
+
+	if err := a.otpRepo.ConsumeAttempt(ctx, otp.ID, a.otp.MaxAttempts); err != nil {
+		a.logger.Error("a.otpRepo.ConsumeAttempt ", err)
+		if errors.Is(err, domainerror.ErrOTPAttemptsExceeded) {
+			a.discardOTP(ctx, otp.ID)
+			return domainerror.New(domainerror.KindTooManyRequests, constants.OTPAttemptsExceeded, err)
+		}
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+
+	if !crypto.VerifyOTP(otp.CodeHash, code, otp.ID) {
+		return domainerror.New(domainerror.KindUnauthorized, constants.InvalidOTP, nil)
+	}
+
+	// Consume the code - failing to do so must not leave it reusable
+	if err := a.otpRepo.Delete(ctx, otp.ID); err != nil {
+		a.logger.Error("a.otpRepo.Delete ", err)
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+	return nil
+}
+
+// discardOTP deletes a code that can no longer be used, a failure only leaves it to expire
+func (a *authUsecase) discardOTP(ctx context.Context, id string) {
+	if err := a.otpRepo.Delete(ctx, id); err != nil {
+		a.logger.Error("a.otpRepo.Delete ", err)
+	}
+}
+
+// getUser retrieves the authenticated user
+func (a *authUsecase) getUser(ctx context.Context, userID string) (*entity.User, error) {
+	user, err := a.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		a.logger.Error("a.userRepo.GetByID ", err)
+		if errors.Is(err, domainerror.ErrUserNotFound) {
+			return nil, domainerror.New(domainerror.KindNotFound, constants.UserNotFound, err)
+		}
+		return nil, domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+	return user, nil
+}
+
+// updateUser writes columns conditioned on the version read, so a concurrent
+// profile edit (e.g. a phone change) is not overwritten
+func (a *authUsecase) updateUser(ctx context.Context, user *entity.User, columns ...string) error {
+	filter := entity.FilterUser{
+		ID:      user.ID,
+		Version: user.Version,
+	}
+	if err := a.userRepo.Update(ctx, filter, user, columns...); err != nil {
+		a.logger.Error("a.userRepo.Update ", err)
+		var conflict *domainerror.ConflictError
+		switch {
+		case errors.As(err, &conflict) && conflict.Field == "phone":
+			return domainerror.New(domainerror.KindConflict, constants.PhoneAlreadyVerified, err).WithField("phone")
+		case errors.As(err, &conflict):
+			return conflictError(conflict.Field)
+		case errors.Is(err, domainerror.ErrVersionConflict):
+			return domainerror.New(domainerror.KindConflict, constants.UserModified, err)
+		default:
+			return domainerror.Internal(constants.FailedToUpdateUser, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,407 @@
+package usecase
+
+import (
+	"app/internal/core/config"
+	"app/internal/features/auth/delivery/http/dto"
+	mocks "app/internal/mocks/repository"
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/infrastructure/sms"
+	"app/internal/testutil"
+	"app/pkg/crypto"
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testPhone = "+6281234567890"
+
+// testPhoneIndex stands for the blind index of testPhone
+const testPhoneIndex = "phone-index-1"
+
+func setupPhoneTest(t *testing.T) (*authUsecase, *mocks.MockUserRepository, *mocks.MockPhoneOTPRepository, *sms.MemorySender) {
+	mockRepo := mocks.NewMockUserRepository(t)
+	mockOTPRepo := mocks.NewMockPhoneOTPRepository(t)
+	sender := sms.NewMemorySender()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	uc := &authUsecase{
+		userRepo:   mockRepo,
+		otpRepo:    mockOTPRepo,
+		transactor: testutil.InlineTransactor{},
+		sms:        sender,
+		hasher:     testHasher,
+		otp: config.OTPConfig{
+			TTL:            5 * time.Minute,
+			MaxAttempts:    5,
+			ResendInterval: time.Minute,
+		},
+		logger: logger,
+	}
+
+	return uc, mockRepo, mockOTPRepo, sender
+}
+
+func newPhoneUser() *entity.User {
+	phone, index := testPhone, testPhoneIndex
+	return &entity.User{
+		ID:         "user-123",
+		Email:      "test@example.com",
+		Username:   "testuser",
+		Phone:      &phone,
+		PhoneIndex: &index,
+		Version:    3,
+	}
+}
+
+// newPendingOTP returns a stored code for the user matching code
+func newPendingOTP(purpose entity.OTPPurpose, code string) *entity.PhoneOTP {
+	otp := entity.NewPhoneOTP("user-123", testPhoneIndex, purpose, 5*time.Minute)
+	otp.CodeHash = crypto.HashOTP(code, otp.ID)
+	otp.CreatedAt = time.Now().Add(-2 * time.Minute)
+	return otp
+}
+
+// sentCode extracts the code from the latest SMS sent to phone
+func sentCode(t *testing.T, sender *sms.MemorySender, phone string) string {
+	msg, ok := sender.Last(phone)
+	require.True(t, ok, "no SMS sent to %s", phone)
+	return regexp.MustCompile(`\d{6}`).FindString(msg.Body)
+}
+
+func TestRequestPhoneVerification_SendsCode(t *testing.T) {
+	uc, mockRepo, mockOTPRepo, sender := setupPhoneTest(t)
+	ctx := createTestContext()
+	user := newPhoneUser()
+
+	var stored *entity.PhoneOTP
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+	mockOTPRepo.EXPECT().GetPending(ctx, user.ID, entity.OTPPurposeVerifyPhone).Return(nil, domainerror.ErrOTPNotFound)
+	mockOTPRepo.EXPECT().Create(ctx, mock.AnythingOfType("*entity.PhoneOTP")).RunAndReturn(func(ctx context.Context, otp *entity.PhoneOTP) error {
+		stored = otp
+		return nil
+	})
+
+	err := uc.RequestPhoneVerification(ctx, user.ID)
+
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	code := sentCode(t, sender, testPhone)
+	assert.True(t, crypto.VerifyOTP(stored.CodeHash, code, stored.ID))
+	assert.NotContains(t, stored.CodeHash, code)
+	assert.Equal(t, testPhoneIndex, stored.PhoneIndex)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), stored.ExpiresAt, time.Second)
+}
+
+func TestRequestPhoneVerification_PhoneNotIndexed(t *testing.T) {
+	uc, mockRepo, mockOTPRepo, sender := setupPhoneTest(t)
+	ctx := createTestContext()
+	user := newPhoneUser()
+	// Stored before migration 011 and not re-encrypted yet
+	user.PhoneIndex = nil
+
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+	mockOTPRepo.EXPECT().GetPending(ctx, user.ID, entity.OTPPurposeVerifyPhone).Return(nil, domainerror.ErrOTPNotFound)
+
+	err := uc.RequestPhoneVerification(ctx, user.ID)
+
+	assert.Equal(t, domainerror.KindInternal, domainerror.KindOf(err))
+	_, sent := sender.Last(testPhone)
+	assert.False(t, sent)
+}
+
+func TestRequestPhoneVerification_NoPhone(t *testing.T) {
+	uc, mockRepo, _, sender := setupPhoneTest(t)
+	ctx := createTestContext()
+	user := newPhoneUser()
+	user.Phone = nil
+
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+
+	err := uc.RequestPhoneVerification(ctx, user.ID)
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerror.KindInvalidInput, domainErr.Kind)
+	assert.Equal(t, constants.PhoneRequired, domainErr.Code)
+	assert.Empty(t, sender.Messages())
+}
+
+func TestRequestPhoneVerification_ResendTooSoon(t *testing.T) {
+	uc, mockRepo, mockOTPRepo, sender := setupPhoneTest(t)
+	ctx := createTestContext()
+	user := newPhoneUser()
+
+	pending := newPendingOTP(entity.OTPPurposeVerifyPhone, "123456")
+	pending.CreatedAt = time.Now().Add(-10 * time.Second)
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+	mockOTPRepo.EXPECT().GetPending(ctx, user.ID, entity.OTPPurposeVerifyPhone).Return(pending, nil)
+
+	err := uc.RequestPhoneVerification(ctx, user.ID)
+
+	assert.Equal(t, domainerror.KindTooManyRequests, domainerror.KindOf(err))
+	assert.Empty(t, sender.Messages())
+}
+
+func TestRequestPhoneVerification_SendFailureDiscardsCode(t *testing.T) {
+	uc, mockRepo, mockOTPRepo, sender := setupPhoneTest(t)
+	ctx := createTestContext()
+	user := newPhoneUser()
+	sender.FailWith(errors.New("gateway down"))
+
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+	mockOTPRepo.EXPECT().GetPending(ctx, user.ID, entity.OTPPurposeVerifyPhone).Return(nil, domainerror.ErrOTPNotFound)
+	mockOTPRepo.EXPECT().Create(ctx, mock.AnythingOfType("*entity.PhoneOTP")).Return(nil)
+	mockOTPRepo.EXPECT().Delete(ctx, mock.AnythingOfType("string")).Return(nil)
+
+	err := uc.RequestPhoneVerification(ctx, user.ID)
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerror.KindUnavailable, domainErr.Kind)
+	assert.Equal(t, constants.FailedToSendOTP, domainErr.Code)
+}
+
+func TestConfirmPhoneVerification_Success(t *testing.T) {
+	uc, mockRepo, mockOTPRepo, _ := setupPhoneTest(t)
+	ctx := createTestContext()
+	user := newPhoneUser()
+	otp := newPendingOTP(entity.OTPPurposeVerifyPhone, "123456")
+
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+	mockOTPRepo.EXPECT().GetPending(ctx, user.ID, entity.OTPPurposeVerifyPhone).Return(otp, nil)
+	mockOTPRepo.EXPECT().ConsumeAttempt(ctx, otp.ID, 5).Return(nil)
+	mockOTPRepo.EXPECT().Delete(ctx, otp.ID).Return(nil)
+	mockRepo.EXPECT().Update(ctx, entity.FilterUser{ID: user.ID, Version: 3}, user, "phone_verified_at").Return(nil)
+
+	resp, err := uc.ConfirmPhoneVerification(ctx, user.ID, dto.ConfirmPhoneRequest{Code: "123456"})
+
+	require.NoError(t, err)
+	assert.NotNil(t, resp.PhoneVerifiedAt)
+}
+
+func TestConfirmPhoneVerification_WrongCode(t *testing.T) {
+	uc, mockRepo, mockOTPRepo, _ := setupPhoneTest(t)
+	ctx := createTestContext()
+	user := newPhoneUser()
+	otp := newPendingOTP(entity.OTPPurposeVerifyPhone, "123456")
+
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+	mockOTPRepo.EXPECT().GetPending(ctx, user.ID, entity.OTPPurposeVerifyPhone).Return(otp, nil)
+	mockOTPRepo.EXPECT().ConsumeAttempt(ctx, otp.ID, 5).Return(nil)
+
+	resp, err := uc.ConfirmPhoneVerification(ctx, user.ID, dto.ConfirmPhoneRequest{Code: "654321"})
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerror.KindUnauthorized, domainErr.Kind)
+	assert.Equal(t, constants.InvalidOTP, domainErr.Code)
+	assert.Nil(t, resp)
+}
+
+func TestConfirmPhoneVerification_Expired(t *testing.T) {
+	uc, mockRepo, mockOTPRepo, _ := setupPhoneTest(t)
+	ctx := createTestContext()
+	user := newPhoneUser()
+	otp := newPendingOTP(entity.OTPPurposeVerifyPhone, "123456")
+	otp.ExpiresAt = time.Now().Add(-time.Second)
+
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+	mockOTPRepo.EXPECT().GetPending(ctx, user.ID, entity.OTPPurposeVerifyPhone).Return(otp, nil)
+	mockOTPRepo.EXPECT().Delete(ctx, otp.ID).Return(nil)
+
+	_, err := uc.ConfirmPhoneVerification(ctx, user.ID, dto.ConfirmPhoneRequest{Code: "123456"})
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, constants.OTPExpired, domainErr.Code)
+}
+
+func TestConfirmPhoneVerification_AttemptsExceeded(t *testing.T) {
+	uc, mockRepo, mockOTPRepo, _ := setupPhoneTest(t)
+	ctx := createTestContext()
+	user := newPhoneUser()
+	otp := newPendingOTP(entity.OTPPurposeVerifyPhone, "123456")
+
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+	mockOTPRepo.EXPECT().GetPending(ctx, user.ID, entity.OTPPurposeVerifyPhone).Return(otp, nil)
+	mockOTPRepo.EXPECT().ConsumeAttempt(ctx, otp.ID, 5).Return(domainerror.ErrOTPAttemptsExceeded)
+	mockOTPRepo.EXPECT().Delete(ctx, otp.ID).Return(nil)
+
+	// Even the right code is refused once the attempts are used up
+	_, err := uc.ConfirmPhoneVerification(ctx, user.ID, dto.ConfirmPhoneRequest{Code: "123456"})
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerror.KindTooManyRequests, domainErr.Kind)
+	assert.Equal(t, constants.OTPAttemptsExceeded, domainErr.Code)
+}
+
+func TestConfirmPhoneVerification_PhoneChanged(t *testing.T) {
+	uc, mockRepo, mockOTPRepo, _ := setupPhoneTest(t)
+	ctx := createTestContext()
+	user := newPhoneUser()
+	otherPhone, otherIndex := "+6289876543210", "phone-index-2"
+	user.Phone, user.PhoneIndex = &otherPhone, &otherIndex
+	otp := newPendingOTP(entity.OTPPurposeVerifyPhone, "123456")
+
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+	mockOTPRepo.EXPECT().GetPending(ctx, user.ID, entity.OTPPurposeVerifyPhone).Return(otp, nil)
+	mockOTPRepo.EXPECT().Delete(ctx, otp.ID).Return(nil)
+
+	_, err := uc.ConfirmPhoneVerification(ctx, user.ID, dto.ConfirmPhoneRequest{Code: "123456"})
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, constants.InvalidOTP, domainErr.Code)
+}
+
+func TestConfirmPhoneVerification_VerifiedByAnotherAccount(t *testing.T) {
+	uc, mockRepo, mockOTPRepo, _ := setupPhoneTest(t)
+	ctx := createTestContext()
+	user := newPhoneUser()
+	otp := newPendingOTP(entity.OTPPurposeVerifyPhone, "123456")
+
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+	mockOTPRepo.EXPECT().GetPending(ctx, user.ID, entity.OTPPurposeVerifyPhone).Return(otp, nil)
+	mockOTPRepo.EXPECT().ConsumeAttempt(ctx, otp.ID, 5).Return(nil)
+	mockOTPRepo.EXPECT().Delete(ctx, otp.ID).Return(nil)
+	mockRepo.EXPECT().Update(ctx, mock.Anything, user, "phone_verified_at").Return(&domainerror.ConflictError{Field: "phone"})
+
+	_, err := uc.ConfirmPhoneVerification(ctx, user.ID, dto.ConfirmPhoneRequest{Code: "123456"})
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerror.KindConflict, domainErr.Kind)
+	assert.Equal(t, constants.PhoneAlreadyVerified, domainErr.Code)
+}
+
+func TestSetTwoFactor_RequiresVerifiedPhone(t *testing.T) {
+	uc, mockRepo, _, _ := setupPhoneTest(t)
+	ctx := createTestContext()
+	user := newPhoneUser()
+	enabled := true
+
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+
+	_, err := uc.SetTwoFactor(ctx, user.ID, dto.TwoFactorRequest{Enabled: &enabled})
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, constants.PhoneNotVerified, domainErr.Code)
+}
+
+func TestSetTwoFactor_Enable(t *testing.T) {
+	uc, mockRepo, _, _ := setupPhoneTest(t)
+	ctx := createTestContext()
+	user := newPhoneUser()
+	verifiedAt := time.Now()
+	user.PhoneVerifiedAt = &verifiedAt
+	enabled := true
+
+	mockRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil)
+	mockRepo.EXPECT().Update(ctx, entity.FilterUser{ID: user.ID, Version: 3}, user, "two_factor_enabled").Return(nil)
+
+	resp, err := uc.SetTwoFactor(ctx, user.ID, dto.TwoFactorRequest{Enabled: &enabled})
+
+	require.NoError(t, err)
+	assert.True(t, resp.TwoFactorEnabled)
+}
+
+func TestLogin_TwoFactorSendsCode(t *testing.T) {
+	uc, mockRepo, mockOTPRepo, sender := setupPhoneTest(t)
+	ctx := createTestContext()
+
+	hashedPassword, err := crypto.HashPasswordWithParams("password123", testPasswordParams)
+	require.NoError(t, err)
+	user := newPhoneUser()
+	user.Password = hashedPassword
+	user.TwoFactorEnabled = true
+
+	mockRepo.EXPECT().GetByEmail(ctx, user.Email).Return(user, nil)
+	mockOTPRepo.EXPECT().GetPending(ctx, user.ID, entity.OTPPurposeLogin).Return(nil, domainerror.ErrOTPNotFound)
+	mockOTPRepo.EXPECT().Create(ctx, mock.AnythingOfType("*entity.PhoneOTP")).Return(nil)
+
+	loginResp, err := uc.Login(ctx, dto.LoginRequest{Email: user.Email, Password: "password123"})
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerror.KindUnauthorized, domainErr.Kind)
+	assert.Equal(t, constants.OTPRequired, domainErr.Code)
+	assert.Nil(t, loginResp)
+	assert.NotEmpty(t, sentCode(t, sender, testPhone))
+}
+
+func TestLogin_TwoFactorWithCode(t *testing.T) {
+	uc, mockRepo, mockOTPRepo, _ := setupPhoneTest(t)
+	ctx := createTestContext()
+
+	hashedPassword, err := crypto.HashPasswordWithParams("password123", testPasswordParams)
+	require.NoError(t, err)
+	user := newPhoneUser()
+	user.Password = hashedPassword
+	user.TwoFactorEnabled = true
+	otp := newPendingOTP(entity.OTPPurposeLogin, "123456")
+
+	mockRepo.EXPECT().GetByEmail(ctx, user.Email).Return(user, nil)
+	mockOTPRepo.EXPECT().GetPending(ctx, user.ID, entity.OTPPurposeLogin).Return(otp, nil)
+	mockOTPRepo.EXPECT().ConsumeAttempt(ctx, otp.ID, 5).Return(nil)
+	mockOTPRepo.EXPECT().Delete(ctx, otp.ID).Return(nil)
+
+	loginResp, err := uc.Login(ctx, dto.LoginRequest{Email: user.Email, Password: "password123", OTP: "123456"})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, loginResp.Token)
+}
+
+func TestRequestLoginOTP_UnknownPhoneIsSilent(t *testing.T) {
+	uc, mockRepo, _, sender := setupPhoneTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByPhone(ctx, testPhone).Return(nil, domainerror.ErrUserNotFound)
+
+	err := uc.RequestLoginOTP(ctx, dto.PhoneOTPRequest{Phone: "0062 812 3456 7890"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, sender.Messages())
+}
+
+func TestLoginWithPhone_Success(t *testing.T) {
+	uc, mockRepo, mockOTPRepo, _ := setupPhoneTest(t)
+	ctx := createTestContext()
+	user := newPhoneUser()
+	otp := newPendingOTP(entity.OTPPurposeLogin, "123456")
+
+	mockRepo.EXPECT().GetByPhone(ctx, testPhone).Return(user, nil)
+	mockOTPRepo.EXPECT().GetPending(ctx, user.ID, entity.OTPPurposeLogin).Return(otp, nil)
+	mockOTPRepo.EXPECT().ConsumeAttempt(ctx, otp.ID, 5).Return(nil)
+	mockOTPRepo.EXPECT().Delete(ctx, otp.ID).Return(nil)
+
+	loginResp, err := uc.LoginWithPhone(ctx, dto.PhoneLoginRequest{Phone: testPhone, Code: "123456"})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, loginResp.Token)
+	assert.Equal(t, user.ID, loginResp.User.ID)
+}
+
+func TestLoginWithPhone_UnknownPhone(t *testing.T) {
+	uc, mockRepo, _, _ := setupPhoneTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByPhone(ctx, testPhone).Return(nil, domainerror.ErrUserNotFound)
+
+	loginResp, err := uc.LoginWithPhone(ctx, dto.PhoneLoginRequest{Phone: testPhone, Code: "123456"})
+
+	assert.Equal(t, domainerror.KindUnauthorized, domainerror.KindOf(err))
+	assert.Nil(t, loginResp)
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	"fmt"
+	"time"
+)
+
+// DownloadRequest holds the parameters of a signed download link
+type DownloadRequest struct {
+	ID        string `form:"id"`
+	Expires   int64  `form:"expires"`
+	Signature string `form:"signature"`
+}
+
+// Validate validates DownloadRequest fields
+func (r *DownloadRequest) Validate(lang constants.Lang) map[string][]string {
+	errors := make(map[string][]string)
+
+	for field, value := range map[string]string{"id": r.ID, "signature": r.Signature} {
+		if value == "" {
+			errors[field] = append(errors[field], fmt.Sprintf(constants.GetValidationMessage(constants.Required, lang), field))
+		}
+	}
+	if r.Expires == 0 {
+		errors["expires"] = append(errors["expires"], fmt.Sprintf(constants.GetValidationMessage(constants.Required, lang), "expires"))
+	}
+
+	return errors
+}
+
+// ExportResponse represents a data export in response. The download link is only
+// set once the archive is ready and is valid until DownloadExpiresAt, fetch the
+// export again for a fresh one
+type ExportResponse struct {
+	ID                string     `json:"id"`
+	Status            string     `json:"status" enums:"pending,running,ready,failed,expired"`
+	CreatedAt         time.Time  `json:"created_at"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	DownloadURL       string     `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+}
+
+// ToExportResponse converts entity.DataExport to ExportResponse
+func ToExportResponse(export *entity.DataExport) *ExportResponse {
+	return &ExportResponse{
+		ID:          export.ID,
+		Status:      export.Status,
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"app/internal/features/export/delivery/http/dto"
+	"app/internal/features/export/usecase"
+	"app/internal/shared/constants"
+	"app/internal/shared/delivery/http/middleware"
+	"app/internal/shared/delivery/http/response"
+	"app/pkg/jwt"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler handles HTTP requests for user data exports
+type ExportHandler struct {
+	exportUsecase usecase.ExportUsecase
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportUsecase usecase.ExportUsecase) *ExportHandler {
+	return &ExportHandler{
+		exportUsecase: exportUsecase,
+	}
+}
+
+// RequestExport handles requesting an export of the user's data
+//
+//	@Summary		Request data export
+//	@Description	Start producing a ZIP archive of all data held about the authenticated user. While an export is in progress it is returned instead of starting another
+//	@Tags			exports
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		202	{object}	response.Response{data=dto.ExportResponse}
+//	@Header			202	{string}	Location	"URL of the export status"
+//	@Failure		401	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Failure		503	{object}	response.Response
+//	@Router			/api/v1/users/me/exports [post]
+func (h *ExportHandler) RequestExport(c *gin.Context) {
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.exportUsecase.RequestExport(c.Request.Context(), userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("Location", path.Join(c.Request.URL.Path, export.ID))
+	response.NewResponse(c, http.StatusAccepted, export, "Data export started", nil)
+}
+
+// GetExport handles getting the status of a data export
+//
+//	@Summary		Get data export
+//	@Description	Get the status of a data export of the authenticated user. Once ready it carries a download link valid for a short time, fetch the export again for a fresh one
+//	@Tags			exports
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Export ID"
+//	@Success		200	{object}	response.Response{data=dto.ExportResponse}
+//	@Failure		401	{object}	response.Response
+//	@Failure		404	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Failure		503	{object}	response.Response
+//	@Router			/api/v1/users/me/exports/{id} [get]
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.exportUsecase.GetExport(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.NewResponse(c, http.StatusOK, export, "Data export retrieved successfully", nil)
+}
+
+// Download handles downloading a data export archive
+//
+//	@Summary		Download data export
+//	@Description	Download the ZIP archive of a data export through the signed link from its status
+//	@Tags			exports
+//	@Produce		application/zip
+//	@Param			id			query		string	true	"Export ID"
+//	@Param			expires		query		int		true	"Link expiry as a Unix timestamp"
+//	@Param			signature	query		string	true	"Link signature"
+//	@Success		200			{file}		binary
+//	@Failure		400			{object}	response.Response
+//	@Failure		403			{object}	response.Response
+//	@Failure		500			{object}	response.Response
+//	@Failure		503			{object}	response.Response
+//	@Router			/api/v1/exports/download [get]
+func (h *ExportHandler) Download(c *gin.Context) {
+	lang := middleware.GetLangFromGin(c)
+
+	var req dto.DownloadRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
+			"query": {err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if errors := req.Validate(lang); len(errors) > 0 {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), errors)
+		return
+	}
+
+	archive, name, err := h.exportUsecase.Download(c.Request.Context(), req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// authenticatedUserID returns the user ID from the session claims, responding
+// 401 when the request carries none
+func authenticatedUserID(c *gin.Context) (string, bool) {
+	claimsVal, _ := c.Get("sess")
+	claims, ok := claimsVal.(*jwt.Claims)
+	if !ok {
+		response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, middleware.GetLangFromGin(c)), nil)
+		return "", false
+	}
+	return claims.UserID, true
+}
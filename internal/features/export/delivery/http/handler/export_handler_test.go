@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"app/internal/features/export/delivery/http/dto"
+	mocks "app/internal/mocks/usecase"
+	"app/internal/shared/constants"
+	"app/internal/shared/delivery/http/middleware"
+	domainerror "app/internal/shared/domain/error"
+	pkgjwt "app/pkg/jwt"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorMiddleware())
+	return router
+}
+
+func setupGinContext(router *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func setLanguageMiddleware(c *gin.Context) {
+	c.Set(middleware.LangKey, constants.LangEN)
+}
+
+func setUserIDMiddleware(userID string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(middleware.LangKey, constants.LangEN)
+		c.Set("sess", &pkgjwt.Claims{
+			UserID:   userID,
+			Email:    "test@example.com",
+			Username: "testuser",
+		})
+	}
+}
+
+func TestRequestExport_Accepted(t *testing.T) {
+	mockUsecase := mocks.NewMockExportUsecase(t)
+	handler := NewExportHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/users/me/exports", setUserIDMiddleware("user-123"), handler.RequestExport)
+
+	mockUsecase.EXPECT().
+		RequestExport(mock.Anything, "user-123").
+		Return(&dto.ExportResponse{ID: "export-1", Status: "pending"}, nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/users/me/exports", nil)
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/users/me/exports/export-1", w.Header().Get("Location"))
+
+	var response map[string]any
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", response["data"].(map[string]any)["status"])
+}
+
+func TestRequestExport_NoUserID(t *testing.T) {
+	mockUsecase := mocks.NewMockExportUsecase(t)
+	handler := NewExportHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/users/me/exports", setLanguageMiddleware, handler.RequestExport)
+
+	req, _ := http.NewRequest(http.MethodPost, "/users/me/exports", nil)
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestGetExport_NotFound(t *testing.T) {
+	mockUsecase := mocks.NewMockExportUsecase(t)
+	handler := NewExportHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.GET("/users/me/exports/:id", setUserIDMiddleware("user-123"), handler.GetExport)
+
+	mockUsecase.EXPECT().
+		GetExport(mock.Anything, "user-123", "export-1").
+		Return(nil, domainerror.New(domainerror.KindNotFound, constants.DataExportNotFound, nil))
+
+	req, _ := http.NewRequest(http.MethodGet, "/users/me/exports/export-1", nil)
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDownload_Success(t *testing.T) {
+	mockUsecase := mocks.NewMockExportUsecase(t)
+	handler := NewExportHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.GET("/exports/download", setLanguageMiddleware, handler.Download)
+
+	mockUsecase.EXPECT().
+		Download(mock.Anything, dto.DownloadRequest{ID: "export-1", Expires: 1700000000, Signature: "sig"}).
+		Return([]byte("archive"), "data-export-2026-01-02.zip", nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/exports/download?id=export-1&expires=1700000000&signature=sig", nil)
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="data-export-2026-01-02.zip"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "archive", w.Body.String())
+}
+
+func TestDownload_MissingSignature(t *testing.T) {
+	mockUsecase := mocks.NewMockExportUsecase(t)
+	handler := NewExportHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.GET("/exports/download", setLanguageMiddleware, handler.Download)
+
+	req, _ := http.NewRequest(http.MethodGet, "/exports/download?id=export-1&expires=1700000000", nil)
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDownload_InvalidLink(t *testing.T) {
+	mockUsecase := mocks.NewMockExportUsecase(t)
+	handler := NewExportHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.GET("/exports/download", setLanguageMiddleware, handler.Download)
+
+	mockUsecase.EXPECT().
+		Download(mock.Anything, mock.Anything).
+		Return(nil, "", domainerror.New(domainerror.KindForbidden, constants.InvalidDownloadLink, nil))
+
+	req, _ := http.NewRequest(http.MethodGet, "/exports/download?id=export-1&expires=1700000000&signature=forged", nil)
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"app/internal/features/export/delivery/http/handler"
+	"app/internal/features/export/usecase"
+	"app/internal/shared/delivery/http/middleware"
+	"app/internal/shared/domain/entity"
+	"app/internal/shared/domain/repository"
+	"app/internal/shared/domain/service"
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Module is the data export feature module that combines DI and route registration
+type Module struct {
+	usecase usecase.ExportUsecase
+	handler *handler.ExportHandler
+	auth    gin.HandlerFunc
+}
+
+// NewModule creates and wires all export feature dependencies. exporters are the
+// feature modules contributing a section to the archives
+func NewModule(exportRepo repository.DataExportRepository, userRepo repository.UserRepository, storage service.FileStorage, exporters []service.DataExporter, logger *logrus.Logger) *Module {
+	// Wire dependencies
+	uc := usecase.NewExportUsecase(exportRepo, userRepo, storage, exporters, logger)
+	h := handler.NewExportHandler(uc)
+
+	return &Module{usecase: uc, handler: h, auth: middleware.AuthMiddleware(userRepo)}
+}
+
+// Name returns the feature name
+func (m *Module) Name() string {
+	return "export"
+}
+
+// AnonymizeUserData erases the export archives when the account is anonymized
+func (m *Module) AnonymizeUserData(ctx context.Context, userID string, dryRun bool) ([]service.AnonymizedRecords, error) {
+	return m.usecase.AnonymizeUserData(ctx, userID, dryRun)
+}
+
+// PurgeExpiredExports removes the archives of expired exports, see cmd/purge
+func (m *Module) PurgeExpiredExports(ctx context.Context, batchSize int) (int, error) {
+	return m.usecase.PurgeExpiredExports(ctx, batchSize)
+}
+
+// Models returns the entities the export feature stores, checked against the schema
+func (m *Module) Models() []any {
+	return []any{&entity.DataExport{}}
+}
+
+// RegisterRoutes registers all export routes
+func (m *Module) RegisterRoutes(rg *gin.RouterGroup) {
+	// Protected routes - auth middleware applied inline
+	rg.POST("/users/me/exports", m.auth, m.handler.RequestExport)
+	rg.GET("/users/me/exports/:id", m.auth, m.handler.GetExport)
+
+	// Public route - the signed link is the credential
+	rg.GET("/exports/download", m.handler.Download)
+}
@@ -0,0 +1,355 @@
+package usecase
+
+import (
+	"app/internal/core/config"
+	"app/internal/features/export/delivery/http/dto"
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/domain/repository"
+	"app/internal/shared/domain/service"
+	"app/pkg/crypto"
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ExportUsecase defines the interface for user data export use cases
+type ExportUsecase interface {
+	RequestExport(ctx context.Context, userID string) (*dto.ExportResponse, error)
+	GetExport(ctx context.Context, userID, exportID string) (*dto.ExportResponse, error)
+	// Download returns the archive of a signed download link and its file name
+	Download(ctx context.Context, req dto.DownloadRequest) ([]byte, string, error)
+	PurgeExpiredExports(ctx context.Context, batchSize int) (int, error)
+	AnonymizeUserData(ctx context.Context, userID string, dryRun bool) ([]service.AnonymizedRecords, error)
+}
+
+// staleAfter is how long an export may stay pending or running before it is
+// considered lost, e.g. to a restart, and a new one can be requested
+const staleAfter = time.Hour
+
+// exportUsecase implements ExportUsecase interface
+type exportUsecase struct {
+	exportRepo repository.DataExportRepository
+	userRepo   repository.UserRepository
+	storage    service.FileStorage
+	exporters  []service.DataExporter
+	export     config.ExportConfig
+	secret     string // Signs download links
+	logger     *logrus.Logger
+	async      func(task func())
+}
+
+// NewExportUsecase creates a new export usecase. Every exporter contributes its
+// section to the archives next to the profile
+func NewExportUsecase(exportRepo repository.DataExportRepository, userRepo repository.UserRepository, storage service.FileStorage, exporters []service.DataExporter, logger *logrus.Logger) ExportUsecase {
+	cfg := config.Load()
+	return &exportUsecase{
+		exportRepo: exportRepo,
+		userRepo:   userRepo,
+		storage:    storage,
+		exporters:  exporters,
+		export:     cfg.Export,
+		secret:     cfg.Export.LinkSecret,
+		logger:     logger,
+		async:      func(task func()) { go task() },
+	}
+}
+
+// RequestExport starts producing an archive of the user's data in the background.
+// While an export is still in progress it is returned instead of starting another
+func (u *exportUsecase) RequestExport(ctx context.Context, userID string) (*dto.ExportResponse, error) {
+	active, err := u.exportRepo.GetActive(ctx, userID)
+	switch {
+	case errors.Is(err, domainerror.ErrDataExportNotFound):
+	case err != nil:
+		u.logger.Error("u.exportRepo.GetActive ", err)
+		return nil, domainerror.Internal(constants.SomethingWentWrong, err)
+	case time.Since(active.CreatedAt) < staleAfter:
+		return dto.ToExportResponse(active), nil
+	default:
+		u.fail(ctx, active)
+	}
+
+	// A user has one active export at most, enforced by a partial unique index: of
+	// concurrent requests passing the lookup above, the later ones get the export
+	// of the first
+	export := entity.NewDataExport(userID)
+	if err := u.exportRepo.Create(ctx, export); err != nil {
+		var conflict *domainerror.ConflictError
+		if errors.As(err, &conflict) {
+			if active, err := u.exportRepo.GetActive(ctx, userID); err == nil {
+				return dto.ToExportResponse(active), nil
+			}
+		}
+		u.logger.Error("u.exportRepo.Create ", err)
+		return nil, domainerror.Internal(constants.FailedToCreateExport, err)
+	}
+
+	// Built before the job starts changing the export. The job outlives the request
+	response := dto.ToExportResponse(export)
+	jobCtx := context.WithoutCancel(ctx)
+	u.async(func() { u.run(jobCtx, export) })
+
+	return response, nil
+}
+
+// run produces and stores the archive of an export
+func (u *exportUsecase) run(ctx context.Context, export *entity.DataExport) {
+	export.Status = entity.DataExportRunning
+	if err := u.exportRepo.Update(ctx, export, "status"); err != nil {
+		u.logger.Error("u.exportRepo.Update ", err)
+		return
+	}
+
+	key := export.ID + ".zip"
+	archive, err := u.buildArchive(ctx, export.UserID)
+	if err == nil {
+		err = u.storage.Put(ctx, key, archive)
+	}
+	if err != nil {
+		u.logger.WithField("export_id", export.ID).Error("data export failed ", err)
+		u.fail(ctx, export)
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(u.export.TTL)
+	export.Status = entity.DataExportReady
+	export.FileKey = key
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+	if err := u.exportRepo.Update(ctx, export, "status", "file_key", "completed_at", "expires_at"); err != nil {
+		u.logger.Error("u.exportRepo.Update ", err)
+		_ = u.storage.Delete(ctx, key)
+	}
+}
+
+// fail marks an export as failed
+func (u *exportUsecase) fail(ctx context.Context, export *entity.DataExport) {
+	export.Status = entity.DataExportFailed
+	if err := u.exportRepo.Update(ctx, export, "status"); err != nil {
+		u.logger.Error("u.exportRepo.Update ", err)
+	}
+}
+
+// buildArchive zips profile.json and one directory per exporter section holding
+// a JSON file per entry it returned
+func (u *exportUsecase) buildArchive(ctx context.Context, userID string) ([]byte, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	if err := writeJSON(archive, "profile.json", user); err != nil {
+		return nil, err
+	}
+
+	for _, exporter := range u.exporters {
+		files, err := exporter.ExportUserData(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", exporter.Name(), err)
+		}
+
+		// Sorted so archives of the same data are identical
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := writeJSON(archive, exporter.Name()+"/"+name+".json", files[name]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeJSON adds value to the archive as an indented JSON file
+func writeJSON(archive *zip.Writer, name string, value any) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("encode %s: %w", name, err)
+	}
+	return nil
+}
+
+// GetExport returns an export of the user, with a fresh download link once its
+// archive is ready
+func (u *exportUsecase) GetExport(ctx context.Context, userID, exportID string) (*dto.ExportResponse, error) {
+	export, err := u.exportRepo.GetByID(ctx, exportID)
+	if err != nil && !errors.Is(err, domainerror.ErrDataExportNotFound) {
+		u.logger.Error("u.exportRepo.GetByID ", err)
+		return nil, domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+	// Exports of other users do not exist as far as the caller is concerned
+	if err != nil || export.UserID != userID {
+		return nil, domainerror.New(domainerror.KindNotFound, constants.DataExportNotFound, domainerror.ErrDataExportNotFound)
+	}
+
+	response := dto.ToExportResponse(export)
+	if export.Downloadable() {
+		expires := time.Now().Add(u.export.LinkTTL)
+		if expires.After(*export.ExpiresAt) {
+			expires = *export.ExpiresAt
+		}
+		response.DownloadURL = u.downloadLink(export.ID, expires)
+		response.DownloadExpiresAt = &expires
+	}
+	return response, nil
+}
+
+// downloadLink returns the download URL of an export signed until expires
+func (u *exportUsecase) downloadLink(exportID string, expires time.Time) string {
+	query := url.Values{}
+	query.Set("id", exportID)
+	query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	query.Set("signature", crypto.Sign(u.secret, signedMessage(exportID, expires.Unix())))
+
+	separator := "?"
+	if strings.Contains(u.export.DownloadURL, "?") {
+		separator = "&"
+	}
+	return u.export.DownloadURL + separator + query.Encode()
+}
+
+// signedMessage is what a download link signature covers
+func signedMessage(exportID string, expires int64) string {
+	return "data-export:" + exportID + ":" + strconv.FormatInt(expires, 10)
+}
+
+// Download checks the link signature and expiry, then returns the archive. The
+// link is the only credential, so any failure reads the same
+func (u *exportUsecase) Download(ctx context.Context, req dto.DownloadRequest) ([]byte, string, error) {
+	if time.Now().Unix() > req.Expires || !crypto.VerifySignature(u.secret, signedMessage(req.ID, req.Expires), req.Signature) {
+		return nil, "", invalidDownloadLink(nil)
+	}
+
+	export, err := u.exportRepo.GetByID(ctx, req.ID)
+	if errors.Is(err, domainerror.ErrDataExportNotFound) {
+		return nil, "", invalidDownloadLink(err)
+	}
+	if err != nil {
+		u.logger.Error("u.exportRepo.GetByID ", err)
+		return nil, "", domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+	if !export.Downloadable() {
+		return nil, "", invalidDownloadLink(nil)
+	}
+
+	archive, err := u.storage.Get(ctx, export.FileKey)
+	if errors.Is(err, domainerror.ErrFileNotFound) {
+		return nil, "", invalidDownloadLink(err)
+	}
+	if err != nil {
+		u.logger.Error("u.storage.Get ", err)
+		return nil, "", domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+
+	return archive, "data-export-" + export.CompletedAt.Format("2006-01-02") + ".zip", nil
+}
+
+// invalidDownloadLink is the error of a download link that cannot be served
+func invalidDownloadLink(err error) error {
+	return domainerror.New(domainerror.KindForbidden, constants.InvalidDownloadLink, err)
+}
+
+// PurgeExpiredExports removes the archives of expired exports, batchSize at a
+// time, and returns how many were removed
+func (u *exportUsecase) PurgeExpiredExports(ctx context.Context, batchSize int) (int, error) {
+	// No batch would ever come back short, the purge would not end
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	purged := 0
+	for {
+		exports, err := u.exportRepo.ListExpired(ctx, time.Now(), batchSize)
+		if err != nil {
+			return purged, fmt.Errorf("list expired exports: %w", err)
+		}
+
+		for _, export := range exports {
+			if err := u.storage.Delete(ctx, export.FileKey); err != nil {
+				return purged, fmt.Errorf("delete archive of export %s: %w", export.ID, err)
+			}
+			export.Status = entity.DataExportExpired
+			export.FileKey = ""
+			if err := u.exportRepo.Update(ctx, export, "status", "file_key"); err != nil {
+				return purged, fmt.Errorf("expire export %s: %w", export.ID, err)
+			}
+			purged++
+		}
+
+		if len(exports) < batchSize {
+			return purged, nil
+		}
+	}
+}
+
+// AnonymizeUserData deletes the archives of the user's exports and fails the ones
+// still in progress. The rows stay, stripped of their archive. Archives are deleted
+// after the rows are updated, a transaction rolled back afterwards leaves ready
+// exports whose download fails
+func (u *exportUsecase) AnonymizeUserData(ctx context.Context, userID string, dryRun bool) ([]service.AnonymizedRecords, error) {
+	exports, err := u.exportRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	touched := 0
+	for _, export := range exports {
+		switch {
+		case export.Active():
+			export.Status = entity.DataExportFailed
+		case export.FileKey != "":
+			keys = append(keys, export.FileKey)
+			export.Status = entity.DataExportExpired
+			export.FileKey = ""
+		default:
+			continue
+		}
+		if err := u.exportRepo.Update(ctx, export, "status", "file_key"); err != nil {
+			return nil, err
+		}
+		touched++
+	}
+
+	if !dryRun {
+		for _, key := range keys {
+			if err := u.storage.Delete(ctx, key); err != nil {
+				return nil, fmt.Errorf("delete archive %s: %w", key, err)
+			}
+		}
+	}
+
+	return []service.AnonymizedRecords{{
+		Table:   "data_exports",
+		Action:  service.AnonymizeRedact,
+		Count:   touched,
+		Columns: []string{"status", "file_key"},
+	}}, nil
+}
@@ -0,0 +1,387 @@
+package usecase
+
+import (
+	"app/internal/core/config"
+	"app/internal/features/export/delivery/http/dto"
+	mocks "app/internal/mocks/repository"
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/domain/service"
+	"app/internal/shared/infrastructure/storage"
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeExporter contributes a fixed section to exports
+type fakeExporter struct {
+	files map[string]any
+	err   error
+}
+
+func (e fakeExporter) Name() string {
+	return "user"
+}
+
+func (e fakeExporter) ExportUserData(ctx context.Context, userID string) (map[string]any, error) {
+	return e.files, e.err
+}
+
+func setupTest(t *testing.T, exporters ...service.DataExporter) (*exportUsecase, *mocks.MockDataExportRepository, *mocks.MockUserRepository, *storage.MemoryStorage) {
+	mockExportRepo := mocks.NewMockDataExportRepository(t)
+	mockUserRepo := mocks.NewMockUserRepository(t)
+	store := storage.NewMemoryStorage()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	uc := &exportUsecase{
+		exportRepo: mockExportRepo,
+		userRepo:   mockUserRepo,
+		storage:    store,
+		exporters:  exporters,
+		export: config.ExportConfig{
+			TTL:         7 * 24 * time.Hour,
+			LinkTTL:     15 * time.Minute,
+			DownloadURL: "https://api.example.com/api/v1/exports/download",
+		},
+		secret: "test-secret",
+		logger: logger,
+		// Jobs run before RequestExport returns
+		async: func(task func()) { task() },
+	}
+
+	return uc, mockExportRepo, mockUserRepo, store
+}
+
+// readArchive returns the files of a ZIP archive keyed by name
+func readArchive(t *testing.T, data []byte) map[string]string {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[file.Name] = string(content)
+	}
+	return files
+}
+
+func newReadyExport() *entity.DataExport {
+	completedAt := time.Now().Add(-time.Hour)
+	expiresAt := time.Now().Add(24 * time.Hour)
+	return &entity.DataExport{
+		ID:          "export-1",
+		UserID:      "user-123",
+		Status:      entity.DataExportReady,
+		FileKey:     "export-1.zip",
+		CompletedAt: &completedAt,
+		ExpiresAt:   &expiresAt,
+	}
+}
+
+func TestRequestExport_ProducesArchive(t *testing.T) {
+	exporter := fakeExporter{files: map[string]any{
+		"username_history": []map[string]string{{"username": "oldname"}},
+	}}
+	uc, mockExportRepo, mockUserRepo, store := setupTest(t, exporter)
+	ctx := context.Background()
+
+	var created *entity.DataExport
+	mockExportRepo.EXPECT().GetActive(ctx, "user-123").Return(nil, domainerror.ErrDataExportNotFound)
+	mockExportRepo.EXPECT().Create(ctx, mock.AnythingOfType("*entity.DataExport")).
+		Run(func(ctx context.Context, export *entity.DataExport) { created = export }).
+		Return(nil)
+	mockExportRepo.EXPECT().Update(mock.Anything, mock.Anything, "status").Return(nil)
+	mockUserRepo.EXPECT().GetByID(mock.Anything, "user-123").
+		Return(&entity.User{ID: "user-123", Email: "test@example.com", Password: "hashed"}, nil)
+	mockExportRepo.EXPECT().Update(mock.Anything, mock.Anything, "status", "file_key", "completed_at", "expires_at").Return(nil)
+
+	resp, err := uc.RequestExport(ctx, "user-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, entity.DataExportPending, resp.Status)
+	assert.Equal(t, entity.DataExportReady, created.Status)
+	require.NotNil(t, created.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), *created.ExpiresAt, time.Minute)
+
+	archive, err := store.Get(ctx, created.FileKey)
+	require.NoError(t, err)
+	files := readArchive(t, archive)
+	assert.Contains(t, files["profile.json"], `"email": "test@example.com"`)
+	assert.NotContains(t, files["profile.json"], "hashed")
+	assert.Contains(t, files["user/username_history.json"], `"username": "oldname"`)
+}
+
+func TestRequestExport_ReturnsExportInProgress(t *testing.T) {
+	uc, mockExportRepo, _, _ := setupTest(t)
+	ctx := context.Background()
+
+	active := &entity.DataExport{ID: "export-1", UserID: "user-123", Status: entity.DataExportRunning, CreatedAt: time.Now().Add(-time.Minute)}
+	mockExportRepo.EXPECT().GetActive(ctx, "user-123").Return(active, nil)
+
+	resp, err := uc.RequestExport(ctx, "user-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, "export-1", resp.ID)
+	assert.Equal(t, entity.DataExportRunning, resp.Status)
+}
+
+func TestRequestExport_ReplacesStaleExport(t *testing.T) {
+	uc, mockExportRepo, mockUserRepo, _ := setupTest(t)
+	ctx := context.Background()
+
+	stale := &entity.DataExport{ID: "export-1", UserID: "user-123", Status: entity.DataExportRunning, CreatedAt: time.Now().Add(-2 * time.Hour)}
+	mockExportRepo.EXPECT().GetActive(ctx, "user-123").Return(stale, nil)
+	mockExportRepo.EXPECT().Update(mock.Anything, stale, "status").Return(nil).Once()
+	mockExportRepo.EXPECT().Create(ctx, mock.AnythingOfType("*entity.DataExport")).Return(nil)
+	mockExportRepo.EXPECT().Update(mock.Anything, mock.Anything, "status").Return(nil)
+	mockUserRepo.EXPECT().GetByID(mock.Anything, "user-123").Return(&entity.User{ID: "user-123"}, nil)
+	mockExportRepo.EXPECT().Update(mock.Anything, mock.Anything, "status", "file_key", "completed_at", "expires_at").Return(nil)
+
+	resp, err := uc.RequestExport(ctx, "user-123")
+
+	require.NoError(t, err)
+	assert.NotEqual(t, "export-1", resp.ID)
+	assert.Equal(t, entity.DataExportFailed, stale.Status)
+}
+
+func TestRequestExport_ExporterFailureFailsExport(t *testing.T) {
+	uc, mockExportRepo, mockUserRepo, store := setupTest(t, fakeExporter{err: errors.New("database down")})
+	ctx := context.Background()
+
+	var created *entity.DataExport
+	mockExportRepo.EXPECT().GetActive(ctx, "user-123").Return(nil, domainerror.ErrDataExportNotFound)
+	mockExportRepo.EXPECT().Create(ctx, mock.AnythingOfType("*entity.DataExport")).
+		Run(func(ctx context.Context, export *entity.DataExport) { created = export }).
+		Return(nil)
+	mockExportRepo.EXPECT().Update(mock.Anything, mock.Anything, "status").Return(nil).Twice()
+	mockUserRepo.EXPECT().GetByID(mock.Anything, "user-123").Return(&entity.User{ID: "user-123"}, nil)
+
+	_, err := uc.RequestExport(ctx, "user-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, entity.DataExportFailed, created.Status)
+	assert.Empty(t, store.Keys())
+}
+
+func TestRequestExport_CreateError(t *testing.T) {
+	uc, mockExportRepo, _, _ := setupTest(t)
+	ctx := context.Background()
+
+	mockExportRepo.EXPECT().GetActive(ctx, "user-123").Return(nil, domainerror.ErrDataExportNotFound)
+	mockExportRepo.EXPECT().Create(ctx, mock.Anything).Return(domainerror.ErrServiceUnavailable)
+
+	resp, err := uc.RequestExport(ctx, "user-123")
+
+	assert.Nil(t, resp)
+	assert.Equal(t, domainerror.KindUnavailable, domainerror.KindOf(err))
+}
+
+func TestRequestExport_ConcurrentRequestReturnsActiveExport(t *testing.T) {
+	uc, mockExportRepo, _, _ := setupTest(t)
+	ctx := context.Background()
+
+	// Another request created its export between the lookup and the insert
+	active := &entity.DataExport{ID: "export-1", UserID: "user-123", Status: entity.DataExportPending, CreatedAt: time.Now()}
+	mockExportRepo.EXPECT().GetActive(ctx, "user-123").Return(nil, domainerror.ErrDataExportNotFound).Once()
+	mockExportRepo.EXPECT().Create(ctx, mock.Anything).Return(&domainerror.ConflictError{Field: "user_id"})
+	mockExportRepo.EXPECT().GetActive(ctx, "user-123").Return(active, nil).Once()
+
+	resp, err := uc.RequestExport(ctx, "user-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, "export-1", resp.ID)
+}
+
+func TestGetExport_OtherUsersExportIsNotFound(t *testing.T) {
+	uc, mockExportRepo, _, _ := setupTest(t)
+	ctx := context.Background()
+
+	mockExportRepo.EXPECT().GetByID(ctx, "export-1").Return(newReadyExport(), nil)
+
+	resp, err := uc.GetExport(ctx, "user-456", "export-1")
+
+	assert.Nil(t, resp)
+	assert.Equal(t, domainerror.KindNotFound, domainerror.KindOf(err))
+}
+
+func TestGetExport_PendingHasNoLink(t *testing.T) {
+	uc, mockExportRepo, _, _ := setupTest(t)
+	ctx := context.Background()
+
+	mockExportRepo.EXPECT().GetByID(ctx, "export-1").
+		Return(&entity.DataExport{ID: "export-1", UserID: "user-123", Status: entity.DataExportPending}, nil)
+
+	resp, err := uc.GetExport(ctx, "user-123", "export-1")
+
+	require.NoError(t, err)
+	assert.Empty(t, resp.DownloadURL)
+	assert.Nil(t, resp.DownloadExpiresAt)
+}
+
+// linkRequest parses a download link back into the request it makes
+func linkRequest(t *testing.T, link string) dto.DownloadRequest {
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	expires, err := strconv.ParseInt(parsed.Query().Get("expires"), 10, 64)
+	require.NoError(t, err)
+	return dto.DownloadRequest{
+		ID:        parsed.Query().Get("id"),
+		Expires:   expires,
+		Signature: parsed.Query().Get("signature"),
+	}
+}
+
+func TestGetExport_ReadyLinkDownloads(t *testing.T) {
+	uc, mockExportRepo, _, store := setupTest(t)
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, "export-1.zip", []byte("archive")))
+
+	export := newReadyExport()
+	mockExportRepo.EXPECT().GetByID(ctx, "export-1").Return(export, nil)
+
+	resp, err := uc.GetExport(ctx, "user-123", "export-1")
+
+	require.NoError(t, err)
+	assert.Contains(t, resp.DownloadURL, "https://api.example.com/api/v1/exports/download?")
+	require.NotNil(t, resp.DownloadExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), *resp.DownloadExpiresAt, time.Minute)
+
+	archive, name, err := uc.Download(ctx, linkRequest(t, resp.DownloadURL))
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte("archive"), archive)
+	assert.Equal(t, "data-export-"+export.CompletedAt.Format("2006-01-02")+".zip", name)
+}
+
+func TestGetExport_LinkNeverOutlivesArchive(t *testing.T) {
+	uc, mockExportRepo, _, _ := setupTest(t)
+	ctx := context.Background()
+
+	export := newReadyExport()
+	expiresAt := time.Now().Add(5 * time.Minute)
+	export.ExpiresAt = &expiresAt
+	mockExportRepo.EXPECT().GetByID(ctx, "export-1").Return(export, nil)
+
+	resp, err := uc.GetExport(ctx, "user-123", "export-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, expiresAt, *resp.DownloadExpiresAt)
+}
+
+func TestDownload_RejectsBadLinks(t *testing.T) {
+	uc, _, _, _ := setupTest(t)
+	ctx := context.Background()
+
+	valid := linkRequest(t, uc.downloadLink("export-1", time.Now().Add(time.Minute)))
+	tampered := valid
+	tampered.ID = "export-2"
+	extended := valid
+	extended.Expires += 3600
+	expired := linkRequest(t, uc.downloadLink("export-1", time.Now().Add(-time.Minute)))
+
+	for name, req := range map[string]dto.DownloadRequest{"tampered": tampered, "extended": extended, "expired": expired} {
+		_, _, err := uc.Download(ctx, req)
+
+		var domainErr *domainerror.Error
+		require.ErrorAs(t, err, &domainErr, name)
+		assert.Equal(t, domainerror.KindForbidden, domainErr.Kind, name)
+		assert.Equal(t, constants.InvalidDownloadLink, domainErr.Code, name)
+	}
+}
+
+func TestDownload_ExpiredExport(t *testing.T) {
+	uc, mockExportRepo, _, _ := setupTest(t)
+	ctx := context.Background()
+
+	mockExportRepo.EXPECT().GetByID(ctx, "export-1").
+		Return(&entity.DataExport{ID: "export-1", UserID: "user-123", Status: entity.DataExportExpired}, nil)
+
+	_, _, err := uc.Download(ctx, linkRequest(t, uc.downloadLink("export-1", time.Now().Add(time.Minute))))
+
+	assert.Equal(t, domainerror.KindForbidden, domainerror.KindOf(err))
+}
+
+func TestPurgeExpiredExports(t *testing.T) {
+	uc, mockExportRepo, _, store := setupTest(t)
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, "export-1.zip", []byte("archive")))
+
+	export := newReadyExport()
+	mockExportRepo.EXPECT().ListExpired(ctx, mock.AnythingOfType("time.Time"), 10).Return([]*entity.DataExport{export}, nil)
+	mockExportRepo.EXPECT().Update(ctx, export, "status", "file_key").Return(nil)
+
+	purged, err := uc.PurgeExpiredExports(ctx, 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.Equal(t, entity.DataExportExpired, export.Status)
+	assert.Empty(t, store.Keys())
+}
+
+func TestPurgeExpiredExports_InvalidBatchSize(t *testing.T) {
+	uc, mockExportRepo, _, _ := setupTest(t)
+
+	for _, batchSize := range []int{0, -1} {
+		purged, err := uc.PurgeExpiredExports(context.Background(), batchSize)
+
+		assert.ErrorContains(t, err, "batch size", batchSize)
+		assert.Zero(t, purged)
+	}
+	mockExportRepo.AssertNotCalled(t, "ListExpired", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAnonymizeUserData_DeletesArchives(t *testing.T) {
+	uc, mockExportRepo, _, store := setupTest(t)
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, "export-1.zip", []byte("archive")))
+
+	ready := newReadyExport()
+	running := &entity.DataExport{ID: "export-2", UserID: "user-123", Status: entity.DataExportRunning}
+	expired := &entity.DataExport{ID: "export-3", UserID: "user-123", Status: entity.DataExportExpired}
+	mockExportRepo.EXPECT().ListByUser(ctx, "user-123").Return([]*entity.DataExport{running, ready, expired}, nil)
+	mockExportRepo.EXPECT().Update(ctx, running, "status", "file_key").Return(nil)
+	mockExportRepo.EXPECT().Update(ctx, ready, "status", "file_key").Return(nil)
+
+	records, err := uc.AnonymizeUserData(ctx, "user-123", false)
+
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, 2, records[0].Count)
+	assert.Equal(t, entity.DataExportFailed, running.Status)
+	assert.Equal(t, entity.DataExportExpired, ready.Status)
+	assert.Empty(t, ready.FileKey)
+	assert.Empty(t, store.Keys())
+}
+
+func TestAnonymizeUserData_DryRunKeepsArchives(t *testing.T) {
+	uc, mockExportRepo, _, store := setupTest(t)
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, "export-1.zip", []byte("archive")))
+
+	ready := newReadyExport()
+	mockExportRepo.EXPECT().ListByUser(ctx, "user-123").Return([]*entity.DataExport{ready}, nil)
+	mockExportRepo.EXPECT().Update(ctx, ready, "status", "file_key").Return(nil)
+
+	records, err := uc.AnonymizeUserData(ctx, "user-123", true)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, records[0].Count)
+	assert.Equal(t, []string{"export-1.zip"}, store.Keys())
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "app/pkg/crypto"
+
+// MetricsResponse represents the runtime metrics of the service
+type MetricsResponse struct {
+	// Queue and timings of the password hashing pool, they tell how loaded the service is
+	PasswordHasher crypto.HasherStats `json:"password_hasher"`
+}
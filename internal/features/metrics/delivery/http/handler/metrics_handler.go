@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"app/internal/features/metrics/delivery/http/dto"
+	"app/internal/shared/delivery/http/response"
+	"app/pkg/crypto"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsHandler handles HTTP requests reporting runtime metrics
+type MetricsHandler struct {
+	hasher *crypto.PasswordHasher
+}
+
+// NewMetricsHandler creates a new metrics handler
+func NewMetricsHandler(hasher *crypto.PasswordHasher) *MetricsHandler {
+	return &MetricsHandler{
+		hasher: hasher,
+	}
+}
+
+// GetMetrics handles reporting the runtime metrics
+//
+//	@Summary		Get runtime metrics
+//	@Description	Report the queue depth, rejections and average wait and hashing times of the password hashing pool. Admins only
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Response{data=dto.MetricsResponse}
+//	@Failure		401	{object}	response.Response
+//	@Failure		403	{object}	response.Response
+//	@Failure		503	{object}	response.Response
+//	@Router			/api/v1/admin/metrics [get]
+func (h *MetricsHandler) GetMetrics(c *gin.Context) {
+	metrics := &dto.MetricsResponse{
+		PasswordHasher: h.hasher.Stats(),
+	}
+	response.NewResponse(c, http.StatusOK, metrics, "Metrics retrieved successfully", nil)
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	mocks "app/internal/mocks/repository"
+	"app/internal/shared/constants"
+	"app/internal/shared/delivery/http/middleware"
+	"app/internal/shared/domain/entity"
+	"app/pkg/crypto"
+	pkgjwt "app/pkg/jwt"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestRouter serves the metrics behind the role check, as a signed in user-123
+func setupTestRouter(t *testing.T) (*gin.Engine, *mocks.MockUserRepository) {
+	gin.SetMode(gin.TestMode)
+	mockRepo := mocks.NewMockUserRepository(t)
+	hasher := crypto.NewPasswordHasher(crypto.DefaultPasswordParams, crypto.HasherConfig{Workers: 2, QueueSize: 8})
+	t.Cleanup(hasher.Close)
+
+	router := gin.New()
+	router.Use(middleware.ErrorMiddleware())
+	setSession := func(c *gin.Context) {
+		c.Set(middleware.LangKey, constants.LangEN)
+		c.Set(middleware.SESS, &pkgjwt.Claims{UserID: "user-123"})
+	}
+	router.GET("/admin/metrics", setSession, middleware.RequireRole(mockRepo, entity.UserRoleAdmin), NewMetricsHandler(hasher).GetMetrics)
+	return router, mockRepo
+}
+
+func TestGetMetrics_Success(t *testing.T) {
+	router, mockRepo := setupTestRouter(t)
+	mockRepo.EXPECT().GetByID(mock.Anything, "user-123", "id", "role").Return(&entity.User{ID: "user-123", Role: entity.UserRoleAdmin}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/metrics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]any
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	stats := response["data"].(map[string]any)["password_hasher"].(map[string]any)
+	assert.Equal(t, float64(2), stats["workers"])
+	assert.Equal(t, float64(8), stats["queue_size"])
+}
+
+func TestGetMetrics_NotAdmin(t *testing.T) {
+	router, mockRepo := setupTestRouter(t)
+	mockRepo.EXPECT().GetByID(mock.Anything, "user-123", "id", "role").Return(&entity.User{ID: "user-123", Role: "user"}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/metrics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NotContains(t, w.Body.String(), "password_hasher")
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"app/internal/features/metrics/delivery/http/handler"
+	"app/internal/shared/delivery/http/middleware"
+	"app/internal/shared/domain/entity"
+	"app/internal/shared/domain/repository"
+	"app/pkg/crypto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Module is the runtime metrics feature module that combines DI and route registration
+type Module struct {
+	handler *handler.MetricsHandler
+	auth    gin.HandlerFunc
+	admin   gin.HandlerFunc
+}
+
+// NewModule creates and wires all metrics feature dependencies. hasher is the
+// password hashing pool shared by the other features
+func NewModule(userRepo repository.UserRepository, hasher *crypto.PasswordHasher) *Module {
+	return &Module{
+		handler: handler.NewMetricsHandler(hasher),
+		auth:    middleware.AuthMiddleware(userRepo),
+		admin:   middleware.RequireRole(userRepo, entity.UserRoleAdmin),
+	}
+}
+
+// Name returns the feature name
+func (m *Module) Name() string {
+	return "metrics"
+}
+
+// RegisterRoutes registers all metrics routes
+func (m *Module) RegisterRoutes(rg *gin.RouterGroup) {
+	admin := rg.Group("/admin")
+	{
+		// Admin routes - auth and role middleware applied inline
+		admin.GET("/metrics", m.auth, m.admin, m.handler.GetMetrics)
+	}
+}
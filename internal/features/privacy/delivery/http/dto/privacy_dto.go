@@ -0,0 +1,16 @@
+package dto
+
+import "app/internal/shared/domain/service"
+
+// AnonymizeRequest represents the query of an anonymization or purge
+type AnonymizeRequest struct {
+	DryRun bool `form:"dry_run"` // Report what would be erased without changing anything
+}
+
+// AnonymizationReport lists the records an anonymization touched, or would touch
+// on a dry run, one entry per table
+type AnonymizationReport struct {
+	UserID  string                      `json:"user_id"`
+	DryRun  bool                        `json:"dry_run"`
+	Records []service.AnonymizedRecords `json:"records"`
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"app/internal/features/privacy/delivery/http/dto"
+	"app/internal/features/privacy/usecase"
+	"app/internal/shared/constants"
+	"app/internal/shared/delivery/http/middleware"
+	"app/internal/shared/delivery/http/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrivacyHandler handles HTTP requests erasing personal data
+type PrivacyHandler struct {
+	privacyUsecase usecase.PrivacyUsecase
+}
+
+// NewPrivacyHandler creates a new privacy handler
+func NewPrivacyHandler(privacyUsecase usecase.PrivacyUsecase) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyUsecase: privacyUsecase,
+	}
+}
+
+// AnonymizeUser handles anonymizing a user on request of an admin
+//
+//	@Summary		Anonymize user
+//	@Description	Irreversibly erase the personal data of a user and the data features hold about them, soft deleting the account if needed. The ID stays so references remain valid. With dry_run the records that would be touched are reported without changing anything. Admins only
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"User ID"
+//	@Param			dry_run	query		bool	false	"Only report what would be erased"
+//	@Success		200		{object}	response.Response{data=dto.AnonymizationReport}
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		403		{object}	response.Response
+//	@Failure		404		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Failure		503		{object}	response.Response
+//	@Router			/api/v1/admin/users/{id}/anonymize [post]
+func (h *PrivacyHandler) AnonymizeUser(c *gin.Context) {
+	lang := middleware.GetLangFromGin(c)
+
+	var req dto.AnonymizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
+			"query": {err.Error()},
+		})
+		return
+	}
+
+	report, err := h.privacyUsecase.AnonymizeUser(c.Request.Context(), c.Param("id"), req.DryRun)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	message := "User anonymized successfully"
+	if req.DryRun {
+		message = "Dry run, nothing was changed"
+	}
+	response.NewResponse(c, http.StatusOK, report, message, nil)
+}
+
+// PurgeUser handles permanently deleting a soft-deleted user on request of an admin
+//
+//	@Summary		Purge deleted user
+//	@Description	Permanently delete a soft-deleted user, anonymized or not, along with the data features hold about them. Fails with 409 when the user is not deleted. With dry_run the records that would be deleted are reported without changing anything. Admins only
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"User ID"
+//	@Param			dry_run	query		bool	false	"Only report what would be deleted"
+//	@Success		200		{object}	response.Response{data=dto.AnonymizationReport}
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		403		{object}	response.Response
+//	@Failure		404		{object}	response.Response
+//	@Failure		409		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Failure		503		{object}	response.Response
+//	@Router			/api/v1/admin/users/{id} [delete]
+func (h *PrivacyHandler) PurgeUser(c *gin.Context) {
+	lang := middleware.GetLangFromGin(c)
+
+	var req dto.AnonymizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
+			"query": {err.Error()},
+		})
+		return
+	}
+
+	report, err := h.privacyUsecase.PurgeUser(c.Request.Context(), c.Param("id"), req.DryRun)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	message := "User purged successfully"
+	if req.DryRun {
+		message = "Dry run, nothing was changed"
+	}
+	response.NewResponse(c, http.StatusOK, report, message, nil)
+}
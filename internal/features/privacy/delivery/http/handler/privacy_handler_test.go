@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"app/internal/features/privacy/delivery/http/dto"
+	mocks "app/internal/mocks/usecase"
+	"app/internal/shared/constants"
+	"app/internal/shared/delivery/http/middleware"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/domain/service"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestRouter(h *PrivacyHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorMiddleware())
+	setLang := func(c *gin.Context) {
+		c.Set(middleware.LangKey, constants.LangEN)
+	}
+	router.POST("/admin/users/:id/anonymize", setLang, h.AnonymizeUser)
+	router.DELETE("/admin/users/:id", setLang, h.PurgeUser)
+	return router
+}
+
+func TestAnonymizeUser_DryRun(t *testing.T) {
+	mockUsecase := mocks.NewMockPrivacyUsecase(t)
+	router := setupTestRouter(NewPrivacyHandler(mockUsecase))
+
+	mockUsecase.EXPECT().
+		AnonymizeUser(mock.Anything, "user-123", true).
+		Return(&dto.AnonymizationReport{
+			UserID:  "user-123",
+			DryRun:  true,
+			Records: []service.AnonymizedRecords{{Table: "users", Action: service.AnonymizeRedact, Count: 1, Columns: []string{"email"}}},
+		}, nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/admin/users/user-123/anonymize?dry_run=true", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]any
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	data := response["data"].(map[string]any)
+	assert.Equal(t, true, data["dry_run"])
+	assert.Equal(t, "users", data["records"].([]any)[0].(map[string]any)["table"])
+}
+
+func TestAnonymizeUser_InvalidDryRun(t *testing.T) {
+	mockUsecase := mocks.NewMockPrivacyUsecase(t)
+	router := setupTestRouter(NewPrivacyHandler(mockUsecase))
+
+	req, _ := http.NewRequest(http.MethodPost, "/admin/users/user-123/anonymize?dry_run=maybe", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAnonymizeUser_NotFound(t *testing.T) {
+	mockUsecase := mocks.NewMockPrivacyUsecase(t)
+	router := setupTestRouter(NewPrivacyHandler(mockUsecase))
+
+	mockUsecase.EXPECT().
+		AnonymizeUser(mock.Anything, "user-123", false).
+		Return(nil, domainerror.New(domainerror.KindNotFound, constants.UserNotFound, nil))
+
+	req, _ := http.NewRequest(http.MethodPost, "/admin/users/user-123/anonymize", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPurgeUser_Success(t *testing.T) {
+	mockUsecase := mocks.NewMockPrivacyUsecase(t)
+	router := setupTestRouter(NewPrivacyHandler(mockUsecase))
+
+	mockUsecase.EXPECT().
+		PurgeUser(mock.Anything, "user-123", false).
+		Return(&dto.AnonymizationReport{
+			UserID:  "user-123",
+			Records: []service.AnonymizedRecords{{Table: "users", Action: service.AnonymizeDelete, Count: 1}},
+		}, nil)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/admin/users/user-123", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPurgeUser_NotDeleted(t *testing.T) {
+	mockUsecase := mocks.NewMockPrivacyUsecase(t)
+	router := setupTestRouter(NewPrivacyHandler(mockUsecase))
+
+	mockUsecase.EXPECT().
+		PurgeUser(mock.Anything, "user-123", true).
+		Return(nil, domainerror.New(domainerror.KindConflict, constants.UserNotDeleted, nil))
+
+	req, _ := http.NewRequest(http.MethodDelete, "/admin/users/user-123?dry_run=true", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
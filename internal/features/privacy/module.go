@@ -0,0 +1,59 @@
+package privacy
+
+import (
+	"app/internal/features/privacy/delivery/http/dto"
+	"app/internal/features/privacy/delivery/http/handler"
+	"app/internal/features/privacy/usecase"
+	"app/internal/shared/delivery/http/middleware"
+	"app/internal/shared/domain/entity"
+	"app/internal/shared/domain/repository"
+	"app/internal/shared/domain/service"
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Module is the privacy feature module that combines DI and route registration
+type Module struct {
+	usecase usecase.PrivacyUsecase
+	handler *handler.PrivacyHandler
+	auth    gin.HandlerFunc
+	admin   gin.HandlerFunc
+}
+
+// NewModule creates and wires all privacy feature dependencies. anonymizers are
+// the feature modules holding personal data of their own
+func NewModule(userRepo repository.UserRepository, transactor repository.Transactor, anonymizers []service.DataAnonymizer, logger *logrus.Logger) *Module {
+	// Wire dependencies
+	uc := usecase.NewPrivacyUsecase(userRepo, transactor, anonymizers, logger)
+	h := handler.NewPrivacyHandler(uc)
+
+	return &Module{
+		usecase: uc,
+		handler: h,
+		auth:    middleware.AuthMiddleware(userRepo),
+		admin:   middleware.RequireRole(userRepo, entity.UserRoleAdmin),
+	}
+}
+
+// Name returns the feature name
+func (m *Module) Name() string {
+	return "privacy"
+}
+
+// PurgeDeletedAccounts erases the accounts past their deletion grace period, and
+// deletes the anonymized ones past their retention period, see cmd/purge
+func (m *Module) PurgeDeletedAccounts(ctx context.Context, batchSize int, dryRun bool) ([]*dto.AnonymizationReport, error) {
+	return m.usecase.PurgeDeletedAccounts(ctx, batchSize, dryRun)
+}
+
+// RegisterRoutes registers all privacy routes
+func (m *Module) RegisterRoutes(rg *gin.RouterGroup) {
+	admin := rg.Group("/admin")
+	{
+		// Admin routes - auth and role middleware applied inline
+		admin.POST("/users/:id/anonymize", m.auth, m.admin, m.handler.AnonymizeUser)
+		admin.DELETE("/users/:id", m.auth, m.admin, m.handler.PurgeUser)
+	}
+}
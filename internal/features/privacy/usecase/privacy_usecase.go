@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"app/internal/core/config"
+	"app/internal/features/privacy/delivery/http/dto"
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/domain/repository"
+	"app/internal/shared/domain/service"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Account purge modes, see config.AccountConfig
+const (
+	PurgeModeAnonymize = "anonymize"
+	PurgeModeDelete    = "delete"
+)
+
+// errDryRun rolls back the transaction of a dry run once its report is complete
+var errDryRun = errors.New("dry run")
+
+// PrivacyUsecase defines the interface for erasing personal data
+type PrivacyUsecase interface {
+	AnonymizeUser(ctx context.Context, userID string, dryRun bool) (*dto.AnonymizationReport, error)
+	PurgeUser(ctx context.Context, userID string, dryRun bool) (*dto.AnonymizationReport, error)
+	PurgeDeletedAccounts(ctx context.Context, batchSize int, dryRun bool) ([]*dto.AnonymizationReport, error)
+}
+
+// privacyUsecase implements PrivacyUsecase interface
+type privacyUsecase struct {
+	userRepo    repository.UserRepository
+	transactor  repository.Transactor
+	anonymizers []service.DataAnonymizer
+	account     config.AccountConfig
+	logger      *logrus.Logger
+}
+
+// NewPrivacyUsecase creates a new privacy usecase. Every anonymizer erases the
+// data its feature holds along with the users row
+func NewPrivacyUsecase(userRepo repository.UserRepository, transactor repository.Transactor, anonymizers []service.DataAnonymizer, logger *logrus.Logger) PrivacyUsecase {
+	return &privacyUsecase{
+		userRepo:    userRepo,
+		transactor:  transactor,
+		anonymizers: anonymizers,
+		account:     config.Load().Account,
+		logger:      logger,
+	}
+}
+
+// AnonymizeUser erases the personal data of a user right away, whether the
+// account was deleted or not. An account still in use is soft deleted and its
+// access tokens are revoked. The ID stays, so rows referencing the user keep
+// pointing at it
+func (u *privacyUsecase) AnonymizeUser(ctx context.Context, userID string, dryRun bool) (*dto.AnonymizationReport, error) {
+	user, err := u.userRepo.GetByIDWithDeleted(ctx, userID)
+	if err != nil {
+		u.logger.Error("u.userRepo.GetByIDWithDeleted ", err)
+		if errors.Is(err, domainerror.ErrUserNotFound) {
+			return nil, domainerror.New(domainerror.KindNotFound, constants.UserNotFound, err)
+		}
+		return nil, domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+
+	report, err := u.erase(ctx, user, false, dryRun)
+	if err != nil {
+		u.logger.Error("u.erase ", err)
+		return nil, domainerror.Internal(constants.FailedToAnonymizeUser, err)
+	}
+	return report, nil
+}
+
+// PurgeUser permanently deletes a soft-deleted user, anonymized or not, on
+// request of an admin. The data features hold is erased first, then rows
+// referencing the user are removed by cascade
+func (u *privacyUsecase) PurgeUser(ctx context.Context, userID string, dryRun bool) (*dto.AnonymizationReport, error) {
+	user, err := u.userRepo.GetByIDWithDeleted(ctx, userID)
+	if err != nil {
+		u.logger.Error("u.userRepo.GetByIDWithDeleted ", err)
+		if errors.Is(err, domainerror.ErrUserNotFound) {
+			return nil, domainerror.New(domainerror.KindNotFound, constants.UserNotFound, err)
+		}
+		return nil, domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+	// Live accounts are deleted by their owner first, or anonymized
+	if !user.DeletedAt.Valid {
+		return nil, domainerror.New(domainerror.KindConflict, constants.UserNotDeleted, nil)
+	}
+
+	report, err := u.erase(ctx, user, true, dryRun)
+	if err != nil {
+		u.logger.Error("u.erase ", err)
+		return nil, domainerror.Internal(constants.FailedToPurgeUser, err)
+	}
+	return report, nil
+}
+
+// PurgeDeletedAccounts anonymizes or permanently deletes, depending on the purge
+// mode, the accounts whose grace period is over, batchSize at a time. When a
+// retention period is set, accounts anonymized longer ago are deleted as well.
+// It returns a report per purged account, the email and username of each are
+// released
+func (u *privacyUsecase) PurgeDeletedAccounts(ctx context.Context, batchSize int, dryRun bool) ([]*dto.AnonymizationReport, error) {
+	if u.account.PurgeMode != PurgeModeAnonymize && u.account.PurgeMode != PurgeModeDelete {
+		return nil, fmt.Errorf("unknown purge mode %q", u.account.PurgeMode)
+	}
+	// No batch would ever come back short, the purge would not end
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	before := time.Now().Add(-u.account.DeletionGracePeriod)
+	reports, err := u.purgeInBatches(ctx, batchSize, u.account.PurgeMode == PurgeModeDelete, dryRun, func(offset int) ([]*entity.User, error) {
+		users, err := u.userRepo.ListDeleted(ctx, before, offset, batchSize)
+		if err != nil {
+			return nil, fmt.Errorf("list deleted users: %w", err)
+		}
+		return users, nil
+	})
+	if err != nil || u.account.RetentionPeriod <= 0 {
+		return reports, err
+	}
+
+	// The deletion time of anonymized accounts is kept, so retention counts from it
+	retainedSince := time.Now().Add(-u.account.DeletionGracePeriod - u.account.RetentionPeriod)
+	expired, err := u.purgeInBatches(ctx, batchSize, true, dryRun, func(offset int) ([]*entity.User, error) {
+		users, err := u.userRepo.ListAnonymized(ctx, retainedSince, offset, batchSize)
+		if err != nil {
+			return nil, fmt.Errorf("list anonymized users: %w", err)
+		}
+		return users, nil
+	})
+	return append(reports, expired...), err
+}
+
+// purgeInBatches erases the users returned by list, batchSize at a time, until a
+// batch comes back short
+func (u *privacyUsecase) purgeInBatches(ctx context.Context, batchSize int, purge, dryRun bool, list func(offset int) ([]*entity.User, error)) ([]*dto.AnonymizationReport, error) {
+	var reports []*dto.AnonymizationReport
+	for offset := 0; ; {
+		users, err := list(offset)
+		if err != nil {
+			return reports, err
+		}
+
+		for _, user := range users {
+			report, err := u.erase(ctx, user, purge, dryRun)
+			if err != nil {
+				return reports, fmt.Errorf("purge user %s: %w", user.ID, err)
+			}
+			reports = append(reports, report)
+		}
+
+		if len(users) < batchSize {
+			return reports, nil
+		}
+		// Purged accounts drop out of the list, the ones of a dry run stay
+		if dryRun {
+			offset += len(users)
+		}
+	}
+}
+
+// erase runs the anonymizers of every feature, then redacts the users row, or
+// deletes it when purge is set. A dry run reports the same records and rolls
+// everything back
+func (u *privacyUsecase) erase(ctx context.Context, user *entity.User, purge, dryRun bool) (*dto.AnonymizationReport, error) {
+	report := &dto.AnonymizationReport{UserID: user.ID, DryRun: dryRun}
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, anonymizer := range u.anonymizers {
+			records, err := anonymizer.AnonymizeUserData(ctx, user.ID, dryRun)
+			if err != nil {
+				return fmt.Errorf("anonymize %s data: %w", anonymizer.Name(), err)
+			}
+			report.Records = append(report.Records, records...)
+		}
+
+		records, err := u.eraseUser(ctx, user, purge)
+		if err != nil {
+			return err
+		}
+		report.Records = append(report.Records, records)
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	u.logger.WithFields(logrus.Fields{"user_id": user.ID, "dry_run": dryRun, "records": report.Records}).Info("user data erased")
+	return report, nil
+}
+
+// eraseUser redacts or deletes the users row
+func (u *privacyUsecase) eraseUser(ctx context.Context, user *entity.User, purge bool) (service.AnonymizedRecords, error) {
+	if purge {
+		if err := u.userRepo.Purge(ctx, user.ID); err != nil {
+			return service.AnonymizedRecords{}, fmt.Errorf("purge user: %w", err)
+		}
+		return service.AnonymizedRecords{Table: "users", Action: service.AnonymizeDelete, Count: 1}, nil
+	}
+
+	// Anonymized accounts are always soft deleted, so they can neither log in
+	// nor be found
+	var revoked []string
+	if !user.DeletedAt.Valid {
+		user.TokenVersion++
+		revoked = []string{"token_version"}
+		if err := u.userRepo.Delete(ctx, user.ID); err != nil {
+			return service.AnonymizedRecords{}, fmt.Errorf("delete user: %w", err)
+		}
+	}
+
+	columns := user.Anonymize()
+	if err := u.userRepo.UpdateDeleted(ctx, user, append(columns, revoked...)...); err != nil {
+		return service.AnonymizedRecords{}, fmt.Errorf("anonymize user: %w", err)
+	}
+	return service.AnonymizedRecords{Table: "users", Action: service.AnonymizeRedact, Count: 1, Columns: columns}, nil
+}
@@ -0,0 +1,345 @@
+package usecase
+
+import (
+	"app/internal/core/config"
+	mocks "app/internal/mocks/repository"
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/domain/service"
+	"app/internal/testutil"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeAnonymizer erases a fixed number of rows of a feature table
+type fakeAnonymizer struct {
+	count  int
+	err    error
+	dryRun []bool // dryRun of every call
+}
+
+func (a *fakeAnonymizer) Name() string {
+	return "fake"
+}
+
+func (a *fakeAnonymizer) AnonymizeUserData(ctx context.Context, userID string, dryRun bool) ([]service.AnonymizedRecords, error) {
+	a.dryRun = append(a.dryRun, dryRun)
+	if a.err != nil {
+		return nil, a.err
+	}
+	return []service.AnonymizedRecords{{Table: "fake_table", Action: service.AnonymizeDelete, Count: a.count}}, nil
+}
+
+func setupTest(t *testing.T, purgeMode string) (*privacyUsecase, *mocks.MockUserRepository, *fakeAnonymizer) {
+	mockRepo := mocks.NewMockUserRepository(t)
+	anonymizer := &fakeAnonymizer{count: 2}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	uc := &privacyUsecase{
+		userRepo:    mockRepo,
+		transactor:  testutil.InlineTransactor{},
+		anonymizers: []service.DataAnonymizer{anonymizer},
+		account: config.AccountConfig{
+			DeletionGracePeriod: 30 * 24 * time.Hour,
+			PurgeMode:           purgeMode,
+		},
+		logger: logger,
+	}
+
+	return uc, mockRepo, anonymizer
+}
+
+func newUser(deleted bool) *entity.User {
+	user := entity.NewUser("test@example.com", "testuser", "hashed", "Test", "User")
+	user.ID = "user-123"
+	user.TokenVersion = 2
+	if deleted {
+		user.DeletedAt = gorm.DeletedAt{Time: time.Now().Add(-40 * 24 * time.Hour), Valid: true}
+	}
+	return user
+}
+
+// anonymizedColumns returns the columns written when anonymizing a user, as
+// variadic mock arguments
+func anonymizedColumns(extra ...string) []interface{} {
+	var columns []interface{}
+	for _, column := range append((&entity.User{}).Anonymize(), extra...) {
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+func TestAnonymizeUser_ActiveAccount(t *testing.T) {
+	uc, mockRepo, anonymizer := setupTest(t, PurgeModeAnonymize)
+	ctx := context.Background()
+
+	user := newUser(false)
+	mockRepo.EXPECT().GetByIDWithDeleted(ctx, "user-123").Return(user, nil)
+	// An account in use is soft deleted and its tokens revoked first
+	mockRepo.EXPECT().Delete(ctx, "user-123").Return(nil)
+	mockRepo.EXPECT().UpdateDeleted(ctx, user, anonymizedColumns("token_version")...).Return(nil)
+
+	report, err := uc.AnonymizeUser(ctx, "user-123", false)
+
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false}, anonymizer.dryRun)
+	assert.False(t, report.DryRun)
+	require.Len(t, report.Records, 2)
+	assert.Equal(t, "fake_table", report.Records[0].Table)
+	assert.Equal(t, service.AnonymizedRecords{Table: "users", Action: service.AnonymizeRedact, Count: 1, Columns: (&entity.User{}).Anonymize()}, report.Records[1])
+	assert.Equal(t, "deleted_user123@deleted.invalid", user.Email)
+	assert.Equal(t, 3, user.TokenVersion)
+}
+
+func TestAnonymizeUser_DeletedAccount(t *testing.T) {
+	uc, mockRepo, _ := setupTest(t, PurgeModeAnonymize)
+	ctx := context.Background()
+
+	user := newUser(true)
+	mockRepo.EXPECT().GetByIDWithDeleted(ctx, "user-123").Return(user, nil)
+	mockRepo.EXPECT().UpdateDeleted(ctx, user, anonymizedColumns()...).Return(nil)
+
+	_, err := uc.AnonymizeUser(ctx, "user-123", false)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, user.TokenVersion)
+}
+
+func TestAnonymizeUser_DryRun(t *testing.T) {
+	uc, mockRepo, anonymizer := setupTest(t, PurgeModeAnonymize)
+	ctx := context.Background()
+
+	var txErr error
+	uc.transactor = recordingTransactor{err: &txErr}
+	user := newUser(true)
+	mockRepo.EXPECT().GetByIDWithDeleted(ctx, "user-123").Return(user, nil)
+	mockRepo.EXPECT().UpdateDeleted(ctx, user, anonymizedColumns()...).Return(nil)
+
+	report, err := uc.AnonymizeUser(ctx, "user-123", true)
+
+	require.NoError(t, err)
+	// The unit of work fails so the transaction is rolled back
+	assert.ErrorIs(t, txErr, errDryRun)
+	assert.Equal(t, []bool{true}, anonymizer.dryRun)
+	assert.True(t, report.DryRun)
+	assert.Len(t, report.Records, 2)
+}
+
+// recordingTransactor runs the unit of work directly and records its error
+type recordingTransactor struct {
+	err *error
+}
+
+func (r recordingTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	*r.err = fn(ctx)
+	return *r.err
+}
+
+func TestAnonymizeUser_NotFound(t *testing.T) {
+	uc, mockRepo, _ := setupTest(t, PurgeModeAnonymize)
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetByIDWithDeleted(ctx, "user-123").Return(nil, domainerror.ErrUserNotFound)
+
+	report, err := uc.AnonymizeUser(ctx, "user-123", false)
+
+	assert.Nil(t, report)
+	assert.Equal(t, domainerror.KindNotFound, domainerror.KindOf(err))
+}
+
+func TestAnonymizeUser_AnonymizerFails(t *testing.T) {
+	uc, mockRepo, anonymizer := setupTest(t, PurgeModeAnonymize)
+	ctx := context.Background()
+
+	anonymizer.err = errors.New("table locked")
+	mockRepo.EXPECT().GetByIDWithDeleted(ctx, "user-123").Return(newUser(true), nil)
+
+	report, err := uc.AnonymizeUser(ctx, "user-123", false)
+
+	assert.Nil(t, report)
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, constants.FailedToAnonymizeUser, domainErr.Code)
+}
+
+func TestPurgeUser_Success(t *testing.T) {
+	uc, mockRepo, anonymizer := setupTest(t, PurgeModeAnonymize)
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetByIDWithDeleted(ctx, "user-123").Return(newUser(true), nil)
+	mockRepo.EXPECT().Purge(ctx, "user-123").Return(nil)
+
+	report, err := uc.PurgeUser(ctx, "user-123", false)
+
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false}, anonymizer.dryRun)
+	assert.Equal(t, service.AnonymizedRecords{Table: "users", Action: service.AnonymizeDelete, Count: 1}, report.Records[1])
+}
+
+func TestPurgeUser_NotDeleted(t *testing.T) {
+	uc, mockRepo, anonymizer := setupTest(t, PurgeModeAnonymize)
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetByIDWithDeleted(ctx, "user-123").Return(newUser(false), nil)
+
+	report, err := uc.PurgeUser(ctx, "user-123", false)
+
+	assert.Nil(t, report)
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, constants.UserNotDeleted, domainErr.Code)
+	assert.Empty(t, anonymizer.dryRun)
+}
+
+func TestPurgeUser_Fails(t *testing.T) {
+	uc, mockRepo, _ := setupTest(t, PurgeModeAnonymize)
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetByIDWithDeleted(ctx, "user-123").Return(newUser(true), nil)
+	mockRepo.EXPECT().Purge(ctx, "user-123").Return(sql.ErrConnDone)
+
+	_, err := uc.PurgeUser(ctx, "user-123", false)
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, constants.FailedToPurgeUser, domainErr.Code)
+}
+
+func TestPurgeDeletedAccounts_Anonymize(t *testing.T) {
+	uc, mockRepo, _ := setupTest(t, PurgeModeAnonymize)
+	ctx := context.Background()
+
+	user := newUser(true)
+	mockRepo.EXPECT().ListDeleted(ctx, mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) >= uc.account.DeletionGracePeriod
+	}), 0, 10).Return([]*entity.User{user}, nil)
+	mockRepo.EXPECT().UpdateDeleted(ctx, user, anonymizedColumns()...).Return(nil)
+
+	reports, err := uc.PurgeDeletedAccounts(ctx, 10, false)
+
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, "user-123", reports[0].UserID)
+	// Email and username are released
+	assert.Equal(t, "deleted_user123@deleted.invalid", user.Email)
+	assert.Equal(t, "deleted_user123", user.UsernameNormalized)
+	assert.Empty(t, user.Password)
+	assert.Equal(t, entity.UserStatusAnonymized, user.Status)
+}
+
+func TestPurgeDeletedAccounts_DeleteInBatches(t *testing.T) {
+	uc, mockRepo, anonymizer := setupTest(t, PurgeModeDelete)
+	ctx := context.Background()
+
+	first := []*entity.User{{ID: "user-1"}, {ID: "user-2"}}
+	second := []*entity.User{{ID: "user-3"}}
+	// Purged accounts drop out of the list
+	mockRepo.EXPECT().ListDeleted(ctx, mock.Anything, 0, 2).Return(first, nil).Once()
+	mockRepo.EXPECT().ListDeleted(ctx, mock.Anything, 0, 2).Return(second, nil).Once()
+	for _, id := range []string{"user-1", "user-2", "user-3"} {
+		mockRepo.EXPECT().Purge(ctx, id).Return(nil)
+	}
+
+	reports, err := uc.PurgeDeletedAccounts(ctx, 2, false)
+
+	require.NoError(t, err)
+	assert.Len(t, reports, 3)
+	// Feature data, such as files, is erased before the cascade
+	assert.Len(t, anonymizer.dryRun, 3)
+	assert.Equal(t, service.AnonymizedRecords{Table: "users", Action: service.AnonymizeDelete, Count: 1}, reports[2].Records[1])
+}
+
+func TestPurgeDeletedAccounts_DryRunPages(t *testing.T) {
+	uc, mockRepo, _ := setupTest(t, PurgeModeDelete)
+	ctx := context.Background()
+
+	mockRepo.EXPECT().ListDeleted(ctx, mock.Anything, 0, 2).Return([]*entity.User{{ID: "user-1"}, {ID: "user-2"}}, nil)
+	mockRepo.EXPECT().ListDeleted(ctx, mock.Anything, 2, 2).Return([]*entity.User{{ID: "user-3"}}, nil)
+	for _, id := range []string{"user-1", "user-2", "user-3"} {
+		mockRepo.EXPECT().Purge(ctx, id).Return(nil)
+	}
+
+	reports, err := uc.PurgeDeletedAccounts(ctx, 2, true)
+
+	require.NoError(t, err)
+	require.Len(t, reports, 3)
+	assert.True(t, reports[0].DryRun)
+}
+
+func TestPurgeDeletedAccounts_Retention(t *testing.T) {
+	uc, mockRepo, _ := setupTest(t, PurgeModeAnonymize)
+	ctx := context.Background()
+
+	uc.account.RetentionPeriod = 365 * 24 * time.Hour
+	mockRepo.EXPECT().ListDeleted(ctx, mock.Anything, 0, 10).Return(nil, nil)
+	// Retention runs from the end of the grace period, when accounts are anonymized
+	mockRepo.EXPECT().ListAnonymized(ctx, mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) >= uc.account.DeletionGracePeriod+uc.account.RetentionPeriod
+	}), 0, 10).Return([]*entity.User{{ID: "user-1", Status: entity.UserStatusAnonymized}}, nil)
+	mockRepo.EXPECT().Purge(ctx, "user-1").Return(nil)
+
+	reports, err := uc.PurgeDeletedAccounts(ctx, 10, false)
+
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, service.AnonymizeDelete, reports[0].Records[1].Action)
+}
+
+func TestPurgeDeletedAccounts_KeepsAnonymizedWithoutRetention(t *testing.T) {
+	uc, mockRepo, _ := setupTest(t, PurgeModeAnonymize)
+	ctx := context.Background()
+
+	mockRepo.EXPECT().ListDeleted(ctx, mock.Anything, 0, 10).Return(nil, nil)
+
+	reports, err := uc.PurgeDeletedAccounts(ctx, 10, false)
+
+	require.NoError(t, err)
+	assert.Empty(t, reports)
+	mockRepo.AssertNotCalled(t, "ListAnonymized", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPurgeDeletedAccounts_StopsOnError(t *testing.T) {
+	uc, mockRepo, _ := setupTest(t, PurgeModeDelete)
+	ctx := context.Background()
+
+	mockRepo.EXPECT().ListDeleted(ctx, mock.Anything, 0, 10).Return([]*entity.User{{ID: "user-1"}, {ID: "user-2"}}, nil)
+	mockRepo.EXPECT().Purge(ctx, "user-1").Return(sql.ErrConnDone)
+
+	reports, err := uc.PurgeDeletedAccounts(ctx, 10, false)
+
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	assert.Empty(t, reports)
+}
+
+func TestPurgeDeletedAccounts_UnknownMode(t *testing.T) {
+	uc, _, _ := setupTest(t, "shred")
+
+	reports, err := uc.PurgeDeletedAccounts(context.Background(), 10, false)
+
+	assert.Error(t, err)
+	assert.Empty(t, reports)
+}
+
+func TestPurgeDeletedAccounts_InvalidBatchSize(t *testing.T) {
+	uc, mockRepo, _ := setupTest(t, PurgeModeDelete)
+
+	for _, batchSize := range []int{0, -1} {
+		reports, err := uc.PurgeDeletedAccounts(context.Background(), batchSize, false)
+
+		assert.ErrorContains(t, err, "batch size", batchSize)
+		assert.Empty(t, reports)
+	}
+	mockRepo.AssertNotCalled(t, "ListDeleted", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -4,6 +4,9 @@ import (
 	"app/internal/shared/constants"
 	"app/internal/shared/domain/entity"
 	"app/pkg"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -11,37 +14,297 @@ import (
 type UpdateProfileRequest struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
+	Phone     string `json:"phone,omitempty"`
+	BirthDate string `json:"birth_date,omitempty" example:"1990-01-31"`
+	Gender    string `json:"gender,omitempty"`
 }
 
-// Validate validates UpdateProfileRequest fields
-func (r *UpdateProfileRequest) Validate(lang constants.Lang) map[string][]string {
+// Validate validates UpdateProfileRequest fields, gender against the accepted values
+func (r *UpdateProfileRequest) Validate(lang constants.Lang, genders []string) map[string][]string {
 	errors := make(map[string][]string)
 
 	// At least one field should be provided
-	if r.FirstName == "" && r.LastName == "" {
+	if r.FirstName == "" && r.LastName == "" && r.Phone == "" && r.BirthDate == "" && r.Gender == "" {
 		errors["first_name"] = append(errors["first_name"], constants.GetValidationMessage(constants.Required, lang))
 		errors["last_name"] = append(errors["last_name"], constants.GetValidationMessage(constants.Required, lang))
 	}
 
+	if r.Phone != "" {
+		if _, ok := constants.NormalizePhone(r.Phone); !ok {
+			errors["phone"] = append(errors["phone"], constants.GetValidationMessage(constants.InvalidPhone, lang))
+		}
+	}
+	if r.BirthDate != "" {
+		if _, ok := constants.ParseBirthDate(r.BirthDate); !ok {
+			errors["birth_date"] = append(errors["birth_date"], constants.GetValidationMessage(constants.InvalidBirthDate, lang))
+		}
+	}
+	if r.Gender != "" && !constants.IsOneOf(r.Gender, genders) {
+		errors["gender"] = append(errors["gender"], fmt.Sprintf(constants.GetValidationMessage(constants.OneOf, lang), "gender", strings.Join(genders, ", ")))
+	}
+
+	return errors
+}
+
+// ApplyTo sets the non-empty fields on user and returns the columns it changed
+func (r *UpdateProfileRequest) ApplyTo(user *entity.User) []string {
+	// Empty values leave the field unchanged
+	var columns []string
+	if r.FirstName != "" {
+		user.FirstName = r.FirstName
+		columns = append(columns, "first_name")
+	}
+	if r.LastName != "" {
+		user.LastName = r.LastName
+		columns = append(columns, "last_name")
+	}
+	if phone, ok := constants.NormalizePhone(r.Phone); ok {
+		columns = append(columns, applyPhone(user, &phone)...)
+	}
+	if birthDate, ok := constants.ParseBirthDate(r.BirthDate); ok {
+		user.BirthDate = &birthDate
+		columns = append(columns, "birth_date")
+	}
+	if r.Gender != "" {
+		user.Gender = r.Gender
+		columns = append(columns, "gender")
+	}
+	return columns
+}
+
+// PatchProfileRequest is an RFC 7396 JSON merge patch of the user profile: absent
+// fields stay unchanged and null clears a field
+type PatchProfileRequest struct {
+	FirstName pkg.Nullable[string] `json:"first_name" swaggertype:"string"`
+	LastName  pkg.Nullable[string] `json:"last_name" swaggertype:"string"`
+	Phone     pkg.Nullable[string] `json:"phone" swaggertype:"string"`
+	BirthDate pkg.Nullable[string] `json:"birth_date" swaggertype:"string" example:"1990-01-31"`
+	Gender    pkg.Nullable[string] `json:"gender" swaggertype:"string"`
+}
+
+// Validate validates the fields present in the patch, gender against the accepted values
+func (r *PatchProfileRequest) Validate(lang constants.Lang, genders []string) map[string][]string {
+	errors := make(map[string][]string)
+
+	// Names are mandatory, they can be changed but not cleared
+	for field, value := range map[string]pkg.Nullable[string]{"first_name": r.FirstName, "last_name": r.LastName} {
+		switch {
+		case !value.Set:
+		case value.Null:
+			errors[field] = append(errors[field], fmt.Sprintf(constants.GetValidationMessage(constants.NotNullable, lang), field))
+		case value.Value == "":
+			errors[field] = append(errors[field], fmt.Sprintf(constants.GetValidationMessage(constants.Required, lang), field))
+		case !constants.MaxLength(value.Value, 100):
+			errors[field] = append(errors[field], fmt.Sprintf(constants.GetValidationMessage(constants.TooLong, lang), field, 100))
+		}
+	}
+
+	if r.Phone.Set && !r.Phone.Null {
+		if _, ok := constants.NormalizePhone(r.Phone.Value); !ok {
+			errors["phone"] = append(errors["phone"], constants.GetValidationMessage(constants.InvalidPhone, lang))
+		}
+	}
+	if r.BirthDate.Set && !r.BirthDate.Null {
+		if _, ok := constants.ParseBirthDate(r.BirthDate.Value); !ok {
+			errors["birth_date"] = append(errors["birth_date"], constants.GetValidationMessage(constants.InvalidBirthDate, lang))
+		}
+	}
+	if r.Gender.Set && !r.Gender.Null && !constants.IsOneOf(r.Gender.Value, genders) {
+		errors["gender"] = append(errors["gender"], fmt.Sprintf(constants.GetValidationMessage(constants.OneOf, lang), "gender", strings.Join(genders, ", ")))
+	}
+
 	return errors
 }
 
+// ApplyTo merges the patch into user and returns the columns it changed,
+// including the ones cleared to their zero value
+func (r *PatchProfileRequest) ApplyTo(user *entity.User) []string {
+	var columns []string
+
+	if r.FirstName.Set {
+		user.FirstName = r.FirstName.Value
+		columns = append(columns, "first_name")
+	}
+	if r.LastName.Set {
+		user.LastName = r.LastName.Value
+		columns = append(columns, "last_name")
+	}
+	if r.Phone.Set {
+		var phone *string
+		if normalized, ok := constants.NormalizePhone(r.Phone.Value); !r.Phone.Null && ok {
+			phone = &normalized
+		}
+		columns = append(columns, applyPhone(user, phone)...)
+	}
+	if r.BirthDate.Set {
+		user.BirthDate = nil
+		if birthDate, ok := constants.ParseBirthDate(r.BirthDate.Value); !r.BirthDate.Null && ok {
+			user.BirthDate = &birthDate
+		}
+		columns = append(columns, "birth_date")
+	}
+	if r.Gender.Set {
+		user.Gender = r.Gender.Value
+		columns = append(columns, "gender")
+	}
+
+	return columns
+}
+
+// ChangeUsernameRequest represents the request for changing the username
+type ChangeUsernameRequest struct {
+	Username string `json:"username"`
+}
+
+// Validate validates ChangeUsernameRequest fields with the same rules as
+// registration, reserved lists the usernames nobody can take
+func (r *ChangeUsernameRequest) Validate(lang constants.Lang, reserved []string) map[string][]string {
+	errors := make(map[string][]string)
+
+	if r.Username == "" {
+		errors["username"] = append(errors["username"], fmt.Sprintf(constants.GetValidationMessage(constants.Required, lang), "username"))
+		return errors
+	}
+	if !constants.MinLength(r.Username, 3) {
+		errors["username"] = append(errors["username"], fmt.Sprintf(constants.GetValidationMessage(constants.UsernameTooShort, lang), 3))
+	}
+	if !constants.MaxLength(r.Username, 20) {
+		errors["username"] = append(errors["username"], fmt.Sprintf(constants.GetValidationMessage(constants.UsernameTooLong, lang), 20))
+	}
+	if !constants.IsValidUsername(r.Username) {
+		errors["username"] = append(errors["username"], constants.GetValidationMessage(constants.InvalidUsername, lang))
+	} else if constants.IsReservedUsername(r.Username, reserved) {
+		errors["username"] = append(errors["username"], constants.GetValidationMessage(constants.ReservedUsername, lang))
+	}
+
+	return errors
+}
+
+// DeleteAccountRequest represents the request for deleting the own account,
+// confirmed by re-entering the password
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// Validate validates DeleteAccountRequest fields
+func (r *DeleteAccountRequest) Validate(lang constants.Lang) map[string][]string {
+	errors := make(map[string][]string)
+
+	if r.Password == "" {
+		errors["password"] = append(errors["password"], fmt.Sprintf(constants.GetValidationMessage(constants.Required, lang), "password"))
+	}
+
+	return errors
+}
+
+// applyPhone sets the phone on user and returns the columns it changed. A different
+// number is no longer verified, so verification and SMS two-factor are reset with it
+func applyPhone(user *entity.User, phone *string) []string {
+	columns := []string{"phone"}
+	if user.Phone != nil && (phone == nil || *phone != *user.Phone) {
+		user.PhoneVerifiedAt = nil
+		user.TwoFactorEnabled = false
+		columns = append(columns, "phone_verified_at", "two_factor_enabled")
+	}
+	user.Phone = phone
+	return columns
+}
+
 // UserResponse represents a user data in response
 type UserResponse struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Username  string    `json:"username"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Phone     *string   `json:"phone,omitempty"`
-	Status    string    `json:"status"`
-	BirthDate *string   `json:"birth_date,omitempty"`
-	Gender    string    `json:"gender,omitempty"`
-	Role      string    `json:"role"`
-	Provider  string    `json:"provider,omitempty"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID               string     `json:"id"`
+	Email            string     `json:"email"`
+	Username         string     `json:"username"`
+	FirstName        string     `json:"first_name"`
+	LastName         string     `json:"last_name"`
+	Phone            *string    `json:"phone,omitempty"`
+	PhoneVerifiedAt  *time.Time `json:"phone_verified_at,omitempty"`
+	Status           string     `json:"status"`
+	BirthDate        *string    `json:"birth_date,omitempty"`
+	Gender           string     `json:"gender,omitempty"`
+	Role             string     `json:"role"`
+	Provider         string     `json:"provider,omitempty"`
+	IsActive         bool       `json:"is_active"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"` // Only set on soft-deleted users
+	Version          int        `json:"-"`                    // Sent as the ETag header
+
+	fields   []string
+	embedded map[string]any
+}
+
+// UserFields maps the public field names accepted by the fields query parameter to their columns
+var UserFields = map[string]string{
+	"id":                 "id",
+	"email":              "email",
+	"username":           "username",
+	"first_name":         "first_name",
+	"last_name":          "last_name",
+	"phone":              "phone",
+	"phone_verified_at":  "phone_verified_at",
+	"status":             "status",
+	"birth_date":         "birth_date",
+	"gender":             "gender",
+	"role":               "role",
+	"provider":           "provider",
+	"is_active":          "is_active",
+	"two_factor_enabled": "two_factor_enabled",
+	"created_at":         "created_at",
+	"updated_at":         "updated_at",
+	"deleted_at":         "deleted_at",
+}
+
+// Sparse limits the rendered JSON to the given public field names
+func (r *UserResponse) Sparse(fields []string) *UserResponse {
+	r.fields = fields
+	return r
+}
+
+// Embed attaches an expanded related resource rendered under name
+func (r *UserResponse) Embed(name string, value any) {
+	if r.embedded == nil {
+		r.embedded = make(map[string]any)
+	}
+	r.embedded[name] = value
+}
+
+// MarshalJSON renders the sparse fieldset and embedded resources when requested
+func (r UserResponse) MarshalJSON() ([]byte, error) {
+	type alias UserResponse
+	if len(r.fields) == 0 && len(r.embedded) == 0 {
+		return json.Marshal(alias(r))
+	}
+
+	raw, err := json.Marshal(alias(r))
+	if err != nil {
+		return nil, err
+	}
+
+	var out map[string]any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+
+	// Drop every field that was not requested
+	if len(r.fields) > 0 {
+		keep := make(map[string]bool, len(r.fields))
+		for _, field := range r.fields {
+			keep[field] = true
+		}
+		for key := range out {
+			if !keep[key] {
+				delete(out, key)
+			}
+		}
+	}
+
+	for name, value := range r.embedded {
+		out[name] = value
+	}
+
+	return json.Marshal(out)
 }
 
 // ToUserResponse converts entity.User to UserResponse
@@ -51,19 +314,27 @@ func ToUserResponse(user *entity.User) *UserResponse {
 	}
 
 	response := &UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		Username:  user.Username,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Phone:     user.Phone,
-		Status:    user.Status,
-		Gender:    user.Gender,
-		Role:      user.Role,
-		Provider:  user.Provider,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:               user.ID,
+		Email:            user.Email,
+		Username:         user.Username,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Phone:            user.Phone,
+		PhoneVerifiedAt:  user.PhoneVerifiedAt,
+		Status:           user.Status,
+		Gender:           user.Gender,
+		Role:             user.Role,
+		Provider:         user.Provider,
+		IsActive:         user.IsActive,
+		TwoFactorEnabled: user.TwoFactorEnabled,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+		Version:          user.Version,
+	}
+
+	if user.DeletedAt.Valid {
+		deletedAt := user.DeletedAt.Time
+		response.DeletedAt = &deletedAt
 	}
 
 	// Format birth date if exists
@@ -1,13 +1,17 @@
 package handler
 
 import (
+	"app/internal/core/config"
 	"app/internal/features/user/delivery/http/dto"
 	"app/internal/features/user/usecase"
 	"app/internal/shared/constants"
 	"app/internal/shared/delivery/http/middleware"
 	"app/internal/shared/delivery/http/response"
+	"app/pkg"
 	"app/pkg/jwt"
 	"net/http"
+	"net/url"
+	"path"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,12 +19,17 @@ import (
 // UserHandler handles HTTP requests for user operations
 type UserHandler struct {
 	userUsecase usecase.UserUsecase
+	profile     config.ProfileConfig
+	username    config.UsernameConfig
 }
 
 // NewUserHandler creates a new user handler
 func NewUserHandler(userUsecase usecase.UserUsecase) *UserHandler {
+	cfg := config.Load()
 	return &UserHandler{
 		userUsecase: userUsecase,
+		profile:     cfg.Profile,
+		username:    cfg.Username,
 	}
 }
 
@@ -32,10 +41,15 @@ func NewUserHandler(userUsecase usecase.UserUsecase) *UserHandler {
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Success		200	{object}	response.Response{data=dto.UserResponse}
-//	@Failure		401	{object}	response.Response
-//	@Failure		404	{object}	response.Response
-//	@Failure		500	{object}	response.Response
+//	@Param			fields	query		string	false	"Comma-separated fields to return (e.g. id,username)"
+//	@Param			expand	query		string	false	"Comma-separated related resources to embed"
+//	@Success		200		{object}	response.Response{data=dto.UserResponse}
+//	@Header			200		{string}	ETag	"Profile version, send it as If-Match when updating"
+//	@Failure		400		{object}	response.Response
+//	@Failure		401		{object}	response.Response
+//	@Failure		404		{object}	response.Response
+//	@Failure		500		{object}	response.Response
+//	@Failure		503		{object}	response.Response
 //	@Router			/api/v1/users/profile [get]
 func (h *UserHandler) GetProfile(c *gin.Context) {
 	lang := middleware.GetLangFromGin(c)
@@ -43,23 +57,32 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 	// Get claims from context
 	claimsVal, exists := c.Get("sess")
 	if !exists {
-		response.NewResponse(c, http.StatusUnauthorized, nil, constants.GetErrorMessage(constants.Unauthorized, lang), nil)
+		response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, lang), nil)
 		return
 	}
 
 	claims, ok := claimsVal.(*jwt.Claims)
 	if !ok {
-		response.NewResponse(c, http.StatusUnauthorized, nil, constants.GetErrorMessage(constants.Unauthorized, lang), nil)
+		response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, lang), nil)
 		return
 	}
 
-	user, status, err := h.userUsecase.GetProfile(c.Request.Context(), claims.UserID)
+	queries := map[string]string{}
+	if err := c.BindQuery(&queries); err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
+			"query": {err.Error()},
+		})
+		return
+	}
+
+	user, err := h.userUsecase.GetProfile(c.Request.Context(), claims.UserID, queries)
 	if err != nil {
-		response.NewResponse(c, status, nil, err.Error(), nil)
+		_ = c.Error(err)
 		return
 	}
 
-	response.NewResponse(c, status, user, "Profile retrieved successfully", nil)
+	c.Header("ETag", pkg.FormatETag(user.Version))
+	response.NewResponse(c, http.StatusOK, user, "Profile retrieved successfully", nil)
 }
 
 // UpdateProfile handles updating user profile
@@ -70,32 +93,282 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Param			request	body		dto.UpdateProfileRequest	true	"Profile update data"
-//	@Success		200		{object}	response.Response{data=dto.UserResponse}
+//	@Param			request		body		dto.UpdateProfileRequest	true	"Profile update data"
+//	@Param			If-Match	header		string						false	"ETag of the profile last read, the update fails with 412 once it changed"
+//	@Success		200			{object}	response.Response{data=dto.UserResponse}
+//	@Header			200			{string}	ETag	"Updated profile version"
+//	@Failure		400			{object}	response.Response
+//	@Failure		401			{object}	response.Response
+//	@Failure		404			{object}	response.Response
+//	@Failure		409			{object}	response.Response
+//	@Failure		412			{object}	response.Response
+//	@Failure		500			{object}	response.Response
+//	@Failure		503			{object}	response.Response
+//	@Router			/api/v1/users/profile [put]
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	lang := middleware.GetLangFromGin(c)
+
+	// Get claims from context
+	claimsVal, exists := c.Get("sess")
+	if !exists {
+		response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, lang), nil)
+		return
+	}
+
+	claims, ok := claimsVal.(*jwt.Claims)
+	if !ok {
+		response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, lang), nil)
+		return
+	}
+
+	var req dto.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
+			"body": {err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if errors := req.Validate(lang, h.profile.Genders); len(errors) > 0 {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), errors)
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		response.NewErrorResponse(c, http.StatusPreconditionFailed, constants.GetError(constants.UserModified, lang), nil)
+		return
+	}
+
+	user, err := h.userUsecase.UpdateProfile(c.Request.Context(), claims.UserID, version, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("ETag", pkg.FormatETag(user.Version))
+	response.NewResponse(c, http.StatusOK, user, "Profile updated successfully", nil)
+}
+
+// PatchProfile handles partially updating user profile
+//
+//	@Summary		Patch user profile
+//	@Description	Apply an RFC 7396 JSON merge patch to the authenticated user's profile, absent fields stay unchanged and null clears a field
+//	@Tags			users
+//	@Accept			json
+//	@Accept			application/merge-patch+json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request		body		dto.PatchProfileRequest	true	"Merge patch of the profile"
+//	@Param			If-Match	header		string					false	"ETag of the profile last read, the update fails with 412 once it changed"
+//	@Success		200			{object}	response.Response{data=dto.UserResponse}
+//	@Header			200			{string}	ETag	"Updated profile version"
+//	@Failure		400			{object}	response.Response
+//	@Failure		401			{object}	response.Response
+//	@Failure		404			{object}	response.Response
+//	@Failure		409			{object}	response.Response
+//	@Failure		412			{object}	response.Response
+//	@Failure		500			{object}	response.Response
+//	@Failure		503			{object}	response.Response
+//	@Router			/api/v1/users/profile [patch]
+func (h *UserHandler) PatchProfile(c *gin.Context) {
+	lang := middleware.GetLangFromGin(c)
+
+	// Get claims from context
+	claimsVal, exists := c.Get("sess")
+	if !exists {
+		response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, lang), nil)
+		return
+	}
+
+	claims, ok := claimsVal.(*jwt.Claims)
+	if !ok {
+		response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, lang), nil)
+		return
+	}
+
+	var req dto.PatchProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
+			"body": {err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if errors := req.Validate(lang, h.profile.Genders); len(errors) > 0 {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), errors)
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		response.NewErrorResponse(c, http.StatusPreconditionFailed, constants.GetError(constants.UserModified, lang), nil)
+		return
+	}
+
+	user, err := h.userUsecase.PatchProfile(c.Request.Context(), claims.UserID, version, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("ETag", pkg.FormatETag(user.Version))
+	response.NewResponse(c, http.StatusOK, user, "Profile updated successfully", nil)
+}
+
+// ChangeUsername handles changing the username
+//
+//	@Summary		Change username
+//	@Description	Change the authenticated user's username. The former username stays held for the user for a while and lookups of it redirect to the new one, and access tokens issued before are revoked
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request		body		dto.ChangeUsernameRequest	true	"New username"
+//	@Param			If-Match	header		string						false	"ETag of the profile last read, the update fails with 412 once it changed"
+//	@Success		200			{object}	response.Response{data=dto.UserResponse}
+//	@Header			200			{string}	ETag	"Updated profile version"
+//	@Failure		400			{object}	response.Response
+//	@Failure		401			{object}	response.Response
+//	@Failure		404			{object}	response.Response
+//	@Failure		409			{object}	response.Response
+//	@Failure		412			{object}	response.Response
+//	@Failure		429			{object}	response.Response
+//	@Failure		500			{object}	response.Response
+//	@Failure		503			{object}	response.Response
+//	@Router			/api/v1/users/username [put]
+func (h *UserHandler) ChangeUsername(c *gin.Context) {
+	lang := middleware.GetLangFromGin(c)
+
+	// Get claims from context
+	claimsVal, exists := c.Get("sess")
+	if !exists {
+		response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, lang), nil)
+		return
+	}
+
+	claims, ok := claimsVal.(*jwt.Claims)
+	if !ok {
+		response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, lang), nil)
+		return
+	}
+
+	var req dto.ChangeUsernameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
+			"body": {err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if errors := req.Validate(lang, h.username.Reserved); len(errors) > 0 {
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), errors)
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		response.NewErrorResponse(c, http.StatusPreconditionFailed, constants.GetError(constants.UserModified, lang), nil)
+		return
+	}
+
+	user, err := h.userUsecase.ChangeUsername(c.Request.Context(), claims.UserID, version, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("ETag", pkg.FormatETag(user.Version))
+	response.NewResponse(c, http.StatusOK, user, "Username changed successfully", nil)
+}
+
+// GetUserByUsername handles getting a user by username
+//
+//	@Summary		Get user by username
+//	@Description	Get a user by username, ignoring case. A former username still held redirects to the current one
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Param			fields		query		string	false	"Comma-separated fields to return (e.g. id,username)"
+//	@Param			expand		query		string	false	"Comma-separated related resources to embed"
+//	@Success		200			{object}	response.Response{data=dto.UserResponse}
+//	@Success		302			"Former username, Location holds the current one"
+//	@Header			302			{string}	Location	"URL of the user under the current username"
+//	@Failure		400			{object}	response.Response
+//	@Failure		401			{object}	response.Response
+//	@Failure		404			{object}	response.Response
+//	@Failure		500			{object}	response.Response
+//	@Failure		503			{object}	response.Response
+//	@Router			/api/v1/users/by-username/{username} [get]
+func (h *UserHandler) GetUserByUsername(c *gin.Context) {
+	queries := map[string]string{}
+	if err := c.BindQuery(&queries); err != nil {
+		lang := middleware.GetLangFromGin(c)
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
+			"query": {err.Error()},
+		})
+		return
+	}
+
+	user, redirect, err := h.userUsecase.GetUserByUsername(c.Request.Context(), c.Param("username"), queries)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	// Former username - point the client at the current one, keeping the query
+	if redirect != "" {
+		location := url.URL{
+			Path:     path.Join(path.Dir(c.Request.URL.Path), redirect),
+			RawQuery: c.Request.URL.RawQuery,
+		}
+		c.Redirect(http.StatusFound, location.String())
+		return
+	}
+
+	response.NewResponse(c, http.StatusOK, user, "User retrieved successfully", nil)
+}
+
+// DeleteAccount handles deleting the authenticated user's account
+//
+//	@Summary		Delete own account
+//	@Description	Schedule the authenticated user's account for deletion after re-entering the password. Access tokens are revoked, and logging in with reactivate set restores the account during the grace period
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.DeleteAccountRequest	true	"Current password"
+//	@Success		200		{object}	response.Response
 //	@Failure		400		{object}	response.Response
 //	@Failure		401		{object}	response.Response
 //	@Failure		404		{object}	response.Response
 //	@Failure		500		{object}	response.Response
-//	@Router			/api/v1/users/profile [put]
-func (h *UserHandler) UpdateProfile(c *gin.Context) {
+//	@Failure		503		{object}	response.Response
+//	@Router			/api/v1/users/me [delete]
+func (h *UserHandler) DeleteAccount(c *gin.Context) {
 	lang := middleware.GetLangFromGin(c)
 
 	// Get claims from context
 	claimsVal, exists := c.Get("sess")
 	if !exists {
-		response.NewResponse(c, http.StatusUnauthorized, nil, constants.GetErrorMessage(constants.Unauthorized, lang), nil)
+		response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, lang), nil)
 		return
 	}
 
 	claims, ok := claimsVal.(*jwt.Claims)
 	if !ok {
-		response.NewResponse(c, http.StatusUnauthorized, nil, constants.GetErrorMessage(constants.Unauthorized, lang), nil)
+		response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, lang), nil)
 		return
 	}
 
-	var req dto.UpdateProfileRequest
+	var req dto.DeleteAccountRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.NewResponse(c, http.StatusBadRequest, nil, constants.GetErrorMessage(constants.ValidationFailed, lang), map[string][]string{
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
 			"body": {err.Error()},
 		})
 		return
@@ -103,17 +376,27 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 
 	// Validate request
 	if errors := req.Validate(lang); len(errors) > 0 {
-		response.NewResponse(c, http.StatusBadRequest, nil, constants.GetErrorMessage(constants.ValidationFailed, lang), errors)
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), errors)
 		return
 	}
 
-	user, status, err := h.userUsecase.UpdateProfile(c.Request.Context(), claims.UserID, &req)
-	if err != nil {
-		response.NewResponse(c, status, nil, err.Error(), nil)
+	if err := h.userUsecase.DeleteAccount(c.Request.Context(), claims.UserID, &req); err != nil {
+		_ = c.Error(err)
 		return
 	}
 
-	response.NewResponse(c, status, user, "Profile updated successfully", nil)
+	response.NewResponse(c, http.StatusOK, nil, "Account scheduled for deletion", nil)
+}
+
+// ifMatchVersion returns the version from the If-Match header, which makes an update
+// conditional on the version the client last read. It is 0 when the header is absent
+// or "*", and not ok when the header holds no entity tag of ours
+func ifMatchVersion(c *gin.Context) (int, bool) {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, true
+	}
+	return pkg.ParseETag(ifMatch)
 }
 
 // GetUsers handles getting list of users
@@ -137,10 +420,14 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 //	@Param			provider	query		string	false	"Filter by provider"
 //	@Param			genders		query		string	false	"Filter by multiple genders (comma-separated)"
 //	@Param			roles		query		string	false	"Filter by multiple roles (comma-separated)"
+//	@Param			fields		query		string	false	"Comma-separated fields to return (e.g. id,username)"
+//	@Param			expand		query		string	false	"Comma-separated related resources to embed"
+//	@Param			with_total	query		string	false	"Total count strategy"	Enums(true, false, estimated)	default(true)
 //	@Success		200			{object}	response.Response{data=dto.UserListResponse}
 //	@Failure		400			{object}	response.Response
 //	@Failure		401			{object}	response.Response
 //	@Failure		500			{object}	response.Response
+//	@Failure		503			{object}	response.Response
 //	@Router			/api/v1/users [get]
 func (h *UserHandler) GetUsers(c *gin.Context) {
 	// Build queries map from query parameters
@@ -149,15 +436,15 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 	err := c.BindQuery(&queries)
 	if err != nil {
 		lang := middleware.GetLangFromGin(c)
-		response.NewResponse(c, http.StatusBadRequest, nil, constants.GetErrorMessage(constants.ValidationFailed, lang), map[string][]string{
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
 			"query": {err.Error()},
 		})
 		return
 	}
 
-	users, pagination, status, err := h.userUsecase.GetUsers(c.Request.Context(), queries)
+	users, pagination, err := h.userUsecase.GetUsers(c.Request.Context(), queries)
 	if err != nil {
-		response.NewResponse(c, status, nil, err.Error(), nil)
+		_ = c.Error(err)
 		return
 	}
 
@@ -167,5 +454,77 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 		Pagination: pagination,
 	}
 
-	response.NewResponse(c, status, responseData, "Users retrieved successfully", nil)
+	response.NewResponse(c, http.StatusOK, responseData, "Users retrieved successfully", nil)
+}
+
+// GetDeletedUsers handles listing soft-deleted users for an admin
+//
+//	@Summary		Get deleted users list
+//	@Description	Get a paginated and filtered list of soft-deleted users, anonymized ones included. Accepts the filters of the users list. Admins only
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			per_page	query		int		false	"Items per page"	default(10)
+//	@Param			page		query		int		false	"Page number"		default(1)
+//	@Param			email		query		string	false	"Filter by email"
+//	@Param			username	query		string	false	"Filter by username"
+//	@Param			status		query		string	false	"Filter by status, anonymized for anonymized users"
+//	@Param			fields		query		string	false	"Comma-separated fields to return (e.g. id,deleted_at)"
+//	@Param			with_total	query		string	false	"Total count strategy"	Enums(true, false, estimated)	default(true)
+//	@Success		200			{object}	response.Response{data=dto.UserListResponse}
+//	@Failure		400			{object}	response.Response
+//	@Failure		401			{object}	response.Response
+//	@Failure		403			{object}	response.Response
+//	@Failure		500			{object}	response.Response
+//	@Failure		503			{object}	response.Response
+//	@Router			/api/v1/admin/users/deleted [get]
+func (h *UserHandler) GetDeletedUsers(c *gin.Context) {
+	queries := map[string]string{}
+	if err := c.BindQuery(&queries); err != nil {
+		lang := middleware.GetLangFromGin(c)
+		response.NewErrorResponse(c, http.StatusBadRequest, constants.GetError(constants.ValidationFailed, lang), map[string][]string{
+			"query": {err.Error()},
+		})
+		return
+	}
+
+	users, pagination, err := h.userUsecase.GetDeletedUsers(c.Request.Context(), queries)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	responseData := dto.UserListResponse{
+		Users:      users,
+		Pagination: pagination,
+	}
+	response.NewResponse(c, http.StatusOK, responseData, "Deleted users retrieved successfully", nil)
+}
+
+// RestoreUser handles restoring a soft-deleted user for an admin
+//
+//	@Summary		Restore deleted user
+//	@Description	Undo the soft delete of a user. Fails with 409 when the user is not deleted, was anonymized, or their email or username was taken since. Admins only
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"User ID"
+//	@Success		200	{object}	response.Response{data=dto.UserResponse}
+//	@Failure		401	{object}	response.Response
+//	@Failure		403	{object}	response.Response
+//	@Failure		404	{object}	response.Response
+//	@Failure		409	{object}	response.Response
+//	@Failure		500	{object}	response.Response
+//	@Failure		503	{object}	response.Response
+//	@Router			/api/v1/admin/users/{id}/restore [post]
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	user, err := h.userUsecase.RestoreUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.NewResponse(c, http.StatusOK, user, "User restored successfully", nil)
 }
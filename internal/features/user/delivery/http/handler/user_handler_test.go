@@ -5,6 +5,7 @@ import (
 	mocks "app/internal/mocks/usecase"
 	"app/internal/shared/constants"
 	"app/internal/shared/delivery/http/middleware"
+	domainerror "app/internal/shared/domain/error"
 	"app/pkg"
 	pkgjwt "app/pkg/jwt"
 	"bytes"
@@ -22,7 +23,9 @@ import (
 
 func setupTestRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
-	return gin.New()
+	router := gin.New()
+	router.Use(middleware.ErrorMiddleware())
+	return router
 }
 
 func setupGinContext(router *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
@@ -60,16 +63,18 @@ func TestGetProfile_Success(t *testing.T) {
 		Username:  "testuser",
 		FirstName: "Test",
 		LastName:  "User",
+		Version:   2,
 	}
 
 	mockUsecase.EXPECT().
-		GetProfile(mock.Anything, userID).
-		Return(expectedUser, http.StatusOK, nil)
+		GetProfile(mock.Anything, userID, mock.Anything).
+		Return(expectedUser, nil)
 
 	req, _ := http.NewRequest(http.MethodGet, "/profile", nil)
 	w := setupGinContext(router, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
 
 	var response map[string]any
 	err := json.Unmarshal(w.Body.Bytes(), &response)
@@ -100,8 +105,8 @@ func TestGetProfile_UsecaseError(t *testing.T) {
 	router.GET("/profile", setUserIDMiddleware(userID), handler.GetProfile)
 
 	mockUsecase.EXPECT().
-		GetProfile(mock.Anything, userID).
-		Return(nil, http.StatusNotFound, errors.New("user not found"))
+		GetProfile(mock.Anything, userID, mock.Anything).
+		Return(nil, domainerror.New(domainerror.KindNotFound, constants.UserNotFound, nil))
 
 	req, _ := http.NewRequest(http.MethodGet, "/profile", nil)
 	w := setupGinContext(router, req)
@@ -131,8 +136,8 @@ func TestUpdateProfile_Success(t *testing.T) {
 	}
 
 	mockUsecase.EXPECT().
-		UpdateProfile(mock.Anything, userID, &reqBody).
-		Return(expectedUser, http.StatusOK, nil)
+		UpdateProfile(mock.Anything, userID, 0, &reqBody).
+		Return(expectedUser, nil)
 
 	body, _ := json.Marshal(reqBody)
 	req, _ := http.NewRequest(http.MethodPut, "/profile", bytes.NewBuffer(body))
@@ -226,8 +231,8 @@ func TestUpdateProfile_UsecaseError(t *testing.T) {
 	}
 
 	mockUsecase.EXPECT().
-		UpdateProfile(mock.Anything, userID, &reqBody).
-		Return(nil, http.StatusInternalServerError, errors.New("database error"))
+		UpdateProfile(mock.Anything, userID, 0, &reqBody).
+		Return(nil, errors.New("database error"))
 
 	body, _ := json.Marshal(reqBody)
 	req, _ := http.NewRequest(http.MethodPut, "/profile", bytes.NewBuffer(body))
@@ -238,6 +243,206 @@ func TestUpdateProfile_UsecaseError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
+func TestUpdateProfile_IfMatch(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+
+	userID := "user-123"
+	router := setupTestRouter()
+	router.PUT("/profile", setUserIDMiddleware(userID), handler.UpdateProfile)
+
+	reqBody := dto.UpdateProfileRequest{
+		FirstName: "NewFirst",
+	}
+
+	mockUsecase.EXPECT().
+		UpdateProfile(mock.Anything, userID, 3, &reqBody).
+		Return(&dto.UserResponse{ID: userID, FirstName: reqBody.FirstName, Version: 4}, nil)
+
+	body, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest(http.MethodPut, "/profile", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"3"`)
+
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+}
+
+func TestUpdateProfile_PreconditionFailed(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		err     error
+	}{
+		{
+			name:    "stale version",
+			ifMatch: `"3"`,
+			err:     domainerror.New(domainerror.KindPreconditionFailed, constants.UserModified, domainerror.ErrVersionConflict),
+		},
+		{
+			name:    "weak etag",
+			ifMatch: `W/"3"`,
+		},
+		{
+			name:    "malformed etag",
+			ifMatch: "3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := mocks.NewMockUserUsecase(t)
+			handler := NewUserHandler(mockUsecase)
+
+			userID := "user-123"
+			router := setupTestRouter()
+			router.PUT("/profile", setUserIDMiddleware(userID), handler.UpdateProfile)
+
+			reqBody := dto.UpdateProfileRequest{
+				FirstName: "NewFirst",
+			}
+
+			if tt.err != nil {
+				mockUsecase.EXPECT().
+					UpdateProfile(mock.Anything, userID, 3, &reqBody).
+					Return(nil, tt.err)
+			}
+
+			body, _ := json.Marshal(reqBody)
+			req, _ := http.NewRequest(http.MethodPut, "/profile", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", tt.ifMatch)
+
+			w := setupGinContext(router, req)
+
+			assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+			var response map[string]any
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			require.NoError(t, err)
+			assert.Equal(t, constants.UserModified.String(), response["code"])
+		})
+	}
+}
+
+func TestUpdateProfile_Conflict(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+
+	userID := "user-123"
+	router := setupTestRouter()
+	router.PUT("/profile", setUserIDMiddleware(userID), handler.UpdateProfile)
+
+	reqBody := dto.UpdateProfileRequest{
+		FirstName: "NewFirst",
+	}
+
+	mockUsecase.EXPECT().
+		UpdateProfile(mock.Anything, userID, 0, &reqBody).
+		Return(nil, domainerror.New(domainerror.KindConflict, constants.UserModified, domainerror.ErrVersionConflict))
+
+	body, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest(http.MethodPut, "/profile", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestPatchProfile_Success(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+
+	userID := "user-123"
+	router := setupTestRouter()
+	router.PATCH("/profile", setUserIDMiddleware(userID), handler.PatchProfile)
+
+	mockUsecase.EXPECT().
+		PatchProfile(mock.Anything, userID, 2, mock.MatchedBy(func(req *dto.PatchProfileRequest) bool {
+			// Present, null and absent fields must stay distinguishable
+			return req.FirstName.Set && req.FirstName.Value == "New" &&
+				req.Phone.Set && req.Phone.Null &&
+				!req.LastName.Set
+		})).
+		Return(&dto.UserResponse{ID: userID, FirstName: "New", Version: 3}, nil)
+
+	req, _ := http.NewRequest(http.MethodPatch, "/profile", bytes.NewBufferString(`{"first_name":"New","phone":null}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"2"`)
+
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+}
+
+func TestPatchProfile_ValidationError(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+
+	userID := "user-123"
+	router := setupTestRouter()
+	router.PATCH("/profile", setUserIDMiddleware(userID), handler.PatchProfile)
+
+	// Names cannot be cleared and birth dates must be dates
+	req, _ := http.NewRequest(http.MethodPatch, "/profile", bytes.NewBufferString(`{"first_name":null,"birth_date":"31-01-1990"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response map[string]any
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	errs := response["errors"].(map[string]any)
+	assert.Equal(t, []any{"first_name cannot be cleared"}, errs["first_name"])
+	assert.Contains(t, errs, "birth_date")
+}
+
+func TestPatchProfile_GenderNotAccepted(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+	handler.profile.Genders = []string{"female", "male"}
+
+	userID := "user-123"
+	router := setupTestRouter()
+	router.PATCH("/profile", setUserIDMiddleware(userID), handler.PatchProfile)
+
+	req, _ := http.NewRequest(http.MethodPatch, "/profile", bytes.NewBufferString(`{"gender":"other"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response map[string]any
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	errs := response["errors"].(map[string]any)
+	assert.Equal(t, []any{"gender must be one of: female, male"}, errs["gender"])
+}
+
+func TestPatchProfile_BindJSONError(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+
+	userID := "user-123"
+	router := setupTestRouter()
+	router.PATCH("/profile", setUserIDMiddleware(userID), handler.PatchProfile)
+
+	// A merge patch of the profile must be an object
+	req, _ := http.NewRequest(http.MethodPatch, "/profile", bytes.NewBufferString(`["first_name"]`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestGetUsers_Success(t *testing.T) {
 	mockUsecase := mocks.NewMockUserUsecase(t)
 	handler := NewUserHandler(mockUsecase)
@@ -264,7 +469,7 @@ func TestGetUsers_Success(t *testing.T) {
 
 	mockUsecase.EXPECT().
 		GetUsers(mock.Anything, mock.AnythingOfType("map[string]string")).
-		Return(expectedUsers, pkg.PaginationResponse{}, http.StatusOK, nil)
+		Return(expectedUsers, pkg.PaginationResponse{}, nil)
 
 	req, _ := http.NewRequest(http.MethodGet, "/users", nil)
 	w := setupGinContext(router, req)
@@ -296,7 +501,7 @@ func TestGetUsers_WithPagination(t *testing.T) {
 
 	mockUsecase.EXPECT().
 		GetUsers(mock.Anything, mock.AnythingOfType("map[string]string")).
-		Return(expectedUsers, pkg.PaginationResponse{}, http.StatusOK, nil)
+		Return(expectedUsers, pkg.PaginationResponse{}, nil)
 
 	req, _ := http.NewRequest(http.MethodGet, "/users?per_page=5&page=2", nil)
 	w := setupGinContext(router, req)
@@ -316,7 +521,7 @@ func TestGetUsers_InvalidPagination(t *testing.T) {
 	// Invalid values should be handled by the usecase
 	mockUsecase.EXPECT().
 		GetUsers(mock.Anything, mock.AnythingOfType("map[string]string")).
-		Return(expectedUsers, pkg.PaginationResponse{}, http.StatusOK, nil)
+		Return(expectedUsers, pkg.PaginationResponse{}, nil)
 
 	req, _ := http.NewRequest(http.MethodGet, "/users?per_page=invalid&page=-5", nil)
 	w := setupGinContext(router, req)
@@ -333,10 +538,233 @@ func TestGetUsers_UsecaseError(t *testing.T) {
 
 	mockUsecase.EXPECT().
 		GetUsers(mock.Anything, mock.AnythingOfType("map[string]string")).
-		Return(nil, pkg.PaginationResponse{}, http.StatusInternalServerError, errors.New("database error"))
+		Return(nil, pkg.PaginationResponse{}, errors.New("database error"))
 
 	req, _ := http.NewRequest(http.MethodGet, "/users", nil)
 	w := setupGinContext(router, req)
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+func TestChangeUsername_Success(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+
+	userID := "user-123"
+	router := setupTestRouter()
+	router.PUT("/username", setUserIDMiddleware(userID), handler.ChangeUsername)
+
+	reqBody := dto.ChangeUsernameRequest{Username: "newname"}
+	mockUsecase.EXPECT().
+		ChangeUsername(mock.Anything, userID, 4, &reqBody).
+		Return(&dto.UserResponse{ID: userID, Username: "newname", Version: 5}, nil)
+
+	body, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest(http.MethodPut, "/username", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"4"`)
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"5"`, w.Header().Get("ETag"))
+}
+
+func TestChangeUsername_ValidationError(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.PUT("/username", setUserIDMiddleware("user-123"), handler.ChangeUsername)
+
+	body, _ := json.Marshal(dto.ChangeUsernameRequest{Username: "support"})
+	req, _ := http.NewRequest(http.MethodPut, "/username", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response map[string]any
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	errs := response["errors"].(map[string]any)
+	assert.Equal(t, []any{constants.GetValidationMessage(constants.ReservedUsername, constants.LangEN)}, errs["username"])
+}
+
+func TestChangeUsername_ConfiguredReserved(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+	handler.username.Reserved = []string{"acme"}
+
+	router := setupTestRouter()
+	router.PUT("/username", setUserIDMiddleware("user-123"), handler.ChangeUsername)
+
+	body, _ := json.Marshal(dto.ChangeUsernameRequest{Username: "acme"})
+	req, _ := http.NewRequest(http.MethodPut, "/username", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response map[string]any
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	errs := response["errors"].(map[string]any)
+	assert.Equal(t, []any{constants.GetValidationMessage(constants.ReservedUsername, constants.LangEN)}, errs["username"])
+}
+
+func TestChangeUsername_TooSoon(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.PUT("/username", setUserIDMiddleware("user-123"), handler.ChangeUsername)
+
+	mockUsecase.EXPECT().
+		ChangeUsername(mock.Anything, "user-123", 0, mock.Anything).
+		Return(nil, domainerror.New(domainerror.KindTooManyRequests, constants.UsernameChangeTooSoon, nil))
+
+	body, _ := json.Marshal(dto.ChangeUsernameRequest{Username: "newname"})
+	req, _ := http.NewRequest(http.MethodPut, "/username", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestGetUserByUsername_Success(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.GET("/users/by-username/:username", setLanguageMiddleware, handler.GetUserByUsername)
+
+	mockUsecase.EXPECT().
+		GetUserByUsername(mock.Anything, "newname", mock.Anything).
+		Return(&dto.UserResponse{ID: "user-123", Username: "newname"}, "", nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/users/by-username/newname", nil)
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGetUserByUsername_RedirectsFormerUsername(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.GET("/api/v1/users/by-username/:username", setLanguageMiddleware, handler.GetUserByUsername)
+
+	mockUsecase.EXPECT().
+		GetUserByUsername(mock.Anything, "oldname", map[string]string{"fields": "id"}).
+		Return(nil, "newname", nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/users/by-username/oldname?fields=id", nil)
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/api/v1/users/by-username/newname?fields=id", w.Header().Get("Location"))
+}
+
+func TestDeleteAccount_Success(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.DELETE("/users/me", setUserIDMiddleware("user-123"), handler.DeleteAccount)
+
+	reqBody := dto.DeleteAccountRequest{Password: "password123"}
+	mockUsecase.EXPECT().
+		DeleteAccount(mock.Anything, "user-123", &reqBody).
+		Return(nil)
+
+	body, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest(http.MethodDelete, "/users/me", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestDeleteAccount_PasswordRequired(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.DELETE("/users/me", setUserIDMiddleware("user-123"), handler.DeleteAccount)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/users/me", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeleteAccount_WrongPassword(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.DELETE("/users/me", setUserIDMiddleware("user-123"), handler.DeleteAccount)
+
+	mockUsecase.EXPECT().
+		DeleteAccount(mock.Anything, "user-123", mock.Anything).
+		Return(domainerror.New(domainerror.KindUnauthorized, constants.InvalidCredentials, nil).WithField("password"))
+
+	req, _ := http.NewRequest(http.MethodDelete, "/users/me", bytes.NewBufferString(`{"password":"wrong"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestGetDeletedUsers_Success(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.GET("/admin/users/deleted", setLanguageMiddleware, handler.GetDeletedUsers)
+
+	mockUsecase.EXPECT().
+		GetDeletedUsers(mock.Anything, map[string]string{"status": "anonymized"}).
+		Return([]*dto.UserResponse{{ID: "user-1"}}, pkg.PaginationResponse{}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/users/deleted?status=anonymized", nil)
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRestoreUser_Success(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/admin/users/:id/restore", setLanguageMiddleware, handler.RestoreUser)
+
+	mockUsecase.EXPECT().
+		RestoreUser(mock.Anything, "user-123").
+		Return(&dto.UserResponse{ID: "user-123"}, nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/admin/users/user-123/restore", nil)
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRestoreUser_EmailTaken(t *testing.T) {
+	mockUsecase := mocks.NewMockUserUsecase(t)
+	handler := NewUserHandler(mockUsecase)
+
+	router := setupTestRouter()
+	router.POST("/admin/users/:id/restore", setLanguageMiddleware, handler.RestoreUser)
+
+	mockUsecase.EXPECT().
+		RestoreUser(mock.Anything, "user-123").
+		Return(nil, domainerror.New(domainerror.KindConflict, constants.EmailAlreadyRegistered, nil).WithField("email"))
+
+	req, _ := http.NewRequest(http.MethodPost, "/admin/users/user-123/restore", nil)
+	w := setupGinContext(router, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
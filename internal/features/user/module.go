@@ -4,7 +4,11 @@ import (
 	"app/internal/features/user/delivery/http/handler"
 	"app/internal/features/user/usecase"
 	"app/internal/shared/delivery/http/middleware"
+	"app/internal/shared/domain/entity"
 	"app/internal/shared/domain/repository"
+	"app/internal/shared/domain/service"
+	"app/pkg/crypto"
+	"context"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -12,16 +16,24 @@ import (
 
 // Module is the user feature module that combines DI and route registration
 type Module struct {
+	usecase usecase.UserUsecase
 	handler *handler.UserHandler
+	auth    gin.HandlerFunc
+	admin   gin.HandlerFunc
 }
 
 // NewModule creates and wires all user feature dependencies
-func NewModule(userRepo repository.UserRepository, logger *logrus.Logger) *Module {
+func NewModule(userRepo repository.UserRepository, historyRepo repository.UsernameHistoryRepository, transactor repository.Transactor, hasher *crypto.PasswordHasher, logger *logrus.Logger) *Module {
 	// Wire dependencies
-	uc := usecase.NewUserUsecase(userRepo, logger)
+	uc := usecase.NewUserUsecase(userRepo, historyRepo, transactor, hasher, logger)
 	h := handler.NewUserHandler(uc)
 
-	return &Module{handler: h}
+	return &Module{
+		usecase: uc,
+		handler: h,
+		auth:    middleware.AuthMiddleware(userRepo),
+		admin:   middleware.RequireRole(userRepo, entity.UserRoleAdmin),
+	}
 }
 
 // Name returns the feature name
@@ -29,13 +41,39 @@ func (m *Module) Name() string {
 	return "user"
 }
 
+// ExportUserData contributes the former usernames to data exports
+func (m *Module) ExportUserData(ctx context.Context, userID string) (map[string]any, error) {
+	return m.usecase.ExportUserData(ctx, userID)
+}
+
+// AnonymizeUserData erases the former usernames when the account is anonymized
+func (m *Module) AnonymizeUserData(ctx context.Context, userID string, dryRun bool) ([]service.AnonymizedRecords, error) {
+	return m.usecase.AnonymizeUserData(ctx, userID, dryRun)
+}
+
+// Models returns the entities the user feature stores, checked against the schema
+func (m *Module) Models() []any {
+	return []any{&entity.User{}, &entity.UsernameHistory{}}
+}
+
 // RegisterRoutes registers all user routes
 func (m *Module) RegisterRoutes(rg *gin.RouterGroup) {
 	users := rg.Group("/users")
 	{
 		// Protected routes - auth middleware applied inline
-		users.GET("/profile", middleware.AuthMiddleware(), m.handler.GetProfile)
-		users.PUT("/profile", middleware.AuthMiddleware(), m.handler.UpdateProfile)
-		users.GET("", middleware.AuthMiddleware(), m.handler.GetUsers)
+		users.GET("/profile", m.auth, m.handler.GetProfile)
+		users.PUT("/profile", m.auth, m.handler.UpdateProfile)
+		users.PATCH("/profile", m.auth, m.handler.PatchProfile)
+		users.PUT("/username", m.auth, m.handler.ChangeUsername)
+		users.GET("/by-username/:username", m.auth, m.handler.GetUserByUsername)
+		users.DELETE("/me", m.auth, m.handler.DeleteAccount)
+		users.GET("", m.auth, m.handler.GetUsers)
+	}
+
+	admin := rg.Group("/admin/users")
+	{
+		// Admin routes - auth and role middleware applied inline
+		admin.GET("/deleted", m.auth, m.admin, m.handler.GetDeletedUsers)
+		admin.POST("/:id/restore", m.auth, m.admin, m.handler.RestoreUser)
 	}
 }
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"app/internal/features/user/delivery/http/dto"
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/pkg/crypto"
+	"context"
+	"errors"
+)
+
+// DeleteAccount soft deletes the user after checking the password. Every access
+// token is revoked, and until the grace period is over logging in again offers to
+// reactivate the account. Its email and username are released right away, if
+// someone takes them meanwhile the account can no longer be reactivated
+func (u *userUsecase) DeleteAccount(ctx context.Context, userID string, req *dto.DeleteAccountRequest) error {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		u.logger.Error("u.userRepo.GetByID ", err)
+		if errors.Is(err, domainerror.ErrUserNotFound) {
+			return domainerror.New(domainerror.KindNotFound, constants.UserNotFound, err)
+		}
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+
+	if err := u.hasher.Verify(ctx, user.Password, req.Password); err != nil {
+		if errors.Is(err, crypto.ErrHasherBusy) || errors.Is(err, crypto.ErrHasherTimeout) {
+			return domainerror.New(domainerror.KindUnavailable, constants.PasswordHasherBusy, err)
+		}
+		return domainerror.New(domainerror.KindUnauthorized, constants.InvalidCredentials, err).WithField("password")
+	}
+
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Tokens would become valid again on reactivation otherwise
+		user.TokenVersion++
+		if err := u.userRepo.Update(ctx, entity.FilterUser{ID: userID}, user, "token_version"); err != nil {
+			return err
+		}
+		return u.userRepo.Delete(ctx, userID)
+	})
+	if err != nil {
+		u.logger.Error("u.userRepo.Delete ", err)
+		return domainerror.Internal(constants.FailedToDeleteUser, err)
+	}
+	return nil
+}
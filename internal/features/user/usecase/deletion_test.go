@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"app/internal/features/user/delivery/http/dto"
+	mocks "app/internal/mocks/repository"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/testutil"
+	"app/pkg/crypto"
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDeletionTest(t *testing.T) (*userUsecase, *mocks.MockUserRepository, *mocks.MockUsernameHistoryRepository) {
+	mockRepo := mocks.NewMockUserRepository(t)
+	mockHistoryRepo := mocks.NewMockUsernameHistoryRepository(t)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	uc := &userUsecase{
+		userRepo:    mockRepo,
+		historyRepo: mockHistoryRepo,
+		transactor:  testutil.InlineTransactor{},
+		hasher:      crypto.NewPasswordHasher(crypto.DefaultPasswordParams, crypto.HasherConfig{}),
+		logger:      logger,
+	}
+
+	return uc, mockRepo, mockHistoryRepo
+}
+
+func newDeletionUser(t *testing.T) *entity.User {
+	hashedPassword, err := crypto.HashPassword("password123")
+	require.NoError(t, err)
+
+	user := entity.NewUser("test@example.com", "testuser", hashedPassword, "Test", "User")
+	user.ID = "user-123"
+	user.TokenVersion = 2
+	return user
+}
+
+func TestDeleteAccount_Success(t *testing.T) {
+	uc, mockRepo, _ := setupDeletionTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByID(ctx, "user-123").Return(newDeletionUser(t), nil)
+	// Tokens are revoked before the soft delete
+	mockRepo.EXPECT().Update(ctx, entity.FilterUser{ID: "user-123"}, mock.MatchedBy(func(u *entity.User) bool {
+		return u.TokenVersion == 3
+	}), "token_version").Return(nil)
+	mockRepo.EXPECT().Delete(ctx, "user-123").Return(nil)
+
+	err := uc.DeleteAccount(ctx, "user-123", &dto.DeleteAccountRequest{Password: "password123"})
+
+	assert.NoError(t, err)
+}
+
+func TestDeleteAccount_WrongPassword(t *testing.T) {
+	uc, mockRepo, _ := setupDeletionTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByID(ctx, "user-123").Return(newDeletionUser(t), nil)
+
+	err := uc.DeleteAccount(ctx, "user-123", &dto.DeleteAccountRequest{Password: "wrong-password"})
+
+	assert.Equal(t, domainerror.KindUnauthorized, domainerror.KindOf(err))
+}
+
+func TestDeleteAccount_Unavailable(t *testing.T) {
+	uc, mockRepo, _ := setupDeletionTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByID(ctx, "user-123").Return(newDeletionUser(t), nil)
+	mockRepo.EXPECT().Update(ctx, mock.Anything, mock.Anything, "token_version").Return(nil)
+	mockRepo.EXPECT().Delete(ctx, "user-123").Return(domainerror.ErrServiceUnavailable)
+
+	err := uc.DeleteAccount(ctx, "user-123", &dto.DeleteAccountRequest{Password: "password123"})
+
+	assert.Equal(t, domainerror.KindUnavailable, domainerror.KindOf(err))
+}
+
+func TestAnonymizeUserData_DeletesUsernameHistory(t *testing.T) {
+	uc, _, mockHistoryRepo := setupDeletionTest(t)
+	ctx := createTestContext()
+
+	mockHistoryRepo.EXPECT().DeleteByUser(ctx, "user-123").Return(2, nil)
+
+	records, err := uc.AnonymizeUserData(ctx, "user-123", false)
+
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "username_history", records[0].Table)
+	assert.Equal(t, 2, records[0].Count)
+}
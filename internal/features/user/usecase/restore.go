@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"app/internal/features/user/delivery/http/dto"
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/pkg"
+	"context"
+	"errors"
+)
+
+// GetDeletedUsers lists soft-deleted users, anonymized ones included, with the
+// same filters and pagination as GetUsers
+func (u *userUsecase) GetDeletedUsers(ctx context.Context, queries map[string]string) ([]*dto.UserResponse, pkg.PaginationResponse, error) {
+	return u.listUsers(ctx, queries, true)
+}
+
+// RestoreUser undoes the soft delete of a user on request of an admin. Anonymized
+// users cannot be restored, and neither can users whose email or username, or a
+// lookalike of it, was taken by someone else since they were deleted
+func (u *userUsecase) RestoreUser(ctx context.Context, userID string) (*dto.UserResponse, error) {
+	user, err := u.userRepo.GetByIDWithDeleted(ctx, userID)
+	if err != nil {
+		u.logger.Error("u.userRepo.GetByIDWithDeleted ", err)
+		if errors.Is(err, domainerror.ErrUserNotFound) {
+			return nil, domainerror.New(domainerror.KindNotFound, constants.UserNotFound, err)
+		}
+		return nil, domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+	if !user.DeletedAt.Valid {
+		return nil, domainerror.New(domainerror.KindConflict, constants.UserNotDeleted, nil)
+	}
+	if user.Status == entity.UserStatusAnonymized {
+		return nil, domainerror.New(domainerror.KindConflict, constants.UserAnonymized, nil)
+	}
+
+	// Exact identities are guarded by the unique indexes, lookalikes of the username
+	// registered or held by someone else since the deletion are checked first
+	err = u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		taken, err := u.userRepo.IsUsernameTaken(ctx, user.Username, user.ID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return &domainerror.ConflictError{Field: "username"}
+		}
+		return u.userRepo.Restore(ctx, userID)
+	})
+	if err != nil {
+		u.logger.Error("u.userRepo.Restore ", err)
+		var conflict *domainerror.ConflictError
+		switch {
+		case errors.As(err, &conflict):
+			return nil, restoreConflictError(conflict)
+		case errors.Is(err, domainerror.ErrUserNotFound):
+			// Restored or purged concurrently
+			return nil, domainerror.New(domainerror.KindConflict, constants.UserNotDeleted, err)
+		}
+		return nil, domainerror.Internal(constants.FailedToRestoreUser, err)
+	}
+
+	user.DeletedAt.Valid = false
+	return dto.ToUserResponse(user), nil
+}
+
+// restoreConflictError maps the identity a restored user lost to its domain error
+func restoreConflictError(conflict *domainerror.ConflictError) *domainerror.Error {
+	switch conflict.Field {
+	case "email":
+		return domainerror.New(domainerror.KindConflict, constants.EmailAlreadyRegistered, conflict).WithField(conflict.Field)
+	case "username":
+		return domainerror.New(domainerror.KindConflict, constants.UsernameAlreadyTaken, conflict).WithField(conflict.Field)
+	case "phone":
+		return domainerror.New(domainerror.KindConflict, constants.PhoneAlreadyVerified, conflict).WithField(conflict.Field)
+	default:
+		return domainerror.New(domainerror.KindConflict, constants.UserAlreadyExists, conflict).WithField(conflict.Field)
+	}
+}
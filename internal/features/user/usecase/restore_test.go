@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newDeletedUser() *entity.User {
+	user := entity.NewUser("test@example.com", "testuser", "hashed", "Test", "User")
+	user.ID = "user-123"
+	user.DeletedAt = gorm.DeletedAt{Time: time.Now().Add(-time.Hour), Valid: true}
+	return user
+}
+
+func TestGetDeletedUsers_FiltersDeleted(t *testing.T) {
+	uc, mockRepo, _ := setupDeletionTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().List(ctx, mock.MatchedBy(func(filter entity.FilterUser) bool {
+		return filter.Deleted && filter.Status == entity.UserStatusAnonymized
+	})).Return([]*entity.User{newDeletedUser()}, entity.Total{Count: 1}, nil)
+
+	users, pagination, err := uc.GetDeletedUsers(ctx, map[string]string{"status": entity.UserStatusAnonymized})
+
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.NotNil(t, users[0].DeletedAt)
+	require.NotNil(t, pagination.TotalData)
+	assert.Equal(t, 1, *pagination.TotalData)
+}
+
+func TestRestoreUser_Success(t *testing.T) {
+	uc, mockRepo, _ := setupDeletionTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByIDWithDeleted(ctx, "user-123").Return(newDeletedUser(), nil)
+	mockRepo.EXPECT().IsUsernameTaken(ctx, "testuser", "user-123").Return(false, nil)
+	mockRepo.EXPECT().Restore(ctx, "user-123").Return(nil)
+
+	user, err := uc.RestoreUser(ctx, "user-123")
+
+	require.NoError(t, err)
+	assert.Nil(t, user.DeletedAt)
+}
+
+func TestRestoreUser_Errors(t *testing.T) {
+	anonymized := newDeletedUser()
+	anonymized.Status = entity.UserStatusAnonymized
+
+	tests := []struct {
+		name       string
+		user       *entity.User
+		getErr     error
+		taken      bool
+		restoreErr error
+		kind       domainerror.Kind
+		code       constants.ErrCode
+		field      string
+	}{
+		{name: "not found", getErr: domainerror.ErrUserNotFound, kind: domainerror.KindNotFound, code: constants.UserNotFound},
+		{name: "not deleted", user: entity.NewUser("test@example.com", "testuser", "hashed", "Test", "User"), kind: domainerror.KindConflict, code: constants.UserNotDeleted},
+		{name: "anonymized", user: anonymized, kind: domainerror.KindConflict, code: constants.UserAnonymized},
+		{name: "email taken", user: newDeletedUser(), restoreErr: &domainerror.ConflictError{Field: "email"}, kind: domainerror.KindConflict, code: constants.EmailAlreadyRegistered, field: "email"},
+		{name: "username taken", user: newDeletedUser(), restoreErr: &domainerror.ConflictError{Field: "username"}, kind: domainerror.KindConflict, code: constants.UsernameAlreadyTaken, field: "username"},
+		{name: "lookalike taken", user: newDeletedUser(), taken: true, kind: domainerror.KindConflict, code: constants.UsernameAlreadyTaken, field: "username"},
+		{name: "restore fails", user: newDeletedUser(), restoreErr: errors.New("connection reset"), kind: domainerror.KindInternal, code: constants.FailedToRestoreUser},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, mockRepo, _ := setupDeletionTest(t)
+			ctx := createTestContext()
+
+			mockRepo.EXPECT().GetByIDWithDeleted(ctx, "user-123").Return(tt.user, tt.getErr)
+			if tt.user != nil && tt.user.DeletedAt.Valid && tt.user.Status != entity.UserStatusAnonymized {
+				mockRepo.EXPECT().IsUsernameTaken(ctx, "testuser", "user-123").Return(tt.taken, nil)
+				if !tt.taken {
+					mockRepo.EXPECT().Restore(ctx, "user-123").Return(tt.restoreErr)
+				}
+			}
+
+			user, err := uc.RestoreUser(ctx, "user-123")
+
+			assert.Nil(t, user)
+			var domainErr *domainerror.Error
+			require.ErrorAs(t, err, &domainErr)
+			assert.Equal(t, tt.kind, domainErr.Kind)
+			assert.Equal(t, tt.code, domainErr.Code)
+			assert.Equal(t, tt.field, domainErr.Field)
+		})
+	}
+}
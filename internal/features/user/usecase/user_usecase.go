@@ -1,14 +1,18 @@
 package usecase
 
 import (
+	"app/internal/core/config"
 	"app/internal/features/user/delivery/http/dto"
 	"app/internal/shared/constants"
-	"app/internal/shared/delivery/http/middleware"
 	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
 	"app/internal/shared/domain/repository"
+	"app/internal/shared/domain/service"
 	"app/pkg"
+	"app/pkg/crypto"
 	"context"
-	"net/http"
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -16,79 +20,230 @@ import (
 
 // UserUsecase defines the interface for user use cases
 type UserUsecase interface {
-	GetProfile(ctx context.Context, userID string) (*dto.UserResponse, int, error)
-	UpdateProfile(ctx context.Context, userID string, req *dto.UpdateProfileRequest) (*dto.UserResponse, int, error)
-	GetUsers(ctx context.Context, queries map[string]string) ([]*dto.UserResponse, pkg.PaginationResponse, int, error)
+	GetProfile(ctx context.Context, userID string, queries map[string]string) (*dto.UserResponse, error)
+	UpdateProfile(ctx context.Context, userID string, version int, req *dto.UpdateProfileRequest) (*dto.UserResponse, error)
+	PatchProfile(ctx context.Context, userID string, version int, req *dto.PatchProfileRequest) (*dto.UserResponse, error)
+	GetUsers(ctx context.Context, queries map[string]string) ([]*dto.UserResponse, pkg.PaginationResponse, error)
+	GetDeletedUsers(ctx context.Context, queries map[string]string) ([]*dto.UserResponse, pkg.PaginationResponse, error)
+	RestoreUser(ctx context.Context, userID string) (*dto.UserResponse, error)
+	ChangeUsername(ctx context.Context, userID string, version int, req *dto.ChangeUsernameRequest) (*dto.UserResponse, error)
+	GetUserByUsername(ctx context.Context, username string, queries map[string]string) (*dto.UserResponse, string, error)
+	DeleteAccount(ctx context.Context, userID string, req *dto.DeleteAccountRequest) error
+	ExportUserData(ctx context.Context, userID string) (map[string]any, error)
+	AnonymizeUserData(ctx context.Context, userID string, dryRun bool) ([]service.AnonymizedRecords, error)
+}
+
+// Expander loads a related resource for a set of users so it can be embedded
+// in user responses when requested through the expand query parameter
+type Expander interface {
+	// Name returns the key accepted by the expand query parameter (e.g. "organizations")
+	Name() string
+	// Expand returns the related resource for each user keyed by user ID
+	Expand(ctx context.Context, users []*entity.User) (map[string]any, error)
 }
 
 // userUsecase implements UserUsecase interface
 type userUsecase struct {
-	userRepo repository.UserRepository
-	logger   *logrus.Logger
+	userRepo    repository.UserRepository
+	historyRepo repository.UsernameHistoryRepository
+	transactor  repository.Transactor
+	hasher      *crypto.PasswordHasher
+	username    config.UsernameConfig
+	logger      *logrus.Logger
+	expanders   map[string]Expander
 }
 
 // NewUserUsecase creates a new user usecase
-func NewUserUsecase(userRepo repository.UserRepository, logger *logrus.Logger) UserUsecase {
+func NewUserUsecase(userRepo repository.UserRepository, historyRepo repository.UsernameHistoryRepository, transactor repository.Transactor, hasher *crypto.PasswordHasher, logger *logrus.Logger, expanders ...Expander) UserUsecase {
+	registered := make(map[string]Expander, len(expanders))
+	for _, e := range expanders {
+		registered[e.Name()] = e
+	}
+
+	cfg := config.Load()
 	return &userUsecase{
-		userRepo: userRepo,
-		logger:   logger,
+		userRepo:    userRepo,
+		historyRepo: historyRepo,
+		transactor:  transactor,
+		hasher:      hasher,
+		username:    cfg.Username,
+		logger:      logger,
+		expanders:   registered,
 	}
 }
 
+// projection holds the sparse fieldset and expansions requested by the client
+type projection struct {
+	fields  []string
+	columns []string
+	expand  []string
+}
+
+// parseProjection validates the fields and expand query parameters against their allowlists
+func (u *userUsecase) parseProjection(queries map[string]string) (projection, error) {
+	var p projection
+
+	p.fields = pkg.SplitQueryList(queries["fields"])
+	if len(p.fields) > 0 {
+		// ID and version are always selected so expanders can match related
+		// resources and the ETag can be computed
+		p.columns = append(p.columns, "id", "version")
+		for _, field := range p.fields {
+			column, ok := dto.UserFields[field]
+			if !ok {
+				return p, domainerror.New(domainerror.KindInvalidInput, constants.UnsupportedQueryValue, nil, "fields", field).WithField("fields")
+			}
+			if column != "id" {
+				p.columns = append(p.columns, column)
+			}
+		}
+	}
+
+	p.expand = pkg.SplitQueryList(queries["expand"])
+	for _, name := range p.expand {
+		if _, ok := u.expanders[name]; !ok {
+			return p, domainerror.New(domainerror.KindInvalidInput, constants.UnsupportedQueryValue, nil, "expand", name).WithField("expand")
+		}
+	}
+
+	return p, nil
+}
+
+// toUserResponses converts users to DTO responses applying the requested projection
+func (u *userUsecase) toUserResponses(ctx context.Context, users []*entity.User, p projection) ([]*dto.UserResponse, error) {
+	userResponses := make([]*dto.UserResponse, 0, len(users))
+	for _, user := range users {
+		userResponses = append(userResponses, dto.ToUserResponse(user).Sparse(p.fields))
+	}
+
+	// Embed related resources
+	for _, name := range p.expand {
+		related, err := u.expanders[name].Expand(ctx, users)
+		if err != nil {
+			return nil, fmt.Errorf("expand %s: %w", name, err)
+		}
+		for i, user := range users {
+			userResponses[i].Embed(name, related[user.ID])
+		}
+	}
+
+	return userResponses, nil
+}
+
 // GetProfile retrieves user profile
-func (u *userUsecase) GetProfile(ctx context.Context, userID string) (*dto.UserResponse, int, error) {
-	lang := middleware.GetLangFromContext(ctx)
+func (u *userUsecase) GetProfile(ctx context.Context, userID string, queries map[string]string) (*dto.UserResponse, error) {
+	p, err := u.parseProjection(queries)
+	if err != nil {
+		return nil, err
+	}
 
-	user, err := u.userRepo.GetByID(ctx, userID)
+	user, err := u.userRepo.GetByID(ctx, userID, p.columns...)
 	if err != nil {
 		u.logger.Error("u.userRepo.GetByID ", err)
-		return nil, http.StatusNotFound, constants.GetError(constants.UserNotFound, lang)
+		if errors.Is(err, domainerror.ErrUserNotFound) {
+			return nil, domainerror.New(domainerror.KindNotFound, constants.UserNotFound, err)
+		}
+		return nil, domainerror.Internal(constants.SomethingWentWrong, err)
 	}
 
 	// Convert to DTO response
-	return dto.ToUserResponse(user), http.StatusOK, nil
+	userResponses, err := u.toUserResponses(ctx, []*entity.User{user}, p)
+	if err != nil {
+		u.logger.Error("u.toUserResponses ", err)
+		return nil, domainerror.New(domainerror.KindInternal, constants.SomethingWentWrong, err)
+	}
+
+	return userResponses[0], nil
 }
 
-// UpdateProfile updates user profile
-func (u *userUsecase) UpdateProfile(ctx context.Context, userID string, req *dto.UpdateProfileRequest) (*dto.UserResponse, int, error) {
-	lang := middleware.GetLangFromContext(ctx)
+// UpdateProfile updates user profile. A non-zero version is the one the client last
+// read, the update is rejected as a failed precondition once the profile moved past it
+func (u *userUsecase) UpdateProfile(ctx context.Context, userID string, version int, req *dto.UpdateProfileRequest) (*dto.UserResponse, error) {
+	return u.updateProfile(ctx, userID, version, req.ApplyTo)
+}
 
+// PatchProfile applies an RFC 7396 merge patch to the user profile, version works as in UpdateProfile
+func (u *userUsecase) PatchProfile(ctx context.Context, userID string, version int, req *dto.PatchProfileRequest) (*dto.UserResponse, error) {
+	return u.updateProfile(ctx, userID, version, req.ApplyTo)
+}
+
+// updateProfile reads the profile, applies mutate and writes back the columns it
+// returns, conditioned on the version read so a concurrent edit is detected
+// instead of silently overwritten
+func (u *userUsecase) updateProfile(ctx context.Context, userID string, version int, mutate func(user *entity.User) []string) (*dto.UserResponse, error) {
 	user, err := u.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		u.logger.Error("u.userRepo.GetByID ", err)
-		return nil, http.StatusNotFound, constants.GetError(constants.UserNotFound, lang)
+		if errors.Is(err, domainerror.ErrUserNotFound) {
+			return nil, domainerror.New(domainerror.KindNotFound, constants.UserNotFound, err)
+		}
+		return nil, domainerror.Internal(constants.SomethingWentWrong, err)
 	}
-
-	// Update fields
-	if req.FirstName != "" {
-		user.FirstName = req.FirstName
+	if version > 0 && user.Version != version {
+		return nil, domainerror.New(domainerror.KindPreconditionFailed, constants.UserModified, domainerror.ErrVersionConflict)
 	}
-	if req.LastName != "" {
-		user.LastName = req.LastName
+
+	// Update fields - nothing to write leaves the profile as it is
+	columns := mutate(user)
+	if len(columns) == 0 {
+		return dto.ToUserResponse(user), nil
 	}
 
 	// Build filter for update
 	filter := entity.FilterUser{
-		ID: userID,
+		ID:      userID,
+		Version: user.Version,
 	}
 
 	// Save updated user
-	if err := u.userRepo.Update(ctx, filter, user); err != nil {
+	if err := u.userRepo.Update(ctx, filter, user, columns...); err != nil {
 		u.logger.Error("u.userRepo.Update ", err)
-		return nil, http.StatusInternalServerError, constants.GetError(constants.FailedToUpdateUser, lang)
+		if errors.Is(err, domainerror.ErrVersionConflict) {
+			if version > 0 {
+				return nil, domainerror.New(domainerror.KindPreconditionFailed, constants.UserModified, err)
+			}
+			return nil, domainerror.New(domainerror.KindConflict, constants.UserModified, err)
+		}
+		return nil, domainerror.Internal(constants.FailedToUpdateUser, err)
 	}
 
 	// Convert to DTO response
-	return dto.ToUserResponse(user), http.StatusOK, nil
+	return dto.ToUserResponse(user), nil
 }
 
 // GetUsers retrieves list of users with filtering and pagination
-func (u *userUsecase) GetUsers(ctx context.Context, queries map[string]string) ([]*dto.UserResponse, pkg.PaginationResponse, int, error) {
-	lang := middleware.GetLangFromContext(ctx)
+func (u *userUsecase) GetUsers(ctx context.Context, queries map[string]string) ([]*dto.UserResponse, pkg.PaginationResponse, error) {
+	return u.listUsers(ctx, queries, false)
+}
+
+// listUsers retrieves a page of live or soft-deleted users matching the queries
+func (u *userUsecase) listUsers(ctx context.Context, queries map[string]string, deleted bool) ([]*dto.UserResponse, pkg.PaginationResponse, error) {
+	p, err := u.parseProjection(queries)
+	if err != nil {
+		return nil, pkg.PaginationResponse{}, err
+	}
 
 	// Build pagination
 	pagination := pkg.PaginationBuilder(queries["per_page"], queries["page"])
 
+	// Total count strategy
+	count := entity.CountExact
+	switch queries["with_total"] {
+	case "", "true":
+	case "false":
+		count = entity.CountNone
+	case "estimated":
+		count = entity.CountEstimated
+	default:
+		return nil, pkg.PaginationResponse{}, domainerror.New(domainerror.KindInvalidInput, constants.UnsupportedQueryValue, nil, "with_total", queries["with_total"]).WithField("with_total")
+	}
+
+	// Without a total, fetch one extra row to tell whether a next page exists
+	limit := pagination.PerPage
+	if count == entity.CountNone {
+		limit++
+	}
+
 	// Parse array filters
 	var genders, roles []string
 	if queries["genders"] != "" {
@@ -111,31 +266,39 @@ func (u *userUsecase) GetUsers(ctx context.Context, queries map[string]string) (
 		Provider:  queries["provider"],
 		Genders:   genders,
 		Roles:     roles,
-		PerPage:   pagination.PerPage,
+		Fields:    p.columns,
+		PerPage:   limit,
 		Offset:    pagination.Offset,
+		Count:     count,
+		Deleted:   deleted,
 	}
 
 	// Get users from repository
 	users, total, err := u.userRepo.List(ctx, filter)
 	if err != nil {
 		u.logger.Error("u.userRepo.List ", err)
-		return nil, pkg.PaginationResponse{}, http.StatusInternalServerError, constants.GetError(constants.FailedToGetUsers, lang)
+		return nil, pkg.PaginationResponse{}, domainerror.Internal(constants.FailedToGetUsers, err)
+	}
+
+	hasNext := len(users) > pagination.PerPage
+	if hasNext {
+		users = users[:pagination.PerPage]
 	}
 
 	// Convert entity users to DTO response
-	userResponses := make([]*dto.UserResponse, 0, len(users))
-	for _, user := range users {
-		userResponses = append(userResponses, dto.ToUserResponse(user))
+	userResponses, err := u.toUserResponses(ctx, users, p)
+	if err != nil {
+		u.logger.Error("u.toUserResponses ", err)
+		return nil, pkg.PaginationResponse{}, domainerror.New(domainerror.KindInternal, constants.FailedToGetUsers, err)
 	}
 
 	// Build pagination response
-	totalPage := pkg.TotalPage(total, pagination.PerPage)
-	paginationResponse := pkg.PaginationResponse{
-		PerPage:   pagination.PerPage,
-		TotalPage: totalPage,
-		TotalData: total,
-		Page:      pagination.Page,
+	paginationResponse := pkg.NewPaginationResponse(pagination, total.Count)
+	if count == entity.CountNone {
+		paginationResponse.HasNext = hasNext
 	}
+	// An estimate may have been answered with an exact count
+	paginationResponse.Estimated = total.Estimated
 
-	return userResponses, paginationResponse, http.StatusOK, nil
+	return userResponses, paginationResponse, nil
 }
@@ -6,11 +6,14 @@ import (
 	"app/internal/shared/constants"
 	"app/internal/shared/delivery/http/middleware"
 	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
 	"context"
+	"encoding/json"
 	"errors"
-	"net/http"
+	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -51,10 +54,9 @@ func TestGetProfile_Success(t *testing.T) {
 
 	mockRepo.EXPECT().GetByID(ctx, userID).Return(expectedUser, nil)
 
-	user, status, err := uc.GetProfile(ctx, userID)
+	user, err := uc.GetProfile(ctx, userID, map[string]string{})
 
 	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, status)
 	assert.NotNil(t, user)
 	assert.Equal(t, userID, user.ID)
 	assert.Equal(t, "test@example.com", user.Email)
@@ -67,12 +69,27 @@ func TestGetProfile_UserNotFound(t *testing.T) {
 
 	userID := "nonexistent-user"
 
-	mockRepo.EXPECT().GetByID(ctx, userID).Return(nil, errors.New("not found"))
+	mockRepo.EXPECT().GetByID(ctx, userID).Return(nil, domainerror.ErrUserNotFound)
 
-	user, status, err := uc.GetProfile(ctx, userID)
+	user, err := uc.GetProfile(ctx, userID, map[string]string{})
 
 	assert.Error(t, err)
-	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, domainerror.KindNotFound, domainerror.KindOf(err))
+	assert.Nil(t, user)
+}
+
+func TestGetProfile_DatabaseUnavailable(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	userID := "user-123"
+
+	mockRepo.EXPECT().GetByID(ctx, userID).Return(nil, fmt.Errorf("%w: connection refused", domainerror.ErrServiceUnavailable))
+
+	user, err := uc.GetProfile(ctx, userID, map[string]string{})
+
+	assert.Error(t, err)
+	assert.Equal(t, domainerror.KindUnavailable, domainerror.KindOf(err))
 	assert.Nil(t, user)
 }
 
@@ -96,12 +113,11 @@ func TestUpdateProfile_Success(t *testing.T) {
 	}
 
 	mockRepo.EXPECT().GetByID(ctx, userID).Return(existingUser, nil)
-	mockRepo.EXPECT().Update(ctx, mock.AnythingOfType("entity.FilterUser"), mock.AnythingOfType("*entity.User")).Return(nil)
+	mockRepo.EXPECT().Update(ctx, mock.AnythingOfType("entity.FilterUser"), mock.AnythingOfType("*entity.User"), "first_name", "last_name").Return(nil)
 
-	user, status, err := uc.UpdateProfile(ctx, userID, req)
+	user, err := uc.UpdateProfile(ctx, userID, 0, req)
 
 	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, status)
 	assert.NotNil(t, user)
 	assert.Equal(t, "New", user.FirstName)
 	assert.Equal(t, "Name", user.LastName)
@@ -118,12 +134,12 @@ func TestUpdateProfile_UserNotFound(t *testing.T) {
 		LastName:  "Name",
 	}
 
-	mockRepo.EXPECT().GetByID(ctx, userID).Return(nil, errors.New("not found"))
+	mockRepo.EXPECT().GetByID(ctx, userID).Return(nil, domainerror.ErrUserNotFound)
 
-	user, status, err := uc.UpdateProfile(ctx, userID, req)
+	user, err := uc.UpdateProfile(ctx, userID, 0, req)
 
 	assert.Error(t, err)
-	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, domainerror.KindNotFound, domainerror.KindOf(err))
 	assert.Nil(t, user)
 }
 
@@ -147,12 +163,12 @@ func TestUpdateProfile_UpdateError(t *testing.T) {
 	}
 
 	mockRepo.EXPECT().GetByID(ctx, userID).Return(existingUser, nil)
-	mockRepo.EXPECT().Update(ctx, mock.AnythingOfType("entity.FilterUser"), mock.AnythingOfType("*entity.User")).Return(errors.New("database error"))
+	mockRepo.EXPECT().Update(ctx, mock.AnythingOfType("entity.FilterUser"), mock.AnythingOfType("*entity.User"), "first_name", "last_name").Return(errors.New("database error"))
 
-	user, status, err := uc.UpdateProfile(ctx, userID, req)
+	user, err := uc.UpdateProfile(ctx, userID, 0, req)
 
 	assert.Error(t, err)
-	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, domainerror.KindInternal, domainerror.KindOf(err))
 	assert.Nil(t, user)
 }
 
@@ -177,17 +193,221 @@ func TestUpdateProfile_PartialUpdate(t *testing.T) {
 	}
 
 	mockRepo.EXPECT().GetByID(ctx, userID).Return(existingUser, nil)
-	mockRepo.EXPECT().Update(ctx, mock.AnythingOfType("entity.FilterUser"), mock.AnythingOfType("*entity.User")).Return(nil)
+	mockRepo.EXPECT().Update(ctx, mock.AnythingOfType("entity.FilterUser"), mock.AnythingOfType("*entity.User"), "first_name").Return(nil)
 
-	user, status, err := uc.UpdateProfile(ctx, userID, req)
+	user, err := uc.UpdateProfile(ctx, userID, 0, req)
 
 	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, status)
 	assert.NotNil(t, user)
 	assert.Equal(t, "NewFirst", user.FirstName)
 	assert.Equal(t, "Name", user.LastName) // Should keep original
 }
 
+func TestUpdateProfile_VersionedUpdate(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	userID := "user-123"
+	existingUser := &entity.User{
+		ID:        userID,
+		FirstName: "Old",
+		LastName:  "Name",
+		Version:   3,
+	}
+
+	req := &dto.UpdateProfileRequest{
+		FirstName: "New",
+	}
+
+	mockRepo.EXPECT().GetByID(ctx, userID).Return(existingUser, nil)
+	// The update must be conditioned on the version that was read
+	mockRepo.EXPECT().Update(ctx, entity.FilterUser{ID: userID, Version: 3}, existingUser, "first_name").
+		RunAndReturn(func(ctx context.Context, filter entity.FilterUser, user *entity.User, fields ...string) error {
+			user.Version = filter.Version + 1
+			return nil
+		})
+
+	user, err := uc.UpdateProfile(ctx, userID, 3, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, user.Version)
+}
+
+func TestUpdateProfile_StaleVersion(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	userID := "user-123"
+	existingUser := &entity.User{
+		ID:      userID,
+		Version: 4,
+	}
+
+	req := &dto.UpdateProfileRequest{
+		FirstName: "New",
+	}
+
+	// The client read version 3, the profile is at 4 - no update is attempted
+	mockRepo.EXPECT().GetByID(ctx, userID).Return(existingUser, nil)
+
+	user, err := uc.UpdateProfile(ctx, userID, 3, req)
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerror.KindPreconditionFailed, domainErr.Kind)
+	assert.Equal(t, constants.UserModified, domainErr.Code)
+	assert.Nil(t, user)
+}
+
+func TestUpdateProfile_ConcurrentModification(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  int
+		wantKind domainerror.Kind
+	}{
+		{name: "without If-Match", version: 0, wantKind: domainerror.KindConflict},
+		{name: "with If-Match", version: 2, wantKind: domainerror.KindPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, mockRepo := setupTest(t)
+			ctx := createTestContext()
+
+			userID := "user-123"
+			existingUser := &entity.User{
+				ID:      userID,
+				Version: 2,
+			}
+
+			req := &dto.UpdateProfileRequest{
+				FirstName: "New",
+			}
+
+			// Another request updated the row between the read and the write
+			mockRepo.EXPECT().GetByID(ctx, userID).Return(existingUser, nil)
+			mockRepo.EXPECT().Update(ctx, mock.AnythingOfType("entity.FilterUser"), mock.AnythingOfType("*entity.User"), "first_name").Return(domainerror.ErrVersionConflict)
+
+			user, err := uc.UpdateProfile(ctx, userID, tt.version, req)
+
+			assert.ErrorIs(t, err, domainerror.ErrVersionConflict)
+			assert.Equal(t, tt.wantKind, domainerror.KindOf(err))
+			assert.Nil(t, user)
+		})
+	}
+}
+
+func TestPatchProfile_ClearsFields(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	userID := "user-123"
+	phone := "+6281234567890"
+	birthDate := time.Date(1990, 1, 31, 0, 0, 0, 0, time.UTC)
+	existingUser := &entity.User{
+		ID:        userID,
+		FirstName: "Old",
+		LastName:  "Name",
+		Phone:     &phone,
+		BirthDate: &birthDate,
+		Gender:    "female",
+		Version:   1,
+	}
+
+	var req dto.PatchProfileRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"first_name":"New","phone":null,"gender":null}`), &req))
+
+	mockRepo.EXPECT().GetByID(ctx, userID).Return(existingUser, nil)
+	// Cleared fields are written explicitly, untouched ones are left out. Dropping
+	// the phone also drops its verification
+	mockRepo.EXPECT().Update(ctx, entity.FilterUser{ID: userID, Version: 1}, existingUser, "first_name", "phone", "phone_verified_at", "two_factor_enabled", "gender").Return(nil)
+
+	user, err := uc.PatchProfile(ctx, userID, 0, &req)
+
+	require.NoError(t, err)
+	assert.Equal(t, "New", user.FirstName)
+	assert.Equal(t, "Name", user.LastName)
+	assert.Nil(t, user.Phone)
+	assert.Equal(t, "", user.Gender)
+	require.NotNil(t, user.BirthDate)
+	assert.Equal(t, "1990-01-31", *user.BirthDate)
+}
+
+func TestPatchProfile_SetsProfileFields(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	userID := "user-123"
+	existingUser := &entity.User{
+		ID:      userID,
+		Version: 1,
+	}
+
+	var req dto.PatchProfileRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"phone":"+62 (812) 3456.7890","birth_date":"1990-01-31","gender":"male"}`), &req))
+
+	mockRepo.EXPECT().GetByID(ctx, userID).Return(existingUser, nil)
+	mockRepo.EXPECT().Update(ctx, entity.FilterUser{ID: userID, Version: 1}, existingUser, "phone", "birth_date", "gender").Return(nil)
+
+	user, err := uc.PatchProfile(ctx, userID, 0, &req)
+
+	require.NoError(t, err)
+	require.NotNil(t, user.Phone)
+	assert.Equal(t, "+6281234567890", *user.Phone)
+	require.NotNil(t, user.BirthDate)
+	assert.Equal(t, "1990-01-31", *user.BirthDate)
+	assert.Equal(t, "male", user.Gender)
+}
+
+func TestPatchProfile_PhoneChangeResetsVerification(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	userID := "user-123"
+	phone := "+6281234567890"
+	verifiedAt := time.Now()
+	existingUser := &entity.User{
+		ID:               userID,
+		Phone:            &phone,
+		PhoneVerifiedAt:  &verifiedAt,
+		TwoFactorEnabled: true,
+		Version:          1,
+	}
+
+	var req dto.PatchProfileRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"phone":"+6289876543210"}`), &req))
+
+	mockRepo.EXPECT().GetByID(ctx, userID).Return(existingUser, nil)
+	mockRepo.EXPECT().Update(ctx, entity.FilterUser{ID: userID, Version: 1}, existingUser, "phone", "phone_verified_at", "two_factor_enabled").Return(nil)
+
+	user, err := uc.PatchProfile(ctx, userID, 0, &req)
+
+	require.NoError(t, err)
+	assert.Nil(t, user.PhoneVerifiedAt)
+	assert.False(t, user.TwoFactorEnabled)
+}
+
+func TestPatchProfile_EmptyPatch(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	userID := "user-123"
+	existingUser := &entity.User{
+		ID:        userID,
+		FirstName: "Old",
+		Version:   1,
+	}
+
+	// Nothing to write - no update is issued
+	mockRepo.EXPECT().GetByID(ctx, userID).Return(existingUser, nil)
+
+	user, err := uc.PatchProfile(ctx, userID, 0, &dto.PatchProfileRequest{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Old", user.FirstName)
+	assert.Equal(t, 1, user.Version)
+}
+
 func TestGetUsers_Success(t *testing.T) {
 	uc, mockRepo := setupTest(t)
 	ctx := createTestContext()
@@ -211,15 +431,15 @@ func TestGetUsers_Success(t *testing.T) {
 		},
 	}
 
-	mockRepo.EXPECT().List(ctx, mock.AnythingOfType("entity.FilterUser")).Return(expectedUsers, 2, nil)
+	mockRepo.EXPECT().List(ctx, mock.AnythingOfType("entity.FilterUser")).Return(expectedUsers, entity.Total{Count: 2}, nil)
 
 	queries := map[string]string{}
-	users, paginationResponse, status, err := uc.GetUsers(ctx, queries)
+	users, paginationResponse, err := uc.GetUsers(ctx, queries)
 
 	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, status)
 	assert.Len(t, users, 2)
-	assert.Equal(t, 2, paginationResponse.TotalData)
+	require.NotNil(t, paginationResponse.TotalData)
+	assert.Equal(t, 2, *paginationResponse.TotalData)
 	// Password is not in the UserResponse DTO
 }
 
@@ -227,28 +447,205 @@ func TestGetUsers_Error(t *testing.T) {
 	uc, mockRepo := setupTest(t)
 	ctx := createTestContext()
 
-	mockRepo.EXPECT().List(ctx, mock.AnythingOfType("entity.FilterUser")).Return(nil, 0, errors.New("database error"))
+	mockRepo.EXPECT().List(ctx, mock.AnythingOfType("entity.FilterUser")).Return(nil, entity.Total{Count: 0}, errors.New("database error"))
 
 	queries := map[string]string{}
-	users, paginationResponse, status, err := uc.GetUsers(ctx, queries)
+	users, paginationResponse, err := uc.GetUsers(ctx, queries)
 
 	assert.Error(t, err)
-	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, domainerror.KindInternal, domainerror.KindOf(err))
 	assert.Nil(t, users)
-	assert.Equal(t, 0, paginationResponse.TotalData)
+	assert.Nil(t, paginationResponse.TotalData)
 }
 
 func TestGetUsers_EmptyList(t *testing.T) {
 	uc, mockRepo := setupTest(t)
 	ctx := createTestContext()
 
-	mockRepo.EXPECT().List(ctx, mock.AnythingOfType("entity.FilterUser")).Return([]*entity.User{}, 0, nil)
+	mockRepo.EXPECT().List(ctx, mock.AnythingOfType("entity.FilterUser")).Return([]*entity.User{}, entity.Total{Count: 0}, nil)
 
 	queries := map[string]string{}
-	users, paginationResponse, status, err := uc.GetUsers(ctx, queries)
+	users, paginationResponse, err := uc.GetUsers(ctx, queries)
 
 	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, status)
 	assert.Empty(t, users)
-	assert.Equal(t, 0, paginationResponse.TotalData)
+	require.NotNil(t, paginationResponse.TotalData)
+	assert.Equal(t, 0, *paginationResponse.TotalData)
+}
+
+func TestGetUsers_WithoutTotal(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	// One extra row is requested to detect the next page
+	mockRepo.EXPECT().
+		List(ctx, mock.MatchedBy(func(filter entity.FilterUser) bool {
+			return filter.Count == entity.CountNone && filter.PerPage == 3
+		})).
+		Return([]*entity.User{{ID: "user-1"}, {ID: "user-2"}, {ID: "user-3"}}, entity.Total{Count: entity.TotalUnknown}, nil)
+
+	users, paginationResponse, err := uc.GetUsers(ctx, map[string]string{"per_page": "2", "with_total": "false"})
+
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.True(t, paginationResponse.HasNext)
+	assert.Nil(t, paginationResponse.TotalData)
+	assert.Nil(t, paginationResponse.TotalPage)
+}
+
+func TestGetUsers_EstimatedTotal(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().
+		List(ctx, mock.MatchedBy(func(filter entity.FilterUser) bool {
+			return filter.Count == entity.CountEstimated && filter.PerPage == 10
+		})).
+		Return([]*entity.User{{ID: "user-1"}}, entity.Total{Count: 250000, Estimated: true}, nil)
+
+	_, paginationResponse, err := uc.GetUsers(ctx, map[string]string{"with_total": "estimated"})
+
+	require.NoError(t, err)
+	assert.True(t, paginationResponse.Estimated)
+	assert.True(t, paginationResponse.HasNext)
+	require.NotNil(t, paginationResponse.TotalPage)
+	assert.Equal(t, 25000, *paginationResponse.TotalPage)
+}
+
+func TestGetUsers_EstimateAnsweredExactly(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	// The table is small enough for the repository to count instead
+	mockRepo.EXPECT().
+		List(ctx, mock.MatchedBy(func(filter entity.FilterUser) bool {
+			return filter.Count == entity.CountEstimated
+		})).
+		Return([]*entity.User{{ID: "user-1"}}, entity.Total{Count: 42}, nil)
+
+	_, paginationResponse, err := uc.GetUsers(ctx, map[string]string{"with_total": "estimated"})
+
+	require.NoError(t, err)
+	assert.False(t, paginationResponse.Estimated)
+	require.NotNil(t, paginationResponse.TotalData)
+	assert.Equal(t, 42, *paginationResponse.TotalData)
+}
+
+func TestGetUsers_InvalidWithTotal(t *testing.T) {
+	uc, _ := setupTest(t)
+	ctx := createTestContext()
+
+	users, _, err := uc.GetUsers(ctx, map[string]string{"with_total": "maybe"})
+
+	assert.Error(t, err)
+	assert.Equal(t, domainerror.KindInvalidInput, domainerror.KindOf(err))
+	assert.Nil(t, users)
+}
+
+type stubExpander struct {
+	name    string
+	related map[string]any
+	err     error
+}
+
+func (e *stubExpander) Name() string { return e.name }
+
+func (e *stubExpander) Expand(ctx context.Context, users []*entity.User) (map[string]any, error) {
+	return e.related, e.err
+}
+
+func TestGetProfile_SparseFields(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	userID := "user-123"
+	mockRepo.EXPECT().GetByID(ctx, userID, "id", "version", "username").Return(&entity.User{ID: userID, Username: "testuser"}, nil)
+
+	user, err := uc.GetProfile(ctx, userID, map[string]string{"fields": "username"})
+
+	require.NoError(t, err)
+
+	body, err := json.Marshal(user)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"username":"testuser"}`, string(body))
+}
+
+func TestGetProfile_UnsupportedField(t *testing.T) {
+	uc, _ := setupTest(t)
+	ctx := createTestContext()
+
+	user, err := uc.GetProfile(ctx, "user-123", map[string]string{"fields": "id,password"})
+
+	assert.Error(t, err)
+	assert.Equal(t, domainerror.KindInvalidInput, domainerror.KindOf(err))
+	assert.Nil(t, user)
+}
+
+func TestGetUsers_SparseFields(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().
+		List(ctx, mock.MatchedBy(func(filter entity.FilterUser) bool {
+			return assert.ObjectsAreEqual([]string{"id", "version", "first_name"}, filter.Fields)
+		})).
+		Return([]*entity.User{{ID: "user-1", FirstName: "User"}}, entity.Total{Count: 1}, nil)
+
+	users, _, err := uc.GetUsers(ctx, map[string]string{"fields": "id, first_name"})
+
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+
+	body, err := json.Marshal(users[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"user-1","first_name":"User"}`, string(body))
+}
+
+func TestGetUsers_Expand(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+	uc.expanders = map[string]Expander{
+		"organizations": &stubExpander{
+			name:    "organizations",
+			related: map[string]any{"user-1": []string{"acme"}},
+		},
+	}
+
+	mockRepo.EXPECT().List(ctx, mock.AnythingOfType("entity.FilterUser")).Return([]*entity.User{{ID: "user-1"}, {ID: "user-2"}}, entity.Total{Count: 2}, nil)
+
+	users, _, err := uc.GetUsers(ctx, map[string]string{"fields": "id", "expand": "organizations"})
+
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+
+	body, err := json.Marshal(users)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"id":"user-1","organizations":["acme"]},{"id":"user-2","organizations":null}]`, string(body))
+}
+
+func TestGetUsers_UnsupportedExpand(t *testing.T) {
+	uc, _ := setupTest(t)
+	ctx := createTestContext()
+
+	users, _, err := uc.GetUsers(ctx, map[string]string{"expand": "roles"})
+
+	assert.Error(t, err)
+	assert.Equal(t, domainerror.KindInvalidInput, domainerror.KindOf(err))
+	assert.Nil(t, users)
+}
+
+func TestGetUsers_ExpandError(t *testing.T) {
+	uc, mockRepo := setupTest(t)
+	ctx := createTestContext()
+	uc.expanders = map[string]Expander{
+		"organizations": &stubExpander{name: "organizations", err: errors.New("database error")},
+	}
+
+	mockRepo.EXPECT().List(ctx, mock.AnythingOfType("entity.FilterUser")).Return([]*entity.User{{ID: "user-1"}}, entity.Total{Count: 1}, nil)
+
+	users, _, err := uc.GetUsers(ctx, map[string]string{"expand": "organizations"})
+
+	assert.Error(t, err)
+	assert.Equal(t, domainerror.KindInternal, domainerror.KindOf(err))
+	assert.Nil(t, users)
 }
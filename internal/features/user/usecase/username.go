@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"app/internal/features/user/delivery/http/dto"
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/domain/service"
+	"context"
+	"errors"
+	"time"
+)
+
+// ChangeUsername renames the user, version works as in UpdateProfile. The former
+// username is recorded and held for the user, and changes are rate limited by the
+// configured cooldown. A change of case only is not a new name and skips both.
+// Access tokens carry the username, so every rename revokes those issued before
+func (u *userUsecase) ChangeUsername(ctx context.Context, userID string, version int, req *dto.ChangeUsernameRequest) (*dto.UserResponse, error) {
+	var user *entity.User
+	err := u.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = u.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			u.logger.Error("u.userRepo.GetByID ", err)
+			if errors.Is(err, domainerror.ErrUserNotFound) {
+				return domainerror.New(domainerror.KindNotFound, constants.UserNotFound, err)
+			}
+			return domainerror.Internal(constants.SomethingWentWrong, err)
+		}
+		if version > 0 && user.Version != version {
+			return domainerror.New(domainerror.KindPreconditionFailed, constants.UserModified, domainerror.ErrVersionConflict)
+		}
+		if req.Username == user.Username {
+			return nil
+		}
+
+		if entity.NormalizeUsername(req.Username) != user.UsernameNormalized {
+			if err := u.claimUsername(ctx, user, req.Username); err != nil {
+				return err
+			}
+		}
+
+		user.SetUsername(req.Username)
+		user.TokenVersion++
+		filter := entity.FilterUser{
+			ID:      userID,
+			Version: user.Version,
+		}
+		if err := u.userRepo.Update(ctx, filter, user, "username", "username_normalized", "username_skeleton", "token_version"); err != nil {
+			u.logger.Error("u.userRepo.Update ", err)
+			switch {
+			case errors.Is(err, domainerror.ErrUserAlreadyExists):
+				return domainerror.New(domainerror.KindConflict, constants.UsernameAlreadyTaken, err).WithField("username")
+			case errors.Is(err, domainerror.ErrVersionConflict) && version > 0:
+				return domainerror.New(domainerror.KindPreconditionFailed, constants.UserModified, err)
+			case errors.Is(err, domainerror.ErrVersionConflict):
+				return domainerror.New(domainerror.KindConflict, constants.UserModified, err)
+			}
+			return domainerror.Internal(constants.FailedToUpdateUser, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToUserResponse(user), nil
+}
+
+// claimUsername checks the cooldown and availability of username, then records
+// the current username of user in the history so it stays held
+func (u *userUsecase) claimUsername(ctx context.Context, user *entity.User, username string) error {
+	latest, err := u.historyRepo.GetLatest(ctx, user.ID)
+	switch {
+	case errors.Is(err, domainerror.ErrUsernameHistoryNotFound):
+	case err != nil:
+		u.logger.Error("u.historyRepo.GetLatest ", err)
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	case time.Since(latest.ChangedAt) < u.username.ChangeCooldown:
+		return domainerror.New(domainerror.KindTooManyRequests, constants.UsernameChangeTooSoon, nil).WithField("username")
+	}
+
+	taken, err := u.userRepo.IsUsernameTaken(ctx, username, user.ID)
+	if err != nil {
+		u.logger.Error("u.userRepo.IsUsernameTaken ", err)
+		return domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+	if taken {
+		return domainerror.New(domainerror.KindConflict, constants.UsernameAlreadyTaken, nil).WithField("username")
+	}
+
+	if err := u.historyRepo.Create(ctx, entity.NewUsernameHistory(user, u.username.HoldPeriod)); err != nil {
+		u.logger.Error("u.historyRepo.Create ", err)
+		return domainerror.Internal(constants.FailedToUpdateUser, err)
+	}
+	return nil
+}
+
+// GetUserByUsername retrieves a user by username, ignoring case. When the username
+// is a former one still held, no user is returned but the current username of its
+// owner, so the caller can redirect
+func (u *userUsecase) GetUserByUsername(ctx context.Context, username string, queries map[string]string) (*dto.UserResponse, string, error) {
+	p, err := u.parseProjection(queries)
+	if err != nil {
+		return nil, "", err
+	}
+
+	user, err := u.userRepo.GetByUsername(ctx, username)
+	if errors.Is(err, domainerror.ErrUserNotFound) {
+		return u.heldUsername(ctx, username)
+	}
+	if err != nil {
+		u.logger.Error("u.userRepo.GetByUsername ", err)
+		return nil, "", domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+
+	userResponses, err := u.toUserResponses(ctx, []*entity.User{user}, p)
+	if err != nil {
+		u.logger.Error("u.toUserResponses ", err)
+		return nil, "", domainerror.New(domainerror.KindInternal, constants.SomethingWentWrong, err)
+	}
+
+	return userResponses[0], "", nil
+}
+
+// heldUsername resolves a former username to the current username of its owner
+func (u *userUsecase) heldUsername(ctx context.Context, username string) (*dto.UserResponse, string, error) {
+	history, err := u.historyRepo.GetHeld(ctx, username)
+	if errors.Is(err, domainerror.ErrUsernameHistoryNotFound) {
+		return nil, "", domainerror.New(domainerror.KindNotFound, constants.UserNotFound, err)
+	}
+	if err != nil {
+		u.logger.Error("u.historyRepo.GetHeld ", err)
+		return nil, "", domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+
+	owner, err := u.userRepo.GetByID(ctx, history.UserID, "id", "username")
+	if err != nil {
+		u.logger.Error("u.userRepo.GetByID ", err)
+		if errors.Is(err, domainerror.ErrUserNotFound) {
+			return nil, "", domainerror.New(domainerror.KindNotFound, constants.UserNotFound, err)
+		}
+		return nil, "", domainerror.Internal(constants.SomethingWentWrong, err)
+	}
+
+	return nil, owner.Username, nil
+}
+
+// ExportUserData returns the former usernames of the user for data exports
+func (u *userUsecase) ExportUserData(ctx context.Context, userID string) (map[string]any, error) {
+	history, err := u.historyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"username_history": history}, nil
+}
+
+// AnonymizeUserData deletes the former usernames of the user, releasing them
+func (u *userUsecase) AnonymizeUserData(ctx context.Context, userID string, dryRun bool) ([]service.AnonymizedRecords, error) {
+	deleted, err := u.historyRepo.DeleteByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return []service.AnonymizedRecords{{Table: "username_history", Action: service.AnonymizeDelete, Count: deleted}}, nil
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"app/internal/core/config"
+	"app/internal/features/user/delivery/http/dto"
+	mocks "app/internal/mocks/repository"
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/testutil"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupUsernameTest(t *testing.T) (*userUsecase, *mocks.MockUserRepository, *mocks.MockUsernameHistoryRepository) {
+	mockRepo := mocks.NewMockUserRepository(t)
+	mockHistoryRepo := mocks.NewMockUsernameHistoryRepository(t)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	uc := &userUsecase{
+		userRepo:    mockRepo,
+		historyRepo: mockHistoryRepo,
+		transactor:  testutil.InlineTransactor{},
+		username: config.UsernameConfig{
+			ChangeCooldown: 24 * time.Hour,
+			HoldPeriod:     30 * 24 * time.Hour,
+		},
+		logger: logger,
+	}
+
+	return uc, mockRepo, mockHistoryRepo
+}
+
+func newUsernameUser() *entity.User {
+	user := &entity.User{ID: "user-123", Email: "test@example.com", Version: 3, TokenVersion: 1}
+	user.SetUsername("oldname")
+	return user
+}
+
+func TestChangeUsername_Success(t *testing.T) {
+	uc, mockRepo, mockHistoryRepo := setupUsernameTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByID(ctx, "user-123").Return(newUsernameUser(), nil)
+	mockHistoryRepo.EXPECT().GetLatest(ctx, "user-123").Return(&entity.UsernameHistory{ChangedAt: time.Now().Add(-48 * time.Hour)}, nil)
+	mockRepo.EXPECT().IsUsernameTaken(ctx, "NewName", "user-123").Return(false, nil)
+	mockHistoryRepo.EXPECT().Create(ctx, mock.MatchedBy(func(h *entity.UsernameHistory) bool {
+		return h.UserID == "user-123" && h.Username == "oldname" &&
+			h.HeldUntil.Sub(h.ChangedAt) == 30*24*time.Hour
+	})).Return(nil)
+	mockRepo.EXPECT().Update(ctx, entity.FilterUser{ID: "user-123", Version: 3}, mock.MatchedBy(func(user *entity.User) bool {
+		return user.Username == "NewName" && user.UsernameNormalized == "newname" && user.UsernameSkeleton == "newname" &&
+			user.TokenVersion == 2
+	}), "username", "username_normalized", "username_skeleton", "token_version").Return(nil)
+
+	user, err := uc.ChangeUsername(ctx, "user-123", 3, &dto.ChangeUsernameRequest{Username: "NewName"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "NewName", user.Username)
+}
+
+func TestChangeUsername_CaseOnlySkipsHistory(t *testing.T) {
+	uc, mockRepo, _ := setupUsernameTest(t)
+	ctx := createTestContext()
+
+	// Mock: no cooldown, availability or history calls for the same name
+	mockRepo.EXPECT().GetByID(ctx, "user-123").Return(newUsernameUser(), nil)
+	// Tokens carry the username as spelled, so they are revoked all the same
+	mockRepo.EXPECT().Update(ctx, mock.Anything, mock.MatchedBy(func(user *entity.User) bool {
+		return user.TokenVersion == 2
+	}), "username", "username_normalized", "username_skeleton", "token_version").Return(nil)
+
+	user, err := uc.ChangeUsername(ctx, "user-123", 0, &dto.ChangeUsernameRequest{Username: "OldName"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "OldName", user.Username)
+}
+
+func TestChangeUsername_TooSoon(t *testing.T) {
+	uc, mockRepo, mockHistoryRepo := setupUsernameTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByID(ctx, "user-123").Return(newUsernameUser(), nil)
+	mockHistoryRepo.EXPECT().GetLatest(ctx, "user-123").Return(&entity.UsernameHistory{ChangedAt: time.Now().Add(-time.Hour)}, nil)
+
+	user, err := uc.ChangeUsername(ctx, "user-123", 0, &dto.ChangeUsernameRequest{Username: "newname"})
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerror.KindTooManyRequests, domainErr.Kind)
+	assert.Equal(t, constants.UsernameChangeTooSoon, domainErr.Code)
+	assert.Nil(t, user)
+}
+
+func TestChangeUsername_Taken(t *testing.T) {
+	uc, mockRepo, mockHistoryRepo := setupUsernameTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByID(ctx, "user-123").Return(newUsernameUser(), nil)
+	mockHistoryRepo.EXPECT().GetLatest(ctx, "user-123").Return(nil, domainerror.ErrUsernameHistoryNotFound)
+	mockRepo.EXPECT().IsUsernameTaken(ctx, "newname", "user-123").Return(true, nil)
+
+	user, err := uc.ChangeUsername(ctx, "user-123", 0, &dto.ChangeUsernameRequest{Username: "newname"})
+
+	var domainErr *domainerror.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerror.KindConflict, domainErr.Kind)
+	assert.Equal(t, constants.UsernameAlreadyTaken, domainErr.Code)
+	assert.Equal(t, "username", domainErr.Field)
+	assert.Nil(t, user)
+}
+
+func TestChangeUsername_StaleVersion(t *testing.T) {
+	uc, mockRepo, _ := setupUsernameTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByID(ctx, "user-123").Return(newUsernameUser(), nil)
+
+	user, err := uc.ChangeUsername(ctx, "user-123", 2, &dto.ChangeUsernameRequest{Username: "newname"})
+
+	assert.Equal(t, domainerror.KindPreconditionFailed, domainerror.KindOf(err))
+	assert.Nil(t, user)
+}
+
+func TestGetUserByUsername_Success(t *testing.T) {
+	uc, mockRepo, _ := setupUsernameTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByUsername(ctx, "OldName").Return(newUsernameUser(), nil)
+
+	user, redirect, err := uc.GetUserByUsername(ctx, "OldName", map[string]string{})
+
+	require.NoError(t, err)
+	assert.Empty(t, redirect)
+	assert.Equal(t, "oldname", user.Username)
+}
+
+func TestGetUserByUsername_HeldRedirects(t *testing.T) {
+	uc, mockRepo, mockHistoryRepo := setupUsernameTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByUsername(ctx, "oldname").Return(nil, domainerror.ErrUserNotFound)
+	mockHistoryRepo.EXPECT().GetHeld(ctx, "oldname").Return(&entity.UsernameHistory{UserID: "user-123"}, nil)
+	mockRepo.EXPECT().GetByID(ctx, "user-123", "id", "username").Return(&entity.User{ID: "user-123", Username: "newname"}, nil)
+
+	user, redirect, err := uc.GetUserByUsername(ctx, "oldname", map[string]string{})
+
+	require.NoError(t, err)
+	assert.Nil(t, user)
+	assert.Equal(t, "newname", redirect)
+}
+
+func TestGetUserByUsername_NotFound(t *testing.T) {
+	uc, mockRepo, mockHistoryRepo := setupUsernameTest(t)
+	ctx := createTestContext()
+
+	mockRepo.EXPECT().GetByUsername(ctx, "nobody").Return(nil, domainerror.ErrUserNotFound)
+	mockHistoryRepo.EXPECT().GetHeld(ctx, "nobody").Return(nil, domainerror.ErrUsernameHistoryNotFound)
+
+	user, redirect, err := uc.GetUserByUsername(ctx, "nobody", map[string]string{})
+
+	assert.Equal(t, domainerror.KindNotFound, domainerror.KindOf(err))
+	assert.Nil(t, user)
+	assert.Empty(t, redirect)
+}
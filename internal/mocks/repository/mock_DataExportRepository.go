@@ -0,0 +1,385 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "app/internal/shared/domain/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MockDataExportRepository is an autogenerated mock type for the DataExportRepository type
+type MockDataExportRepository struct {
+	mock.Mock
+}
+
+type MockDataExportRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockDataExportRepository) EXPECT() *MockDataExportRepository_Expecter {
+	return &MockDataExportRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: ctx, export
+func (_m *MockDataExportRepository) Create(ctx context.Context, export *entity.DataExport) error {
+	ret := _m.Called(ctx, export)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DataExport) error); ok {
+		r0 = rf(ctx, export)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDataExportRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockDataExportRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - export *entity.DataExport
+func (_e *MockDataExportRepository_Expecter) Create(ctx interface{}, export interface{}) *MockDataExportRepository_Create_Call {
+	return &MockDataExportRepository_Create_Call{Call: _e.mock.On("Create", ctx, export)}
+}
+
+func (_c *MockDataExportRepository_Create_Call) Run(run func(ctx context.Context, export *entity.DataExport)) *MockDataExportRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.DataExport))
+	})
+	return _c
+}
+
+func (_c *MockDataExportRepository_Create_Call) Return(_a0 error) *MockDataExportRepository_Create_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDataExportRepository_Create_Call) RunAndReturn(run func(context.Context, *entity.DataExport) error) *MockDataExportRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetActive provides a mock function with given fields: ctx, userID
+func (_m *MockDataExportRepository) GetActive(ctx context.Context, userID string) (*entity.DataExport, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActive")
+	}
+
+	var r0 *entity.DataExport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.DataExport, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.DataExport); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.DataExport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDataExportRepository_GetActive_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetActive'
+type MockDataExportRepository_GetActive_Call struct {
+	*mock.Call
+}
+
+// GetActive is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockDataExportRepository_Expecter) GetActive(ctx interface{}, userID interface{}) *MockDataExportRepository_GetActive_Call {
+	return &MockDataExportRepository_GetActive_Call{Call: _e.mock.On("GetActive", ctx, userID)}
+}
+
+func (_c *MockDataExportRepository_GetActive_Call) Run(run func(ctx context.Context, userID string)) *MockDataExportRepository_GetActive_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDataExportRepository_GetActive_Call) Return(_a0 *entity.DataExport, _a1 error) *MockDataExportRepository_GetActive_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDataExportRepository_GetActive_Call) RunAndReturn(run func(context.Context, string) (*entity.DataExport, error)) *MockDataExportRepository_GetActive_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *MockDataExportRepository) GetByID(ctx context.Context, id string) (*entity.DataExport, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *entity.DataExport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.DataExport, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.DataExport); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.DataExport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDataExportRepository_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockDataExportRepository_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockDataExportRepository_Expecter) GetByID(ctx interface{}, id interface{}) *MockDataExportRepository_GetByID_Call {
+	return &MockDataExportRepository_GetByID_Call{Call: _e.mock.On("GetByID", ctx, id)}
+}
+
+func (_c *MockDataExportRepository_GetByID_Call) Run(run func(ctx context.Context, id string)) *MockDataExportRepository_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDataExportRepository_GetByID_Call) Return(_a0 *entity.DataExport, _a1 error) *MockDataExportRepository_GetByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDataExportRepository_GetByID_Call) RunAndReturn(run func(context.Context, string) (*entity.DataExport, error)) *MockDataExportRepository_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListByUser provides a mock function with given fields: ctx, userID
+func (_m *MockDataExportRepository) ListByUser(ctx context.Context, userID string) ([]*entity.DataExport, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByUser")
+	}
+
+	var r0 []*entity.DataExport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*entity.DataExport, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*entity.DataExport); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.DataExport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDataExportRepository_ListByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListByUser'
+type MockDataExportRepository_ListByUser_Call struct {
+	*mock.Call
+}
+
+// ListByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockDataExportRepository_Expecter) ListByUser(ctx interface{}, userID interface{}) *MockDataExportRepository_ListByUser_Call {
+	return &MockDataExportRepository_ListByUser_Call{Call: _e.mock.On("ListByUser", ctx, userID)}
+}
+
+func (_c *MockDataExportRepository_ListByUser_Call) Run(run func(ctx context.Context, userID string)) *MockDataExportRepository_ListByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDataExportRepository_ListByUser_Call) Return(_a0 []*entity.DataExport, _a1 error) *MockDataExportRepository_ListByUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDataExportRepository_ListByUser_Call) RunAndReturn(run func(context.Context, string) ([]*entity.DataExport, error)) *MockDataExportRepository_ListByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListExpired provides a mock function with given fields: ctx, before, limit
+func (_m *MockDataExportRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]*entity.DataExport, error) {
+	ret := _m.Called(ctx, before, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListExpired")
+	}
+
+	var r0 []*entity.DataExport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]*entity.DataExport, error)); ok {
+		return rf(ctx, before, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) []*entity.DataExport); ok {
+		r0 = rf(ctx, before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.DataExport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDataExportRepository_ListExpired_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListExpired'
+type MockDataExportRepository_ListExpired_Call struct {
+	*mock.Call
+}
+
+// ListExpired is a helper method to define mock.On call
+//   - ctx context.Context
+//   - before time.Time
+//   - limit int
+func (_e *MockDataExportRepository_Expecter) ListExpired(ctx interface{}, before interface{}, limit interface{}) *MockDataExportRepository_ListExpired_Call {
+	return &MockDataExportRepository_ListExpired_Call{Call: _e.mock.On("ListExpired", ctx, before, limit)}
+}
+
+func (_c *MockDataExportRepository_ListExpired_Call) Run(run func(ctx context.Context, before time.Time, limit int)) *MockDataExportRepository_ListExpired_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDataExportRepository_ListExpired_Call) Return(_a0 []*entity.DataExport, _a1 error) *MockDataExportRepository_ListExpired_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDataExportRepository_ListExpired_Call) RunAndReturn(run func(context.Context, time.Time, int) ([]*entity.DataExport, error)) *MockDataExportRepository_ListExpired_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function with given fields: ctx, export, fields
+func (_m *MockDataExportRepository) Update(ctx context.Context, export *entity.DataExport, fields ...string) error {
+	_va := make([]interface{}, len(fields))
+	for _i := range fields {
+		_va[_i] = fields[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, export)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DataExport, ...string) error); ok {
+		r0 = rf(ctx, export, fields...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDataExportRepository_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockDataExportRepository_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - export *entity.DataExport
+//   - fields ...string
+func (_e *MockDataExportRepository_Expecter) Update(ctx interface{}, export interface{}, fields ...interface{}) *MockDataExportRepository_Update_Call {
+	return &MockDataExportRepository_Update_Call{Call: _e.mock.On("Update",
+		append([]interface{}{ctx, export}, fields...)...)}
+}
+
+func (_c *MockDataExportRepository_Update_Call) Run(run func(ctx context.Context, export *entity.DataExport, fields ...string)) *MockDataExportRepository_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), args[1].(*entity.DataExport), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MockDataExportRepository_Update_Call) Return(_a0 error) *MockDataExportRepository_Update_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDataExportRepository_Update_Call) RunAndReturn(run func(context.Context, *entity.DataExport, ...string) error) *MockDataExportRepository_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockDataExportRepository creates a new instance of MockDataExportRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockDataExportRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockDataExportRepository {
+	mock := &MockDataExportRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,365 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "app/internal/shared/domain/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockEmailChangeRepository is an autogenerated mock type for the EmailChangeRepository type
+type MockEmailChangeRepository struct {
+	mock.Mock
+}
+
+type MockEmailChangeRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockEmailChangeRepository) EXPECT() *MockEmailChangeRepository_Expecter {
+	return &MockEmailChangeRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: ctx, change
+func (_m *MockEmailChangeRepository) Create(ctx context.Context, change *entity.EmailChange) error {
+	ret := _m.Called(ctx, change)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.EmailChange) error); ok {
+		r0 = rf(ctx, change)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockEmailChangeRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockEmailChangeRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - change *entity.EmailChange
+func (_e *MockEmailChangeRepository_Expecter) Create(ctx interface{}, change interface{}) *MockEmailChangeRepository_Create_Call {
+	return &MockEmailChangeRepository_Create_Call{Call: _e.mock.On("Create", ctx, change)}
+}
+
+func (_c *MockEmailChangeRepository_Create_Call) Run(run func(ctx context.Context, change *entity.EmailChange)) *MockEmailChangeRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.EmailChange))
+	})
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_Create_Call) Return(_a0 error) *MockEmailChangeRepository_Create_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_Create_Call) RunAndReturn(run func(context.Context, *entity.EmailChange) error) *MockEmailChangeRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *MockEmailChangeRepository) Delete(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockEmailChangeRepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockEmailChangeRepository_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockEmailChangeRepository_Expecter) Delete(ctx interface{}, id interface{}) *MockEmailChangeRepository_Delete_Call {
+	return &MockEmailChangeRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, id)}
+}
+
+func (_c *MockEmailChangeRepository_Delete_Call) Run(run func(ctx context.Context, id string)) *MockEmailChangeRepository_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_Delete_Call) Return(_a0 error) *MockEmailChangeRepository_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_Delete_Call) RunAndReturn(run func(context.Context, string) error) *MockEmailChangeRepository_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByUser provides a mock function with given fields: ctx, userID
+func (_m *MockEmailChangeRepository) DeleteByUser(ctx context.Context, userID string) (int, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByUser")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockEmailChangeRepository_DeleteByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByUser'
+type MockEmailChangeRepository_DeleteByUser_Call struct {
+	*mock.Call
+}
+
+// DeleteByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockEmailChangeRepository_Expecter) DeleteByUser(ctx interface{}, userID interface{}) *MockEmailChangeRepository_DeleteByUser_Call {
+	return &MockEmailChangeRepository_DeleteByUser_Call{Call: _e.mock.On("DeleteByUser", ctx, userID)}
+}
+
+func (_c *MockEmailChangeRepository_DeleteByUser_Call) Run(run func(ctx context.Context, userID string)) *MockEmailChangeRepository_DeleteByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_DeleteByUser_Call) Return(_a0 int, _a1 error) *MockEmailChangeRepository_DeleteByUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_DeleteByUser_Call) RunAndReturn(run func(context.Context, string) (int, error)) *MockEmailChangeRepository_DeleteByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByCancelToken provides a mock function with given fields: ctx, cancelTokenHash
+func (_m *MockEmailChangeRepository) GetByCancelToken(ctx context.Context, cancelTokenHash string) (*entity.EmailChange, error) {
+	ret := _m.Called(ctx, cancelTokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByCancelToken")
+	}
+
+	var r0 *entity.EmailChange
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.EmailChange, error)); ok {
+		return rf(ctx, cancelTokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.EmailChange); ok {
+		r0 = rf(ctx, cancelTokenHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.EmailChange)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, cancelTokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockEmailChangeRepository_GetByCancelToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByCancelToken'
+type MockEmailChangeRepository_GetByCancelToken_Call struct {
+	*mock.Call
+}
+
+// GetByCancelToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - cancelTokenHash string
+func (_e *MockEmailChangeRepository_Expecter) GetByCancelToken(ctx interface{}, cancelTokenHash interface{}) *MockEmailChangeRepository_GetByCancelToken_Call {
+	return &MockEmailChangeRepository_GetByCancelToken_Call{Call: _e.mock.On("GetByCancelToken", ctx, cancelTokenHash)}
+}
+
+func (_c *MockEmailChangeRepository_GetByCancelToken_Call) Run(run func(ctx context.Context, cancelTokenHash string)) *MockEmailChangeRepository_GetByCancelToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_GetByCancelToken_Call) Return(_a0 *entity.EmailChange, _a1 error) *MockEmailChangeRepository_GetByCancelToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_GetByCancelToken_Call) RunAndReturn(run func(context.Context, string) (*entity.EmailChange, error)) *MockEmailChangeRepository_GetByCancelToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByToken provides a mock function with given fields: ctx, tokenHash
+func (_m *MockEmailChangeRepository) GetByToken(ctx context.Context, tokenHash string) (*entity.EmailChange, error) {
+	ret := _m.Called(ctx, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByToken")
+	}
+
+	var r0 *entity.EmailChange
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.EmailChange, error)); ok {
+		return rf(ctx, tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.EmailChange); ok {
+		r0 = rf(ctx, tokenHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.EmailChange)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockEmailChangeRepository_GetByToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByToken'
+type MockEmailChangeRepository_GetByToken_Call struct {
+	*mock.Call
+}
+
+// GetByToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tokenHash string
+func (_e *MockEmailChangeRepository_Expecter) GetByToken(ctx interface{}, tokenHash interface{}) *MockEmailChangeRepository_GetByToken_Call {
+	return &MockEmailChangeRepository_GetByToken_Call{Call: _e.mock.On("GetByToken", ctx, tokenHash)}
+}
+
+func (_c *MockEmailChangeRepository_GetByToken_Call) Run(run func(ctx context.Context, tokenHash string)) *MockEmailChangeRepository_GetByToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_GetByToken_Call) Return(_a0 *entity.EmailChange, _a1 error) *MockEmailChangeRepository_GetByToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_GetByToken_Call) RunAndReturn(run func(context.Context, string) (*entity.EmailChange, error)) *MockEmailChangeRepository_GetByToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByUser provides a mock function with given fields: ctx, userID
+func (_m *MockEmailChangeRepository) GetByUser(ctx context.Context, userID string) (*entity.EmailChange, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByUser")
+	}
+
+	var r0 *entity.EmailChange
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.EmailChange, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.EmailChange); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.EmailChange)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockEmailChangeRepository_GetByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByUser'
+type MockEmailChangeRepository_GetByUser_Call struct {
+	*mock.Call
+}
+
+// GetByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockEmailChangeRepository_Expecter) GetByUser(ctx interface{}, userID interface{}) *MockEmailChangeRepository_GetByUser_Call {
+	return &MockEmailChangeRepository_GetByUser_Call{Call: _e.mock.On("GetByUser", ctx, userID)}
+}
+
+func (_c *MockEmailChangeRepository_GetByUser_Call) Run(run func(ctx context.Context, userID string)) *MockEmailChangeRepository_GetByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_GetByUser_Call) Return(_a0 *entity.EmailChange, _a1 error) *MockEmailChangeRepository_GetByUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_GetByUser_Call) RunAndReturn(run func(context.Context, string) (*entity.EmailChange, error)) *MockEmailChangeRepository_GetByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockEmailChangeRepository creates a new instance of MockEmailChangeRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockEmailChangeRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockEmailChangeRepository {
+	mock := &MockEmailChangeRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,296 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "app/internal/shared/domain/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockPhoneOTPRepository is an autogenerated mock type for the PhoneOTPRepository type
+type MockPhoneOTPRepository struct {
+	mock.Mock
+}
+
+type MockPhoneOTPRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockPhoneOTPRepository) EXPECT() *MockPhoneOTPRepository_Expecter {
+	return &MockPhoneOTPRepository_Expecter{mock: &_m.Mock}
+}
+
+// ConsumeAttempt provides a mock function with given fields: ctx, id, maxAttempts
+func (_m *MockPhoneOTPRepository) ConsumeAttempt(ctx context.Context, id string, maxAttempts int) error {
+	ret := _m.Called(ctx, id, maxAttempts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeAttempt")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) error); ok {
+		r0 = rf(ctx, id, maxAttempts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockPhoneOTPRepository_ConsumeAttempt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConsumeAttempt'
+type MockPhoneOTPRepository_ConsumeAttempt_Call struct {
+	*mock.Call
+}
+
+// ConsumeAttempt is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - maxAttempts int
+func (_e *MockPhoneOTPRepository_Expecter) ConsumeAttempt(ctx interface{}, id interface{}, maxAttempts interface{}) *MockPhoneOTPRepository_ConsumeAttempt_Call {
+	return &MockPhoneOTPRepository_ConsumeAttempt_Call{Call: _e.mock.On("ConsumeAttempt", ctx, id, maxAttempts)}
+}
+
+func (_c *MockPhoneOTPRepository_ConsumeAttempt_Call) Run(run func(ctx context.Context, id string, maxAttempts int)) *MockPhoneOTPRepository_ConsumeAttempt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockPhoneOTPRepository_ConsumeAttempt_Call) Return(_a0 error) *MockPhoneOTPRepository_ConsumeAttempt_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockPhoneOTPRepository_ConsumeAttempt_Call) RunAndReturn(run func(context.Context, string, int) error) *MockPhoneOTPRepository_ConsumeAttempt_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Create provides a mock function with given fields: ctx, otp
+func (_m *MockPhoneOTPRepository) Create(ctx context.Context, otp *entity.PhoneOTP) error {
+	ret := _m.Called(ctx, otp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.PhoneOTP) error); ok {
+		r0 = rf(ctx, otp)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockPhoneOTPRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockPhoneOTPRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - otp *entity.PhoneOTP
+func (_e *MockPhoneOTPRepository_Expecter) Create(ctx interface{}, otp interface{}) *MockPhoneOTPRepository_Create_Call {
+	return &MockPhoneOTPRepository_Create_Call{Call: _e.mock.On("Create", ctx, otp)}
+}
+
+func (_c *MockPhoneOTPRepository_Create_Call) Run(run func(ctx context.Context, otp *entity.PhoneOTP)) *MockPhoneOTPRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.PhoneOTP))
+	})
+	return _c
+}
+
+func (_c *MockPhoneOTPRepository_Create_Call) Return(_a0 error) *MockPhoneOTPRepository_Create_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockPhoneOTPRepository_Create_Call) RunAndReturn(run func(context.Context, *entity.PhoneOTP) error) *MockPhoneOTPRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *MockPhoneOTPRepository) Delete(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockPhoneOTPRepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockPhoneOTPRepository_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockPhoneOTPRepository_Expecter) Delete(ctx interface{}, id interface{}) *MockPhoneOTPRepository_Delete_Call {
+	return &MockPhoneOTPRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, id)}
+}
+
+func (_c *MockPhoneOTPRepository_Delete_Call) Run(run func(ctx context.Context, id string)) *MockPhoneOTPRepository_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockPhoneOTPRepository_Delete_Call) Return(_a0 error) *MockPhoneOTPRepository_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockPhoneOTPRepository_Delete_Call) RunAndReturn(run func(context.Context, string) error) *MockPhoneOTPRepository_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByUser provides a mock function with given fields: ctx, userID
+func (_m *MockPhoneOTPRepository) DeleteByUser(ctx context.Context, userID string) (int, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByUser")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPhoneOTPRepository_DeleteByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByUser'
+type MockPhoneOTPRepository_DeleteByUser_Call struct {
+	*mock.Call
+}
+
+// DeleteByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockPhoneOTPRepository_Expecter) DeleteByUser(ctx interface{}, userID interface{}) *MockPhoneOTPRepository_DeleteByUser_Call {
+	return &MockPhoneOTPRepository_DeleteByUser_Call{Call: _e.mock.On("DeleteByUser", ctx, userID)}
+}
+
+func (_c *MockPhoneOTPRepository_DeleteByUser_Call) Run(run func(ctx context.Context, userID string)) *MockPhoneOTPRepository_DeleteByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockPhoneOTPRepository_DeleteByUser_Call) Return(_a0 int, _a1 error) *MockPhoneOTPRepository_DeleteByUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPhoneOTPRepository_DeleteByUser_Call) RunAndReturn(run func(context.Context, string) (int, error)) *MockPhoneOTPRepository_DeleteByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPending provides a mock function with given fields: ctx, userID, purpose
+func (_m *MockPhoneOTPRepository) GetPending(ctx context.Context, userID string, purpose entity.OTPPurpose) (*entity.PhoneOTP, error) {
+	ret := _m.Called(ctx, userID, purpose)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPending")
+	}
+
+	var r0 *entity.PhoneOTP
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.OTPPurpose) (*entity.PhoneOTP, error)); ok {
+		return rf(ctx, userID, purpose)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.OTPPurpose) *entity.PhoneOTP); ok {
+		r0 = rf(ctx, userID, purpose)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.PhoneOTP)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, entity.OTPPurpose) error); ok {
+		r1 = rf(ctx, userID, purpose)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPhoneOTPRepository_GetPending_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPending'
+type MockPhoneOTPRepository_GetPending_Call struct {
+	*mock.Call
+}
+
+// GetPending is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - purpose entity.OTPPurpose
+func (_e *MockPhoneOTPRepository_Expecter) GetPending(ctx interface{}, userID interface{}, purpose interface{}) *MockPhoneOTPRepository_GetPending_Call {
+	return &MockPhoneOTPRepository_GetPending_Call{Call: _e.mock.On("GetPending", ctx, userID, purpose)}
+}
+
+func (_c *MockPhoneOTPRepository_GetPending_Call) Run(run func(ctx context.Context, userID string, purpose entity.OTPPurpose)) *MockPhoneOTPRepository_GetPending_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(entity.OTPPurpose))
+	})
+	return _c
+}
+
+func (_c *MockPhoneOTPRepository_GetPending_Call) Return(_a0 *entity.PhoneOTP, _a1 error) *MockPhoneOTPRepository_GetPending_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPhoneOTPRepository_GetPending_Call) RunAndReturn(run func(context.Context, string, entity.OTPPurpose) (*entity.PhoneOTP, error)) *MockPhoneOTPRepository_GetPending_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockPhoneOTPRepository creates a new instance of MockPhoneOTPRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockPhoneOTPRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockPhoneOTPRepository {
+	mock := &MockPhoneOTPRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
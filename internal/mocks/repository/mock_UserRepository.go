@@ -7,6 +7,8 @@ import (
 	context "context"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // MockUserRepository is an autogenerated mock type for the UserRepository type
@@ -175,14 +177,88 @@ func (_c *MockUserRepository_GetByEmail_Call) RunAndReturn(run func(context.Cont
 	return _c
 }
 
-// GetByID provides a mock function with given fields: ctx, id
-func (_m *MockUserRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
-	ret := _m.Called(ctx, id)
+// GetByID provides a mock function with given fields: ctx, id, fields
+func (_m *MockUserRepository) GetByID(ctx context.Context, id string, fields ...string) (*entity.User, error) {
+	_va := make([]interface{}, len(fields))
+	for _i := range fields {
+		_va[_i] = fields[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, id)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetByID")
 	}
 
+	var r0 *entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) (*entity.User, error)); ok {
+		return rf(ctx, id, fields...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) *entity.User); ok {
+		r0 = rf(ctx, id, fields...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...string) error); ok {
+		r1 = rf(ctx, id, fields...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepository_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockUserRepository_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - fields ...string
+func (_e *MockUserRepository_Expecter) GetByID(ctx interface{}, id interface{}, fields ...interface{}) *MockUserRepository_GetByID_Call {
+	return &MockUserRepository_GetByID_Call{Call: _e.mock.On("GetByID",
+		append([]interface{}{ctx, id}, fields...)...)}
+}
+
+func (_c *MockUserRepository_GetByID_Call) Run(run func(ctx context.Context, id string, fields ...string)) *MockUserRepository_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_GetByID_Call) Return(_a0 *entity.User, _a1 error) *MockUserRepository_GetByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepository_GetByID_Call) RunAndReturn(run func(context.Context, string, ...string) (*entity.User, error)) *MockUserRepository_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByIDWithDeleted provides a mock function with given fields: ctx, id
+func (_m *MockUserRepository) GetByIDWithDeleted(ctx context.Context, id string) (*entity.User, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDWithDeleted")
+	}
+
 	var r0 *entity.User
 	var r1 error
 	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.User, error)); ok {
@@ -205,31 +281,90 @@ func (_m *MockUserRepository) GetByID(ctx context.Context, id string) (*entity.U
 	return r0, r1
 }
 
-// MockUserRepository_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
-type MockUserRepository_GetByID_Call struct {
+// MockUserRepository_GetByIDWithDeleted_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByIDWithDeleted'
+type MockUserRepository_GetByIDWithDeleted_Call struct {
 	*mock.Call
 }
 
-// GetByID is a helper method to define mock.On call
+// GetByIDWithDeleted is a helper method to define mock.On call
 //   - ctx context.Context
 //   - id string
-func (_e *MockUserRepository_Expecter) GetByID(ctx interface{}, id interface{}) *MockUserRepository_GetByID_Call {
-	return &MockUserRepository_GetByID_Call{Call: _e.mock.On("GetByID", ctx, id)}
+func (_e *MockUserRepository_Expecter) GetByIDWithDeleted(ctx interface{}, id interface{}) *MockUserRepository_GetByIDWithDeleted_Call {
+	return &MockUserRepository_GetByIDWithDeleted_Call{Call: _e.mock.On("GetByIDWithDeleted", ctx, id)}
 }
 
-func (_c *MockUserRepository_GetByID_Call) Run(run func(ctx context.Context, id string)) *MockUserRepository_GetByID_Call {
+func (_c *MockUserRepository_GetByIDWithDeleted_Call) Run(run func(ctx context.Context, id string)) *MockUserRepository_GetByIDWithDeleted_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockUserRepository_GetByID_Call) Return(_a0 *entity.User, _a1 error) *MockUserRepository_GetByID_Call {
+func (_c *MockUserRepository_GetByIDWithDeleted_Call) Return(_a0 *entity.User, _a1 error) *MockUserRepository_GetByIDWithDeleted_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepository_GetByIDWithDeleted_Call) RunAndReturn(run func(context.Context, string) (*entity.User, error)) *MockUserRepository_GetByIDWithDeleted_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByPhone provides a mock function with given fields: ctx, phone
+func (_m *MockUserRepository) GetByPhone(ctx context.Context, phone string) (*entity.User, error) {
+	ret := _m.Called(ctx, phone)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByPhone")
+	}
+
+	var r0 *entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.User, error)); ok {
+		return rf(ctx, phone)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.User); ok {
+		r0 = rf(ctx, phone)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, phone)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepository_GetByPhone_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByPhone'
+type MockUserRepository_GetByPhone_Call struct {
+	*mock.Call
+}
+
+// GetByPhone is a helper method to define mock.On call
+//   - ctx context.Context
+//   - phone string
+func (_e *MockUserRepository_Expecter) GetByPhone(ctx interface{}, phone interface{}) *MockUserRepository_GetByPhone_Call {
+	return &MockUserRepository_GetByPhone_Call{Call: _e.mock.On("GetByPhone", ctx, phone)}
+}
+
+func (_c *MockUserRepository_GetByPhone_Call) Run(run func(ctx context.Context, phone string)) *MockUserRepository_GetByPhone_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_GetByPhone_Call) Return(_a0 *entity.User, _a1 error) *MockUserRepository_GetByPhone_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockUserRepository_GetByID_Call) RunAndReturn(run func(context.Context, string) (*entity.User, error)) *MockUserRepository_GetByID_Call {
+func (_c *MockUserRepository_GetByPhone_Call) RunAndReturn(run func(context.Context, string) (*entity.User, error)) *MockUserRepository_GetByPhone_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -293,8 +428,184 @@ func (_c *MockUserRepository_GetByUsername_Call) RunAndReturn(run func(context.C
 	return _c
 }
 
+// GetDeletedByEmail provides a mock function with given fields: ctx, email
+func (_m *MockUserRepository) GetDeletedByEmail(ctx context.Context, email string) (*entity.User, error) {
+	ret := _m.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeletedByEmail")
+	}
+
+	var r0 *entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.User, error)); ok {
+		return rf(ctx, email)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.User); ok {
+		r0 = rf(ctx, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepository_GetDeletedByEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDeletedByEmail'
+type MockUserRepository_GetDeletedByEmail_Call struct {
+	*mock.Call
+}
+
+// GetDeletedByEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+func (_e *MockUserRepository_Expecter) GetDeletedByEmail(ctx interface{}, email interface{}) *MockUserRepository_GetDeletedByEmail_Call {
+	return &MockUserRepository_GetDeletedByEmail_Call{Call: _e.mock.On("GetDeletedByEmail", ctx, email)}
+}
+
+func (_c *MockUserRepository_GetDeletedByEmail_Call) Run(run func(ctx context.Context, email string)) *MockUserRepository_GetDeletedByEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_GetDeletedByEmail_Call) Return(_a0 *entity.User, _a1 error) *MockUserRepository_GetDeletedByEmail_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepository_GetDeletedByEmail_Call) RunAndReturn(run func(context.Context, string) (*entity.User, error)) *MockUserRepository_GetDeletedByEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDeletedByUsername provides a mock function with given fields: ctx, username
+func (_m *MockUserRepository) GetDeletedByUsername(ctx context.Context, username string) (*entity.User, error) {
+	ret := _m.Called(ctx, username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeletedByUsername")
+	}
+
+	var r0 *entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.User, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.User); ok {
+		r0 = rf(ctx, username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepository_GetDeletedByUsername_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDeletedByUsername'
+type MockUserRepository_GetDeletedByUsername_Call struct {
+	*mock.Call
+}
+
+// GetDeletedByUsername is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+func (_e *MockUserRepository_Expecter) GetDeletedByUsername(ctx interface{}, username interface{}) *MockUserRepository_GetDeletedByUsername_Call {
+	return &MockUserRepository_GetDeletedByUsername_Call{Call: _e.mock.On("GetDeletedByUsername", ctx, username)}
+}
+
+func (_c *MockUserRepository_GetDeletedByUsername_Call) Run(run func(ctx context.Context, username string)) *MockUserRepository_GetDeletedByUsername_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_GetDeletedByUsername_Call) Return(_a0 *entity.User, _a1 error) *MockUserRepository_GetDeletedByUsername_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepository_GetDeletedByUsername_Call) RunAndReturn(run func(context.Context, string) (*entity.User, error)) *MockUserRepository_GetDeletedByUsername_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IsUsernameTaken provides a mock function with given fields: ctx, username, exceptUserID
+func (_m *MockUserRepository) IsUsernameTaken(ctx context.Context, username string, exceptUserID string) (bool, error) {
+	ret := _m.Called(ctx, username, exceptUserID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsUsernameTaken")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
+		return rf(ctx, username, exceptUserID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, username, exceptUserID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, username, exceptUserID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepository_IsUsernameTaken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsUsernameTaken'
+type MockUserRepository_IsUsernameTaken_Call struct {
+	*mock.Call
+}
+
+// IsUsernameTaken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+//   - exceptUserID string
+func (_e *MockUserRepository_Expecter) IsUsernameTaken(ctx interface{}, username interface{}, exceptUserID interface{}) *MockUserRepository_IsUsernameTaken_Call {
+	return &MockUserRepository_IsUsernameTaken_Call{Call: _e.mock.On("IsUsernameTaken", ctx, username, exceptUserID)}
+}
+
+func (_c *MockUserRepository_IsUsernameTaken_Call) Run(run func(ctx context.Context, username string, exceptUserID string)) *MockUserRepository_IsUsernameTaken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_IsUsernameTaken_Call) Return(_a0 bool, _a1 error) *MockUserRepository_IsUsernameTaken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepository_IsUsernameTaken_Call) RunAndReturn(run func(context.Context, string, string) (bool, error)) *MockUserRepository_IsUsernameTaken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // List provides a mock function with given fields: ctx, filter
-func (_m *MockUserRepository) List(ctx context.Context, filter entity.FilterUser) ([]*entity.User, int, error) {
+func (_m *MockUserRepository) List(ctx context.Context, filter entity.FilterUser) ([]*entity.User, entity.Total, error) {
 	ret := _m.Called(ctx, filter)
 
 	if len(ret) == 0 {
@@ -302,9 +613,9 @@ func (_m *MockUserRepository) List(ctx context.Context, filter entity.FilterUser
 	}
 
 	var r0 []*entity.User
-	var r1 int
+	var r1 entity.Total
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, entity.FilterUser) ([]*entity.User, int, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, entity.FilterUser) ([]*entity.User, entity.Total, error)); ok {
 		return rf(ctx, filter)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, entity.FilterUser) []*entity.User); ok {
@@ -315,10 +626,10 @@ func (_m *MockUserRepository) List(ctx context.Context, filter entity.FilterUser
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, entity.FilterUser) int); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, entity.FilterUser) entity.Total); ok {
 		r1 = rf(ctx, filter)
 	} else {
-		r1 = ret.Get(1).(int)
+		r1 = ret.Get(1).(entity.Total)
 	}
 
 	if rf, ok := ret.Get(2).(func(context.Context, entity.FilterUser) error); ok {
@@ -349,27 +660,250 @@ func (_c *MockUserRepository_List_Call) Run(run func(ctx context.Context, filter
 	return _c
 }
 
-func (_c *MockUserRepository_List_Call) Return(_a0 []*entity.User, _a1 int, _a2 error) *MockUserRepository_List_Call {
+func (_c *MockUserRepository_List_Call) Return(_a0 []*entity.User, _a1 entity.Total, _a2 error) *MockUserRepository_List_Call {
 	_c.Call.Return(_a0, _a1, _a2)
 	return _c
 }
 
-func (_c *MockUserRepository_List_Call) RunAndReturn(run func(context.Context, entity.FilterUser) ([]*entity.User, int, error)) *MockUserRepository_List_Call {
+func (_c *MockUserRepository_List_Call) RunAndReturn(run func(context.Context, entity.FilterUser) ([]*entity.User, entity.Total, error)) *MockUserRepository_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAnonymized provides a mock function with given fields: ctx, before, offset, limit
+func (_m *MockUserRepository) ListAnonymized(ctx context.Context, before time.Time, offset int, limit int) ([]*entity.User, error) {
+	ret := _m.Called(ctx, before, offset, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAnonymized")
+	}
+
+	var r0 []*entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int, int) ([]*entity.User, error)); ok {
+		return rf(ctx, before, offset, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int, int) []*entity.User); ok {
+		r0 = rf(ctx, before, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int, int) error); ok {
+		r1 = rf(ctx, before, offset, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepository_ListAnonymized_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAnonymized'
+type MockUserRepository_ListAnonymized_Call struct {
+	*mock.Call
+}
+
+// ListAnonymized is a helper method to define mock.On call
+//   - ctx context.Context
+//   - before time.Time
+//   - offset int
+//   - limit int
+func (_e *MockUserRepository_Expecter) ListAnonymized(ctx interface{}, before interface{}, offset interface{}, limit interface{}) *MockUserRepository_ListAnonymized_Call {
+	return &MockUserRepository_ListAnonymized_Call{Call: _e.mock.On("ListAnonymized", ctx, before, offset, limit)}
+}
+
+func (_c *MockUserRepository_ListAnonymized_Call) Run(run func(ctx context.Context, before time.Time, offset int, limit int)) *MockUserRepository_ListAnonymized_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_ListAnonymized_Call) Return(_a0 []*entity.User, _a1 error) *MockUserRepository_ListAnonymized_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepository_ListAnonymized_Call) RunAndReturn(run func(context.Context, time.Time, int, int) ([]*entity.User, error)) *MockUserRepository_ListAnonymized_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListDeleted provides a mock function with given fields: ctx, before, offset, limit
+func (_m *MockUserRepository) ListDeleted(ctx context.Context, before time.Time, offset int, limit int) ([]*entity.User, error) {
+	ret := _m.Called(ctx, before, offset, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDeleted")
+	}
+
+	var r0 []*entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int, int) ([]*entity.User, error)); ok {
+		return rf(ctx, before, offset, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int, int) []*entity.User); ok {
+		r0 = rf(ctx, before, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int, int) error); ok {
+		r1 = rf(ctx, before, offset, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserRepository_ListDeleted_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListDeleted'
+type MockUserRepository_ListDeleted_Call struct {
+	*mock.Call
+}
+
+// ListDeleted is a helper method to define mock.On call
+//   - ctx context.Context
+//   - before time.Time
+//   - offset int
+//   - limit int
+func (_e *MockUserRepository_Expecter) ListDeleted(ctx interface{}, before interface{}, offset interface{}, limit interface{}) *MockUserRepository_ListDeleted_Call {
+	return &MockUserRepository_ListDeleted_Call{Call: _e.mock.On("ListDeleted", ctx, before, offset, limit)}
+}
+
+func (_c *MockUserRepository_ListDeleted_Call) Run(run func(ctx context.Context, before time.Time, offset int, limit int)) *MockUserRepository_ListDeleted_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_ListDeleted_Call) Return(_a0 []*entity.User, _a1 error) *MockUserRepository_ListDeleted_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserRepository_ListDeleted_Call) RunAndReturn(run func(context.Context, time.Time, int, int) ([]*entity.User, error)) *MockUserRepository_ListDeleted_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Update provides a mock function with given fields: ctx, filter, user
-func (_m *MockUserRepository) Update(ctx context.Context, filter entity.FilterUser, user *entity.User) error {
-	ret := _m.Called(ctx, filter, user)
+// Purge provides a mock function with given fields: ctx, id
+func (_m *MockUserRepository) Purge(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Purge")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepository_Purge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Purge'
+type MockUserRepository_Purge_Call struct {
+	*mock.Call
+}
+
+// Purge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockUserRepository_Expecter) Purge(ctx interface{}, id interface{}) *MockUserRepository_Purge_Call {
+	return &MockUserRepository_Purge_Call{Call: _e.mock.On("Purge", ctx, id)}
+}
+
+func (_c *MockUserRepository_Purge_Call) Run(run func(ctx context.Context, id string)) *MockUserRepository_Purge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_Purge_Call) Return(_a0 error) *MockUserRepository_Purge_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepository_Purge_Call) RunAndReturn(run func(context.Context, string) error) *MockUserRepository_Purge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Restore provides a mock function with given fields: ctx, id
+func (_m *MockUserRepository) Restore(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restore")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepository_Restore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Restore'
+type MockUserRepository_Restore_Call struct {
+	*mock.Call
+}
+
+// Restore is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockUserRepository_Expecter) Restore(ctx interface{}, id interface{}) *MockUserRepository_Restore_Call {
+	return &MockUserRepository_Restore_Call{Call: _e.mock.On("Restore", ctx, id)}
+}
+
+func (_c *MockUserRepository_Restore_Call) Run(run func(ctx context.Context, id string)) *MockUserRepository_Restore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_Restore_Call) Return(_a0 error) *MockUserRepository_Restore_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepository_Restore_Call) RunAndReturn(run func(context.Context, string) error) *MockUserRepository_Restore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function with given fields: ctx, filter, user, fields
+func (_m *MockUserRepository) Update(ctx context.Context, filter entity.FilterUser, user *entity.User, fields ...string) error {
+	_va := make([]interface{}, len(fields))
+	for _i := range fields {
+		_va[_i] = fields[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, filter, user)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, entity.FilterUser, *entity.User) error); ok {
-		r0 = rf(ctx, filter, user)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.FilterUser, *entity.User, ...string) error); ok {
+		r0 = rf(ctx, filter, user, fields...)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -386,13 +920,21 @@ type MockUserRepository_Update_Call struct {
 //   - ctx context.Context
 //   - filter entity.FilterUser
 //   - user *entity.User
-func (_e *MockUserRepository_Expecter) Update(ctx interface{}, filter interface{}, user interface{}) *MockUserRepository_Update_Call {
-	return &MockUserRepository_Update_Call{Call: _e.mock.On("Update", ctx, filter, user)}
+//   - fields ...string
+func (_e *MockUserRepository_Expecter) Update(ctx interface{}, filter interface{}, user interface{}, fields ...interface{}) *MockUserRepository_Update_Call {
+	return &MockUserRepository_Update_Call{Call: _e.mock.On("Update",
+		append([]interface{}{ctx, filter, user}, fields...)...)}
 }
 
-func (_c *MockUserRepository_Update_Call) Run(run func(ctx context.Context, filter entity.FilterUser, user *entity.User)) *MockUserRepository_Update_Call {
+func (_c *MockUserRepository_Update_Call) Run(run func(ctx context.Context, filter entity.FilterUser, user *entity.User, fields ...string)) *MockUserRepository_Update_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(entity.FilterUser), args[2].(*entity.User))
+		variadicArgs := make([]string, len(args)-3)
+		for i, a := range args[3:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), args[1].(entity.FilterUser), args[2].(*entity.User), variadicArgs...)
 	})
 	return _c
 }
@@ -402,7 +944,118 @@ func (_c *MockUserRepository_Update_Call) Return(_a0 error) *MockUserRepository_
 	return _c
 }
 
-func (_c *MockUserRepository_Update_Call) RunAndReturn(run func(context.Context, entity.FilterUser, *entity.User) error) *MockUserRepository_Update_Call {
+func (_c *MockUserRepository_Update_Call) RunAndReturn(run func(context.Context, entity.FilterUser, *entity.User, ...string) error) *MockUserRepository_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateDeleted provides a mock function with given fields: ctx, user, fields
+func (_m *MockUserRepository) UpdateDeleted(ctx context.Context, user *entity.User, fields ...string) error {
+	_va := make([]interface{}, len(fields))
+	for _i := range fields {
+		_va[_i] = fields[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, user)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateDeleted")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User, ...string) error); ok {
+		r0 = rf(ctx, user, fields...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepository_UpdateDeleted_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateDeleted'
+type MockUserRepository_UpdateDeleted_Call struct {
+	*mock.Call
+}
+
+// UpdateDeleted is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user *entity.User
+//   - fields ...string
+func (_e *MockUserRepository_Expecter) UpdateDeleted(ctx interface{}, user interface{}, fields ...interface{}) *MockUserRepository_UpdateDeleted_Call {
+	return &MockUserRepository_UpdateDeleted_Call{Call: _e.mock.On("UpdateDeleted",
+		append([]interface{}{ctx, user}, fields...)...)}
+}
+
+func (_c *MockUserRepository_UpdateDeleted_Call) Run(run func(ctx context.Context, user *entity.User, fields ...string)) *MockUserRepository_UpdateDeleted_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), args[1].(*entity.User), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_UpdateDeleted_Call) Return(_a0 error) *MockUserRepository_UpdateDeleted_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepository_UpdateDeleted_Call) RunAndReturn(run func(context.Context, *entity.User, ...string) error) *MockUserRepository_UpdateDeleted_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdatePassword provides a mock function with given fields: ctx, id, currentHash, newHash
+func (_m *MockUserRepository) UpdatePassword(ctx context.Context, id string, currentHash string, newHash string) error {
+	ret := _m.Called(ctx, id, currentHash, newHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, id, currentHash, newHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserRepository_UpdatePassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdatePassword'
+type MockUserRepository_UpdatePassword_Call struct {
+	*mock.Call
+}
+
+// UpdatePassword is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - currentHash string
+//   - newHash string
+func (_e *MockUserRepository_Expecter) UpdatePassword(ctx interface{}, id interface{}, currentHash interface{}, newHash interface{}) *MockUserRepository_UpdatePassword_Call {
+	return &MockUserRepository_UpdatePassword_Call{Call: _e.mock.On("UpdatePassword", ctx, id, currentHash, newHash)}
+}
+
+func (_c *MockUserRepository_UpdatePassword_Call) Run(run func(ctx context.Context, id string, currentHash string, newHash string)) *MockUserRepository_UpdatePassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_UpdatePassword_Call) Return(_a0 error) *MockUserRepository_UpdatePassword_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserRepository_UpdatePassword_Call) RunAndReturn(run func(context.Context, string, string, string) error) *MockUserRepository_UpdatePassword_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,318 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "app/internal/shared/domain/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockUsernameHistoryRepository is an autogenerated mock type for the UsernameHistoryRepository type
+type MockUsernameHistoryRepository struct {
+	mock.Mock
+}
+
+type MockUsernameHistoryRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockUsernameHistoryRepository) EXPECT() *MockUsernameHistoryRepository_Expecter {
+	return &MockUsernameHistoryRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: ctx, history
+func (_m *MockUsernameHistoryRepository) Create(ctx context.Context, history *entity.UsernameHistory) error {
+	ret := _m.Called(ctx, history)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.UsernameHistory) error); ok {
+		r0 = rf(ctx, history)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUsernameHistoryRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockUsernameHistoryRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - history *entity.UsernameHistory
+func (_e *MockUsernameHistoryRepository_Expecter) Create(ctx interface{}, history interface{}) *MockUsernameHistoryRepository_Create_Call {
+	return &MockUsernameHistoryRepository_Create_Call{Call: _e.mock.On("Create", ctx, history)}
+}
+
+func (_c *MockUsernameHistoryRepository_Create_Call) Run(run func(ctx context.Context, history *entity.UsernameHistory)) *MockUsernameHistoryRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.UsernameHistory))
+	})
+	return _c
+}
+
+func (_c *MockUsernameHistoryRepository_Create_Call) Return(_a0 error) *MockUsernameHistoryRepository_Create_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUsernameHistoryRepository_Create_Call) RunAndReturn(run func(context.Context, *entity.UsernameHistory) error) *MockUsernameHistoryRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByUser provides a mock function with given fields: ctx, userID
+func (_m *MockUsernameHistoryRepository) DeleteByUser(ctx context.Context, userID string) (int, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByUser")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUsernameHistoryRepository_DeleteByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByUser'
+type MockUsernameHistoryRepository_DeleteByUser_Call struct {
+	*mock.Call
+}
+
+// DeleteByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockUsernameHistoryRepository_Expecter) DeleteByUser(ctx interface{}, userID interface{}) *MockUsernameHistoryRepository_DeleteByUser_Call {
+	return &MockUsernameHistoryRepository_DeleteByUser_Call{Call: _e.mock.On("DeleteByUser", ctx, userID)}
+}
+
+func (_c *MockUsernameHistoryRepository_DeleteByUser_Call) Run(run func(ctx context.Context, userID string)) *MockUsernameHistoryRepository_DeleteByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUsernameHistoryRepository_DeleteByUser_Call) Return(_a0 int, _a1 error) *MockUsernameHistoryRepository_DeleteByUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUsernameHistoryRepository_DeleteByUser_Call) RunAndReturn(run func(context.Context, string) (int, error)) *MockUsernameHistoryRepository_DeleteByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetHeld provides a mock function with given fields: ctx, username
+func (_m *MockUsernameHistoryRepository) GetHeld(ctx context.Context, username string) (*entity.UsernameHistory, error) {
+	ret := _m.Called(ctx, username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHeld")
+	}
+
+	var r0 *entity.UsernameHistory
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.UsernameHistory, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.UsernameHistory); ok {
+		r0 = rf(ctx, username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.UsernameHistory)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUsernameHistoryRepository_GetHeld_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetHeld'
+type MockUsernameHistoryRepository_GetHeld_Call struct {
+	*mock.Call
+}
+
+// GetHeld is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+func (_e *MockUsernameHistoryRepository_Expecter) GetHeld(ctx interface{}, username interface{}) *MockUsernameHistoryRepository_GetHeld_Call {
+	return &MockUsernameHistoryRepository_GetHeld_Call{Call: _e.mock.On("GetHeld", ctx, username)}
+}
+
+func (_c *MockUsernameHistoryRepository_GetHeld_Call) Run(run func(ctx context.Context, username string)) *MockUsernameHistoryRepository_GetHeld_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUsernameHistoryRepository_GetHeld_Call) Return(_a0 *entity.UsernameHistory, _a1 error) *MockUsernameHistoryRepository_GetHeld_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUsernameHistoryRepository_GetHeld_Call) RunAndReturn(run func(context.Context, string) (*entity.UsernameHistory, error)) *MockUsernameHistoryRepository_GetHeld_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetLatest provides a mock function with given fields: ctx, userID
+func (_m *MockUsernameHistoryRepository) GetLatest(ctx context.Context, userID string) (*entity.UsernameHistory, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLatest")
+	}
+
+	var r0 *entity.UsernameHistory
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.UsernameHistory, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.UsernameHistory); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.UsernameHistory)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUsernameHistoryRepository_GetLatest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetLatest'
+type MockUsernameHistoryRepository_GetLatest_Call struct {
+	*mock.Call
+}
+
+// GetLatest is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockUsernameHistoryRepository_Expecter) GetLatest(ctx interface{}, userID interface{}) *MockUsernameHistoryRepository_GetLatest_Call {
+	return &MockUsernameHistoryRepository_GetLatest_Call{Call: _e.mock.On("GetLatest", ctx, userID)}
+}
+
+func (_c *MockUsernameHistoryRepository_GetLatest_Call) Run(run func(ctx context.Context, userID string)) *MockUsernameHistoryRepository_GetLatest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUsernameHistoryRepository_GetLatest_Call) Return(_a0 *entity.UsernameHistory, _a1 error) *MockUsernameHistoryRepository_GetLatest_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUsernameHistoryRepository_GetLatest_Call) RunAndReturn(run func(context.Context, string) (*entity.UsernameHistory, error)) *MockUsernameHistoryRepository_GetLatest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListByUser provides a mock function with given fields: ctx, userID
+func (_m *MockUsernameHistoryRepository) ListByUser(ctx context.Context, userID string) ([]*entity.UsernameHistory, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByUser")
+	}
+
+	var r0 []*entity.UsernameHistory
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*entity.UsernameHistory, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*entity.UsernameHistory); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.UsernameHistory)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUsernameHistoryRepository_ListByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListByUser'
+type MockUsernameHistoryRepository_ListByUser_Call struct {
+	*mock.Call
+}
+
+// ListByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockUsernameHistoryRepository_Expecter) ListByUser(ctx interface{}, userID interface{}) *MockUsernameHistoryRepository_ListByUser_Call {
+	return &MockUsernameHistoryRepository_ListByUser_Call{Call: _e.mock.On("ListByUser", ctx, userID)}
+}
+
+func (_c *MockUsernameHistoryRepository_ListByUser_Call) Run(run func(ctx context.Context, userID string)) *MockUsernameHistoryRepository_ListByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUsernameHistoryRepository_ListByUser_Call) Return(_a0 []*entity.UsernameHistory, _a1 error) *MockUsernameHistoryRepository_ListByUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUsernameHistoryRepository_ListByUser_Call) RunAndReturn(run func(context.Context, string) ([]*entity.UsernameHistory, error)) *MockUsernameHistoryRepository_ListByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockUsernameHistoryRepository creates a new instance of MockUsernameHistoryRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockUsernameHistoryRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockUsernameHistoryRepository {
+	mock := &MockUsernameHistoryRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -7,6 +7,8 @@ import (
 	context "context"
 
 	mock "github.com/stretchr/testify/mock"
+
+	service "app/internal/shared/domain/service"
 )
 
 // MockAuthUsecase is an autogenerated mock type for the AuthUsecase type
@@ -22,8 +24,293 @@ func (_m *MockAuthUsecase) EXPECT() *MockAuthUsecase_Expecter {
 	return &MockAuthUsecase_Expecter{mock: &_m.Mock}
 }
 
+// AnonymizeUserData provides a mock function with given fields: ctx, userID, dryRun
+func (_m *MockAuthUsecase) AnonymizeUserData(ctx context.Context, userID string, dryRun bool) ([]service.AnonymizedRecords, error) {
+	ret := _m.Called(ctx, userID, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AnonymizeUserData")
+	}
+
+	var r0 []service.AnonymizedRecords
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) ([]service.AnonymizedRecords, error)); ok {
+		return rf(ctx, userID, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) []service.AnonymizedRecords); ok {
+		r0 = rf(ctx, userID, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.AnonymizedRecords)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, userID, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuthUsecase_AnonymizeUserData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AnonymizeUserData'
+type MockAuthUsecase_AnonymizeUserData_Call struct {
+	*mock.Call
+}
+
+// AnonymizeUserData is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - dryRun bool
+func (_e *MockAuthUsecase_Expecter) AnonymizeUserData(ctx interface{}, userID interface{}, dryRun interface{}) *MockAuthUsecase_AnonymizeUserData_Call {
+	return &MockAuthUsecase_AnonymizeUserData_Call{Call: _e.mock.On("AnonymizeUserData", ctx, userID, dryRun)}
+}
+
+func (_c *MockAuthUsecase_AnonymizeUserData_Call) Run(run func(ctx context.Context, userID string, dryRun bool)) *MockAuthUsecase_AnonymizeUserData_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *MockAuthUsecase_AnonymizeUserData_Call) Return(_a0 []service.AnonymizedRecords, _a1 error) *MockAuthUsecase_AnonymizeUserData_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuthUsecase_AnonymizeUserData_Call) RunAndReturn(run func(context.Context, string, bool) ([]service.AnonymizedRecords, error)) *MockAuthUsecase_AnonymizeUserData_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CancelEmailChange provides a mock function with given fields: ctx, req
+func (_m *MockAuthUsecase) CancelEmailChange(ctx context.Context, req dto.EmailChangeTokenRequest) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelEmailChange")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.EmailChangeTokenRequest) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAuthUsecase_CancelEmailChange_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CancelEmailChange'
+type MockAuthUsecase_CancelEmailChange_Call struct {
+	*mock.Call
+}
+
+// CancelEmailChange is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req dto.EmailChangeTokenRequest
+func (_e *MockAuthUsecase_Expecter) CancelEmailChange(ctx interface{}, req interface{}) *MockAuthUsecase_CancelEmailChange_Call {
+	return &MockAuthUsecase_CancelEmailChange_Call{Call: _e.mock.On("CancelEmailChange", ctx, req)}
+}
+
+func (_c *MockAuthUsecase_CancelEmailChange_Call) Run(run func(ctx context.Context, req dto.EmailChangeTokenRequest)) *MockAuthUsecase_CancelEmailChange_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(dto.EmailChangeTokenRequest))
+	})
+	return _c
+}
+
+func (_c *MockAuthUsecase_CancelEmailChange_Call) Return(_a0 error) *MockAuthUsecase_CancelEmailChange_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAuthUsecase_CancelEmailChange_Call) RunAndReturn(run func(context.Context, dto.EmailChangeTokenRequest) error) *MockAuthUsecase_CancelEmailChange_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ConfirmEmailChange provides a mock function with given fields: ctx, req
+func (_m *MockAuthUsecase) ConfirmEmailChange(ctx context.Context, req dto.EmailChangeTokenRequest) (*dto.RegisterResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConfirmEmailChange")
+	}
+
+	var r0 *dto.RegisterResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.EmailChangeTokenRequest) (*dto.RegisterResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, dto.EmailChangeTokenRequest) *dto.RegisterResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.RegisterResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, dto.EmailChangeTokenRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuthUsecase_ConfirmEmailChange_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConfirmEmailChange'
+type MockAuthUsecase_ConfirmEmailChange_Call struct {
+	*mock.Call
+}
+
+// ConfirmEmailChange is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req dto.EmailChangeTokenRequest
+func (_e *MockAuthUsecase_Expecter) ConfirmEmailChange(ctx interface{}, req interface{}) *MockAuthUsecase_ConfirmEmailChange_Call {
+	return &MockAuthUsecase_ConfirmEmailChange_Call{Call: _e.mock.On("ConfirmEmailChange", ctx, req)}
+}
+
+func (_c *MockAuthUsecase_ConfirmEmailChange_Call) Run(run func(ctx context.Context, req dto.EmailChangeTokenRequest)) *MockAuthUsecase_ConfirmEmailChange_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(dto.EmailChangeTokenRequest))
+	})
+	return _c
+}
+
+func (_c *MockAuthUsecase_ConfirmEmailChange_Call) Return(_a0 *dto.RegisterResponse, _a1 error) *MockAuthUsecase_ConfirmEmailChange_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuthUsecase_ConfirmEmailChange_Call) RunAndReturn(run func(context.Context, dto.EmailChangeTokenRequest) (*dto.RegisterResponse, error)) *MockAuthUsecase_ConfirmEmailChange_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ConfirmPhoneVerification provides a mock function with given fields: ctx, userID, req
+func (_m *MockAuthUsecase) ConfirmPhoneVerification(ctx context.Context, userID string, req dto.ConfirmPhoneRequest) (*dto.RegisterResponse, error) {
+	ret := _m.Called(ctx, userID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConfirmPhoneVerification")
+	}
+
+	var r0 *dto.RegisterResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.ConfirmPhoneRequest) (*dto.RegisterResponse, error)); ok {
+		return rf(ctx, userID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.ConfirmPhoneRequest) *dto.RegisterResponse); ok {
+		r0 = rf(ctx, userID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.RegisterResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, dto.ConfirmPhoneRequest) error); ok {
+		r1 = rf(ctx, userID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuthUsecase_ConfirmPhoneVerification_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConfirmPhoneVerification'
+type MockAuthUsecase_ConfirmPhoneVerification_Call struct {
+	*mock.Call
+}
+
+// ConfirmPhoneVerification is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - req dto.ConfirmPhoneRequest
+func (_e *MockAuthUsecase_Expecter) ConfirmPhoneVerification(ctx interface{}, userID interface{}, req interface{}) *MockAuthUsecase_ConfirmPhoneVerification_Call {
+	return &MockAuthUsecase_ConfirmPhoneVerification_Call{Call: _e.mock.On("ConfirmPhoneVerification", ctx, userID, req)}
+}
+
+func (_c *MockAuthUsecase_ConfirmPhoneVerification_Call) Run(run func(ctx context.Context, userID string, req dto.ConfirmPhoneRequest)) *MockAuthUsecase_ConfirmPhoneVerification_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(dto.ConfirmPhoneRequest))
+	})
+	return _c
+}
+
+func (_c *MockAuthUsecase_ConfirmPhoneVerification_Call) Return(_a0 *dto.RegisterResponse, _a1 error) *MockAuthUsecase_ConfirmPhoneVerification_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuthUsecase_ConfirmPhoneVerification_Call) RunAndReturn(run func(context.Context, string, dto.ConfirmPhoneRequest) (*dto.RegisterResponse, error)) *MockAuthUsecase_ConfirmPhoneVerification_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExportUserData provides a mock function with given fields: ctx, userID
+func (_m *MockAuthUsecase) ExportUserData(ctx context.Context, userID string) (map[string]interface{}, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportUserData")
+	}
+
+	var r0 map[string]interface{}
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (map[string]interface{}, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) map[string]interface{}); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]interface{})
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuthUsecase_ExportUserData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportUserData'
+type MockAuthUsecase_ExportUserData_Call struct {
+	*mock.Call
+}
+
+// ExportUserData is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockAuthUsecase_Expecter) ExportUserData(ctx interface{}, userID interface{}) *MockAuthUsecase_ExportUserData_Call {
+	return &MockAuthUsecase_ExportUserData_Call{Call: _e.mock.On("ExportUserData", ctx, userID)}
+}
+
+func (_c *MockAuthUsecase_ExportUserData_Call) Run(run func(ctx context.Context, userID string)) *MockAuthUsecase_ExportUserData_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuthUsecase_ExportUserData_Call) Return(_a0 map[string]interface{}, _a1 error) *MockAuthUsecase_ExportUserData_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuthUsecase_ExportUserData_Call) RunAndReturn(run func(context.Context, string) (map[string]interface{}, error)) *MockAuthUsecase_ExportUserData_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Login provides a mock function with given fields: ctx, req
-func (_m *MockAuthUsecase) Login(ctx context.Context, req dto.LoginRequest) (*dto.LoginResponse, int, error) {
+func (_m *MockAuthUsecase) Login(ctx context.Context, req dto.LoginRequest) (*dto.LoginResponse, error) {
 	ret := _m.Called(ctx, req)
 
 	if len(ret) == 0 {
@@ -31,9 +318,8 @@ func (_m *MockAuthUsecase) Login(ctx context.Context, req dto.LoginRequest) (*dt
 	}
 
 	var r0 *dto.LoginResponse
-	var r1 int
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, dto.LoginRequest) (*dto.LoginResponse, int, error)); ok {
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.LoginRequest) (*dto.LoginResponse, error)); ok {
 		return rf(ctx, req)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, dto.LoginRequest) *dto.LoginResponse); ok {
@@ -44,19 +330,13 @@ func (_m *MockAuthUsecase) Login(ctx context.Context, req dto.LoginRequest) (*dt
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, dto.LoginRequest) int); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, dto.LoginRequest) error); ok {
 		r1 = rf(ctx, req)
 	} else {
-		r1 = ret.Get(1).(int)
-	}
-
-	if rf, ok := ret.Get(2).(func(context.Context, dto.LoginRequest) error); ok {
-		r2 = rf(ctx, req)
-	} else {
-		r2 = ret.Error(2)
+		r1 = ret.Error(1)
 	}
 
-	return r0, r1, r2
+	return r0, r1
 }
 
 // MockAuthUsecase_Login_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Login'
@@ -78,18 +358,77 @@ func (_c *MockAuthUsecase_Login_Call) Run(run func(ctx context.Context, req dto.
 	return _c
 }
 
-func (_c *MockAuthUsecase_Login_Call) Return(_a0 *dto.LoginResponse, _a1 int, _a2 error) *MockAuthUsecase_Login_Call {
-	_c.Call.Return(_a0, _a1, _a2)
+func (_c *MockAuthUsecase_Login_Call) Return(_a0 *dto.LoginResponse, _a1 error) *MockAuthUsecase_Login_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockAuthUsecase_Login_Call) RunAndReturn(run func(context.Context, dto.LoginRequest) (*dto.LoginResponse, int, error)) *MockAuthUsecase_Login_Call {
+func (_c *MockAuthUsecase_Login_Call) RunAndReturn(run func(context.Context, dto.LoginRequest) (*dto.LoginResponse, error)) *MockAuthUsecase_Login_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LoginWithPhone provides a mock function with given fields: ctx, req
+func (_m *MockAuthUsecase) LoginWithPhone(ctx context.Context, req dto.PhoneLoginRequest) (*dto.LoginResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoginWithPhone")
+	}
+
+	var r0 *dto.LoginResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.PhoneLoginRequest) (*dto.LoginResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, dto.PhoneLoginRequest) *dto.LoginResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.LoginResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, dto.PhoneLoginRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuthUsecase_LoginWithPhone_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LoginWithPhone'
+type MockAuthUsecase_LoginWithPhone_Call struct {
+	*mock.Call
+}
+
+// LoginWithPhone is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req dto.PhoneLoginRequest
+func (_e *MockAuthUsecase_Expecter) LoginWithPhone(ctx interface{}, req interface{}) *MockAuthUsecase_LoginWithPhone_Call {
+	return &MockAuthUsecase_LoginWithPhone_Call{Call: _e.mock.On("LoginWithPhone", ctx, req)}
+}
+
+func (_c *MockAuthUsecase_LoginWithPhone_Call) Run(run func(ctx context.Context, req dto.PhoneLoginRequest)) *MockAuthUsecase_LoginWithPhone_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(dto.PhoneLoginRequest))
+	})
+	return _c
+}
+
+func (_c *MockAuthUsecase_LoginWithPhone_Call) Return(_a0 *dto.LoginResponse, _a1 error) *MockAuthUsecase_LoginWithPhone_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuthUsecase_LoginWithPhone_Call) RunAndReturn(run func(context.Context, dto.PhoneLoginRequest) (*dto.LoginResponse, error)) *MockAuthUsecase_LoginWithPhone_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // Register provides a mock function with given fields: ctx, req
-func (_m *MockAuthUsecase) Register(ctx context.Context, req dto.RegisterRequest) (*dto.RegisterResponse, int, error) {
+func (_m *MockAuthUsecase) Register(ctx context.Context, req dto.RegisterRequest) (*dto.RegisterResponse, error) {
 	ret := _m.Called(ctx, req)
 
 	if len(ret) == 0 {
@@ -97,9 +436,8 @@ func (_m *MockAuthUsecase) Register(ctx context.Context, req dto.RegisterRequest
 	}
 
 	var r0 *dto.RegisterResponse
-	var r1 int
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, dto.RegisterRequest) (*dto.RegisterResponse, int, error)); ok {
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.RegisterRequest) (*dto.RegisterResponse, error)); ok {
 		return rf(ctx, req)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, dto.RegisterRequest) *dto.RegisterResponse); ok {
@@ -110,19 +448,13 @@ func (_m *MockAuthUsecase) Register(ctx context.Context, req dto.RegisterRequest
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, dto.RegisterRequest) int); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, dto.RegisterRequest) error); ok {
 		r1 = rf(ctx, req)
 	} else {
-		r1 = ret.Get(1).(int)
-	}
-
-	if rf, ok := ret.Get(2).(func(context.Context, dto.RegisterRequest) error); ok {
-		r2 = rf(ctx, req)
-	} else {
-		r2 = ret.Error(2)
+		r1 = ret.Error(1)
 	}
 
-	return r0, r1, r2
+	return r0, r1
 }
 
 // MockAuthUsecase_Register_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Register'
@@ -144,12 +476,214 @@ func (_c *MockAuthUsecase_Register_Call) Run(run func(ctx context.Context, req d
 	return _c
 }
 
-func (_c *MockAuthUsecase_Register_Call) Return(_a0 *dto.RegisterResponse, _a1 int, _a2 error) *MockAuthUsecase_Register_Call {
-	_c.Call.Return(_a0, _a1, _a2)
+func (_c *MockAuthUsecase_Register_Call) Return(_a0 *dto.RegisterResponse, _a1 error) *MockAuthUsecase_Register_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuthUsecase_Register_Call) RunAndReturn(run func(context.Context, dto.RegisterRequest) (*dto.RegisterResponse, error)) *MockAuthUsecase_Register_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RequestEmailChange provides a mock function with given fields: ctx, userID, req
+func (_m *MockAuthUsecase) RequestEmailChange(ctx context.Context, userID string, req dto.EmailChangeRequest) error {
+	ret := _m.Called(ctx, userID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestEmailChange")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.EmailChangeRequest) error); ok {
+		r0 = rf(ctx, userID, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAuthUsecase_RequestEmailChange_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RequestEmailChange'
+type MockAuthUsecase_RequestEmailChange_Call struct {
+	*mock.Call
+}
+
+// RequestEmailChange is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - req dto.EmailChangeRequest
+func (_e *MockAuthUsecase_Expecter) RequestEmailChange(ctx interface{}, userID interface{}, req interface{}) *MockAuthUsecase_RequestEmailChange_Call {
+	return &MockAuthUsecase_RequestEmailChange_Call{Call: _e.mock.On("RequestEmailChange", ctx, userID, req)}
+}
+
+func (_c *MockAuthUsecase_RequestEmailChange_Call) Run(run func(ctx context.Context, userID string, req dto.EmailChangeRequest)) *MockAuthUsecase_RequestEmailChange_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(dto.EmailChangeRequest))
+	})
+	return _c
+}
+
+func (_c *MockAuthUsecase_RequestEmailChange_Call) Return(_a0 error) *MockAuthUsecase_RequestEmailChange_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAuthUsecase_RequestEmailChange_Call) RunAndReturn(run func(context.Context, string, dto.EmailChangeRequest) error) *MockAuthUsecase_RequestEmailChange_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RequestLoginOTP provides a mock function with given fields: ctx, req
+func (_m *MockAuthUsecase) RequestLoginOTP(ctx context.Context, req dto.PhoneOTPRequest) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestLoginOTP")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.PhoneOTPRequest) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAuthUsecase_RequestLoginOTP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RequestLoginOTP'
+type MockAuthUsecase_RequestLoginOTP_Call struct {
+	*mock.Call
+}
+
+// RequestLoginOTP is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req dto.PhoneOTPRequest
+func (_e *MockAuthUsecase_Expecter) RequestLoginOTP(ctx interface{}, req interface{}) *MockAuthUsecase_RequestLoginOTP_Call {
+	return &MockAuthUsecase_RequestLoginOTP_Call{Call: _e.mock.On("RequestLoginOTP", ctx, req)}
+}
+
+func (_c *MockAuthUsecase_RequestLoginOTP_Call) Run(run func(ctx context.Context, req dto.PhoneOTPRequest)) *MockAuthUsecase_RequestLoginOTP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(dto.PhoneOTPRequest))
+	})
+	return _c
+}
+
+func (_c *MockAuthUsecase_RequestLoginOTP_Call) Return(_a0 error) *MockAuthUsecase_RequestLoginOTP_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAuthUsecase_RequestLoginOTP_Call) RunAndReturn(run func(context.Context, dto.PhoneOTPRequest) error) *MockAuthUsecase_RequestLoginOTP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RequestPhoneVerification provides a mock function with given fields: ctx, userID
+func (_m *MockAuthUsecase) RequestPhoneVerification(ctx context.Context, userID string) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestPhoneVerification")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAuthUsecase_RequestPhoneVerification_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RequestPhoneVerification'
+type MockAuthUsecase_RequestPhoneVerification_Call struct {
+	*mock.Call
+}
+
+// RequestPhoneVerification is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockAuthUsecase_Expecter) RequestPhoneVerification(ctx interface{}, userID interface{}) *MockAuthUsecase_RequestPhoneVerification_Call {
+	return &MockAuthUsecase_RequestPhoneVerification_Call{Call: _e.mock.On("RequestPhoneVerification", ctx, userID)}
+}
+
+func (_c *MockAuthUsecase_RequestPhoneVerification_Call) Run(run func(ctx context.Context, userID string)) *MockAuthUsecase_RequestPhoneVerification_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuthUsecase_RequestPhoneVerification_Call) Return(_a0 error) *MockAuthUsecase_RequestPhoneVerification_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAuthUsecase_RequestPhoneVerification_Call) RunAndReturn(run func(context.Context, string) error) *MockAuthUsecase_RequestPhoneVerification_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetTwoFactor provides a mock function with given fields: ctx, userID, req
+func (_m *MockAuthUsecase) SetTwoFactor(ctx context.Context, userID string, req dto.TwoFactorRequest) (*dto.RegisterResponse, error) {
+	ret := _m.Called(ctx, userID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetTwoFactor")
+	}
+
+	var r0 *dto.RegisterResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.TwoFactorRequest) (*dto.RegisterResponse, error)); ok {
+		return rf(ctx, userID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, dto.TwoFactorRequest) *dto.RegisterResponse); ok {
+		r0 = rf(ctx, userID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.RegisterResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, dto.TwoFactorRequest) error); ok {
+		r1 = rf(ctx, userID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuthUsecase_SetTwoFactor_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetTwoFactor'
+type MockAuthUsecase_SetTwoFactor_Call struct {
+	*mock.Call
+}
+
+// SetTwoFactor is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - req dto.TwoFactorRequest
+func (_e *MockAuthUsecase_Expecter) SetTwoFactor(ctx interface{}, userID interface{}, req interface{}) *MockAuthUsecase_SetTwoFactor_Call {
+	return &MockAuthUsecase_SetTwoFactor_Call{Call: _e.mock.On("SetTwoFactor", ctx, userID, req)}
+}
+
+func (_c *MockAuthUsecase_SetTwoFactor_Call) Run(run func(ctx context.Context, userID string, req dto.TwoFactorRequest)) *MockAuthUsecase_SetTwoFactor_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(dto.TwoFactorRequest))
+	})
+	return _c
+}
+
+func (_c *MockAuthUsecase_SetTwoFactor_Call) Return(_a0 *dto.RegisterResponse, _a1 error) *MockAuthUsecase_SetTwoFactor_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockAuthUsecase_Register_Call) RunAndReturn(run func(context.Context, dto.RegisterRequest) (*dto.RegisterResponse, int, error)) *MockAuthUsecase_Register_Call {
+func (_c *MockAuthUsecase_SetTwoFactor_Call) RunAndReturn(run func(context.Context, string, dto.TwoFactorRequest) (*dto.RegisterResponse, error)) *MockAuthUsecase_SetTwoFactor_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,341 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	dto "app/internal/features/export/delivery/http/dto"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	service "app/internal/shared/domain/service"
+)
+
+// MockExportUsecase is an autogenerated mock type for the ExportUsecase type
+type MockExportUsecase struct {
+	mock.Mock
+}
+
+type MockExportUsecase_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockExportUsecase) EXPECT() *MockExportUsecase_Expecter {
+	return &MockExportUsecase_Expecter{mock: &_m.Mock}
+}
+
+// AnonymizeUserData provides a mock function with given fields: ctx, userID, dryRun
+func (_m *MockExportUsecase) AnonymizeUserData(ctx context.Context, userID string, dryRun bool) ([]service.AnonymizedRecords, error) {
+	ret := _m.Called(ctx, userID, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AnonymizeUserData")
+	}
+
+	var r0 []service.AnonymizedRecords
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) ([]service.AnonymizedRecords, error)); ok {
+		return rf(ctx, userID, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) []service.AnonymizedRecords); ok {
+		r0 = rf(ctx, userID, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.AnonymizedRecords)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, userID, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockExportUsecase_AnonymizeUserData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AnonymizeUserData'
+type MockExportUsecase_AnonymizeUserData_Call struct {
+	*mock.Call
+}
+
+// AnonymizeUserData is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - dryRun bool
+func (_e *MockExportUsecase_Expecter) AnonymizeUserData(ctx interface{}, userID interface{}, dryRun interface{}) *MockExportUsecase_AnonymizeUserData_Call {
+	return &MockExportUsecase_AnonymizeUserData_Call{Call: _e.mock.On("AnonymizeUserData", ctx, userID, dryRun)}
+}
+
+func (_c *MockExportUsecase_AnonymizeUserData_Call) Run(run func(ctx context.Context, userID string, dryRun bool)) *MockExportUsecase_AnonymizeUserData_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *MockExportUsecase_AnonymizeUserData_Call) Return(_a0 []service.AnonymizedRecords, _a1 error) *MockExportUsecase_AnonymizeUserData_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockExportUsecase_AnonymizeUserData_Call) RunAndReturn(run func(context.Context, string, bool) ([]service.AnonymizedRecords, error)) *MockExportUsecase_AnonymizeUserData_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Download provides a mock function with given fields: ctx, req
+func (_m *MockExportUsecase) Download(ctx context.Context, req dto.DownloadRequest) ([]byte, string, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Download")
+	}
+
+	var r0 []byte
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.DownloadRequest) ([]byte, string, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, dto.DownloadRequest) []byte); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, dto.DownloadRequest) string); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, dto.DownloadRequest) error); ok {
+		r2 = rf(ctx, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockExportUsecase_Download_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Download'
+type MockExportUsecase_Download_Call struct {
+	*mock.Call
+}
+
+// Download is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req dto.DownloadRequest
+func (_e *MockExportUsecase_Expecter) Download(ctx interface{}, req interface{}) *MockExportUsecase_Download_Call {
+	return &MockExportUsecase_Download_Call{Call: _e.mock.On("Download", ctx, req)}
+}
+
+func (_c *MockExportUsecase_Download_Call) Run(run func(ctx context.Context, req dto.DownloadRequest)) *MockExportUsecase_Download_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(dto.DownloadRequest))
+	})
+	return _c
+}
+
+func (_c *MockExportUsecase_Download_Call) Return(_a0 []byte, _a1 string, _a2 error) *MockExportUsecase_Download_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockExportUsecase_Download_Call) RunAndReturn(run func(context.Context, dto.DownloadRequest) ([]byte, string, error)) *MockExportUsecase_Download_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetExport provides a mock function with given fields: ctx, userID, exportID
+func (_m *MockExportUsecase) GetExport(ctx context.Context, userID string, exportID string) (*dto.ExportResponse, error) {
+	ret := _m.Called(ctx, userID, exportID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetExport")
+	}
+
+	var r0 *dto.ExportResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*dto.ExportResponse, error)); ok {
+		return rf(ctx, userID, exportID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *dto.ExportResponse); ok {
+		r0 = rf(ctx, userID, exportID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ExportResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, exportID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockExportUsecase_GetExport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetExport'
+type MockExportUsecase_GetExport_Call struct {
+	*mock.Call
+}
+
+// GetExport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - exportID string
+func (_e *MockExportUsecase_Expecter) GetExport(ctx interface{}, userID interface{}, exportID interface{}) *MockExportUsecase_GetExport_Call {
+	return &MockExportUsecase_GetExport_Call{Call: _e.mock.On("GetExport", ctx, userID, exportID)}
+}
+
+func (_c *MockExportUsecase_GetExport_Call) Run(run func(ctx context.Context, userID string, exportID string)) *MockExportUsecase_GetExport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockExportUsecase_GetExport_Call) Return(_a0 *dto.ExportResponse, _a1 error) *MockExportUsecase_GetExport_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockExportUsecase_GetExport_Call) RunAndReturn(run func(context.Context, string, string) (*dto.ExportResponse, error)) *MockExportUsecase_GetExport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PurgeExpiredExports provides a mock function with given fields: ctx, batchSize
+func (_m *MockExportUsecase) PurgeExpiredExports(ctx context.Context, batchSize int) (int, error) {
+	ret := _m.Called(ctx, batchSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeExpiredExports")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (int, error)); ok {
+		return rf(ctx, batchSize)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) int); ok {
+		r0 = rf(ctx, batchSize)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, batchSize)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockExportUsecase_PurgeExpiredExports_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PurgeExpiredExports'
+type MockExportUsecase_PurgeExpiredExports_Call struct {
+	*mock.Call
+}
+
+// PurgeExpiredExports is a helper method to define mock.On call
+//   - ctx context.Context
+//   - batchSize int
+func (_e *MockExportUsecase_Expecter) PurgeExpiredExports(ctx interface{}, batchSize interface{}) *MockExportUsecase_PurgeExpiredExports_Call {
+	return &MockExportUsecase_PurgeExpiredExports_Call{Call: _e.mock.On("PurgeExpiredExports", ctx, batchSize)}
+}
+
+func (_c *MockExportUsecase_PurgeExpiredExports_Call) Run(run func(ctx context.Context, batchSize int)) *MockExportUsecase_PurgeExpiredExports_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockExportUsecase_PurgeExpiredExports_Call) Return(_a0 int, _a1 error) *MockExportUsecase_PurgeExpiredExports_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockExportUsecase_PurgeExpiredExports_Call) RunAndReturn(run func(context.Context, int) (int, error)) *MockExportUsecase_PurgeExpiredExports_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RequestExport provides a mock function with given fields: ctx, userID
+func (_m *MockExportUsecase) RequestExport(ctx context.Context, userID string) (*dto.ExportResponse, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestExport")
+	}
+
+	var r0 *dto.ExportResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*dto.ExportResponse, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *dto.ExportResponse); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ExportResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockExportUsecase_RequestExport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RequestExport'
+type MockExportUsecase_RequestExport_Call struct {
+	*mock.Call
+}
+
+// RequestExport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockExportUsecase_Expecter) RequestExport(ctx interface{}, userID interface{}) *MockExportUsecase_RequestExport_Call {
+	return &MockExportUsecase_RequestExport_Call{Call: _e.mock.On("RequestExport", ctx, userID)}
+}
+
+func (_c *MockExportUsecase_RequestExport_Call) Run(run func(ctx context.Context, userID string)) *MockExportUsecase_RequestExport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockExportUsecase_RequestExport_Call) Return(_a0 *dto.ExportResponse, _a1 error) *MockExportUsecase_RequestExport_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockExportUsecase_RequestExport_Call) RunAndReturn(run func(context.Context, string) (*dto.ExportResponse, error)) *MockExportUsecase_RequestExport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockExportUsecase creates a new instance of MockExportUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockExportUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockExportUsecase {
+	mock := &MockExportUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
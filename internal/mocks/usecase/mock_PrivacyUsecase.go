@@ -0,0 +1,217 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	dto "app/internal/features/privacy/delivery/http/dto"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockPrivacyUsecase is an autogenerated mock type for the PrivacyUsecase type
+type MockPrivacyUsecase struct {
+	mock.Mock
+}
+
+type MockPrivacyUsecase_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockPrivacyUsecase) EXPECT() *MockPrivacyUsecase_Expecter {
+	return &MockPrivacyUsecase_Expecter{mock: &_m.Mock}
+}
+
+// AnonymizeUser provides a mock function with given fields: ctx, userID, dryRun
+func (_m *MockPrivacyUsecase) AnonymizeUser(ctx context.Context, userID string, dryRun bool) (*dto.AnonymizationReport, error) {
+	ret := _m.Called(ctx, userID, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AnonymizeUser")
+	}
+
+	var r0 *dto.AnonymizationReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) (*dto.AnonymizationReport, error)); ok {
+		return rf(ctx, userID, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) *dto.AnonymizationReport); ok {
+		r0 = rf(ctx, userID, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.AnonymizationReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, userID, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPrivacyUsecase_AnonymizeUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AnonymizeUser'
+type MockPrivacyUsecase_AnonymizeUser_Call struct {
+	*mock.Call
+}
+
+// AnonymizeUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - dryRun bool
+func (_e *MockPrivacyUsecase_Expecter) AnonymizeUser(ctx interface{}, userID interface{}, dryRun interface{}) *MockPrivacyUsecase_AnonymizeUser_Call {
+	return &MockPrivacyUsecase_AnonymizeUser_Call{Call: _e.mock.On("AnonymizeUser", ctx, userID, dryRun)}
+}
+
+func (_c *MockPrivacyUsecase_AnonymizeUser_Call) Run(run func(ctx context.Context, userID string, dryRun bool)) *MockPrivacyUsecase_AnonymizeUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *MockPrivacyUsecase_AnonymizeUser_Call) Return(_a0 *dto.AnonymizationReport, _a1 error) *MockPrivacyUsecase_AnonymizeUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPrivacyUsecase_AnonymizeUser_Call) RunAndReturn(run func(context.Context, string, bool) (*dto.AnonymizationReport, error)) *MockPrivacyUsecase_AnonymizeUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PurgeDeletedAccounts provides a mock function with given fields: ctx, batchSize, dryRun
+func (_m *MockPrivacyUsecase) PurgeDeletedAccounts(ctx context.Context, batchSize int, dryRun bool) ([]*dto.AnonymizationReport, error) {
+	ret := _m.Called(ctx, batchSize, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeDeletedAccounts")
+	}
+
+	var r0 []*dto.AnonymizationReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, bool) ([]*dto.AnonymizationReport, error)); ok {
+		return rf(ctx, batchSize, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, bool) []*dto.AnonymizationReport); ok {
+		r0 = rf(ctx, batchSize, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.AnonymizationReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, bool) error); ok {
+		r1 = rf(ctx, batchSize, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPrivacyUsecase_PurgeDeletedAccounts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PurgeDeletedAccounts'
+type MockPrivacyUsecase_PurgeDeletedAccounts_Call struct {
+	*mock.Call
+}
+
+// PurgeDeletedAccounts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - batchSize int
+//   - dryRun bool
+func (_e *MockPrivacyUsecase_Expecter) PurgeDeletedAccounts(ctx interface{}, batchSize interface{}, dryRun interface{}) *MockPrivacyUsecase_PurgeDeletedAccounts_Call {
+	return &MockPrivacyUsecase_PurgeDeletedAccounts_Call{Call: _e.mock.On("PurgeDeletedAccounts", ctx, batchSize, dryRun)}
+}
+
+func (_c *MockPrivacyUsecase_PurgeDeletedAccounts_Call) Run(run func(ctx context.Context, batchSize int, dryRun bool)) *MockPrivacyUsecase_PurgeDeletedAccounts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *MockPrivacyUsecase_PurgeDeletedAccounts_Call) Return(_a0 []*dto.AnonymizationReport, _a1 error) *MockPrivacyUsecase_PurgeDeletedAccounts_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPrivacyUsecase_PurgeDeletedAccounts_Call) RunAndReturn(run func(context.Context, int, bool) ([]*dto.AnonymizationReport, error)) *MockPrivacyUsecase_PurgeDeletedAccounts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PurgeUser provides a mock function with given fields: ctx, userID, dryRun
+func (_m *MockPrivacyUsecase) PurgeUser(ctx context.Context, userID string, dryRun bool) (*dto.AnonymizationReport, error) {
+	ret := _m.Called(ctx, userID, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeUser")
+	}
+
+	var r0 *dto.AnonymizationReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) (*dto.AnonymizationReport, error)); ok {
+		return rf(ctx, userID, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) *dto.AnonymizationReport); ok {
+		r0 = rf(ctx, userID, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.AnonymizationReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, userID, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPrivacyUsecase_PurgeUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PurgeUser'
+type MockPrivacyUsecase_PurgeUser_Call struct {
+	*mock.Call
+}
+
+// PurgeUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - dryRun bool
+func (_e *MockPrivacyUsecase_Expecter) PurgeUser(ctx interface{}, userID interface{}, dryRun interface{}) *MockPrivacyUsecase_PurgeUser_Call {
+	return &MockPrivacyUsecase_PurgeUser_Call{Call: _e.mock.On("PurgeUser", ctx, userID, dryRun)}
+}
+
+func (_c *MockPrivacyUsecase_PurgeUser_Call) Run(run func(ctx context.Context, userID string, dryRun bool)) *MockPrivacyUsecase_PurgeUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *MockPrivacyUsecase_PurgeUser_Call) Return(_a0 *dto.AnonymizationReport, _a1 error) *MockPrivacyUsecase_PurgeUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPrivacyUsecase_PurgeUser_Call) RunAndReturn(run func(context.Context, string, bool) (*dto.AnonymizationReport, error)) *MockPrivacyUsecase_PurgeUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockPrivacyUsecase creates a new instance of MockPrivacyUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockPrivacyUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockPrivacyUsecase {
+	mock := &MockPrivacyUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -9,6 +9,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	pkg "app/pkg"
+
+	service "app/internal/shared/domain/service"
 )
 
 // MockUserUsecase is an autogenerated mock type for the UserUsecase type
@@ -24,36 +26,264 @@ func (_m *MockUserUsecase) EXPECT() *MockUserUsecase_Expecter {
 	return &MockUserUsecase_Expecter{mock: &_m.Mock}
 }
 
-// GetProfile provides a mock function with given fields: ctx, userID
-func (_m *MockUserUsecase) GetProfile(ctx context.Context, userID string) (*dto.UserResponse, int, error) {
-	ret := _m.Called(ctx, userID)
+// AnonymizeUserData provides a mock function with given fields: ctx, userID, dryRun
+func (_m *MockUserUsecase) AnonymizeUserData(ctx context.Context, userID string, dryRun bool) ([]service.AnonymizedRecords, error) {
+	ret := _m.Called(ctx, userID, dryRun)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetProfile")
+		panic("no return value specified for AnonymizeUserData")
+	}
+
+	var r0 []service.AnonymizedRecords
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) ([]service.AnonymizedRecords, error)); ok {
+		return rf(ctx, userID, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) []service.AnonymizedRecords); ok {
+		r0 = rf(ctx, userID, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.AnonymizedRecords)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, userID, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserUsecase_AnonymizeUserData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AnonymizeUserData'
+type MockUserUsecase_AnonymizeUserData_Call struct {
+	*mock.Call
+}
+
+// AnonymizeUserData is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - dryRun bool
+func (_e *MockUserUsecase_Expecter) AnonymizeUserData(ctx interface{}, userID interface{}, dryRun interface{}) *MockUserUsecase_AnonymizeUserData_Call {
+	return &MockUserUsecase_AnonymizeUserData_Call{Call: _e.mock.On("AnonymizeUserData", ctx, userID, dryRun)}
+}
+
+func (_c *MockUserUsecase_AnonymizeUserData_Call) Run(run func(ctx context.Context, userID string, dryRun bool)) *MockUserUsecase_AnonymizeUserData_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *MockUserUsecase_AnonymizeUserData_Call) Return(_a0 []service.AnonymizedRecords, _a1 error) *MockUserUsecase_AnonymizeUserData_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserUsecase_AnonymizeUserData_Call) RunAndReturn(run func(context.Context, string, bool) ([]service.AnonymizedRecords, error)) *MockUserUsecase_AnonymizeUserData_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ChangeUsername provides a mock function with given fields: ctx, userID, version, req
+func (_m *MockUserUsecase) ChangeUsername(ctx context.Context, userID string, version int, req *dto.ChangeUsernameRequest) (*dto.UserResponse, error) {
+	ret := _m.Called(ctx, userID, version, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangeUsername")
 	}
 
 	var r0 *dto.UserResponse
-	var r1 int
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*dto.UserResponse, int, error)); ok {
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, *dto.ChangeUsernameRequest) (*dto.UserResponse, error)); ok {
+		return rf(ctx, userID, version, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, *dto.ChangeUsernameRequest) *dto.UserResponse); ok {
+		r0 = rf(ctx, userID, version, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, *dto.ChangeUsernameRequest) error); ok {
+		r1 = rf(ctx, userID, version, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserUsecase_ChangeUsername_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ChangeUsername'
+type MockUserUsecase_ChangeUsername_Call struct {
+	*mock.Call
+}
+
+// ChangeUsername is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - version int
+//   - req *dto.ChangeUsernameRequest
+func (_e *MockUserUsecase_Expecter) ChangeUsername(ctx interface{}, userID interface{}, version interface{}, req interface{}) *MockUserUsecase_ChangeUsername_Call {
+	return &MockUserUsecase_ChangeUsername_Call{Call: _e.mock.On("ChangeUsername", ctx, userID, version, req)}
+}
+
+func (_c *MockUserUsecase_ChangeUsername_Call) Run(run func(ctx context.Context, userID string, version int, req *dto.ChangeUsernameRequest)) *MockUserUsecase_ChangeUsername_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(*dto.ChangeUsernameRequest))
+	})
+	return _c
+}
+
+func (_c *MockUserUsecase_ChangeUsername_Call) Return(_a0 *dto.UserResponse, _a1 error) *MockUserUsecase_ChangeUsername_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserUsecase_ChangeUsername_Call) RunAndReturn(run func(context.Context, string, int, *dto.ChangeUsernameRequest) (*dto.UserResponse, error)) *MockUserUsecase_ChangeUsername_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteAccount provides a mock function with given fields: ctx, userID, req
+func (_m *MockUserUsecase) DeleteAccount(ctx context.Context, userID string, req *dto.DeleteAccountRequest) error {
+	ret := _m.Called(ctx, userID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAccount")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.DeleteAccountRequest) error); ok {
+		r0 = rf(ctx, userID, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserUsecase_DeleteAccount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAccount'
+type MockUserUsecase_DeleteAccount_Call struct {
+	*mock.Call
+}
+
+// DeleteAccount is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - req *dto.DeleteAccountRequest
+func (_e *MockUserUsecase_Expecter) DeleteAccount(ctx interface{}, userID interface{}, req interface{}) *MockUserUsecase_DeleteAccount_Call {
+	return &MockUserUsecase_DeleteAccount_Call{Call: _e.mock.On("DeleteAccount", ctx, userID, req)}
+}
+
+func (_c *MockUserUsecase_DeleteAccount_Call) Run(run func(ctx context.Context, userID string, req *dto.DeleteAccountRequest)) *MockUserUsecase_DeleteAccount_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*dto.DeleteAccountRequest))
+	})
+	return _c
+}
+
+func (_c *MockUserUsecase_DeleteAccount_Call) Return(_a0 error) *MockUserUsecase_DeleteAccount_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserUsecase_DeleteAccount_Call) RunAndReturn(run func(context.Context, string, *dto.DeleteAccountRequest) error) *MockUserUsecase_DeleteAccount_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExportUserData provides a mock function with given fields: ctx, userID
+func (_m *MockUserUsecase) ExportUserData(ctx context.Context, userID string) (map[string]interface{}, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportUserData")
+	}
+
+	var r0 map[string]interface{}
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (map[string]interface{}, error)); ok {
 		return rf(ctx, userID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *dto.UserResponse); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) map[string]interface{}); ok {
 		r0 = rf(ctx, userID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*dto.UserResponse)
+			r0 = ret.Get(0).(map[string]interface{})
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) int); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
 		r1 = rf(ctx, userID)
 	} else {
-		r1 = ret.Get(1).(int)
+		r1 = ret.Error(1)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
-		r2 = rf(ctx, userID)
+	return r0, r1
+}
+
+// MockUserUsecase_ExportUserData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportUserData'
+type MockUserUsecase_ExportUserData_Call struct {
+	*mock.Call
+}
+
+// ExportUserData is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockUserUsecase_Expecter) ExportUserData(ctx interface{}, userID interface{}) *MockUserUsecase_ExportUserData_Call {
+	return &MockUserUsecase_ExportUserData_Call{Call: _e.mock.On("ExportUserData", ctx, userID)}
+}
+
+func (_c *MockUserUsecase_ExportUserData_Call) Run(run func(ctx context.Context, userID string)) *MockUserUsecase_ExportUserData_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserUsecase_ExportUserData_Call) Return(_a0 map[string]interface{}, _a1 error) *MockUserUsecase_ExportUserData_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserUsecase_ExportUserData_Call) RunAndReturn(run func(context.Context, string) (map[string]interface{}, error)) *MockUserUsecase_ExportUserData_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDeletedUsers provides a mock function with given fields: ctx, queries
+func (_m *MockUserUsecase) GetDeletedUsers(ctx context.Context, queries map[string]string) ([]*dto.UserResponse, pkg.PaginationResponse, error) {
+	ret := _m.Called(ctx, queries)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeletedUsers")
+	}
+
+	var r0 []*dto.UserResponse
+	var r1 pkg.PaginationResponse
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, map[string]string) ([]*dto.UserResponse, pkg.PaginationResponse, error)); ok {
+		return rf(ctx, queries)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, map[string]string) []*dto.UserResponse); ok {
+		r0 = rf(ctx, queries)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, map[string]string) pkg.PaginationResponse); ok {
+		r1 = rf(ctx, queries)
+	} else {
+		r1 = ret.Get(1).(pkg.PaginationResponse)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, map[string]string) error); ok {
+		r2 = rf(ctx, queries)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -61,6 +291,65 @@ func (_m *MockUserUsecase) GetProfile(ctx context.Context, userID string) (*dto.
 	return r0, r1, r2
 }
 
+// MockUserUsecase_GetDeletedUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDeletedUsers'
+type MockUserUsecase_GetDeletedUsers_Call struct {
+	*mock.Call
+}
+
+// GetDeletedUsers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - queries map[string]string
+func (_e *MockUserUsecase_Expecter) GetDeletedUsers(ctx interface{}, queries interface{}) *MockUserUsecase_GetDeletedUsers_Call {
+	return &MockUserUsecase_GetDeletedUsers_Call{Call: _e.mock.On("GetDeletedUsers", ctx, queries)}
+}
+
+func (_c *MockUserUsecase_GetDeletedUsers_Call) Run(run func(ctx context.Context, queries map[string]string)) *MockUserUsecase_GetDeletedUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(map[string]string))
+	})
+	return _c
+}
+
+func (_c *MockUserUsecase_GetDeletedUsers_Call) Return(_a0 []*dto.UserResponse, _a1 pkg.PaginationResponse, _a2 error) *MockUserUsecase_GetDeletedUsers_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockUserUsecase_GetDeletedUsers_Call) RunAndReturn(run func(context.Context, map[string]string) ([]*dto.UserResponse, pkg.PaginationResponse, error)) *MockUserUsecase_GetDeletedUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetProfile provides a mock function with given fields: ctx, userID, queries
+func (_m *MockUserUsecase) GetProfile(ctx context.Context, userID string, queries map[string]string) (*dto.UserResponse, error) {
+	ret := _m.Called(ctx, userID, queries)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProfile")
+	}
+
+	var r0 *dto.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]string) (*dto.UserResponse, error)); ok {
+		return rf(ctx, userID, queries)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]string) *dto.UserResponse); ok {
+		r0 = rf(ctx, userID, queries)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, map[string]string) error); ok {
+		r1 = rf(ctx, userID, queries)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // MockUserUsecase_GetProfile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProfile'
 type MockUserUsecase_GetProfile_Call struct {
 	*mock.Call
@@ -69,29 +358,97 @@ type MockUserUsecase_GetProfile_Call struct {
 // GetProfile is a helper method to define mock.On call
 //   - ctx context.Context
 //   - userID string
-func (_e *MockUserUsecase_Expecter) GetProfile(ctx interface{}, userID interface{}) *MockUserUsecase_GetProfile_Call {
-	return &MockUserUsecase_GetProfile_Call{Call: _e.mock.On("GetProfile", ctx, userID)}
+//   - queries map[string]string
+func (_e *MockUserUsecase_Expecter) GetProfile(ctx interface{}, userID interface{}, queries interface{}) *MockUserUsecase_GetProfile_Call {
+	return &MockUserUsecase_GetProfile_Call{Call: _e.mock.On("GetProfile", ctx, userID, queries)}
 }
 
-func (_c *MockUserUsecase_GetProfile_Call) Run(run func(ctx context.Context, userID string)) *MockUserUsecase_GetProfile_Call {
+func (_c *MockUserUsecase_GetProfile_Call) Run(run func(ctx context.Context, userID string, queries map[string]string)) *MockUserUsecase_GetProfile_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(map[string]string))
+	})
+	return _c
+}
+
+func (_c *MockUserUsecase_GetProfile_Call) Return(_a0 *dto.UserResponse, _a1 error) *MockUserUsecase_GetProfile_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserUsecase_GetProfile_Call) RunAndReturn(run func(context.Context, string, map[string]string) (*dto.UserResponse, error)) *MockUserUsecase_GetProfile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserByUsername provides a mock function with given fields: ctx, username, queries
+func (_m *MockUserUsecase) GetUserByUsername(ctx context.Context, username string, queries map[string]string) (*dto.UserResponse, string, error) {
+	ret := _m.Called(ctx, username, queries)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserByUsername")
+	}
+
+	var r0 *dto.UserResponse
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]string) (*dto.UserResponse, string, error)); ok {
+		return rf(ctx, username, queries)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]string) *dto.UserResponse); ok {
+		r0 = rf(ctx, username, queries)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, map[string]string) string); ok {
+		r1 = rf(ctx, username, queries)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, map[string]string) error); ok {
+		r2 = rf(ctx, username, queries)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockUserUsecase_GetUserByUsername_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserByUsername'
+type MockUserUsecase_GetUserByUsername_Call struct {
+	*mock.Call
+}
+
+// GetUserByUsername is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+//   - queries map[string]string
+func (_e *MockUserUsecase_Expecter) GetUserByUsername(ctx interface{}, username interface{}, queries interface{}) *MockUserUsecase_GetUserByUsername_Call {
+	return &MockUserUsecase_GetUserByUsername_Call{Call: _e.mock.On("GetUserByUsername", ctx, username, queries)}
+}
+
+func (_c *MockUserUsecase_GetUserByUsername_Call) Run(run func(ctx context.Context, username string, queries map[string]string)) *MockUserUsecase_GetUserByUsername_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(map[string]string))
 	})
 	return _c
 }
 
-func (_c *MockUserUsecase_GetProfile_Call) Return(_a0 *dto.UserResponse, _a1 int, _a2 error) *MockUserUsecase_GetProfile_Call {
+func (_c *MockUserUsecase_GetUserByUsername_Call) Return(_a0 *dto.UserResponse, _a1 string, _a2 error) *MockUserUsecase_GetUserByUsername_Call {
 	_c.Call.Return(_a0, _a1, _a2)
 	return _c
 }
 
-func (_c *MockUserUsecase_GetProfile_Call) RunAndReturn(run func(context.Context, string) (*dto.UserResponse, int, error)) *MockUserUsecase_GetProfile_Call {
+func (_c *MockUserUsecase_GetUserByUsername_Call) RunAndReturn(run func(context.Context, string, map[string]string) (*dto.UserResponse, string, error)) *MockUserUsecase_GetUserByUsername_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // GetUsers provides a mock function with given fields: ctx, queries
-func (_m *MockUserUsecase) GetUsers(ctx context.Context, queries map[string]string) ([]*dto.UserResponse, pkg.PaginationResponse, int, error) {
+func (_m *MockUserUsecase) GetUsers(ctx context.Context, queries map[string]string) ([]*dto.UserResponse, pkg.PaginationResponse, error) {
 	ret := _m.Called(ctx, queries)
 
 	if len(ret) == 0 {
@@ -100,9 +457,8 @@ func (_m *MockUserUsecase) GetUsers(ctx context.Context, queries map[string]stri
 
 	var r0 []*dto.UserResponse
 	var r1 pkg.PaginationResponse
-	var r2 int
-	var r3 error
-	if rf, ok := ret.Get(0).(func(context.Context, map[string]string) ([]*dto.UserResponse, pkg.PaginationResponse, int, error)); ok {
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, map[string]string) ([]*dto.UserResponse, pkg.PaginationResponse, error)); ok {
 		return rf(ctx, queries)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, map[string]string) []*dto.UserResponse); ok {
@@ -119,19 +475,13 @@ func (_m *MockUserUsecase) GetUsers(ctx context.Context, queries map[string]stri
 		r1 = ret.Get(1).(pkg.PaginationResponse)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, map[string]string) int); ok {
+	if rf, ok := ret.Get(2).(func(context.Context, map[string]string) error); ok {
 		r2 = rf(ctx, queries)
 	} else {
-		r2 = ret.Get(2).(int)
-	}
-
-	if rf, ok := ret.Get(3).(func(context.Context, map[string]string) error); ok {
-		r3 = rf(ctx, queries)
-	} else {
-		r3 = ret.Error(3)
+		r2 = ret.Error(2)
 	}
 
-	return r0, r1, r2, r3
+	return r0, r1, r2
 }
 
 // MockUserUsecase_GetUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUsers'
@@ -153,51 +503,164 @@ func (_c *MockUserUsecase_GetUsers_Call) Run(run func(ctx context.Context, queri
 	return _c
 }
 
-func (_c *MockUserUsecase_GetUsers_Call) Return(_a0 []*dto.UserResponse, _a1 pkg.PaginationResponse, _a2 int, _a3 error) *MockUserUsecase_GetUsers_Call {
-	_c.Call.Return(_a0, _a1, _a2, _a3)
+func (_c *MockUserUsecase_GetUsers_Call) Return(_a0 []*dto.UserResponse, _a1 pkg.PaginationResponse, _a2 error) *MockUserUsecase_GetUsers_Call {
+	_c.Call.Return(_a0, _a1, _a2)
 	return _c
 }
 
-func (_c *MockUserUsecase_GetUsers_Call) RunAndReturn(run func(context.Context, map[string]string) ([]*dto.UserResponse, pkg.PaginationResponse, int, error)) *MockUserUsecase_GetUsers_Call {
+func (_c *MockUserUsecase_GetUsers_Call) RunAndReturn(run func(context.Context, map[string]string) ([]*dto.UserResponse, pkg.PaginationResponse, error)) *MockUserUsecase_GetUsers_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// UpdateProfile provides a mock function with given fields: ctx, userID, req
-func (_m *MockUserUsecase) UpdateProfile(ctx context.Context, userID string, req *dto.UpdateProfileRequest) (*dto.UserResponse, int, error) {
-	ret := _m.Called(ctx, userID, req)
+// PatchProfile provides a mock function with given fields: ctx, userID, version, req
+func (_m *MockUserUsecase) PatchProfile(ctx context.Context, userID string, version int, req *dto.PatchProfileRequest) (*dto.UserResponse, error) {
+	ret := _m.Called(ctx, userID, version, req)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateProfile")
+		panic("no return value specified for PatchProfile")
 	}
 
 	var r0 *dto.UserResponse
-	var r1 int
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.UpdateProfileRequest) (*dto.UserResponse, int, error)); ok {
-		return rf(ctx, userID, req)
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, *dto.PatchProfileRequest) (*dto.UserResponse, error)); ok {
+		return rf(ctx, userID, version, req)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, *dto.UpdateProfileRequest) *dto.UserResponse); ok {
-		r0 = rf(ctx, userID, req)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, *dto.PatchProfileRequest) *dto.UserResponse); ok {
+		r0 = rf(ctx, userID, version, req)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*dto.UserResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, *dto.UpdateProfileRequest) int); ok {
-		r1 = rf(ctx, userID, req)
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, *dto.PatchProfileRequest) error); ok {
+		r1 = rf(ctx, userID, version, req)
 	} else {
-		r1 = ret.Get(1).(int)
+		r1 = ret.Error(1)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, string, *dto.UpdateProfileRequest) error); ok {
-		r2 = rf(ctx, userID, req)
+	return r0, r1
+}
+
+// MockUserUsecase_PatchProfile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PatchProfile'
+type MockUserUsecase_PatchProfile_Call struct {
+	*mock.Call
+}
+
+// PatchProfile is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - version int
+//   - req *dto.PatchProfileRequest
+func (_e *MockUserUsecase_Expecter) PatchProfile(ctx interface{}, userID interface{}, version interface{}, req interface{}) *MockUserUsecase_PatchProfile_Call {
+	return &MockUserUsecase_PatchProfile_Call{Call: _e.mock.On("PatchProfile", ctx, userID, version, req)}
+}
+
+func (_c *MockUserUsecase_PatchProfile_Call) Run(run func(ctx context.Context, userID string, version int, req *dto.PatchProfileRequest)) *MockUserUsecase_PatchProfile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(*dto.PatchProfileRequest))
+	})
+	return _c
+}
+
+func (_c *MockUserUsecase_PatchProfile_Call) Return(_a0 *dto.UserResponse, _a1 error) *MockUserUsecase_PatchProfile_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserUsecase_PatchProfile_Call) RunAndReturn(run func(context.Context, string, int, *dto.PatchProfileRequest) (*dto.UserResponse, error)) *MockUserUsecase_PatchProfile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RestoreUser provides a mock function with given fields: ctx, userID
+func (_m *MockUserUsecase) RestoreUser(ctx context.Context, userID string) (*dto.UserResponse, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreUser")
+	}
+
+	var r0 *dto.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*dto.UserResponse, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *dto.UserResponse); ok {
+		r0 = rf(ctx, userID)
 	} else {
-		r2 = ret.Error(2)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserResponse)
+		}
 	}
 
-	return r0, r1, r2
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserUsecase_RestoreUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestoreUser'
+type MockUserUsecase_RestoreUser_Call struct {
+	*mock.Call
+}
+
+// RestoreUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *MockUserUsecase_Expecter) RestoreUser(ctx interface{}, userID interface{}) *MockUserUsecase_RestoreUser_Call {
+	return &MockUserUsecase_RestoreUser_Call{Call: _e.mock.On("RestoreUser", ctx, userID)}
+}
+
+func (_c *MockUserUsecase_RestoreUser_Call) Run(run func(ctx context.Context, userID string)) *MockUserUsecase_RestoreUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserUsecase_RestoreUser_Call) Return(_a0 *dto.UserResponse, _a1 error) *MockUserUsecase_RestoreUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserUsecase_RestoreUser_Call) RunAndReturn(run func(context.Context, string) (*dto.UserResponse, error)) *MockUserUsecase_RestoreUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateProfile provides a mock function with given fields: ctx, userID, version, req
+func (_m *MockUserUsecase) UpdateProfile(ctx context.Context, userID string, version int, req *dto.UpdateProfileRequest) (*dto.UserResponse, error) {
+	ret := _m.Called(ctx, userID, version, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProfile")
+	}
+
+	var r0 *dto.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, *dto.UpdateProfileRequest) (*dto.UserResponse, error)); ok {
+		return rf(ctx, userID, version, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, *dto.UpdateProfileRequest) *dto.UserResponse); ok {
+		r0 = rf(ctx, userID, version, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, *dto.UpdateProfileRequest) error); ok {
+		r1 = rf(ctx, userID, version, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // MockUserUsecase_UpdateProfile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateProfile'
@@ -208,24 +671,25 @@ type MockUserUsecase_UpdateProfile_Call struct {
 // UpdateProfile is a helper method to define mock.On call
 //   - ctx context.Context
 //   - userID string
+//   - version int
 //   - req *dto.UpdateProfileRequest
-func (_e *MockUserUsecase_Expecter) UpdateProfile(ctx interface{}, userID interface{}, req interface{}) *MockUserUsecase_UpdateProfile_Call {
-	return &MockUserUsecase_UpdateProfile_Call{Call: _e.mock.On("UpdateProfile", ctx, userID, req)}
+func (_e *MockUserUsecase_Expecter) UpdateProfile(ctx interface{}, userID interface{}, version interface{}, req interface{}) *MockUserUsecase_UpdateProfile_Call {
+	return &MockUserUsecase_UpdateProfile_Call{Call: _e.mock.On("UpdateProfile", ctx, userID, version, req)}
 }
 
-func (_c *MockUserUsecase_UpdateProfile_Call) Run(run func(ctx context.Context, userID string, req *dto.UpdateProfileRequest)) *MockUserUsecase_UpdateProfile_Call {
+func (_c *MockUserUsecase_UpdateProfile_Call) Run(run func(ctx context.Context, userID string, version int, req *dto.UpdateProfileRequest)) *MockUserUsecase_UpdateProfile_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(*dto.UpdateProfileRequest))
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(*dto.UpdateProfileRequest))
 	})
 	return _c
 }
 
-func (_c *MockUserUsecase_UpdateProfile_Call) Return(_a0 *dto.UserResponse, _a1 int, _a2 error) *MockUserUsecase_UpdateProfile_Call {
-	_c.Call.Return(_a0, _a1, _a2)
+func (_c *MockUserUsecase_UpdateProfile_Call) Return(_a0 *dto.UserResponse, _a1 error) *MockUserUsecase_UpdateProfile_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockUserUsecase_UpdateProfile_Call) RunAndReturn(run func(context.Context, string, *dto.UpdateProfileRequest) (*dto.UserResponse, int, error)) *MockUserUsecase_UpdateProfile_Call {
+func (_c *MockUserUsecase_UpdateProfile_Call) RunAndReturn(run func(context.Context, string, int, *dto.UpdateProfileRequest) (*dto.UserResponse, error)) *MockUserUsecase_UpdateProfile_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -1,7 +1,5 @@
 package constants
 
-import "errors"
-
 type ErrCode int
 type Lang string
 
@@ -16,21 +14,136 @@ const (
 	InvalidInput
 	ValidationFailed
 	Unauthorized
+	UnsupportedQueryValue
+	ServiceUnavailable
+	Forbidden
 
 	// Auth errors
 	InvalidCredentials
 	UserAlreadyExists
 	UsernameAlreadyTaken
+	EmailAlreadyRegistered
 	FailedToHashPassword
 	FailedToCreateUser
 	FailedToGenerateToken
+	OTPRequired
+	InvalidOTP
+	OTPExpired
+	OTPAttemptsExceeded
+	OTPResendTooSoon
+	FailedToSendOTP
+	EmailUnchanged
+	InvalidEmailChangeToken
+	FailedToSendEmail
+	AccountPendingDeletion
+	PasswordHasherBusy
 
 	// User errors
 	UserNotFound
 	FailedToUpdateUser
 	FailedToGetUsers
+	UserModified
+	PhoneRequired
+	PhoneNotVerified
+	PhoneAlreadyVerified
+	UsernameChangeTooSoon
+	FailedToDeleteUser
+	UserNotDeleted
+	UserAnonymized
+	FailedToRestoreUser
+
+	// Export errors
+	DataExportNotFound
+	InvalidDownloadLink
+	FailedToCreateExport
+
+	// Privacy errors
+	FailedToAnonymizeUser
+	FailedToPurgeUser
 )
 
+// errCodes holds the stable machine-readable name of each error code. Clients
+// match on these, so never rename an existing entry
+var errCodes = map[ErrCode]string{
+	// Global errors
+	SomethingWentWrong:    "INTERNAL_ERROR",
+	InvalidInput:          "INVALID_INPUT",
+	ValidationFailed:      "VALIDATION_FAILED",
+	Unauthorized:          "UNAUTHORIZED",
+	UnsupportedQueryValue: "UNSUPPORTED_QUERY_VALUE",
+	ServiceUnavailable:    "SERVICE_UNAVAILABLE",
+	Forbidden:             "FORBIDDEN",
+
+	// Auth errors
+	InvalidCredentials:      "AUTH_INVALID_CREDENTIALS",
+	UserAlreadyExists:       "AUTH_USER_ALREADY_EXISTS",
+	UsernameAlreadyTaken:    "AUTH_USERNAME_TAKEN",
+	EmailAlreadyRegistered:  "AUTH_EMAIL_TAKEN",
+	FailedToHashPassword:    "AUTH_PASSWORD_HASH_FAILED",
+	FailedToCreateUser:      "AUTH_USER_CREATE_FAILED",
+	FailedToGenerateToken:   "AUTH_TOKEN_GENERATION_FAILED",
+	OTPRequired:             "AUTH_OTP_REQUIRED",
+	InvalidOTP:              "AUTH_OTP_INVALID",
+	OTPExpired:              "AUTH_OTP_EXPIRED",
+	OTPAttemptsExceeded:     "AUTH_OTP_ATTEMPTS_EXCEEDED",
+	OTPResendTooSoon:        "AUTH_OTP_RESEND_TOO_SOON",
+	FailedToSendOTP:         "AUTH_OTP_SEND_FAILED",
+	EmailUnchanged:          "AUTH_EMAIL_UNCHANGED",
+	InvalidEmailChangeToken: "AUTH_EMAIL_CHANGE_INVALID",
+	FailedToSendEmail:       "AUTH_EMAIL_SEND_FAILED",
+	AccountPendingDeletion:  "AUTH_ACCOUNT_PENDING_DELETION",
+	PasswordHasherBusy:      "AUTH_BUSY",
+
+	// User errors
+	UserNotFound:          "USER_NOT_FOUND",
+	FailedToUpdateUser:    "USER_UPDATE_FAILED",
+	FailedToGetUsers:      "USER_LIST_FAILED",
+	UserModified:          "USER_MODIFIED",
+	PhoneRequired:         "USER_PHONE_REQUIRED",
+	PhoneNotVerified:      "USER_PHONE_NOT_VERIFIED",
+	PhoneAlreadyVerified:  "USER_PHONE_TAKEN",
+	UsernameChangeTooSoon: "USER_USERNAME_CHANGE_TOO_SOON",
+	FailedToDeleteUser:    "USER_DELETE_FAILED",
+	UserNotDeleted:        "USER_NOT_DELETED",
+	UserAnonymized:        "USER_ANONYMIZED",
+	FailedToRestoreUser:   "USER_RESTORE_FAILED",
+
+	// Export errors
+	DataExportNotFound:   "EXPORT_NOT_FOUND",
+	InvalidDownloadLink:  "EXPORT_LINK_INVALID",
+	FailedToCreateExport: "EXPORT_CREATE_FAILED",
+
+	// Privacy errors
+	FailedToAnonymizeUser: "PRIVACY_ANONYMIZE_FAILED",
+	FailedToPurgeUser:     "PRIVACY_PURGE_FAILED",
+}
+
+// String returns the stable machine-readable name of the error code
+func (c ErrCode) String() string {
+	if name, ok := errCodes[c]; ok {
+		return name
+	}
+	return errCodes[SomethingWentWrong]
+}
+
+// Error is a localized error message carrying its error code
+type Error struct {
+	Code    ErrCode
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NewError creates an error with a custom message for the given code
+func NewError(code ErrCode, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+	}
+}
+
 var errMessages = map[ErrCode]map[Lang]string{
 	// Global errors
 	SomethingWentWrong: {
@@ -49,6 +162,18 @@ var errMessages = map[ErrCode]map[Lang]string{
 		LangEN: "unauthorized",
 		LangID: "tidak memiliki akses",
 	},
+	UnsupportedQueryValue: {
+		LangEN: "%s contains unsupported value %q",
+		LangID: "%s berisi nilai yang tidak didukung %q",
+	},
+	ServiceUnavailable: {
+		LangEN: "service temporarily unavailable, please try again later",
+		LangID: "layanan sedang tidak tersedia, silakan coba lagi nanti",
+	},
+	Forbidden: {
+		LangEN: "you are not allowed to perform this action",
+		LangID: "Anda tidak diizinkan melakukan tindakan ini",
+	},
 
 	// Auth errors
 	InvalidCredentials: {
@@ -63,6 +188,10 @@ var errMessages = map[ErrCode]map[Lang]string{
 		LangEN: "username already taken",
 		LangID: "username sudah digunakan",
 	},
+	EmailAlreadyRegistered: {
+		LangEN: "email already registered",
+		LangID: "email sudah terdaftar",
+	},
 	FailedToHashPassword: {
 		LangEN: "failed to process password",
 		LangID: "gagal memproses password",
@@ -75,6 +204,50 @@ var errMessages = map[ErrCode]map[Lang]string{
 		LangEN: "failed to generate token",
 		LangID: "gagal membuat token",
 	},
+	OTPRequired: {
+		LangEN: "a verification code was sent to your phone, submit it to continue",
+		LangID: "kode verifikasi telah dikirim ke ponsel Anda, kirimkan kode tersebut untuk melanjutkan",
+	},
+	InvalidOTP: {
+		LangEN: "invalid verification code",
+		LangID: "kode verifikasi salah",
+	},
+	OTPExpired: {
+		LangEN: "verification code has expired, request a new one",
+		LangID: "kode verifikasi sudah kedaluwarsa, minta kode baru",
+	},
+	OTPAttemptsExceeded: {
+		LangEN: "too many wrong attempts, request a new verification code",
+		LangID: "terlalu banyak percobaan salah, minta kode verifikasi baru",
+	},
+	OTPResendTooSoon: {
+		LangEN: "a verification code was sent recently, please wait before requesting another",
+		LangID: "kode verifikasi baru saja dikirim, harap tunggu sebelum meminta lagi",
+	},
+	FailedToSendOTP: {
+		LangEN: "failed to send verification code",
+		LangID: "gagal mengirim kode verifikasi",
+	},
+	EmailUnchanged: {
+		LangEN: "new email is the same as the current one",
+		LangID: "email baru sama dengan email saat ini",
+	},
+	InvalidEmailChangeToken: {
+		LangEN: "email change link is invalid or has expired",
+		LangID: "tautan perubahan email tidak valid atau sudah kedaluwarsa",
+	},
+	FailedToSendEmail: {
+		LangEN: "failed to send email",
+		LangID: "gagal mengirim email",
+	},
+	AccountPendingDeletion: {
+		LangEN: "account is scheduled for deletion, log in again with reactivate set to restore it",
+		LangID: "akun dijadwalkan untuk dihapus, masuk kembali dengan reactivate untuk memulihkannya",
+	},
+	PasswordHasherBusy: {
+		LangEN: "too many sign-in requests at the moment, please try again shortly",
+		LangID: "terlalu banyak permintaan masuk saat ini, silakan coba lagi sebentar lagi",
+	},
 
 	// User errors
 	UserNotFound: {
@@ -89,20 +262,71 @@ var errMessages = map[ErrCode]map[Lang]string{
 		LangEN: "failed to get users",
 		LangID: "gagal mengambil data pengguna",
 	},
+	UserModified: {
+		LangEN: "user was modified by another request, fetch the latest version and retry",
+		LangID: "pengguna telah diubah oleh permintaan lain, ambil versi terbaru lalu coba lagi",
+	},
+	PhoneRequired: {
+		LangEN: "add a phone number to your profile first",
+		LangID: "tambahkan nomor telepon ke profil Anda terlebih dahulu",
+	},
+	PhoneNotVerified: {
+		LangEN: "verify your phone number first",
+		LangID: "verifikasi nomor telepon Anda terlebih dahulu",
+	},
+	PhoneAlreadyVerified: {
+		LangEN: "phone number is already verified by another account",
+		LangID: "nomor telepon sudah diverifikasi oleh akun lain",
+	},
+	UsernameChangeTooSoon: {
+		LangEN: "username was changed recently, please wait before changing it again",
+		LangID: "username baru saja diubah, harap tunggu sebelum mengubahnya lagi",
+	},
+	FailedToDeleteUser: {
+		LangEN: "failed to delete account",
+		LangID: "gagal menghapus akun",
+	},
+	UserNotDeleted: {
+		LangEN: "user is not deleted",
+		LangID: "pengguna tidak dihapus",
+	},
+	UserAnonymized: {
+		LangEN: "user was anonymized and can no longer be restored",
+		LangID: "pengguna telah dianonimkan dan tidak dapat dipulihkan lagi",
+	},
+	FailedToRestoreUser: {
+		LangEN: "failed to restore user",
+		LangID: "gagal memulihkan pengguna",
+	},
+
+	// Export errors
+	DataExportNotFound: {
+		LangEN: "data export not found",
+		LangID: "ekspor data tidak ditemukan",
+	},
+	InvalidDownloadLink: {
+		LangEN: "download link is invalid or has expired",
+		LangID: "tautan unduhan tidak valid atau sudah kedaluwarsa",
+	},
+	FailedToCreateExport: {
+		LangEN: "failed to start data export",
+		LangID: "gagal memulai ekspor data",
+	},
+
+	// Privacy errors
+	FailedToAnonymizeUser: {
+		LangEN: "failed to anonymize user",
+		LangID: "gagal menganonimkan pengguna",
+	},
+	FailedToPurgeUser: {
+		LangEN: "failed to purge user",
+		LangID: "gagal menghapus permanen pengguna",
+	},
 }
 
-// GetError returns error message based on code and language
+// GetError returns error based on code and language
 func GetError(code ErrCode, lang Lang) error {
-	if msgs, ok := errMessages[code]; ok {
-		if msg, ok := msgs[lang]; ok {
-			return errors.New(msg)
-		}
-		// Fallback to English
-		if msg, ok := msgs[LangEN]; ok {
-			return errors.New(msg)
-		}
-	}
-	return errors.New("unknown error")
+	return NewError(code, GetErrorMessage(code, lang))
 }
 
 // GetErrorMessage returns error message string based on code and language
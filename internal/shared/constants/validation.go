@@ -1,6 +1,12 @@
 package constants
 
-import "regexp"
+import (
+	"app/pkg/canonical"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
 
 type ValidationCode int
 
@@ -11,11 +17,18 @@ const (
 	TooShort
 	TooLong
 	InvalidEmail
+	NotNullable
+	OneOf
 
 	// Field specific
 	PasswordTooShort
 	UsernameTooShort
 	UsernameTooLong
+	InvalidPhone
+	InvalidBirthDate
+	InvalidOTPCode
+	InvalidUsername
+	ReservedUsername
 )
 
 var validationMessages = map[ValidationCode]map[Lang]string{
@@ -39,6 +52,14 @@ var validationMessages = map[ValidationCode]map[Lang]string{
 		LangEN: "invalid email format",
 		LangID: "format email tidak valid",
 	},
+	NotNullable: {
+		LangEN: "%s cannot be cleared",
+		LangID: "%s tidak boleh dikosongkan",
+	},
+	OneOf: {
+		LangEN: "%s must be one of: %s",
+		LangID: "%s harus salah satu dari: %s",
+	},
 	PasswordTooShort: {
 		LangEN: "password must be at least %d characters",
 		LangID: "password minimal %d karakter",
@@ -51,6 +72,26 @@ var validationMessages = map[ValidationCode]map[Lang]string{
 		LangEN: "username must be at most %d characters",
 		LangID: "username maksimal %d karakter",
 	},
+	InvalidPhone: {
+		LangEN: "phone must be an international number, e.g. +6281234567890",
+		LangID: "phone harus berupa nomor internasional, contoh +6281234567890",
+	},
+	InvalidBirthDate: {
+		LangEN: "birth_date must be a past date in YYYY-MM-DD format",
+		LangID: "birth_date harus berupa tanggal lampau dengan format YYYY-MM-DD",
+	},
+	InvalidUsername: {
+		LangEN: "username may only contain letters, digits, '.', '_' and '-', starting and ending with a letter or digit",
+		LangID: "username hanya boleh berisi huruf, angka, '.', '_' dan '-', diawali dan diakhiri huruf atau angka",
+	},
+	ReservedUsername: {
+		LangEN: "username is reserved",
+		LangID: "username sudah dicadangkan",
+	},
+	InvalidOTPCode: {
+		LangEN: "%s must be a 6-digit code",
+		LangID: "%s harus berupa kode 6 digit",
+	},
 }
 
 // GetValidationMessage returns validation message based on code and language
@@ -94,3 +135,66 @@ func MinLength(s string, min int) bool {
 func MaxLength(s string, max int) bool {
 	return len(s) <= max
 }
+
+// e164Regex matches a phone number in E.164 format
+var e164Regex = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// NormalizePhone converts a phone number to E.164, dropping common separators and
+// accepting the 00 international prefix. It reports false when the result is not E.164
+func NormalizePhone(phone string) (string, bool) {
+	phone = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").Replace(strings.TrimSpace(phone))
+	if strings.HasPrefix(phone, "00") {
+		phone = "+" + phone[2:]
+	}
+	return phone, e164Regex.MatchString(phone)
+}
+
+// maxAge bounds how far in the past a birth date is considered plausible
+const maxAge = 130
+
+// ParseBirthDate parses a YYYY-MM-DD birth date, reporting false for dates that are
+// malformed, in the future or more than maxAge years ago
+func ParseBirthDate(value string) (time.Time, bool) {
+	birthDate, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	now := time.Now().UTC()
+	if birthDate.After(now) || birthDate.Before(now.AddDate(-maxAge, 0, 0)) {
+		return time.Time{}, false
+	}
+	return birthDate, true
+}
+
+// IsOneOf checks if value is one of the allowed values
+func IsOneOf(value string, allowed []string) bool {
+	return slices.Contains(allowed, value)
+}
+
+// otpCodeRegex matches a 6-digit one-time password
+var otpCodeRegex = regexp.MustCompile(`^[0-9]{6}$`)
+
+// IsOTPCode checks if code looks like a one-time password
+func IsOTPCode(code string) bool {
+	return otpCodeRegex.MatchString(code)
+}
+
+// usernameRegex matches letters and digits of any script joined by single '.', '_' or '-'
+var usernameRegex = regexp.MustCompile(`^[\p{L}\p{N}]+(?:[._-][\p{L}\p{N}]+)*$`)
+
+// IsValidUsername checks if username uses the allowed characters
+func IsValidUsername(username string) bool {
+	return usernameRegex.MatchString(username)
+}
+
+// IsReservedUsername checks if username is, or looks like, one of the reserved words
+func IsReservedUsername(username string, reserved []string) bool {
+	skeleton := canonical.Skeleton(username)
+	for _, word := range reserved {
+		if canonical.Skeleton(word) == skeleton {
+			return true
+		}
+	}
+	return false
+}
@@ -4,7 +4,10 @@ import (
 	"app/internal/core/config"
 	"app/internal/shared/constants"
 	"app/internal/shared/delivery/http/response"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/domain/repository"
 	"app/pkg/jwt"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -15,21 +18,23 @@ const (
 	SESS = "sess"
 )
 
-// AuthMiddleware creates an authentication middleware using JWT secret
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware creates an authentication middleware using JWT secret. Tokens are
+// also checked against the user's token version, so they can be revoked before
+// they expire
+func AuthMiddleware(userRepo repository.UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		lang := GetLangFromGin(c)
 
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			response.NewResponse(c, http.StatusUnauthorized, nil, constants.GetErrorMessage(constants.Unauthorized, lang), nil)
+			response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, lang), nil)
 			c.Abort()
 			return
 		}
 
 		// Check if the header starts with "Bearer "
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			response.NewResponse(c, http.StatusUnauthorized, nil, constants.GetErrorMessage(constants.Unauthorized, lang), nil)
+			response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, lang), nil)
 			c.Abort()
 			return
 		}
@@ -37,7 +42,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Extract the token
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		if token == "" {
-			response.NewResponse(c, http.StatusUnauthorized, nil, constants.GetErrorMessage(constants.Unauthorized, lang), nil)
+			response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, lang), nil)
 			c.Abort()
 			return
 		}
@@ -45,7 +50,21 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Validate the token
 		claims, err := jwt.ValidateToken(config.Load().JWT.Secret, token)
 		if err != nil {
-			response.NewResponse(c, http.StatusUnauthorized, nil, constants.GetErrorMessage(constants.Unauthorized, lang), nil)
+			response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, lang), nil)
+			c.Abort()
+			return
+		}
+
+		// Check the token was not revoked - the user's token version moves past
+		// the one embedded in the token, or the user is gone
+		user, err := userRepo.GetByID(c.Request.Context(), claims.UserID, "id", "token_version")
+		if err != nil && !errors.Is(err, domainerror.ErrUserNotFound) {
+			_ = c.Error(domainerror.Internal(constants.SomethingWentWrong, err))
+			c.Abort()
+			return
+		}
+		if err != nil || user.TokenVersion != claims.TokenVersion {
+			response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, lang), nil)
 			c.Abort()
 			return
 		}
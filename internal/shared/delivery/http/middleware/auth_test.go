@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	mocks "app/internal/mocks/repository"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/pkg/jwt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuthRouter(t *testing.T) (*gin.Engine, *mocks.MockUserRepository) {
+	gin.SetMode(gin.TestMode)
+	mockRepo := mocks.NewMockUserRepository(t)
+
+	router := gin.New()
+	router.Use(ErrorMiddleware())
+	router.GET("/me", AuthMiddleware(mockRepo), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router, mockRepo
+}
+
+func authRequest(t *testing.T, tokenVersion int) *http.Request {
+	token, err := jwt.GenerateToken(jwt.UserPayload{ID: "user-123", Email: "test@example.com", TokenVersion: tokenVersion})
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestAuthMiddleware_CurrentToken(t *testing.T) {
+	router, mockRepo := setupAuthRouter(t)
+	mockRepo.EXPECT().GetByID(mock.Anything, "user-123", "id", "token_version").Return(&entity.User{ID: "user-123", TokenVersion: 2}, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authRequest(t, 2))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestAuthMiddleware_RevokedToken(t *testing.T) {
+	router, mockRepo := setupAuthRouter(t)
+	mockRepo.EXPECT().GetByID(mock.Anything, "user-123", "id", "token_version").Return(&entity.User{ID: "user-123", TokenVersion: 3}, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authRequest(t, 2))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthMiddleware_UserGone(t *testing.T) {
+	router, mockRepo := setupAuthRouter(t)
+	mockRepo.EXPECT().GetByID(mock.Anything, "user-123", "id", "token_version").Return(nil, domainerror.ErrUserNotFound)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authRequest(t, 0))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthMiddleware_DatabaseUnavailable(t *testing.T) {
+	router, mockRepo := setupAuthRouter(t)
+	mockRepo.EXPECT().GetByID(mock.Anything, "user-123", "id", "token_version").Return(nil, domainerror.ErrServiceUnavailable)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authRequest(t, 0))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestAuthMiddleware_MissingToken(t *testing.T) {
+	router, _ := setupAuthRouter(t)
+
+	req, _ := http.NewRequest(http.MethodGet, "/me", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"app/internal/shared/constants"
+	"app/internal/shared/delivery/http/response"
+	domainerror "app/internal/shared/domain/error"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// kindStatus maps domain error kinds to HTTP status codes
+var kindStatus = map[domainerror.Kind]int{
+	domainerror.KindInternal:           http.StatusInternalServerError,
+	domainerror.KindInvalidInput:       http.StatusBadRequest,
+	domainerror.KindUnauthorized:       http.StatusUnauthorized,
+	domainerror.KindForbidden:          http.StatusForbidden,
+	domainerror.KindNotFound:           http.StatusNotFound,
+	domainerror.KindConflict:           http.StatusConflict,
+	domainerror.KindUnavailable:        http.StatusServiceUnavailable,
+	domainerror.KindPreconditionFailed: http.StatusPreconditionFailed,
+	domainerror.KindTooManyRequests:    http.StatusTooManyRequests,
+}
+
+// ErrorMiddleware turns the last error a handler attached with c.Error into a
+// localized error response with the status matching its kind
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		lang := GetLangFromGin(c)
+		err := c.Errors.Last().Err
+
+		var domainErr *domainerror.Error
+		if !errors.As(err, &domainErr) {
+			response.NewErrorResponse(c, http.StatusInternalServerError, constants.GetError(constants.SomethingWentWrong, lang), nil)
+			return
+		}
+
+		status, ok := kindStatus[domainErr.Kind]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+
+		message := domainErr.Message(lang)
+		var errs any
+		if domainErr.Field != "" {
+			errs = map[string][]string{domainErr.Field: {message}}
+		}
+
+		response.NewErrorResponse(c, status, constants.NewError(domainErr.Code, message), errs)
+	}
+}
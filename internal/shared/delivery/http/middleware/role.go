@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"app/internal/shared/constants"
+	"app/internal/shared/delivery/http/response"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/domain/repository"
+	"app/pkg/jwt"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole creates a middleware letting through only users having one of the
+// given roles. It runs after AuthMiddleware; the role is read from the user rather
+// than the token, so taking a role away applies right away
+func RequireRole(userRepo repository.UserRepository, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := GetLangFromGin(c)
+
+		claimsVal, _ := c.Get(SESS)
+		claims, ok := claimsVal.(*jwt.Claims)
+		if !ok {
+			response.NewErrorResponse(c, http.StatusUnauthorized, constants.GetError(constants.Unauthorized, lang), nil)
+			c.Abort()
+			return
+		}
+
+		user, err := userRepo.GetByID(c.Request.Context(), claims.UserID, "id", "role")
+		if err != nil && !errors.Is(err, domainerror.ErrUserNotFound) {
+			_ = c.Error(domainerror.Internal(constants.SomethingWentWrong, err))
+			c.Abort()
+			return
+		}
+		if err != nil || !slices.Contains(roles, user.Role) {
+			response.NewErrorResponse(c, http.StatusForbidden, constants.GetError(constants.Forbidden, lang), nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	mocks "app/internal/mocks/repository"
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/pkg/jwt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupRoleRouter(t *testing.T) (*gin.Engine, *mocks.MockUserRepository) {
+	gin.SetMode(gin.TestMode)
+	mockRepo := mocks.NewMockUserRepository(t)
+
+	router := gin.New()
+	router.Use(ErrorMiddleware())
+	router.GET("/admin", func(c *gin.Context) {
+		c.Set(SESS, &jwt.Claims{UserID: "user-123"})
+	}, RequireRole(mockRepo, entity.UserRoleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router, mockRepo
+}
+
+func TestRequireRole_Allowed(t *testing.T) {
+	router, mockRepo := setupRoleRouter(t)
+	mockRepo.EXPECT().GetByID(mock.Anything, "user-123", "id", "role").Return(&entity.User{ID: "user-123", Role: entity.UserRoleAdmin}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/admin", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestRequireRole_OtherRole(t *testing.T) {
+	router, mockRepo := setupRoleRouter(t)
+	mockRepo.EXPECT().GetByID(mock.Anything, "user-123", "id", "role").Return(&entity.User{ID: "user-123", Role: "user"}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/admin", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRequireRole_DatabaseUnavailable(t *testing.T) {
+	router, mockRepo := setupRoleRouter(t)
+	mockRepo.EXPECT().GetByID(mock.Anything, "user-123", "id", "role").Return(nil, domainerror.ErrServiceUnavailable)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/admin", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
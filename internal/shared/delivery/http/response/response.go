@@ -1,18 +1,45 @@
 package response
 
 import (
+	"app/internal/shared/constants"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 )
 
+// ProblemContentType is the media type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
 // Response represents a unified API response
 type Response struct {
 	Error   bool   `json:"error"`
 	Status  int    `json:"status"`
+	Code    string `json:"code,omitempty"`
 	Message string `json:"message"`
 	Data    any    `json:"data"`
 	Errors  any    `json:"errors,omitempty"`
 }
 
+// Problem represents an RFC 7807 problem details response
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail"`
+	Instance      string         `json:"instance"`
+	Code          string         `json:"code"`
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// InvalidParam describes a single field that failed validation
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
 // NewResponse creates a new response with automatic error detection
 func NewResponse(c *gin.Context, status int, data any, message string, errs any) {
 	isError := status >= 400
@@ -25,3 +52,70 @@ func NewResponse(c *gin.Context, status int, data any, message string, errs any)
 		Errors:  errs,
 	})
 }
+
+// NewErrorResponse writes err with its machine code, as problem+json when the
+// client asks for it through the Accept header and as a Response otherwise
+func NewErrorResponse(c *gin.Context, status int, err error, errs any) {
+	code := errorCode(status, err)
+
+	if c.NegotiateFormat(gin.MIMEJSON, ProblemContentType) == ProblemContentType {
+		c.Header("Content-Type", ProblemContentType)
+		c.JSON(status, Problem{
+			Type:          "/problems/" + strings.ToLower(strings.ReplaceAll(code.String(), "_", "-")),
+			Title:         http.StatusText(status),
+			Status:        status,
+			Detail:        err.Error(),
+			Instance:      c.Request.URL.Path,
+			Code:          code.String(),
+			InvalidParams: invalidParams(errs),
+		})
+		return
+	}
+
+	c.JSON(status, Response{
+		Error:   true,
+		Status:  status,
+		Code:    code.String(),
+		Message: err.Error(),
+		Errors:  errs,
+	})
+}
+
+// errorCode returns the code carried by err, falling back to one matching the status
+func errorCode(status int, err error) constants.ErrCode {
+	var codedErr *constants.Error
+	if errors.As(err, &codedErr) {
+		return codedErr.Code
+	}
+
+	switch status {
+	case http.StatusBadRequest:
+		return constants.InvalidInput
+	case http.StatusUnauthorized:
+		return constants.Unauthorized
+	default:
+		return constants.SomethingWentWrong
+	}
+}
+
+// invalidParams flattens field validation errors into problem invalid params
+func invalidParams(errs any) []InvalidParam {
+	fields, ok := errs.(map[string][]string)
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make([]InvalidParam, 0)
+	for _, name := range names {
+		for _, reason := range fields[name] {
+			params = append(params, InvalidParam{Name: name, Reason: reason})
+		}
+	}
+	return params
+}
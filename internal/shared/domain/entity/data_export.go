@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Data export statuses. An export is pending until a worker picks it up, ready
+// once its archive is stored and expired after the archive was removed
+const (
+	DataExportPending = "pending"
+	DataExportRunning = "running"
+	DataExportReady   = "ready"
+	DataExportFailed  = "failed"
+	DataExportExpired = "expired"
+)
+
+// DataExport is an archive of all personal data held about a user, produced
+// asynchronously to answer a data-subject access request
+type DataExport struct {
+	ID          string     `json:"id" gorm:"type:varchar(36);primaryKey"`
+	UserID      string     `json:"user_id" gorm:"type:varchar(36);not null;index;uniqueIndex:idx_data_exports_user_id_active,where:status = 'pending' OR status = 'running'"` // One active export per user
+	Status      string     `json:"status" gorm:"type:varchar(20);not null"`
+	FileKey     string     `json:"-" gorm:"type:varchar(255);not null"` // Key of the archive in the file storage
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // When the archive is removed
+}
+
+// TableName specifies the table name for GORM
+func (DataExport) TableName() string {
+	return "data_exports"
+}
+
+// NewDataExport creates a pending export of the user's data
+func NewDataExport(userID string) *DataExport {
+	return &DataExport{
+		ID:     uuid.New().String(),
+		UserID: userID,
+		Status: DataExportPending,
+	}
+}
+
+// Active reports whether the export is still being produced
+func (e *DataExport) Active() bool {
+	return e.Status == DataExportPending || e.Status == DataExportRunning
+}
+
+// Downloadable reports whether the archive is stored and not expired yet
+func (e *DataExport) Downloadable() bool {
+	return e.Status == DataExportReady && e.ExpiresAt != nil && time.Now().Before(*e.ExpiresAt)
+}
+
+// BeforeCreate hook to ensure UUID is set
+func (e *DataExport) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EmailChange is a pending change of a user's email. It is applied once the link
+// sent to the new address is followed, the link sent to the old address cancels
+// it. Only hashes of both tokens are stored
+type EmailChange struct {
+	ID              string    `json:"id" gorm:"type:varchar(36);primaryKey"`
+	UserID          string    `json:"user_id" gorm:"type:varchar(36);not null;uniqueIndex"`
+	OldEmail        string    `json:"old_email" gorm:"type:varchar(255);not null"`
+	NewEmail        string    `json:"new_email" gorm:"type:varchar(255);not null"`
+	TokenHash       string    `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	CancelTokenHash string    `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt       time.Time `json:"expires_at" gorm:"not null"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (EmailChange) TableName() string {
+	return "email_changes"
+}
+
+// NewEmailChange creates a change of the user's email to newEmail valid for ttl
+func NewEmailChange(user *User, newEmail string, ttl time.Duration) *EmailChange {
+	return &EmailChange{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		OldEmail:  user.Email,
+		NewEmail:  newEmail,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+}
+
+// Expired reports whether the change can no longer be confirmed
+func (c *EmailChange) Expired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
+
+// BeforeCreate hook to ensure UUID is set
+func (c *EmailChange) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OTPPurpose tells what a one-time password was issued for
+type OTPPurpose string
+
+const (
+	// OTPPurposeVerifyPhone proves ownership of the phone number on the profile
+	OTPPurposeVerifyPhone OTPPurpose = "verify_phone"
+	// OTPPurposeLogin signs in with a verified phone, alone or as a second factor
+	OTPPurposeLogin OTPPurpose = "login"
+)
+
+// PhoneOTP is a one-time password sent by SMS. Only a hash of the code is stored,
+// and the number it was sent to only as the blind index of the user's phone
+type PhoneOTP struct {
+	ID         string     `json:"id" gorm:"type:varchar(36);primaryKey"`
+	UserID     string     `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	PhoneIndex string     `json:"-" gorm:"type:varchar(64);not null"`
+	Purpose    OTPPurpose `json:"purpose" gorm:"type:varchar(20);not null"`
+	CodeHash   string     `json:"-" gorm:"type:varchar(64);not null"`
+	Attempts   int        `json:"attempts" gorm:"not null;default:0"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (PhoneOTP) TableName() string {
+	return "phone_otps"
+}
+
+// NewPhoneOTP creates a one-time password valid for ttl for the user's phone,
+// given by its blind index
+func NewPhoneOTP(userID, phoneIndex string, purpose OTPPurpose, ttl time.Duration) *PhoneOTP {
+	return &PhoneOTP{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		PhoneIndex: phoneIndex,
+		Purpose:    purpose,
+		ExpiresAt:  time.Now().Add(ttl),
+	}
+}
+
+// BeforeCreate hook to ensure UUID is set
+func (o *PhoneOTP) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == "" {
+		o.ID = uuid.New().String()
+	}
+	return nil
+}
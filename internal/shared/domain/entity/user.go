@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"app/pkg/canonical"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,24 +11,42 @@ import (
 
 // User represents a user entity in the domain layer
 type User struct {
-	ID        string         `json:"id" gorm:"type:varchar(36);primaryKey"`
-	Email     string         `json:"email" gorm:"type:varchar(255);uniqueIndex;not null"`
-	Username  string         `json:"username" gorm:"type:varchar(100);uniqueIndex;not null"`
-	Password  string         `json:"-" gorm:"type:varchar(255);not null"`
-	FirstName string         `json:"first_name" gorm:"type:varchar(100);not null"`
-	LastName  string         `json:"last_name" gorm:"type:varchar(100);not null"`
-	Phone     *string        `json:"phone,omitempty" gorm:"type:varchar(20)"`
-	Status    string         `json:"status" gorm:"type:varchar(50);default:'active'"`
-	BirthDate *time.Time     `json:"birth_date,omitempty" gorm:"type:date"`
-	Gender    string         `json:"gender,omitempty" gorm:"type:varchar(10)"`
-	Role      string         `json:"role" gorm:"type:varchar(50);default:'user'"`
-	Provider  string         `json:"provider,omitempty" gorm:"type:varchar(50)"`
-	IsActive  bool           `json:"is_active" gorm:"default:true"`
-	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                 string         `json:"id" gorm:"type:varchar(36);primaryKey"`
+	Email              string         `json:"email" gorm:"type:varchar(255);index;not null"`
+	Username           string         `json:"username" gorm:"type:varchar(100);index;not null"`
+	EmailNormalized    string         `json:"-" gorm:"type:varchar(255);uniqueIndex:idx_users_email_normalized,where:deleted_at IS NULL"`    // Canonical key live emails are unique by, see NormalizeEmail
+	UsernameNormalized string         `json:"-" gorm:"type:varchar(100);uniqueIndex:idx_users_username_normalized,where:deleted_at IS NULL"` // Canonical key live usernames are unique by, see NormalizeUsername
+	UsernameSkeleton   string         `json:"-" gorm:"type:varchar(100);index"`                                                              // Lookalike key, see UsernameSkeleton
+	Password           string         `json:"-" gorm:"type:varchar(255);not null"`
+	FirstName          string         `json:"first_name" gorm:"type:varchar(100);not null"`
+	LastName           string         `json:"last_name" gorm:"type:varchar(100);not null"`
+	Phone              *string        `json:"phone,omitempty" gorm:"type:text;serializer:encrypted"` // Encrypted, looked up through PhoneIndex
+	PhoneIndex         *string        `json:"-" gorm:"type:varchar(64);blindindex:Phone"`            // Blind index of Phone, set on write
+	PhoneVerifiedAt    *time.Time     `json:"phone_verified_at,omitempty"`
+	Status             string         `json:"status" gorm:"type:varchar(50);default:'active'"`
+	BirthDate          *time.Time     `json:"birth_date,omitempty" gorm:"type:text;serializer:encrypted"` // Encrypted, looked up through BirthDateIndex
+	BirthDateIndex     *string        `json:"-" gorm:"type:varchar(64);blindindex:BirthDate"`             // Blind index of BirthDate, set on write
+	Gender             string         `json:"gender,omitempty" gorm:"type:varchar(10)"`
+	Role               string         `json:"role" gorm:"type:varchar(50);default:'user'"`
+	Provider           string         `json:"provider,omitempty" gorm:"type:varchar(50)"`
+	IsActive           bool           `json:"is_active" gorm:"default:true"`
+	TwoFactorEnabled   bool           `json:"two_factor_enabled" gorm:"default:false"` // Login also requires an SMS code
+	Version            int            `json:"version" gorm:"not null;default:1"`       // Incremented on every conditional update
+	TokenVersion       int            `json:"-" gorm:"not null"`                       // Embedded in access tokens, incrementing it revokes them all
+	CreatedAt          time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt          time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
+// UserStatusActive is the status of an account in use
+const UserStatusActive = "active"
+
+// UserStatusAnonymized is the status of a deleted account whose personal data was erased
+const UserStatusAnonymized = "anonymized"
+
+// UserRoleAdmin is the role of users allowed to use the admin endpoints
+const UserRoleAdmin = "admin"
+
 // TableName specifies the table name for GORM
 func (User) TableName() string {
 	return "users"
@@ -35,20 +55,95 @@ func (User) TableName() string {
 // NewUser creates a new user entity with generated UUID
 func NewUser(email, username, password, firstName, lastName string) *User {
 	return &User{
-		ID:        uuid.New().String(),
-		Email:     email,
-		Username:  username,
-		Password:  password,
-		FirstName: firstName,
-		LastName:  lastName,
-		IsActive:  true,
+		ID:                 uuid.New().String(),
+		Email:              email,
+		EmailNormalized:    NormalizeEmail(email),
+		Username:           username,
+		UsernameNormalized: NormalizeUsername(username),
+		UsernameSkeleton:   UsernameSkeleton(username),
+		Password:           password,
+		FirstName:          firstName,
+		LastName:           lastName,
+		IsActive:           true,
 	}
 }
 
-// BeforeCreate hook to ensure UUID is set
+// BeforeCreate hook to ensure UUID and lookup keys are set
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == "" {
 		u.ID = uuid.New().String()
 	}
+	if u.EmailNormalized == "" {
+		u.EmailNormalized = NormalizeEmail(u.Email)
+	}
+	if u.UsernameNormalized == "" {
+		u.UsernameNormalized = NormalizeUsername(u.Username)
+	}
+	if u.UsernameSkeleton == "" {
+		u.UsernameSkeleton = UsernameSkeleton(u.Username)
+	}
 	return nil
 }
+
+// NormalizeEmail returns the canonical key of an email, so addresses differing only
+// in case, Unicode form or surrounding spaces (and Gmail dots, when configured) match
+func NormalizeEmail(email string) string {
+	return canonical.Email(email)
+}
+
+// SetUsername changes the username along with its lookup keys
+func (u *User) SetUsername(username string) {
+	u.Username = username
+	u.UsernameNormalized = NormalizeUsername(username)
+	u.UsernameSkeleton = UsernameSkeleton(username)
+}
+
+// SetEmail changes the email along with its lookup key
+func (u *User) SetEmail(email string) {
+	u.Email = email
+	u.EmailNormalized = NormalizeEmail(email)
+}
+
+// NormalizeUsername returns the canonical key of a username, so usernames differing
+// only in case, Unicode form or surrounding spaces match
+func NormalizeUsername(username string) string {
+	return canonical.Username(username)
+}
+
+// UsernameSkeleton returns the lookalike key of a username, so usernames that are
+// visually confusable (e.g. "admin" and "аdmin" with a Cyrillic а) match
+func UsernameSkeleton(username string) string {
+	return canonical.Skeleton(username)
+}
+
+// Reactivatable reports whether a deleted account is still within the grace
+// period during which logging in can restore it
+func (u *User) Reactivatable(gracePeriod time.Duration) bool {
+	return u.DeletedAt.Valid && time.Since(u.DeletedAt.Time) < gracePeriod
+}
+
+// Anonymize erases the personal data of a deleted account and returns the columns
+// it changed. Email and username are replaced by placeholders derived from the ID,
+// so the originals become available again while the row keeps its references
+func (u *User) Anonymize() []string {
+	placeholder := "deleted_" + strings.ReplaceAll(u.ID, "-", "")
+	u.SetEmail(placeholder + "@deleted.invalid")
+	u.SetUsername(placeholder)
+	u.Password = ""
+	u.FirstName = "Deleted"
+	u.LastName = "User"
+	u.Phone = nil
+	u.PhoneVerifiedAt = nil
+	u.BirthDate = nil
+	u.Gender = ""
+	u.Provider = ""
+	u.Status = UserStatusAnonymized
+	u.IsActive = false
+	u.TwoFactorEnabled = false
+
+	return []string{
+		"email", "email_normalized", "username", "username_normalized", "username_skeleton",
+		"password", "first_name", "last_name", "phone", "phone_verified_at", "birth_date",
+		"gender", "provider", "status", "is_active", "two_factor_enabled",
+	}
+}
@@ -2,6 +2,27 @@ package entity
 
 import "time"
 
+// CountMode controls how the total number of matching rows is computed
+type CountMode string
+
+const (
+	// CountExact counts matching rows in the same query using COUNT(*) OVER()
+	CountExact CountMode = "exact"
+	// CountEstimated uses planner statistics on large tables instead of counting
+	CountEstimated CountMode = "estimated"
+	// CountNone skips counting entirely
+	CountNone CountMode = "none"
+)
+
+// TotalUnknown is returned as the total when counting was skipped
+const TotalUnknown = -1
+
+// Total is the number of rows matching a filter, as a list computed it
+type Total struct {
+	Count     int  // TotalUnknown when counting was skipped
+	Estimated bool // Count comes from planner statistics rather than counting
+}
+
 // FilterUser represents the filtering options for user queries
 type FilterUser struct {
 	// Basic filters
@@ -24,7 +45,17 @@ type FilterUser struct {
 	Genders []string `json:"genders,omitempty"`
 	Roles   []string `json:"roles,omitempty"`
 
+	// Soft delete - list soft-deleted users instead of live ones
+	Deleted bool `json:"deleted,omitempty"`
+
+	// Optimistic locking - expected row version for updates, 0 skips the check
+	Version int `json:"version,omitempty"`
+
+	// Projection - columns to select, empty selects all
+	Fields []string `json:"fields,omitempty"`
+
 	// Pagination
-	Offset  int `json:"offset"`
-	PerPage int `json:"per_page"`
+	Offset  int       `json:"offset"`
+	PerPage int       `json:"per_page"`
+	Count   CountMode `json:"count,omitempty"` // Defaults to CountExact
 }
@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UsernameHistory records a username a user gave up. The name stays held for
+// its former owner until HeldUntil, lookups of it redirect to the user meanwhile
+type UsernameHistory struct {
+	ID                 string    `json:"id" gorm:"type:varchar(36);primaryKey"`
+	UserID             string    `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	Username           string    `json:"username" gorm:"type:varchar(100);not null"`
+	UsernameNormalized string    `json:"-" gorm:"type:varchar(100);not null;index"`
+	UsernameSkeleton   string    `json:"-" gorm:"type:varchar(100);not null;index"`
+	ChangedAt          time.Time `json:"changed_at" gorm:"not null"`
+	HeldUntil          time.Time `json:"held_until" gorm:"not null"`
+}
+
+// TableName specifies the table name for GORM
+func (UsernameHistory) TableName() string {
+	return "username_history"
+}
+
+// NewUsernameHistory records the current username of user as given up now and held for holdPeriod
+func NewUsernameHistory(user *User, holdPeriod time.Duration) *UsernameHistory {
+	now := time.Now()
+	return &UsernameHistory{
+		ID:                 uuid.New().String(),
+		UserID:             user.ID,
+		Username:           user.Username,
+		UsernameNormalized: NormalizeUsername(user.Username),
+		UsernameSkeleton:   UsernameSkeleton(user.Username),
+		ChangedAt:          now,
+		HeldUntil:          now.Add(holdPeriod),
+	}
+}
+
+// BeforeCreate hook to ensure UUID is set
+func (h *UsernameHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == "" {
+		h.ID = uuid.New().String()
+	}
+	return nil
+}
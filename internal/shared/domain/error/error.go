@@ -1,38 +1,119 @@
 package error
 
-import "errors"
+import (
+	"app/internal/shared/constants"
+	"errors"
+	"fmt"
+)
 
 // Domain errors
 var (
-	ErrUserNotFound       = errors.New("user not found")
-	ErrProductNotFound    = errors.New("product not found")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrUserAlreadyExists  = errors.New("user already exists")
-	ErrInvalidInput       = errors.New("invalid input")
-	ErrUnauthorized       = errors.New("unauthorized")
-	ErrForbidden          = errors.New("forbidden")
-	ErrInternalServer     = errors.New("internal server error")
+	ErrUserNotFound            = errors.New("user not found")
+	ErrProductNotFound         = errors.New("product not found")
+	ErrInvalidCredentials      = errors.New("invalid credentials")
+	ErrUserAlreadyExists       = errors.New("user already exists")
+	ErrInvalidInput            = errors.New("invalid input")
+	ErrUnauthorized            = errors.New("unauthorized")
+	ErrForbidden               = errors.New("forbidden")
+	ErrInternalServer          = errors.New("internal server error")
+	ErrServiceUnavailable      = errors.New("service unavailable")
+	ErrVersionConflict         = errors.New("version conflict")
+	ErrOTPNotFound             = errors.New("otp not found")
+	ErrOTPAttemptsExceeded     = errors.New("otp attempts exceeded")
+	ErrUsernameHistoryNotFound = errors.New("username history not found")
+	ErrEmailChangeNotFound     = errors.New("email change not found")
+	ErrDataExportNotFound      = errors.New("data export not found")
+	ErrFileNotFound            = errors.New("file not found")
+)
+
+// ConflictError reports a uniqueness conflict on Field, it matches ErrUserAlreadyExists
+type ConflictError struct {
+	Field string
+}
+
+func (e *ConflictError) Error() string {
+	return ErrUserAlreadyExists.Error() + ": " + e.Field + " is taken"
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrUserAlreadyExists
+}
+
+// Kind classifies a domain error independently of any transport
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalidInput
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindUnavailable
+	KindPreconditionFailed
+	KindTooManyRequests
 )
 
-// CustomError represents a custom error with additional context
-type CustomError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Err     error  `json:"-"`
+// Error is a typed domain error returned by usecases. Code selects the
+// localized message and machine code, Params are formatted into the message
+type Error struct {
+	Kind   Kind
+	Code   constants.ErrCode
+	Params []any
+	Field  string // Request field the error relates to, if any
+	Cause  error
 }
 
-func (e *CustomError) Error() string {
-	if e.Err != nil {
-		return e.Err.Error()
+// New creates a domain error of the given kind
+func New(kind Kind, code constants.ErrCode, cause error, params ...any) *Error {
+	return &Error{
+		Kind:   kind,
+		Code:   code,
+		Params: params,
+		Cause:  cause,
 	}
-	return e.Message
 }
 
-// NewCustomError creates a new custom error
-func NewCustomError(code int, message string, err error) *CustomError {
-	return &CustomError{
-		Code:    code,
-		Message: message,
-		Err:     err,
+// Internal wraps an unexpected infrastructure failure, reported as unavailable
+// when the cause is ErrServiceUnavailable
+func Internal(code constants.ErrCode, cause error) *Error {
+	if errors.Is(cause, ErrServiceUnavailable) {
+		return New(KindUnavailable, constants.ServiceUnavailable, cause)
+	}
+	return New(KindInternal, code, cause)
+}
+
+// WithField marks the request field the error relates to
+func (e *Error) WithField(field string) *Error {
+	e.Field = field
+	return e
+}
+
+// Message returns the error message localized to lang
+func (e *Error) Message(lang constants.Lang) string {
+	msg := constants.GetErrorMessage(e.Code, lang)
+	if len(e.Params) > 0 {
+		msg = fmt.Sprintf(msg, e.Params...)
+	}
+	return msg
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Message(constants.LangEN) + ": " + e.Cause.Error()
+	}
+	return e.Message(constants.LangEN)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// KindOf returns the kind of err, KindInternal for errors that are not domain errors
+func KindOf(err error) Kind {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Kind
 	}
+	return KindInternal
 }
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"app/internal/shared/domain/entity"
+	"context"
+	"time"
+)
+
+// DataExportRepository defines the interface for user data exports. Implementations
+// return domainerror.ErrDataExportNotFound when no export matches
+type DataExportRepository interface {
+	Create(ctx context.Context, export *entity.DataExport) error
+	GetByID(ctx context.Context, id string) (*entity.DataExport, error)
+	// GetActive retrieves the user's export still pending or running, if any
+	GetActive(ctx context.Context, userID string) (*entity.DataExport, error)
+	// ListByUser retrieves all exports of the user, most recent first
+	ListByUser(ctx context.Context, userID string) ([]*entity.DataExport, error)
+	// Update writes the given columns of export
+	Update(ctx context.Context, export *entity.DataExport, fields ...string) error
+	// ListExpired returns up to limit ready exports whose archive expired before
+	// the given time
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]*entity.DataExport, error)
+}
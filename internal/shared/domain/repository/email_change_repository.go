@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"app/internal/shared/domain/entity"
+	"context"
+)
+
+// EmailChangeRepository defines the interface for pending email changes. A user
+// has at most one pending change. Implementations return
+// domainerror.ErrEmailChangeNotFound when no change matches
+type EmailChangeRepository interface {
+	// Create stores change, replacing any change pending for the same user
+	Create(ctx context.Context, change *entity.EmailChange) error
+	// GetByToken retrieves the change confirmed by the token with the given hash
+	GetByToken(ctx context.Context, tokenHash string) (*entity.EmailChange, error)
+	// GetByCancelToken retrieves the change cancelled by the token with the given hash
+	GetByCancelToken(ctx context.Context, cancelTokenHash string) (*entity.EmailChange, error)
+	// GetByUser retrieves the change pending for the user
+	GetByUser(ctx context.Context, userID string) (*entity.EmailChange, error)
+	// Delete removes a change once it is applied, cancelled or no longer valid
+	Delete(ctx context.Context, id string) error
+	// DeleteByUser removes the change pending for the user and returns how many
+	// were removed
+	DeleteByUser(ctx context.Context, userID string) (int, error)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"app/internal/shared/domain/entity"
+	"context"
+)
+
+// PhoneOTPRepository defines the interface for one-time password storage. A user
+// has at most one pending code per purpose. Implementations return
+// domainerror.ErrOTPNotFound when no code is pending and
+// domainerror.ErrOTPAttemptsExceeded once a code has no attempts left
+type PhoneOTPRepository interface {
+	// Create stores otp, replacing any code pending for the same user and purpose
+	Create(ctx context.Context, otp *entity.PhoneOTP) error
+	// GetPending retrieves the code pending for the user and purpose
+	GetPending(ctx context.Context, userID string, purpose entity.OTPPurpose) (*entity.PhoneOTP, error)
+	// ConsumeAttempt atomically counts one verification attempt against maxAttempts
+	ConsumeAttempt(ctx context.Context, id string, maxAttempts int) error
+	// Delete removes a code once it is used or no longer valid
+	Delete(ctx context.Context, id string) error
+	// DeleteByUser removes every code pending for the user and returns how many
+	// were removed
+	DeleteByUser(ctx context.Context, userID string) (int, error)
+}
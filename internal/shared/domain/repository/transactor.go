@@ -0,0 +1,14 @@
+package repository
+
+import "context"
+
+// Transactor runs a unit of work atomically. Repository calls made with the
+// context passed to fn take part in the same transaction, which is committed
+// when fn returns nil and rolled back otherwise.
+//
+// Nested calls run in a savepoint of the enclosing transaction. The outermost
+// call may run fn again after a serialization failure, so fn must not have
+// side effects outside the database
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -3,15 +3,50 @@ package repository
 import (
 	"app/internal/shared/domain/entity"
 	"context"
+	"time"
 )
 
-// UserRepository defines the interface for user data operations
+// UserRepository defines the interface for user data operations. Implementations
+// return domainerror.ErrUserNotFound when no row matches, a *domainerror.ConflictError
+// on unique violations, domainerror.ErrVersionConflict when a versioned update
+// finds the row changed, and wrap any other failure with domainerror.ErrServiceUnavailable
+// or domainerror.ErrInternalServer
 type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) error
-	GetByID(ctx context.Context, id string) (*entity.User, error)
+	GetByID(ctx context.Context, id string, fields ...string) (*entity.User, error)
+	// GetByIDWithDeleted retrieves a user by ID, soft-deleted ones included
+	GetByIDWithDeleted(ctx context.Context, id string) (*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
 	GetByUsername(ctx context.Context, username string) (*entity.User, error)
-	Update(ctx context.Context, filter entity.FilterUser, user *entity.User) error
+	GetByPhone(ctx context.Context, phone string) (*entity.User, error)
+	// IsUsernameTaken reports whether a live user other than exceptUserID has a username
+	// looking like username, or holds one as a former username
+	IsUsernameTaken(ctx context.Context, username, exceptUserID string) (bool, error)
+	Update(ctx context.Context, filter entity.FilterUser, user *entity.User, fields ...string) error
+	// UpdatePassword replaces the password hash of a live user as long as it is
+	// still currentHash, returning ErrUserNotFound otherwise. The version and
+	// updated_at are left as is
+	UpdatePassword(ctx context.Context, id, currentHash, newHash string) error
 	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, filter entity.FilterUser) ([]*entity.User, int, error)
+	// GetDeletedByEmail and GetDeletedByUsername look up soft-deleted users only,
+	// anonymized ones included
+	GetDeletedByEmail(ctx context.Context, email string) (*entity.User, error)
+	GetDeletedByUsername(ctx context.Context, username string) (*entity.User, error)
+	// ListDeleted returns up to limit soft-deleted users deleted before the given
+	// time that were not anonymized yet, oldest first, skipping the first offset
+	ListDeleted(ctx context.Context, before time.Time, offset, limit int) ([]*entity.User, error)
+	// ListAnonymized returns up to limit anonymized users deleted before the given
+	// time, oldest first, skipping the first offset
+	ListAnonymized(ctx context.Context, before time.Time, offset, limit int) ([]*entity.User, error)
+	// UpdateDeleted writes the given columns of a soft-deleted user
+	UpdateDeleted(ctx context.Context, user *entity.User, fields ...string) error
+	// Restore undoes the soft delete of a user, returning a *domainerror.ConflictError
+	// when their email or username was taken in the meantime
+	Restore(ctx context.Context, id string) error
+	// Purge deletes the row for good, rows referencing it are removed by cascade
+	Purge(ctx context.Context, id string) error
+	// List returns a page of users matching filter, soft-deleted ones instead of
+	// live ones when filter.Deleted is set, and their total. An estimated total
+	// is only requested, the total tells whether it was estimated or counted
+	List(ctx context.Context, filter entity.FilterUser) ([]*entity.User, entity.Total, error)
 }
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"app/internal/shared/domain/entity"
+	"context"
+)
+
+// UsernameHistoryRepository defines the interface for former usernames. Implementations
+// return domainerror.ErrUsernameHistoryNotFound when no entry matches
+type UsernameHistoryRepository interface {
+	Create(ctx context.Context, history *entity.UsernameHistory) error
+	// GetLatest retrieves the user's most recent username change
+	GetLatest(ctx context.Context, userID string) (*entity.UsernameHistory, error)
+	// GetHeld retrieves the entry still holding username, ignoring case
+	GetHeld(ctx context.Context, username string) (*entity.UsernameHistory, error)
+	// ListByUser retrieves all former usernames of the user, most recent first
+	ListByUser(ctx context.Context, userID string) ([]*entity.UsernameHistory, error)
+	// DeleteByUser removes all former usernames of the user, releasing them, and
+	// returns how many were removed
+	DeleteByUser(ctx context.Context, userID string) (int, error)
+}
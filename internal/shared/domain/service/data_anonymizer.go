@@ -0,0 +1,30 @@
+package service
+
+import "context"
+
+// Ways personal data is erased from a table
+const (
+	// AnonymizeRedact overwrites the personal columns, the rows stay referenced
+	AnonymizeRedact = "redact"
+	// AnonymizeDelete removes the rows
+	AnonymizeDelete = "delete"
+)
+
+// AnonymizedRecords reports the rows of one table an anonymization touched
+type AnonymizedRecords struct {
+	Table   string   `json:"table"`
+	Action  string   `json:"action"` // AnonymizeRedact or AnonymizeDelete
+	Count   int      `json:"count"`
+	Columns []string `json:"columns,omitempty"` // Columns overwritten by AnonymizeRedact
+}
+
+// DataAnonymizer is implemented by feature modules holding personal data of their
+// own, so it is erased along with the user's account
+type DataAnonymizer interface {
+	Name() string
+	// AnonymizeUserData erases the personal data of the user and reports the rows
+	// it touched, one entry per table. It runs in the caller's transaction, which
+	// is rolled back on a dry run, so with dryRun set only side effects outside
+	// the database, such as deleting files, are skipped
+	AnonymizeUserData(ctx context.Context, userID string, dryRun bool) ([]AnonymizedRecords, error)
+}
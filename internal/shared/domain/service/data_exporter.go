@@ -0,0 +1,14 @@
+package service
+
+import "context"
+
+// DataExporter is implemented by feature modules holding personal data of their
+// own, so it is included in the user's data export. Name names the section, the
+// directory of the archive its files are written to
+type DataExporter interface {
+	Name() string
+	// ExportUserData returns the section contents keyed by file name, each value
+	// is written as a JSON file. Features holding nothing about the user return
+	// an empty map
+	ExportUserData(ctx context.Context, userID string) (map[string]any, error)
+}
@@ -0,0 +1,9 @@
+package service
+
+import "context"
+
+// EmailSender delivers emails. Implementations wrap a provider such as an SMTP
+// server or a transactional email API
+type EmailSender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
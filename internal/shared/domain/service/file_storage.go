@@ -0,0 +1,13 @@
+package service
+
+import "context"
+
+// FileStorage stores generated files such as data export archives. Implementations
+// wrap a local directory or an object store, and return domainerror.ErrFileNotFound
+// for a key holding no file
+type FileStorage interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete removes the file, a missing file is not an error
+	Delete(ctx context.Context, key string) error
+}
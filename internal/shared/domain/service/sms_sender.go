@@ -0,0 +1,9 @@
+package service
+
+import "context"
+
+// SMSSender delivers text messages. Implementations wrap a provider such as an
+// SMS gateway, phone numbers are in E.164 format
+type SMSSender interface {
+	Send(ctx context.Context, phone, message string) error
+}
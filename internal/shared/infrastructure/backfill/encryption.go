@@ -0,0 +1,172 @@
+package backfill
+
+import (
+	"app/internal/shared/domain/entity"
+	"app/pkg/crypto"
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EncryptionMode selects the values EncryptedFields rewrites
+type EncryptionMode int
+
+const (
+	// EncryptStale encrypts plaintext values and re-encrypts values under a former key
+	EncryptStale EncryptionMode = iota
+	// EncryptAll re-encrypts and re-indexes every value, e.g. after the index key changed
+	EncryptAll
+	// Decrypt writes every value back as plaintext, before migration 011 is rolled back
+	Decrypt
+)
+
+// EncryptionReport summarizes an EncryptedFields run
+type EncryptionReport struct {
+	Scanned int
+	Stale   int // Users due for a write
+	Updated int
+}
+
+// encryptedRow is the stored form of the encrypted columns of a user
+type encryptedRow struct {
+	ID        string
+	Phone     *string
+	BirthDate *string
+}
+
+// EncryptedFields rewrites the encrypted columns of every user, soft-deleted ones
+// included, reading batchSize rows at a time. Values are encrypted under the
+// primary key of k and their blind indexes refilled, so db must have encryption
+// enabled with k. Nothing is written if dryRun is set
+func EncryptedFields(ctx context.Context, db *gorm.DB, k *crypto.Keyring, batchSize int, mode EncryptionMode, dryRun bool) (*EncryptionReport, error) {
+	report := &EncryptionReport{}
+
+	// Keyset pagination - stable while rows are being rewritten
+	lastID := ""
+	for {
+		var rows []encryptedRow
+		err := db.WithContext(ctx).
+			Table(entity.User{}.TableName()).
+			Select("id", "phone", "birth_date").
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(batchSize).
+			Find(&rows).Error
+		if err != nil {
+			return report, err
+		}
+		if len(rows) == 0 {
+			return report, nil
+		}
+		report.Scanned += len(rows)
+		lastID = rows[len(rows)-1].ID
+
+		var stale []encryptedRow
+		for _, row := range rows {
+			if isStale(k, row, mode) {
+				stale = append(stale, row)
+			}
+		}
+		report.Stale += len(stale)
+		if dryRun || len(stale) == 0 {
+			continue
+		}
+
+		if mode == Decrypt {
+			err = writePlaintext(ctx, db, k, stale)
+		} else {
+			err = writeEncrypted(ctx, db, stale)
+		}
+		if err != nil {
+			return report, err
+		}
+		report.Updated += len(stale)
+	}
+}
+
+// isStale reports whether row has a value mode rewrites
+func isStale(k *crypto.Keyring, row encryptedRow, mode EncryptionMode) bool {
+	if mode == EncryptAll {
+		return true
+	}
+	for _, value := range []*string{row.Phone, row.BirthDate} {
+		if value == nil {
+			continue
+		}
+		if mode == Decrypt && crypto.IsEncrypted(*value) || mode == EncryptStale && k.NeedsRotation(*value) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeEncrypted rewrites a batch through the model, whose serializer encrypts the
+// values under the primary key and whose callbacks refill the blind indexes.
+// Hooks are skipped so updated_at is left as is
+func writeEncrypted(ctx context.Context, db *gorm.DB, batch []encryptedRow) error {
+	ids := make([]string, 0, len(batch))
+	for _, row := range batch {
+		ids = append(ids, row.ID)
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var users []entity.User
+		err := tx.Unscoped().Select("id", "phone", "birth_date").Where("id IN ?", ids).Find(&users).Error
+		if err != nil {
+			return err
+		}
+		for i := range users {
+			err := tx.Session(&gorm.Session{SkipHooks: true}).Unscoped().
+				Select("phone", "birth_date").
+				Updates(&users[i]).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writePlaintext writes a batch back decrypted, birth dates as YYYY-MM-DD as they
+// were stored before migration 011. The blind indexes stay valid
+func writePlaintext(ctx context.Context, db *gorm.DB, k *crypto.Keyring, batch []encryptedRow) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, row := range batch {
+			phone, err := decrypt(k, row.ID, row.Phone, "phone")
+			if err != nil {
+				return fmt.Errorf("user %s: %w", row.ID, err)
+			}
+			birthDate, err := decrypt(k, row.ID, row.BirthDate, "birth_date")
+			if err != nil {
+				return fmt.Errorf("user %s: %w", row.ID, err)
+			}
+			if birthDate != nil {
+				if parsed, err := time.Parse(time.RFC3339Nano, *birthDate); err == nil {
+					date := parsed.Format(time.DateOnly)
+					birthDate = &date
+				}
+			}
+
+			err = tx.Exec("UPDATE users SET phone = ?, birth_date = ? WHERE id = ?", phone, birthDate, row.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// decrypt returns the plaintext of a stored value of column of user id, plaintext values as is
+func decrypt(k *crypto.Keyring, id string, value *string, column string) (*string, error) {
+	if value == nil || !crypto.IsEncrypted(*value) {
+		return value, nil
+	}
+	plaintext, err := k.Decrypt(*value, crypto.FieldAAD(entity.User{}.TableName(), column, id))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s: %w", column, err)
+	}
+	result := string(plaintext)
+	return &result, nil
+}
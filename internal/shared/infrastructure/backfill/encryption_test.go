@@ -0,0 +1,195 @@
+package backfill
+
+import (
+	"app/internal/shared/infrastructure/database"
+	"app/pkg/crypto"
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type EncryptedFieldsTestSuite struct {
+	suite.Suite
+	db      *gorm.DB
+	mock    sqlmock.Sqlmock
+	former  *crypto.Keyring
+	keyring *crypto.Keyring
+	ctx     context.Context
+	sqlDB   *sql.DB
+}
+
+func (s *EncryptedFieldsTestSuite) SetupTest() {
+	var err error
+	s.sqlDB, s.mock, err = sqlmock.New()
+	require.NoError(s.T(), err)
+
+	s.db, err = gorm.Open(postgres.New(postgres.Config{
+		Conn:       s.sqlDB,
+		DriverName: "postgres",
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(s.T(), err)
+
+	keys := map[string][]byte{"1": bytes.Repeat([]byte{1}, 32), "2": bytes.Repeat([]byte{2}, 32)}
+	s.former, err = crypto.NewKeyring(keys, "1", bytes.Repeat([]byte{9}, 32))
+	require.NoError(s.T(), err)
+	s.keyring, err = crypto.NewKeyring(keys, "2", bytes.Repeat([]byte{9}, 32))
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), database.EnableEncryption(s.db, s.keyring))
+
+	s.ctx = context.Background()
+}
+
+func (s *EncryptedFieldsTestSuite) TearDownTest() {
+	s.sqlDB.Close()
+}
+
+func TestEncryptedFieldsTestSuite(t *testing.T) {
+	suite.Run(t, new(EncryptedFieldsTestSuite))
+}
+
+const selectEncrypted = `SELECT "id","phone","birth_date" FROM "users" WHERE id > $1 ORDER BY id LIMIT $2`
+
+func encryptedRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "phone", "birth_date"})
+}
+
+// encrypt encrypts plaintext as stored in column of user id by k
+func (s *EncryptedFieldsTestSuite) encrypt(k *crypto.Keyring, id, plaintext, column string) string {
+	ciphertext, err := k.Encrypt([]byte(plaintext), crypto.FieldAAD("users", column, id))
+	require.NoError(s.T(), err)
+	return ciphertext
+}
+
+// reencrypted matches a value encrypted under the primary key of the keyring
+type reencrypted struct {
+	keyring   *crypto.Keyring
+	id        string
+	column    string
+	plaintext string
+}
+
+func (r reencrypted) Match(v driver.Value) bool {
+	value, ok := v.(string)
+	if !ok || r.keyring.NeedsRotation(value) {
+		return false
+	}
+	plaintext, err := r.keyring.Decrypt(value, crypto.FieldAAD("users", r.column, r.id))
+	return err == nil && string(plaintext) == r.plaintext
+}
+
+func (s *EncryptedFieldsTestSuite) TestEncryptsStaleValues() {
+	current := s.encrypt(s.keyring, "user-2", "+6281111111111", "phone")
+	former := s.encrypt(s.former, "user-3", "+6282222222222", "phone")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectEncrypted)).
+		WithArgs("", 10).
+		WillReturnRows(encryptedRows().
+			AddRow("user-1", "+6280000000000", "1990-01-31").
+			AddRow("user-2", current, nil).
+			AddRow("user-3", former, nil))
+
+	// Plaintext and values under the former key are rewritten, the indexes along
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id","phone","birth_date" FROM "users" WHERE id IN ($1,$2)`)).
+		WithArgs("user-1", "user-3").
+		WillReturnRows(encryptedRows().
+			AddRow("user-1", "+6280000000000", "1990-01-31").
+			AddRow("user-3", former, nil))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "phone"=$1,"phone_index"=$2,"birth_date"=$3,"birth_date_index"=$4 WHERE "id" = $5`)).
+		WithArgs(
+			reencrypted{s.keyring, "user-1", "phone", "+6280000000000"},
+			s.keyring.BlindIndex("phone", []byte("+6280000000000")),
+			reencrypted{s.keyring, "user-1", "birth_date", "1990-01-31T00:00:00Z"},
+			s.keyring.BlindIndex("birth_date", []byte("1990-01-31T00:00:00Z")),
+			"user-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "phone"=$1,"phone_index"=$2,"birth_date"=$3,"birth_date_index"=$4 WHERE "id" = $5`)).
+		WithArgs(
+			reencrypted{s.keyring, "user-3", "phone", "+6282222222222"},
+			s.keyring.BlindIndex("phone", []byte("+6282222222222")),
+			nil, nil,
+			"user-3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectEncrypted)).
+		WithArgs("user-3", 10).
+		WillReturnRows(encryptedRows())
+
+	report, err := EncryptedFields(s.ctx, s.db, s.keyring, 10, EncryptStale, false)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), &EncryptionReport{Scanned: 3, Stale: 2, Updated: 2}, report)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *EncryptedFieldsTestSuite) TestDecrypt() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectEncrypted)).
+		WithArgs("", 10).
+		WillReturnRows(encryptedRows().
+			AddRow("user-1", s.encrypt(s.former, "user-1", "+6281111111111", "phone"), s.encrypt(s.keyring, "user-1", "1990-01-31T00:00:00Z", "birth_date")).
+			AddRow("user-2", "+6282222222222", nil))
+
+	// Birth dates go back to their date form
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET phone = $1, birth_date = $2 WHERE id = $3`)).
+		WithArgs("+6281111111111", "1990-01-31", "user-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectEncrypted)).
+		WithArgs("user-2", 10).
+		WillReturnRows(encryptedRows())
+
+	report, err := EncryptedFields(s.ctx, s.db, s.keyring, 10, Decrypt, false)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), &EncryptionReport{Scanned: 2, Stale: 1, Updated: 1}, report)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *EncryptedFieldsTestSuite) TestDecryptUnknownKey() {
+	other, err := crypto.NewKeyring(map[string][]byte{"3": bytes.Repeat([]byte{3}, 32)}, "3", bytes.Repeat([]byte{9}, 32))
+	require.NoError(s.T(), err)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectEncrypted)).
+		WithArgs("", 10).
+		WillReturnRows(encryptedRows().AddRow("user-1", s.encrypt(other, "user-1", "+6281111111111", "phone"), nil))
+	s.mock.ExpectBegin()
+	s.mock.ExpectRollback()
+
+	_, err = EncryptedFields(s.ctx, s.db, s.keyring, 10, Decrypt, false)
+
+	assert.ErrorContains(s.T(), err, "user user-1")
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *EncryptedFieldsTestSuite) TestDryRunWritesNothing() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectEncrypted)).
+		WithArgs("", 10).
+		WillReturnRows(encryptedRows().
+			AddRow("user-1", "+6280000000000", nil).
+			AddRow("user-2", nil, nil))
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectEncrypted)).
+		WithArgs("user-2", 10).
+		WillReturnRows(encryptedRows())
+
+	report, err := EncryptedFields(s.ctx, s.db, s.keyring, 10, EncryptStale, true)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), &EncryptionReport{Scanned: 2, Stale: 1}, report)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
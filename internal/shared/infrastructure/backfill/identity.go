@@ -0,0 +1,158 @@
+// Package backfill holds one-off data migrations that need application code,
+// such as canonicalization rules the database cannot express
+package backfill
+
+import (
+	"app/internal/shared/domain/entity"
+	"context"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// Collision is a canonical key shared by more than one user
+type Collision struct {
+	Field   string   // email or username
+	Key     string   // The canonical key
+	UserIDs []string // Users claiming the key, sorted
+}
+
+// Report summarizes a backfill run
+type Report struct {
+	Scanned    int
+	Updated    int
+	Skipped    int // Users left untouched because their keys collide
+	Collisions []Collision
+}
+
+// identityRow is the slice of a user the backfill reads
+type identityRow struct {
+	ID                 string
+	Email              string
+	Username           string
+	EmailNormalized    *string
+	UsernameNormalized *string
+	UsernameSkeleton   *string
+}
+
+// identityUpdate is a user whose stored keys are stale
+type identityUpdate struct {
+	id       string
+	email    string
+	username string
+	skeleton string
+}
+
+// IdentityKeys recomputes the canonical email and username keys, and the username
+// skeleton, of every user,
+// soft-deleted ones included, reading batchSize rows at a time. Users whose key
+// collides with another user's are reported and left untouched, the rest are
+// written unless dryRun is set
+func IdentityKeys(ctx context.Context, db *gorm.DB, batchSize int, dryRun bool) (*Report, error) {
+	report := &Report{}
+	emails := map[string][]string{}
+	usernames := map[string][]string{}
+	var pending []identityUpdate
+
+	// Keyset pagination - stable while rows are being read
+	lastID := ""
+	for {
+		var rows []identityRow
+		err := db.WithContext(ctx).
+			Table(entity.User{}.TableName()).
+			Select("id", "email", "username", "email_normalized", "username_normalized", "username_skeleton").
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(batchSize).
+			Find(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		for _, row := range rows {
+			email := entity.NormalizeEmail(row.Email)
+			username := entity.NormalizeUsername(row.Username)
+			skeleton := entity.UsernameSkeleton(row.Username)
+			emails[email] = append(emails[email], row.ID)
+			usernames[username] = append(usernames[username], row.ID)
+
+			if !equal(row.EmailNormalized, email) || !equal(row.UsernameNormalized, username) || !equal(row.UsernameSkeleton, skeleton) {
+				pending = append(pending, identityUpdate{id: row.ID, email: email, username: username, skeleton: skeleton})
+			}
+		}
+		report.Scanned += len(rows)
+		lastID = rows[len(rows)-1].ID
+	}
+
+	colliding := map[string]bool{}
+	report.Collisions = append(collisions("email", emails, colliding), collisions("username", usernames, colliding)...)
+
+	var updates []identityUpdate
+	for _, u := range pending {
+		if colliding[u.id] {
+			report.Skipped++
+			continue
+		}
+		updates = append(updates, u)
+	}
+	if dryRun || len(updates) == 0 {
+		return report, nil
+	}
+
+	for start := 0; start < len(updates); start += batchSize {
+		batch := updates[start:min(start+batchSize, len(updates))]
+		if err := writeKeys(ctx, db, batch); err != nil {
+			return report, err
+		}
+		report.Updated += len(batch)
+	}
+	return report, nil
+}
+
+// writeKeys stores the keys of a batch in one transaction. The old keys are
+// cleared first so swapping keys between users does not trip the unique indexes
+func writeKeys(ctx context.Context, db *gorm.DB, batch []identityUpdate) error {
+	ids := make([]string, 0, len(batch))
+	for _, u := range batch {
+		ids = append(ids, u.id)
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("UPDATE users SET email_normalized = NULL, username_normalized = NULL WHERE id IN ?", ids).Error
+		if err != nil {
+			return err
+		}
+		for _, u := range batch {
+			err := tx.Exec("UPDATE users SET email_normalized = ?, username_normalized = ?, username_skeleton = ? WHERE id = ?", u.email, u.username, u.skeleton, u.id).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// collisions lists the keys claimed by more than one user, marking those users in colliding
+func collisions(field string, keys map[string][]string, colliding map[string]bool) []Collision {
+	var result []Collision
+	for key, ids := range keys {
+		if len(ids) < 2 {
+			continue
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			colliding[id] = true
+		}
+		result = append(result, Collision{Field: field, Key: key, UserIDs: ids})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+// equal reports whether a stored nullable key matches key
+func equal(stored *string, key string) bool {
+	return stored != nil && *stored == key
+}
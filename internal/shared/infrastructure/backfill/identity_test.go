@@ -0,0 +1,153 @@
+package backfill
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type IdentityKeysTestSuite struct {
+	suite.Suite
+	db    *gorm.DB
+	mock  sqlmock.Sqlmock
+	ctx   context.Context
+	sqlDB *sql.DB
+}
+
+func (s *IdentityKeysTestSuite) SetupTest() {
+	var err error
+	s.sqlDB, s.mock, err = sqlmock.New()
+	require.NoError(s.T(), err)
+
+	s.db, err = gorm.Open(postgres.New(postgres.Config{
+		Conn:       s.sqlDB,
+		DriverName: "postgres",
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(s.T(), err)
+
+	s.ctx = context.Background()
+}
+
+func (s *IdentityKeysTestSuite) TearDownTest() {
+	s.sqlDB.Close()
+}
+
+func TestIdentityKeysTestSuite(t *testing.T) {
+	suite.Run(t, new(IdentityKeysTestSuite))
+}
+
+const selectRows = `SELECT "id","email","username","email_normalized","username_normalized","username_skeleton" FROM "users" WHERE id > $1 ORDER BY id LIMIT $2`
+
+func identityRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "email", "username", "email_normalized", "username_normalized", "username_skeleton"})
+}
+
+func (s *IdentityKeysTestSuite) TestUpdatesStaleKeys() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectRows)).
+		WithArgs("", 2).
+		WillReturnRows(identityRows().
+			AddRow("user-1", "Bob@X.com", "Bob", nil, nil, nil).
+			AddRow("user-2", "alice@x.com", "alice", "alice@x.com", "alice", "alice"))
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectRows)).
+		WithArgs("user-2", 2).
+		WillReturnRows(identityRows())
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET email_normalized = NULL, username_normalized = NULL WHERE id IN ($1)`)).
+		WithArgs("user-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET email_normalized = $1, username_normalized = $2, username_skeleton = $3 WHERE id = $4`)).
+		WithArgs("bob@x.com", "bob", "bob", "user-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	report, err := IdentityKeys(s.ctx, s.db, 2, false)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, report.Scanned)
+	assert.Equal(s.T(), 1, report.Updated)
+	assert.Empty(s.T(), report.Collisions)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *IdentityKeysTestSuite) TestReportsCollisions() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectRows)).
+		WithArgs("", 10).
+		WillReturnRows(identityRows().
+			AddRow("user-1", "bob@x.com", "bob", "bob@x.com", "bob", "bob").
+			AddRow("user-2", "Bob@X.com", "bobby", "Bob@X.com", "bobby", "bobby").
+			AddRow("user-3", "carol@x.com", "Carol", "carol@x.com", "Carol", "carol"))
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectRows)).
+		WithArgs("user-3", 10).
+		WillReturnRows(identityRows())
+
+	// Only the non-colliding user is written
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET email_normalized = NULL`)).
+		WithArgs("user-3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET email_normalized = $1`)).
+		WithArgs("carol@x.com", "carol", "carol", "user-3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	report, err := IdentityKeys(s.ctx, s.db, 10, false)
+
+	require.NoError(s.T(), err)
+	require.Len(s.T(), report.Collisions, 1)
+	assert.Equal(s.T(), Collision{Field: "email", Key: "bob@x.com", UserIDs: []string{"user-1", "user-2"}}, report.Collisions[0])
+	assert.Equal(s.T(), 1, report.Skipped)
+	assert.Equal(s.T(), 1, report.Updated)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *IdentityKeysTestSuite) TestDryRunWritesNothing() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectRows)).
+		WithArgs("", 10).
+		WillReturnRows(identityRows().AddRow("user-1", "Bob@X.com", "Bob", nil, nil, nil))
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectRows)).
+		WithArgs("user-1", 10).
+		WillReturnRows(identityRows())
+
+	report, err := IdentityKeys(s.ctx, s.db, 10, true)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, report.Scanned)
+	assert.Equal(s.T(), 0, report.Updated)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *IdentityKeysTestSuite) TestUpdatesStaleSkeleton() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectRows)).
+		WithArgs("", 10).
+		WillReturnRows(identityRows().AddRow("user-1", "a@x.com", "Admin_1", "a@x.com", "admin_1", nil))
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectRows)).
+		WithArgs("user-1", 10).
+		WillReturnRows(identityRows())
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET email_normalized = NULL`)).
+		WithArgs("user-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET email_normalized = $1`)).
+		WithArgs("a@x.com", "admin_1", "admin1", "user-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	report, err := IdentityKeys(s.ctx, s.db, 10, false)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, report.Updated)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
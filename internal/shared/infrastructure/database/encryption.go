@@ -0,0 +1,283 @@
+package database
+
+import (
+	"app/internal/core/config"
+	"app/pkg/crypto"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// keyring encrypts the columns tagged serializer:encrypted, nil until EnableEncryption
+var keyring atomic.Pointer[crypto.Keyring]
+
+// errNoKeyring is returned when an encrypted column is written before EnableEncryption
+var errNoKeyring = errors.New("field encryption is not configured")
+
+func init() {
+	// Registered up front so models parse even where encryption is not enabled
+	schema.RegisterSerializer("encrypted", encryptedSerializer{})
+}
+
+// NewKeyring builds the field encryption keyring from config. The primary key
+// defaults to the last one listed
+func NewKeyring(cfg config.EncryptionConfig) (*crypto.Keyring, error) {
+	keys, ids, err := crypto.ParseKeys(cfg.Keys)
+	if err != nil {
+		return nil, fmt.Errorf("FIELD_ENCRYPTION_KEYS: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("FIELD_ENCRYPTION_KEYS is not set")
+	}
+	primary := cfg.KeyID
+	if primary == "" {
+		primary = ids[len(ids)-1]
+	}
+
+	indexKey, err := base64.StdEncoding.DecodeString(cfg.IndexKey)
+	if err != nil || len(indexKey) == 0 {
+		return nil, errors.New("FIELD_ENCRYPTION_INDEX_KEY must be a base64 key")
+	}
+	return crypto.NewKeyring(keys, primary, indexKey)
+}
+
+// EnableEncryption sets the keyring of the encrypted columns and registers the
+// callbacks filling their blind indexes on db. A field tagged blindindex:<Field>
+// holds the blind index of <Field> and is written along with it
+func EnableEncryption(db *gorm.DB, k *crypto.Keyring) error {
+	keyring.Store(k)
+
+	if err := db.Callback().Create().Before("gorm:create").Register("app:blind_index", blindIndexes(true)); err != nil {
+		return err
+	}
+	return db.Callback().Update().Before("gorm:update").Register("app:blind_index", blindIndexes(false))
+}
+
+// BlindIndex returns the blind index of value as stored for column, for equality
+// lookups of encrypted columns
+func BlindIndex(column string, value any) string {
+	k := keyring.Load()
+	if k == nil {
+		return ""
+	}
+	plaintext, ok, err := encodePlaintext(reflect.ValueOf(value))
+	if err != nil || !ok {
+		return ""
+	}
+	return k.BlindIndex(column, plaintext)
+}
+
+// encryptedSerializer encrypts string and time columns with the keyring. The
+// table, column and primary key are authenticated along, so a value cannot be
+// moved to another column or row; the primary key must be set before the column
+// is written and selected before it when read. Plaintext written before
+// encryption was enabled is still read
+type encryptedSerializer struct{}
+
+// Scan implements schema.SerializerInterface
+func (encryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := reflect.New(field.FieldType).Elem()
+
+	var stored string
+	switch v := dbValue.(type) {
+	case nil:
+		field.ReflectValueOf(ctx, dst).Set(fieldValue)
+		return nil
+	case time.Time:
+		// A column not migrated yet
+		return field.Set(ctx, dst, v)
+	case []byte:
+		stored = string(v)
+	case string:
+		stored = v
+	default:
+		return fmt.Errorf("unsupported encrypted value %T for %s", dbValue, field.Name)
+	}
+
+	plaintext := []byte(stored)
+	if crypto.IsEncrypted(stored) {
+		k := keyring.Load()
+		if k == nil {
+			return errNoKeyring
+		}
+		aad, err := cellAAD(ctx, field, dst)
+		if err != nil {
+			return err
+		}
+		if plaintext, err = k.Decrypt(stored, aad); err != nil {
+			return fmt.Errorf("decrypt %s: %w", field.Name, err)
+		}
+	}
+
+	value, err := decodePlaintext(field.FieldType, plaintext)
+	if err != nil {
+		return fmt.Errorf("decode %s: %w", field.Name, err)
+	}
+	field.ReflectValueOf(ctx, dst).Set(value)
+	return nil
+}
+
+// Value implements schema.SerializerValuerInterface, nil values stay NULL
+func (encryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plaintext, ok, err := encodePlaintext(reflect.ValueOf(fieldValue))
+	if err != nil || !ok {
+		return nil, err
+	}
+	k := keyring.Load()
+	if k == nil {
+		return nil, errNoKeyring
+	}
+	aad, err := cellAAD(ctx, field, dst)
+	if err != nil {
+		return nil, err
+	}
+	return k.Encrypt(plaintext, aad)
+}
+
+// cellAAD returns the additional data of field in the row dst
+func cellAAD(ctx context.Context, field *schema.Field, dst reflect.Value) ([]byte, error) {
+	primary := field.Schema.PrioritizedPrimaryField
+	if primary == nil {
+		return nil, fmt.Errorf("encrypted %s: %s has no primary key", field.Name, field.Schema.Table)
+	}
+	id, zero := primary.ValueOf(ctx, dst)
+	if zero {
+		return nil, fmt.Errorf("encrypted %s: primary key %s is not set", field.Name, primary.Name)
+	}
+	return crypto.FieldAAD(field.Schema.Table, field.DBName, fmt.Sprint(id)), nil
+}
+
+// blindIndexes fills the blind index fields of the statement's model from their
+// source fields, selecting them whenever their source column is written
+func blindIndexes(create bool) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		stmt := db.Statement
+		if db.Error != nil || stmt.Schema == nil {
+			return
+		}
+		k := keyring.Load()
+		if k == nil {
+			return
+		}
+		// Only whole models carry the source values, not maps
+		if kind := reflect.Indirect(reflect.ValueOf(stmt.Dest)).Kind(); kind != reflect.Struct && kind != reflect.Slice && kind != reflect.Array {
+			return
+		}
+
+		selected, restricted := stmt.SelectAndOmitColumns(create, !create)
+		for _, field := range stmt.Schema.Fields {
+			name, ok := field.TagSettings["BLINDINDEX"]
+			if !ok {
+				continue
+			}
+			source := stmt.Schema.LookUpField(name)
+			if source == nil {
+				_ = db.AddError(fmt.Errorf("blind index %s: unknown field %s", field.Name, name))
+				return
+			}
+			if restricted && !selected[source.DBName] {
+				continue
+			}
+
+			if err := setBlindIndex(stmt, k, field, source); err != nil {
+				_ = db.AddError(err)
+				return
+			}
+			if restricted && !selected[field.DBName] {
+				stmt.Selects = append(stmt.Selects, field.DBName)
+			}
+		}
+	}
+}
+
+// setBlindIndex sets field to the blind index of source on every model of the statement
+func setBlindIndex(stmt *gorm.Statement, k *crypto.Keyring, field, source *schema.Field) error {
+	set := func(model reflect.Value) error {
+		plaintext, ok, err := encodePlaintext(reflect.Indirect(model).FieldByIndex(source.StructField.Index))
+		if err != nil {
+			return fmt.Errorf("blind index %s: %w", field.Name, err)
+		}
+		var index *string
+		if ok {
+			value := k.BlindIndex(source.DBName, plaintext)
+			index = &value
+		}
+		return field.Set(stmt.Context, model, index)
+	}
+
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			if err := set(stmt.ReflectValue.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		return set(stmt.ReflectValue)
+	}
+	return nil
+}
+
+// timeType is the type of time columns
+var timeType = reflect.TypeOf(time.Time{})
+
+// encodePlaintext returns the plaintext of a string or time value, reporting
+// false for nil
+func encodePlaintext(v reflect.Value) ([]byte, bool, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false, nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, false, nil
+	}
+
+	switch {
+	case v.Type() == timeType:
+		return []byte(v.Interface().(time.Time).UTC().Format(time.RFC3339Nano)), true, nil
+	case v.Kind() == reflect.String:
+		return []byte(v.String()), true, nil
+	}
+	return nil, false, fmt.Errorf("unsupported encrypted type %s", v.Type())
+}
+
+// decodePlaintext parses plaintext into a value of type t, the inverse of
+// encodePlaintext. Dates stored before encryption was enabled are accepted too
+func decodePlaintext(t reflect.Type, plaintext []byte) (reflect.Value, error) {
+	if t.Kind() == reflect.Pointer {
+		elem, err := decodePlaintext(t.Elem(), plaintext)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+
+	value := reflect.New(t).Elem()
+	switch {
+	case t == timeType:
+		parsed, err := time.Parse(time.RFC3339Nano, string(plaintext))
+		if err != nil {
+			if parsed, err = time.Parse(time.DateOnly, string(plaintext)); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+		value.Set(reflect.ValueOf(parsed))
+	case t.Kind() == reflect.String:
+		value.SetString(string(plaintext))
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported encrypted type %s", t)
+	}
+	return value, nil
+}
@@ -0,0 +1,244 @@
+package database
+
+import (
+	"app/internal/core/config"
+	"app/pkg/crypto"
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// secretRecord is a model with encrypted columns
+type secretRecord struct {
+	ID         string
+	Phone      *string    `gorm:"serializer:encrypted"`
+	PhoneIndex *string    `gorm:"blindindex:Phone"`
+	BirthDate  *time.Time `gorm:"serializer:encrypted"`
+	Note       string
+}
+
+// captured records the value a statement was run with
+type captured struct {
+	value driver.Value
+}
+
+func (c *captured) Match(v driver.Value) bool {
+	c.value = v
+	return true
+}
+
+type EncryptionTestSuite struct {
+	suite.Suite
+	db      *gorm.DB
+	mock    sqlmock.Sqlmock
+	keyring *crypto.Keyring
+	ctx     context.Context
+	sqlDB   *sql.DB
+}
+
+func (s *EncryptionTestSuite) SetupTest() {
+	var err error
+	s.sqlDB, s.mock, err = sqlmock.New()
+	require.NoError(s.T(), err)
+
+	s.db, err = gorm.Open(postgres.New(postgres.Config{
+		Conn:       s.sqlDB,
+		DriverName: "postgres",
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(s.T(), err)
+
+	s.keyring, err = crypto.NewKeyring(map[string][]byte{"1": bytes.Repeat([]byte{1}, 32)}, "1", bytes.Repeat([]byte{2}, 32))
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), EnableEncryption(s.db, s.keyring))
+	s.ctx = context.Background()
+}
+
+func (s *EncryptionTestSuite) TearDownTest() {
+	s.sqlDB.Close()
+}
+
+func TestEncryptionTestSuite(t *testing.T) {
+	suite.Run(t, new(EncryptionTestSuite))
+}
+
+func (s *EncryptionTestSuite) TestCreate_EncryptsAndIndexes() {
+	phone := "+6281234567890"
+	birthDate := time.Date(1990, 1, 31, 0, 0, 0, 0, time.UTC)
+	record := &secretRecord{ID: "rec-1", Phone: &phone, BirthDate: &birthDate}
+
+	phoneArg, birthDateArg := &captured{}, &captured{}
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO "secret_records" ("id","phone","phone_index","birth_date","note") VALUES ($1,$2,$3,$4,$5)`)).
+		WithArgs("rec-1", phoneArg, BlindIndex("phone", phone), birthDateArg, "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.db.WithContext(s.ctx).Create(record).Error
+
+	require.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+	require.IsType(s.T(), "", phoneArg.value)
+	assert.True(s.T(), crypto.IsEncrypted(phoneArg.value.(string)))
+	plaintext, err := s.keyring.Decrypt(phoneArg.value.(string), crypto.FieldAAD("secret_records", "phone", "rec-1"))
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), phone, string(plaintext))
+	plaintext, err = s.keyring.Decrypt(birthDateArg.value.(string), crypto.FieldAAD("secret_records", "birth_date", "rec-1"))
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "1990-01-31T00:00:00Z", string(plaintext))
+	// The model carries the index it was written with
+	require.NotNil(s.T(), record.PhoneIndex)
+	assert.Equal(s.T(), BlindIndex("phone", phone), *record.PhoneIndex)
+}
+
+func (s *EncryptionTestSuite) TestFind_DecryptsAndReadsPlaintext() {
+	phone, err := s.keyring.Encrypt([]byte("+6281234567890"), crypto.FieldAAD("secret_records", "phone", "rec-1"))
+	require.NoError(s.T(), err)
+
+	// The second row predates encryption
+	rows := sqlmock.NewRows([]string{"id", "phone", "birth_date"}).
+		AddRow("rec-1", phone, nil).
+		AddRow("rec-2", "+6289876543210", "1990-01-31")
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "secret_records"`)).WillReturnRows(rows)
+
+	var records []secretRecord
+	err = s.db.WithContext(s.ctx).Find(&records).Error
+
+	require.NoError(s.T(), err)
+	require.Len(s.T(), records, 2)
+	assert.Equal(s.T(), "+6281234567890", *records[0].Phone)
+	assert.Nil(s.T(), records[0].BirthDate)
+	assert.Equal(s.T(), "+6289876543210", *records[1].Phone)
+	assert.Equal(s.T(), time.Date(1990, 1, 31, 0, 0, 0, 0, time.UTC), *records[1].BirthDate)
+}
+
+func (s *EncryptionTestSuite) TestFind_RejectsMovedValues() {
+	tests := []struct {
+		name string
+		aad  []byte
+	}{
+		{name: "other column", aad: crypto.FieldAAD("secret_records", "birth_date", "rec-1")},
+		{name: "other row", aad: crypto.FieldAAD("secret_records", "phone", "rec-2")},
+		{name: "other table", aad: crypto.FieldAAD("users", "phone", "rec-1")},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			moved, err := s.keyring.Encrypt([]byte("+6281234567890"), tt.aad)
+			require.NoError(s.T(), err)
+
+			s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "secret_records"`)).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "phone"}).AddRow("rec-1", moved))
+
+			var records []secretRecord
+			err = s.db.WithContext(s.ctx).Find(&records).Error
+
+			assert.ErrorContains(s.T(), err, "decrypt Phone")
+		})
+	}
+}
+
+func (s *EncryptionTestSuite) TestEncrypted_RequiresPrimaryKey() {
+	phone := "+6281234567890"
+	s.mock.ExpectBegin()
+	s.mock.ExpectRollback()
+
+	err := s.db.WithContext(s.ctx).Create(&secretRecord{Phone: &phone}).Error
+
+	assert.ErrorContains(s.T(), err, "primary key ID is not set")
+
+	// Selected without the primary key the row cannot be decrypted
+	ciphertext, err := s.keyring.Encrypt([]byte(phone), crypto.FieldAAD("secret_records", "phone", "rec-1"))
+	require.NoError(s.T(), err)
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "phone" FROM "secret_records"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"phone"}).AddRow(ciphertext))
+
+	var records []secretRecord
+	err = s.db.WithContext(s.ctx).Select("phone").Find(&records).Error
+
+	assert.ErrorContains(s.T(), err, "primary key ID is not set")
+}
+
+func (s *EncryptionTestSuite) TestUpdate_SelectsIndexWithSource() {
+	record := &secretRecord{ID: "rec-1", Note: "moved"}
+
+	// Clearing the phone clears its index too
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "secret_records" SET "phone"=$1,"phone_index"=$2,"note"=$3 WHERE "id" = $4`)).
+		WithArgs(nil, nil, "moved", "rec-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.db.WithContext(s.ctx).Select("phone", "note").Updates(record).Error
+
+	require.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *EncryptionTestSuite) TestUpdate_LeavesIndexWithoutSource() {
+	record := &secretRecord{ID: "rec-1", Note: "moved"}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "secret_records" SET "note"=$1 WHERE "id" = $2`)).
+		WithArgs("moved", "rec-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.db.WithContext(s.ctx).Select("note").Updates(record).Error
+
+	require.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *EncryptionTestSuite) TestCreate_WithoutKeyring() {
+	keyring.Store(nil)
+	defer keyring.Store(s.keyring)
+
+	phone := "+6281234567890"
+	s.mock.ExpectBegin()
+	s.mock.ExpectRollback()
+
+	err := s.db.WithContext(s.ctx).Create(&secretRecord{ID: "rec-1", Phone: &phone}).Error
+
+	assert.ErrorIs(s.T(), err, errNoKeyring)
+}
+
+func TestNewKeyring_FromConfig(t *testing.T) {
+	key := func(b byte) string {
+		return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+	}
+
+	keyring, err := NewKeyring(config.EncryptionConfig{
+		Keys:     "old:" + key(1) + ",new:" + key(2),
+		IndexKey: key(3),
+	})
+	require.NoError(t, err)
+
+	// The last listed key is the primary one
+	ciphertext, err := keyring.Encrypt([]byte("+6281234567890"), nil)
+	require.NoError(t, err)
+	assert.Regexp(t, `^enc:new:`, ciphertext)
+
+	_, err = NewKeyring(config.EncryptionConfig{IndexKey: key(3)})
+	assert.ErrorContains(t, err, "FIELD_ENCRYPTION_KEYS")
+	_, err = NewKeyring(config.EncryptionConfig{Keys: "1:" + key(1)})
+	assert.ErrorContains(t, err, "FIELD_ENCRYPTION_INDEX_KEY")
+	_, err = NewKeyring(config.EncryptionConfig{Keys: "1:" + key(1), KeyID: "2", IndexKey: key(3)})
+	assert.Error(t, err)
+}
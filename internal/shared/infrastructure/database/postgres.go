@@ -17,6 +17,29 @@ type PostgresDB struct {
 
 // NewPostgresDB creates a new PostgreSQL database connection using GORM
 func NewPostgresDB() (*PostgresDB, error) {
+	appConfig := config.Load()
+	db, err := NewPlainPostgresDB()
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypted columns cannot be read or written without the keyring
+	keyring, err := NewKeyring(appConfig.Encryption)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to load field encryption keys: %w", err)
+	}
+	if err := EnableEncryption(db.DB, keyring); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to enable field encryption: %w", err)
+	}
+
+	return db, nil
+}
+
+// NewPlainPostgresDB creates a connection without field encryption, for commands
+// that only change the schema such as migrate
+func NewPlainPostgresDB() (*PostgresDB, error) {
 	cfg := config.Load().Database
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Host, cfg.Port, cfg.User, cfg.Pass, cfg.Name, cfg.SSLMode)
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"app/internal/shared/domain/repository"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxTxAttempts bounds how often a transaction is run when it keeps failing to serialize
+	maxTxAttempts = 3
+	// txRetryBackoff is the base delay between attempts, growing linearly per attempt
+	txRetryBackoff = 20 * time.Millisecond
+)
+
+// PostgreSQL SQLSTATEs of failures that succeed when the transaction is run again
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// txKey is the context key holding the active transaction
+type txKey struct{}
+
+// transactor implements repository.Transactor using GORM transactions
+type transactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor creates a new transactor
+func NewTransactor(db *gorm.DB) repository.Transactor {
+	return &transactor{db: db}
+}
+
+// WithinTransaction runs fn in a transaction. Called with a ctx that already carries a
+// transaction it runs fn in a savepoint instead, so only that part rolls back on error.
+// Serialization failures and deadlocks rerun the outermost transaction
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return run(ctx, tx, fn)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := run(ctx, t.db.WithContext(ctx), fn)
+		if attempt == maxTxAttempts || !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * txRetryBackoff):
+		}
+	}
+}
+
+// run executes fn in a transaction on db (a savepoint when db is a transaction)
+func run(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// isRetryable reports whether err is a serialization failure or deadlock
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected
+}
+
+// Conn returns the transaction bound to ctx, or db when there is none
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,193 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type TransactorTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	mock       sqlmock.Sqlmock
+	transactor *transactor
+	ctx        context.Context
+	sqlDB      *sql.DB
+}
+
+func (s *TransactorTestSuite) SetupTest() {
+	var err error
+	s.sqlDB, s.mock, err = sqlmock.New()
+	require.NoError(s.T(), err)
+
+	dialector := postgres.New(postgres.Config{
+		Conn:       s.sqlDB,
+		DriverName: "postgres",
+	})
+
+	s.db, err = gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(s.T(), err)
+
+	s.transactor = &transactor{db: s.db}
+	s.ctx = context.Background()
+}
+
+func (s *TransactorTestSuite) TearDownTest() {
+	s.sqlDB.Close()
+}
+
+func TestTransactorTestSuite(t *testing.T) {
+	suite.Run(t, new(TransactorTestSuite))
+}
+
+func (s *TransactorTestSuite) TestWithinTransaction_Commit() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.transactor.WithinTransaction(s.ctx, func(ctx context.Context) error {
+		return Conn(ctx, s.db).Exec("UPDATE users SET is_active = true").Error
+	})
+
+	s.NoError(err)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *TransactorTestSuite) TestWithinTransaction_Rollback() {
+	fnErr := errors.New("boom")
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectRollback()
+
+	err := s.transactor.WithinTransaction(s.ctx, func(ctx context.Context) error {
+		if err := Conn(ctx, s.db).Exec("UPDATE users SET is_active = true").Error; err != nil {
+			return err
+		}
+		return fnErr
+	})
+
+	s.ErrorIs(err, fnErr)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *TransactorTestSuite) TestWithinTransaction_NestedSavepoint() {
+	// A single BEGIN/COMMIT pair - the inner call runs in a savepoint
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.transactor.WithinTransaction(s.ctx, func(ctx context.Context) error {
+		return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			return Conn(ctx, s.db).Exec("UPDATE users SET is_active = true").Error
+		})
+	})
+
+	s.NoError(err)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *TransactorTestSuite) TestWithinTransaction_NestedRollbackToSavepoint() {
+	fnErr := errors.New("boom")
+
+	// The inner failure only undoes the savepoint, the outer transaction still commits
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectExec("DELETE FROM users").WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec("ROLLBACK TO SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	var innerErr error
+	err := s.transactor.WithinTransaction(s.ctx, func(ctx context.Context) error {
+		if err := Conn(ctx, s.db).Exec("UPDATE users SET is_active = true").Error; err != nil {
+			return err
+		}
+		innerErr = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := Conn(ctx, s.db).Exec("DELETE FROM users").Error; err != nil {
+				return err
+			}
+			return fnErr
+		})
+		return nil
+	})
+
+	s.NoError(err)
+	s.ErrorIs(innerErr, fnErr)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *TransactorTestSuite) TestWithinTransaction_RetriesSerializationFailure() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("UPDATE users").WillReturnError(&pgconn.PgError{Code: serializationFailure})
+	s.mock.ExpectRollback()
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	calls := 0
+	err := s.transactor.WithinTransaction(s.ctx, func(ctx context.Context) error {
+		calls++
+		return Conn(ctx, s.db).Exec("UPDATE users SET is_active = true").Error
+	})
+
+	s.NoError(err)
+	s.Equal(2, calls)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *TransactorTestSuite) TestWithinTransaction_GivesUpAfterMaxAttempts() {
+	for i := 0; i < maxTxAttempts; i++ {
+		s.mock.ExpectBegin()
+		s.mock.ExpectExec("UPDATE users").WillReturnError(&pgconn.PgError{Code: deadlockDetected})
+		s.mock.ExpectRollback()
+	}
+
+	calls := 0
+	err := s.transactor.WithinTransaction(s.ctx, func(ctx context.Context) error {
+		calls++
+		return Conn(ctx, s.db).Exec("UPDATE users SET is_active = true").Error
+	})
+
+	var pgErr *pgconn.PgError
+	s.ErrorAs(err, &pgErr)
+	s.Equal(maxTxAttempts, calls)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *TransactorTestSuite) TestWithinTransaction_NoRetryOnOtherErrors() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("UPDATE users").WillReturnError(&pgconn.PgError{Code: "23505"})
+	s.mock.ExpectRollback()
+
+	calls := 0
+	err := s.transactor.WithinTransaction(s.ctx, func(ctx context.Context) error {
+		calls++
+		return Conn(ctx, s.db).Exec("UPDATE users SET is_active = true").Error
+	})
+
+	s.Error(err)
+	s.Equal(1, calls)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *TransactorTestSuite) TestConn_WithoutTransaction() {
+	s.mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := Conn(s.ctx, s.db).Exec("UPDATE users SET is_active = true").Error
+
+	s.NoError(err)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
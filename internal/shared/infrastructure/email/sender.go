@@ -0,0 +1,96 @@
+package email
+
+import (
+	"app/internal/shared/domain/service"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NewSender creates the email sender for provider, unknown providers fall back
+// to the log sender
+func NewSender(provider string, logger *logrus.Logger) service.EmailSender {
+	switch provider {
+	case "memory":
+		return NewMemorySender()
+	default:
+		return NewLogSender(logger)
+	}
+}
+
+// logSender writes emails to the log instead of delivering them. Meant for
+// development only, the log then contains the links
+type logSender struct {
+	logger *logrus.Logger
+}
+
+// NewLogSender creates an email sender that logs every email
+func NewLogSender(logger *logrus.Logger) service.EmailSender {
+	return &logSender{logger: logger}
+}
+
+// Send logs the email
+func (s *logSender) Send(ctx context.Context, to, subject, body string) error {
+	s.logger.WithFields(logrus.Fields{"to": to, "subject": subject}).Info("email: ", body)
+	return nil
+}
+
+// Message is an email recorded by MemorySender
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// MemorySender keeps sent emails in memory so tests can read them back
+type MemorySender struct {
+	mu       sync.Mutex
+	messages []Message
+	err      error
+}
+
+// NewMemorySender creates an in-memory email sender
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+// Send records the email, or returns the error set with FailWith
+func (s *MemorySender) Send(ctx context.Context, to, subject, body string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return fmt.Errorf("send email: %w", s.err)
+	}
+	s.messages = append(s.messages, Message{To: to, Subject: subject, Body: body})
+	return nil
+}
+
+// FailWith makes subsequent sends fail with err, nil restores delivery
+func (s *MemorySender) FailWith(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// Messages returns the emails sent so far
+func (s *MemorySender) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Last returns the latest email sent to the given address
+func (s *MemorySender) Last(to string) (Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if s.messages[i].To == to {
+			return s.messages[i], true
+		}
+	}
+	return Message{}, false
+}
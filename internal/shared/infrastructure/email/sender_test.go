@@ -0,0 +1,46 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSender_Provider(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	assert.IsType(t, &MemorySender{}, NewSender("memory", logger))
+	assert.IsType(t, &logSender{}, NewSender("log", logger))
+	assert.IsType(t, &logSender{}, NewSender("unknown", logger))
+}
+
+func TestMemorySender_Last(t *testing.T) {
+	sender := NewMemorySender()
+	ctx := context.Background()
+
+	require.NoError(t, sender.Send(ctx, "bob@example.com", "Hello", "first"))
+	require.NoError(t, sender.Send(ctx, "alice@example.com", "Hello", "other"))
+	require.NoError(t, sender.Send(ctx, "bob@example.com", "Hello", "second"))
+
+	msg, ok := sender.Last("bob@example.com")
+
+	assert.True(t, ok)
+	assert.Equal(t, "second", msg.Body)
+	assert.Len(t, sender.Messages(), 3)
+}
+
+func TestMemorySender_FailWith(t *testing.T) {
+	sender := NewMemorySender()
+	sender.FailWith(errors.New("smtp down"))
+
+	err := sender.Send(context.Background(), "bob@example.com", "Hello", "body")
+
+	assert.Error(t, err)
+	assert.Empty(t, sender.Messages())
+}
@@ -0,0 +1,271 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// DriftKind tells how the database schema differs from a model
+type DriftKind string
+
+// Ways the database schema can differ from a model
+const (
+	DriftMissingTable  DriftKind = "missing_table"
+	DriftMissingColumn DriftKind = "missing_column"
+	DriftExtraColumn   DriftKind = "extra_column"
+	DriftType          DriftKind = "type_mismatch"
+	DriftNullability   DriftKind = "nullability_mismatch"
+	DriftMissingIndex  DriftKind = "missing_index"
+)
+
+// Drift is a difference between the database schema and a model
+type Drift struct {
+	Kind   DriftKind
+	Table  string
+	Column string // Column or index name, empty for a missing table
+	Detail string // Model and database sides, e.g. "model varchar(20), database text"
+}
+
+// String returns the drift as one line, e.g. "type_mismatch users.phone: model varchar(20), database text"
+func (d Drift) String() string {
+	name := d.Table
+	if d.Column != "" {
+		name += "." + d.Column
+	}
+	if d.Detail == "" {
+		return fmt.Sprintf("%s %s", d.Kind, name)
+	}
+	return fmt.Sprintf("%s %s: %s", d.Kind, name, d.Detail)
+}
+
+// dbColumn is a column as information_schema describes it
+type dbColumn struct {
+	ColumnName             string
+	DataType               string
+	CharacterMaximumLength *int
+	IsNullable             string
+}
+
+// dbIndex is an index as pg_catalog describes it, information_schema has none
+type dbIndex struct {
+	Name      string
+	IsUnique  bool
+	Columns   string // Comma-separated, in index order
+	Predicate string // WHERE clause of a partial index
+}
+
+// CheckDrift compares the live schema of the tables of models with their GORM
+// definitions: columns missing on either side, types, nullability and the
+// indexes declared by index and uniqueIndex tags. Types are compared by what the
+// Go field can hold, so integer widths and time zones are not told apart while
+// string lengths are. A declared index is found by its columns rather than its
+// name, a wider index starting with them serves as well
+func CheckDrift(ctx context.Context, db *gorm.DB, models ...any) ([]Drift, error) {
+	var drifts []Drift
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("parse model %T: %w", model, err)
+		}
+		found, err := checkTable(ctx, db, stmt.Schema)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, found...)
+	}
+
+	sort.SliceStable(drifts, func(i, j int) bool {
+		if drifts[i].Table != drifts[j].Table {
+			return drifts[i].Table < drifts[j].Table
+		}
+		return drifts[i].Column < drifts[j].Column
+	})
+	return drifts, nil
+}
+
+// checkTable compares the table of one model
+func checkTable(ctx context.Context, db *gorm.DB, s *schema.Schema) ([]Drift, error) {
+	table := s.Table
+
+	var columns []dbColumn
+	err := db.WithContext(ctx).Raw(`SELECT column_name, data_type, character_maximum_length, is_nullable
+		FROM information_schema.columns
+		WHERE table_schema = CURRENT_SCHEMA() AND table_name = ?
+		ORDER BY ordinal_position`, table).Scan(&columns).Error
+	if err != nil {
+		return nil, fmt.Errorf("read columns of %s: %w", table, err)
+	}
+	if len(columns) == 0 {
+		return []Drift{{Kind: DriftMissingTable, Table: table}}, nil
+	}
+
+	var drifts []Drift
+	byName := make(map[string]dbColumn, len(columns))
+	for _, column := range columns {
+		byName[column.ColumnName] = column
+	}
+	modelColumns := map[string]bool{}
+	for _, field := range s.Fields {
+		if field.DBName == "" || field.IgnoreMigration {
+			continue
+		}
+		modelColumns[field.DBName] = true
+
+		modelType := db.Dialector.DataTypeOf(field)
+		column, ok := byName[field.DBName]
+		if !ok {
+			drifts = append(drifts, Drift{Kind: DriftMissingColumn, Table: table, Column: field.DBName, Detail: "model " + modelType})
+			continue
+		}
+
+		dbType := column.DataType
+		if column.CharacterMaximumLength != nil {
+			dbType = fmt.Sprintf("%s(%d)", dbType, *column.CharacterMaximumLength)
+		}
+		if canonicalType(modelType) != canonicalType(dbType) {
+			drifts = append(drifts, Drift{Kind: DriftType, Table: table, Column: field.DBName,
+				Detail: fmt.Sprintf("model %s, database %s", modelType, dbType)})
+		}
+
+		modelNullable := !field.NotNull && !field.PrimaryKey
+		dbNullable := column.IsNullable == "YES"
+		if modelNullable != dbNullable {
+			drifts = append(drifts, Drift{Kind: DriftNullability, Table: table, Column: field.DBName,
+				Detail: fmt.Sprintf("model %s, database %s", nullability(modelNullable), nullability(dbNullable))})
+		}
+	}
+	for _, column := range columns {
+		if !modelColumns[column.ColumnName] {
+			drifts = append(drifts, Drift{Kind: DriftExtraColumn, Table: table, Column: column.ColumnName, Detail: "database " + column.DataType})
+		}
+	}
+
+	var indexes []dbIndex
+	err = db.WithContext(ctx).Raw(`SELECT i.relname AS name, x.indisunique AS is_unique,
+			ARRAY_TO_STRING(ARRAY(
+				SELECT a.attname FROM UNNEST(x.indkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = x.indrelid AND a.attnum = k.attnum
+				ORDER BY k.ord), ',') AS columns,
+			COALESCE(PG_GET_EXPR(x.indpred, x.indrelid), '') AS predicate
+		FROM pg_index x
+		JOIN pg_class t ON t.oid = x.indrelid
+		JOIN pg_class i ON i.oid = x.indexrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = CURRENT_SCHEMA() AND t.relname = ?`, table).Scan(&indexes).Error
+	if err != nil {
+		return nil, fmt.Errorf("read indexes of %s: %w", table, err)
+	}
+	for _, index := range s.ParseIndexes() {
+		if !hasIndex(indexes, index) {
+			drifts = append(drifts, Drift{Kind: DriftMissingIndex, Table: table, Column: index.Name, Detail: describeIndex(index)})
+		}
+	}
+	return drifts, nil
+}
+
+// hasIndex reports whether one of indexes serves the declared index: it starts
+// with the same columns, is unique when that is required and has the same
+// predicate
+func hasIndex(indexes []dbIndex, declared *schema.Index) bool {
+	columns := indexColumns(declared)
+	unique := declared.Class == "UNIQUE"
+	for _, index := range indexes {
+		if unique && (!index.IsUnique || index.Columns != columns) {
+			continue
+		}
+		if index.Columns != columns && !strings.HasPrefix(index.Columns, columns+",") {
+			continue
+		}
+		if normalizePredicate(index.Predicate) == normalizePredicate(declared.Where) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexColumns returns the columns of a declared index as dbIndex.Columns lists them
+func indexColumns(index *schema.Index) string {
+	columns := make([]string, 0, len(index.Fields))
+	for _, field := range index.Fields {
+		columns = append(columns, field.DBName)
+	}
+	return strings.Join(columns, ",")
+}
+
+// describeIndex returns the declared index as it would be created
+func describeIndex(index *schema.Index) string {
+	description := "model "
+	if index.Class == "UNIQUE" {
+		description += "unique "
+	}
+	description += "(" + strings.ReplaceAll(indexColumns(index), ",", ", ") + ")"
+	if index.Where != "" {
+		description += " WHERE " + index.Where
+	}
+	return description
+}
+
+var (
+	sized      = regexp.MustCompile(`^([a-z ]+?)\s*(\(\d+(,\s*\d+)?\))?$`)
+	casts      = regexp.MustCompile(`::(character varying|[a-z]+)(\[\])?`)
+	predicates = strings.NewReplacer("(", "", ")", "", " ", "", "\n", "", "\t", "")
+)
+
+// typeFamilies maps the Postgres spellings of a type to the one it is compared by
+var typeFamilies = map[string]string{
+	"character varying":           "varchar",
+	"character":                   "char",
+	"smallint":                    "integer",
+	"bigint":                      "integer",
+	"int":                         "integer",
+	"int2":                        "integer",
+	"int4":                        "integer",
+	"int8":                        "integer",
+	"smallserial":                 "integer",
+	"serial":                      "integer",
+	"bigserial":                   "integer",
+	"bool":                        "boolean",
+	"timestamptz":                 "timestamp",
+	"timestamp with time zone":    "timestamp",
+	"timestamp without time zone": "timestamp",
+	"decimal":                     "numeric",
+	"float8":                      "double precision",
+}
+
+// canonicalType returns the spelling a model or database type is compared by
+func canonicalType(sqlType string) string {
+	sqlType = strings.ToLower(strings.TrimSpace(sqlType))
+	match := sized.FindStringSubmatch(sqlType)
+	if match == nil {
+		return sqlType
+	}
+	name, size := match[1], strings.ReplaceAll(match[2], " ", "")
+	if family, ok := typeFamilies[name]; ok {
+		name = family
+	}
+	// Precision of integers and timestamps does not change what they hold
+	if name == "integer" || name == "timestamp" {
+		size = ""
+	}
+	return name + size
+}
+
+// normalizePredicate strips the parentheses, spaces and casts Postgres adds to an
+// index predicate, e.g. "((status)::text = 'pending'::text)" for "status = 'pending'"
+func normalizePredicate(predicate string) string {
+	return strings.ToLower(predicates.Replace(casts.ReplaceAllString(predicate, "")))
+}
+
+// nullability names the nullability of a column in a Drift
+func nullability(nullable bool) string {
+	if nullable {
+		return "nullable"
+	}
+	return "not null"
+}
@@ -0,0 +1,139 @@
+package migration
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type driftThing struct {
+	ID        string  `gorm:"type:varchar(36);primaryKey"`
+	Name      string  `gorm:"type:varchar(100);not null;index"`
+	Code      string  `gorm:"type:varchar(20);uniqueIndex:idx_drift_things_code,where:deleted_at IS NULL"`
+	Count     int     `gorm:"not null"`
+	Note      *string `gorm:"type:text"`
+	OwnerID   string  `gorm:"type:varchar(36);index"`
+	CreatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+func (driftThing) TableName() string {
+	return "drift_things"
+}
+
+const (
+	selectColumns = "FROM information_schema.columns"
+	selectIndexes = "FROM pg_index"
+)
+
+func columnRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"column_name", "data_type", "character_maximum_length", "is_nullable"})
+}
+
+func indexRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"name", "is_unique", "columns", "predicate"})
+}
+
+func (s *MigratorTestSuite) TestCheckDrift() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectColumns)).
+		WithArgs("drift_things").
+		WillReturnRows(columnRows().
+			AddRow("id", "character varying", 36, "NO").
+			AddRow("name", "character varying", 50, "NO").
+			AddRow("code", "character varying", 20, "YES").
+			AddRow("count", "integer", nil, "YES").
+			AddRow("note", "text", nil, "YES").
+			AddRow("created_at", "timestamp without time zone", nil, "YES").
+			AddRow("deleted_at", "timestamp without time zone", nil, "YES").
+			AddRow("legacy", "text", nil, "YES"))
+	// The name index is served by a wider one, the owner_id one is missing
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectIndexes)).
+		WithArgs("drift_things").
+		WillReturnRows(indexRows().
+			AddRow("drift_things_pkey", true, "id", "").
+			AddRow("idx_drift_things_name_code", false, "name,code", "").
+			AddRow("idx_drift_things_code", true, "code", "(deleted_at IS NULL)").
+			AddRow("idx_drift_things_deleted_at", false, "deleted_at", ""))
+
+	drifts, err := CheckDrift(s.ctx, s.db, &driftThing{})
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []Drift{
+		{Kind: DriftNullability, Table: "drift_things", Column: "count", Detail: "model not null, database nullable"},
+		{Kind: DriftMissingIndex, Table: "drift_things", Column: "idx_drift_things_owner_id", Detail: "model (owner_id)"},
+		{Kind: DriftExtraColumn, Table: "drift_things", Column: "legacy", Detail: "database text"},
+		{Kind: DriftType, Table: "drift_things", Column: "name", Detail: "model varchar(100), database character varying(50)"},
+		{Kind: DriftMissingColumn, Table: "drift_things", Column: "owner_id", Detail: "model varchar(36)"},
+	}, drifts)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *MigratorTestSuite) TestCheckDrift_PartialIndexMismatch() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectColumns)).
+		WithArgs("drift_things").
+		WillReturnRows(columnRows().
+			AddRow("id", "character varying", 36, "NO").
+			AddRow("name", "character varying", 100, "NO").
+			AddRow("code", "character varying", 20, "YES").
+			AddRow("count", "bigint", nil, "NO").
+			AddRow("note", "text", nil, "YES").
+			AddRow("owner_id", "character varying", 36, "YES").
+			AddRow("created_at", "timestamp with time zone", nil, "YES").
+			AddRow("deleted_at", "timestamp with time zone", nil, "YES"))
+	// A unique index over every row is not the partial one declared
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectIndexes)).
+		WithArgs("drift_things").
+		WillReturnRows(indexRows().
+			AddRow("idx_drift_things_name", false, "name", "").
+			AddRow("drift_things_code_key", true, "code", "").
+			AddRow("idx_drift_things_owner_id", false, "owner_id", "").
+			AddRow("idx_drift_things_deleted_at", false, "deleted_at", ""))
+
+	drifts, err := CheckDrift(s.ctx, s.db, &driftThing{})
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []Drift{
+		{Kind: DriftMissingIndex, Table: "drift_things", Column: "idx_drift_things_code", Detail: "model unique (code) WHERE deleted_at IS NULL"},
+	}, drifts)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *MigratorTestSuite) TestCheckDrift_MissingTable() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(selectColumns)).
+		WithArgs("drift_things").
+		WillReturnRows(columnRows())
+
+	drifts, err := CheckDrift(s.ctx, s.db, &driftThing{})
+
+	require.NoError(s.T(), err)
+	require.Len(s.T(), drifts, 1)
+	assert.Equal(s.T(), "missing_table drift_things", drifts[0].String())
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *MigratorTestSuite) TestCanonicalType() {
+	for model, database := range map[string]string{
+		"varchar(36)":   "character varying(36)",
+		"bigint":        "integer",
+		"timestamptz":   "timestamp without time zone",
+		"boolean":       "bool",
+		"numeric(10,2)": "numeric(10, 2)",
+		"text":          "TEXT",
+	} {
+		assert.Equal(s.T(), canonicalType(model), canonicalType(database), model)
+	}
+	assert.NotEqual(s.T(), canonicalType("varchar(20)"), canonicalType("text"))
+	assert.NotEqual(s.T(), canonicalType("varchar(20)"), canonicalType("character varying(255)"))
+}
+
+func (s *MigratorTestSuite) TestNormalizePredicate() {
+	assert.Equal(s.T(),
+		normalizePredicate("status = 'pending' OR status = 'running'"),
+		normalizePredicate("(((status)::text = 'pending'::text) OR ((status)::text = 'running'::text))"))
+	assert.Equal(s.T(), normalizePredicate("deleted_at IS NULL"), normalizePredicate("(deleted_at IS NULL)"))
+	assert.NotEqual(s.T(), normalizePredicate("status = 'pending'"), normalizePredicate("((status)::text = 'ready'::text)"))
+}
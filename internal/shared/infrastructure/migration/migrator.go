@@ -0,0 +1,228 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrDirty is returned while a migration applied by hand or by another tool is
+// recorded as failed halfway, the schema has to be fixed and the version forced
+var ErrDirty = errors.New("database is dirty")
+
+// versionTable records the applied version in the single row golang-migrate uses,
+// so databases it migrated carry on from where they are
+const versionTable = "schema_migrations"
+
+// lockKey identifies the session advisory lock held while migrating
+var lockKey = int64(crc32.ChecksumIEEE([]byte("app:" + versionTable)))
+
+// Status is the state of a database against the known migrations
+type Status struct {
+	Version uint64 // Last applied migration, 0 when none is
+	Dirty   bool
+	Applied []Migration
+	Pending []Migration
+}
+
+// Migrator applies migrations to a database. Every migration runs in its own
+// transaction along with the version update, so a failing one leaves nothing
+// behind. Runs hold a Postgres advisory lock: replicas starting at once apply
+// each migration once, the others wait and find nothing pending
+type Migrator struct {
+	db         *gorm.DB
+	source     fs.FS
+	migrations []Migration
+}
+
+// New creates a migrator applying the migrations of source, see Load
+func New(db *gorm.DB, source fs.FS) (*Migrator, error) {
+	migrations, err := Load(source)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, source: source, migrations: migrations}, nil
+}
+
+// Up applies up to limit pending migrations, all of them when limit is 0, and
+// returns those it applied
+func (m *Migrator) Up(ctx context.Context, limit int) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func(conn *gorm.DB) error {
+		current, err := m.current(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version <= current {
+				continue
+			}
+			if limit > 0 && len(applied) == limit {
+				break
+			}
+			if err := m.apply(conn, migration.Up, migration.Version); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back up to limit applied migrations, latest first, and returns
+// those it rolled back
+func (m *Migrator) Down(ctx context.Context, limit int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.locked(ctx, func(conn *gorm.DB) error {
+		current, err := m.current(conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if migration.Version > current {
+				continue
+			}
+			if limit > 0 && len(reverted) == limit {
+				break
+			}
+			var previous uint64
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+			if err := m.apply(conn, migration.Down, previous); err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Force records version as applied and clears the dirty flag without running
+// anything, once a failed migration was fixed by hand. 0 records none
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	if version != 0 && m.find(version) < 0 {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+	return m.locked(ctx, func(conn *gorm.DB) error {
+		return conn.Transaction(func(tx *gorm.DB) error {
+			return setVersion(tx, version)
+		})
+	})
+}
+
+// Status reports the applied version and which migrations are pending
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	status := &Status{}
+	err := m.locked(ctx, func(conn *gorm.DB) error {
+		var err error
+		status.Version, status.Dirty, err = readVersion(conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if migration.Version <= status.Version {
+				status.Applied = append(status.Applied, migration)
+			} else {
+				status.Pending = append(status.Pending, migration)
+			}
+		}
+		return nil
+	})
+	return status, err
+}
+
+// locked runs fn on a single connection holding the migration lock, creating the
+// version table first
+func (m *Migrator) locked(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		// Waits for a replica migrating meanwhile
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", lockKey).Error; err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		// The lock belongs to the session, release it even when ctx is done
+		defer conn.WithContext(context.Background()).Exec("SELECT pg_advisory_unlock(?)", lockKey)
+
+		err := conn.Exec("CREATE TABLE IF NOT EXISTS " + versionTable + " (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)").Error
+		if err != nil {
+			return fmt.Errorf("create %s: %w", versionTable, err)
+		}
+		return fn(conn)
+	})
+}
+
+// current returns the applied version, which must be clean and known
+func (m *Migrator) current(conn *gorm.DB) (uint64, error) {
+	version, dirty, err := readVersion(conn)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w at version %d, fix the schema then run migrate force", ErrDirty, version)
+	}
+	if version != 0 && m.find(version) < 0 {
+		return 0, fmt.Errorf("database is at version %d, which has no migration here", version)
+	}
+	return version, nil
+}
+
+// apply runs a migration file and records version in the same transaction
+func (m *Migrator) apply(conn *gorm.DB, file string, version uint64) error {
+	body, err := fs.ReadFile(m.source, file)
+	if err != nil {
+		return err
+	}
+
+	return conn.Transaction(func(tx *gorm.DB) error {
+		if strings.TrimSpace(string(body)) != "" {
+			if err := tx.Exec(string(body)).Error; err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+		}
+		return setVersion(tx, version)
+	})
+}
+
+// find returns the index of the migration of version, -1 if there is none
+func (m *Migrator) find(version uint64) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// readVersion reads the version table, 0 when no migration was applied
+func readVersion(conn *gorm.DB) (uint64, bool, error) {
+	var row struct {
+		Version uint64
+		Dirty   bool
+	}
+	err := conn.Raw("SELECT version, dirty FROM " + versionTable + " LIMIT 1").Scan(&row).Error
+	if err != nil {
+		return 0, false, fmt.Errorf("read %s: %w", versionTable, err)
+	}
+	return row.Version, row.Dirty, nil
+}
+
+// setVersion replaces the version row, left empty for version 0
+func setVersion(conn *gorm.DB, version uint64) error {
+	if err := conn.Exec("DELETE FROM " + versionTable).Error; err != nil {
+		return err
+	}
+	if version == 0 {
+		return nil
+	}
+	return conn.Exec("INSERT INTO "+versionTable+" (version, dirty) VALUES (?, false)", version).Error
+}
@@ -0,0 +1,286 @@
+package migration
+
+import (
+	"app/migrations"
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type MigratorTestSuite struct {
+	suite.Suite
+	db       *gorm.DB
+	mock     sqlmock.Sqlmock
+	migrator *Migrator
+	ctx      context.Context
+	sqlDB    *sql.DB
+}
+
+var testSource = fstest.MapFS{
+	"001_create_things.up.sql":   {Data: []byte("CREATE TABLE things (id INT)")},
+	"001_create_things.down.sql": {Data: []byte("DROP TABLE things")},
+	"002_add_name.up.sql":        {Data: []byte("ALTER TABLE things ADD name TEXT")},
+	"002_add_name.down.sql":      {Data: []byte("ALTER TABLE things DROP name")},
+	"003_add_index.up.sql":       {Data: []byte("CREATE INDEX idx_things_name ON things(name)")},
+	"003_add_index.down.sql":     {Data: []byte("DROP INDEX idx_things_name")},
+	"README.md":                  {Data: []byte("ignored")},
+}
+
+func (s *MigratorTestSuite) SetupTest() {
+	var err error
+	s.sqlDB, s.mock, err = sqlmock.New()
+	require.NoError(s.T(), err)
+
+	s.db, err = gorm.Open(postgres.New(postgres.Config{
+		Conn:       s.sqlDB,
+		DriverName: "postgres",
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(s.T(), err)
+
+	s.migrator, err = New(s.db, testSource)
+	require.NoError(s.T(), err)
+	s.ctx = context.Background()
+}
+
+func (s *MigratorTestSuite) TearDownTest() {
+	s.sqlDB.Close()
+}
+
+func TestMigratorTestSuite(t *testing.T) {
+	suite.Run(t, new(MigratorTestSuite))
+}
+
+// expectLocked expects the lock and version table around a run at version
+func (s *MigratorTestSuite) expectLocked(version int64, dirty bool) {
+	s.mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).
+		WithArgs(lockKey).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS schema_migrations")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rows := sqlmock.NewRows([]string{"version", "dirty"})
+	if version > 0 {
+		rows.AddRow(version, dirty)
+	}
+	s.mock.ExpectQuery(regexp.QuoteMeta("SELECT version, dirty FROM schema_migrations LIMIT 1")).
+		WillReturnRows(rows)
+}
+
+// expectApply expects a migration and the version it records in one transaction
+func (s *MigratorTestSuite) expectApply(statement string, version int64) {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(statement)).WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations")).WillReturnResult(sqlmock.NewResult(0, 1))
+	if version > 0 {
+		s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)")).
+			WithArgs(version).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	s.mock.ExpectCommit()
+}
+
+func (s *MigratorTestSuite) expectUnlock() {
+	s.mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).
+		WithArgs(lockKey).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func (s *MigratorTestSuite) TestUp_AppliesPending() {
+	s.expectLocked(1, false)
+	s.expectApply("ALTER TABLE things ADD name TEXT", 2)
+	s.expectApply("CREATE INDEX idx_things_name ON things(name)", 3)
+	s.expectUnlock()
+
+	applied, err := s.migrator.Up(s.ctx, 0)
+
+	require.NoError(s.T(), err)
+	require.Len(s.T(), applied, 2)
+	assert.Equal(s.T(), "002_add_name", applied[0].String())
+	assert.Equal(s.T(), "003_add_index", applied[1].String())
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *MigratorTestSuite) TestUp_Limit() {
+	s.expectLocked(0, false)
+	s.expectApply("CREATE TABLE things (id INT)", 1)
+	s.expectUnlock()
+
+	applied, err := s.migrator.Up(s.ctx, 1)
+
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), applied, 1)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *MigratorTestSuite) TestUp_NothingPending() {
+	s.expectLocked(3, false)
+	s.expectUnlock()
+
+	applied, err := s.migrator.Up(s.ctx, 0)
+
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), applied)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *MigratorTestSuite) TestUp_FailureRollsBack() {
+	s.expectLocked(1, false)
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE things ADD name TEXT")).
+		WillReturnError(errors.New("column already exists"))
+	s.mock.ExpectRollback()
+	s.expectUnlock()
+
+	applied, err := s.migrator.Up(s.ctx, 0)
+
+	assert.ErrorContains(s.T(), err, "002_add_name.up.sql: column already exists")
+	assert.Empty(s.T(), applied)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *MigratorTestSuite) TestUp_Dirty() {
+	s.expectLocked(2, true)
+	s.expectUnlock()
+
+	_, err := s.migrator.Up(s.ctx, 0)
+
+	assert.ErrorIs(s.T(), err, ErrDirty)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *MigratorTestSuite) TestUp_UnknownVersion() {
+	s.expectLocked(7, false)
+	s.expectUnlock()
+
+	_, err := s.migrator.Up(s.ctx, 0)
+
+	assert.ErrorContains(s.T(), err, "version 7")
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *MigratorTestSuite) TestUp_LockFailure() {
+	s.mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).
+		WithArgs(lockKey).
+		WillReturnError(context.DeadlineExceeded)
+
+	_, err := s.migrator.Up(s.ctx, 0)
+
+	assert.ErrorIs(s.T(), err, context.DeadlineExceeded)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *MigratorTestSuite) TestDown_RecordsPreviousVersion() {
+	s.expectLocked(3, false)
+	s.expectApply("DROP INDEX idx_things_name", 2)
+	s.expectApply("ALTER TABLE things DROP name", 1)
+	s.expectUnlock()
+
+	reverted, err := s.migrator.Down(s.ctx, 2)
+
+	require.NoError(s.T(), err)
+	require.Len(s.T(), reverted, 2)
+	assert.Equal(s.T(), uint64(3), reverted[0].Version)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *MigratorTestSuite) TestDown_First() {
+	s.expectLocked(1, false)
+	s.expectApply("DROP TABLE things", 0)
+	s.expectUnlock()
+
+	reverted, err := s.migrator.Down(s.ctx, 1)
+
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), reverted, 1)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *MigratorTestSuite) TestStatus() {
+	s.expectLocked(2, true)
+	s.expectUnlock()
+
+	status, err := s.migrator.Status(s.ctx)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), uint64(2), status.Version)
+	assert.True(s.T(), status.Dirty)
+	assert.Len(s.T(), status.Applied, 2)
+	require.Len(s.T(), status.Pending, 1)
+	assert.Equal(s.T(), uint64(3), status.Pending[0].Version)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *MigratorTestSuite) TestForce() {
+	s.mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).
+		WithArgs(lockKey).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS schema_migrations")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations")).WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)")).
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+	s.expectUnlock()
+
+	err := s.migrator.Force(s.ctx, 2)
+
+	require.NoError(s.T(), err)
+	assert.ErrorContains(s.T(), s.migrator.Force(s.ctx, 9), "unknown migration version 9")
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := Load(fstest.MapFS{"001_a.up.sql": {}})
+	assert.ErrorContains(t, err, "needs both an up and a down file")
+
+	_, err = Load(fstest.MapFS{"001_a.up.sql": {}, "001_a.down.sql": {}, "001_b.up.sql": {}})
+	assert.ErrorContains(t, err, "share version 1")
+
+	_, err = Load(fstest.MapFS{"000_a.up.sql": {}, "000_a.down.sql": {}})
+	assert.ErrorContains(t, err, "invalid migration version")
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	loaded, err := Load(migrations.FS)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, loaded)
+	// Numbered without gaps, as Create numbers them
+	for i, m := range loaded {
+		assert.Equal(t, uint64(i+1), m.Version, m.String())
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "007_existing.up.sql"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "007_existing.down.sql"), nil, 0o644))
+
+	up, down, err := Create(dir, "Add Seed-Data")
+
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "008_add_seed_data.up.sql"), up)
+	assert.Equal(t, filepath.Join(dir, "008_add_seed_data.down.sql"), down)
+	assert.FileExists(t, up)
+	assert.FileExists(t, down)
+
+	_, _, err = Create(dir, "  ")
+	assert.ErrorContains(t, err, "name is required")
+}
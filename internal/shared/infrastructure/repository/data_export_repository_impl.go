@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/domain/repository"
+	"app/internal/shared/infrastructure/database"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// dataExportRepository implements repository.DataExportRepository interface
+type dataExportRepository struct {
+	db *gorm.DB
+}
+
+// NewDataExportRepository creates a new data export repository
+func NewDataExportRepository(db *gorm.DB) repository.DataExportRepository {
+	return &dataExportRepository{db: db}
+}
+
+// Create stores a new export
+func (r *dataExportRepository) Create(ctx context.Context, export *entity.DataExport) error {
+	if err := database.Conn(ctx, r.db).Create(export).Error; err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// GetByID retrieves an export by ID
+func (r *dataExportRepository) GetByID(ctx context.Context, id string) (*entity.DataExport, error) {
+	return r.getBy(ctx, "id = ?", id)
+}
+
+// GetActive retrieves the user's export still pending or running
+func (r *dataExportRepository) GetActive(ctx context.Context, userID string) (*entity.DataExport, error) {
+	return r.getBy(ctx, "user_id = ? AND status IN ?", userID, []string{entity.DataExportPending, entity.DataExportRunning})
+}
+
+// ListByUser retrieves all exports of the user, most recent first
+func (r *dataExportRepository) ListByUser(ctx context.Context, userID string) ([]*entity.DataExport, error) {
+	var exports []*entity.DataExport
+	err := database.Conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at DESC").Find(&exports).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return exports, nil
+}
+
+// getBy retrieves the export matching the condition
+func (r *dataExportRepository) getBy(ctx context.Context, query string, args ...any) (*entity.DataExport, error) {
+	var export entity.DataExport
+	err := database.Conn(ctx, r.db).Where(query, args...).First(&export).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domainerror.ErrDataExportNotFound
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &export, nil
+}
+
+// Update writes the given columns of export, zero values included
+func (r *dataExportRepository) Update(ctx context.Context, export *entity.DataExport, fields ...string) error {
+	result := database.Conn(ctx, r.db).Select(fields).Updates(export)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domainerror.ErrDataExportNotFound
+	}
+	return nil
+}
+
+// ListExpired retrieves ready exports whose archive expired before the given time
+func (r *dataExportRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]*entity.DataExport, error) {
+	var exports []*entity.DataExport
+	err := database.Conn(ctx, r.db).
+		Where("status = ? AND expires_at < ?", entity.DataExportReady, before).
+		Order("expires_at").
+		Limit(limit).
+		Find(&exports).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return exports, nil
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type DataExportRepositoryTestSuite struct {
+	suite.Suite
+	mock  sqlmock.Sqlmock
+	repo  *dataExportRepository
+	ctx   context.Context
+	sqlDB *sql.DB
+}
+
+func (s *DataExportRepositoryTestSuite) SetupTest() {
+	var err error
+	s.sqlDB, s.mock, err = sqlmock.New()
+	require.NoError(s.T(), err)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		Conn:       s.sqlDB,
+		DriverName: "postgres",
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(s.T(), err)
+
+	s.repo = &dataExportRepository{db: db}
+	s.ctx = context.Background()
+}
+
+func (s *DataExportRepositoryTestSuite) TearDownTest() {
+	s.sqlDB.Close()
+}
+
+func TestDataExportRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(DataExportRepositoryTestSuite))
+}
+
+func (s *DataExportRepositoryTestSuite) TestCreate_Success() {
+	export := entity.NewDataExport("user-123")
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "data_exports"`)).
+		WithArgs(export.ID, "user-123", entity.DataExportPending, "", sqlmock.AnyArg(), nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.Create(s.ctx, export)
+
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *DataExportRepositoryTestSuite) TestCreate_ActiveExportConflict() {
+	export := entity.NewDataExport("user-123")
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "data_exports"`)).
+		WillReturnError(&pgconn.PgError{
+			Code:           "23505",
+			ConstraintName: "idx_data_exports_user_id_active",
+			Detail:         "Key (user_id)=(user-123) already exists.",
+		})
+	s.mock.ExpectRollback()
+
+	err := s.repo.Create(s.ctx, export)
+
+	var conflict *domainerror.ConflictError
+	require.ErrorAs(s.T(), err, &conflict)
+	assert.Equal(s.T(), "user_id", conflict.Field)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *DataExportRepositoryTestSuite) TestGetByID_NotFound() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "data_exports" WHERE id = $1 ORDER BY "data_exports"."id" LIMIT $2`)).
+		WithArgs("export-1", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	export, err := s.repo.GetByID(s.ctx, "export-1")
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrDataExportNotFound)
+	assert.Nil(s.T(), export)
+}
+
+func (s *DataExportRepositoryTestSuite) TestGetActive_Success() {
+	rows := sqlmock.NewRows([]string{"id", "user_id", "status"}).
+		AddRow("export-1", "user-123", entity.DataExportRunning)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "data_exports" WHERE user_id = $1 AND status IN ($2,$3) ORDER BY "data_exports"."id" LIMIT $4`)).
+		WithArgs("user-123", entity.DataExportPending, entity.DataExportRunning, 1).
+		WillReturnRows(rows)
+
+	export, err := s.repo.GetActive(s.ctx, "user-123")
+
+	assert.NoError(s.T(), err)
+	require.NotNil(s.T(), export)
+	assert.Equal(s.T(), "export-1", export.ID)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *DataExportRepositoryTestSuite) TestGetActive_Error() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "data_exports"`)).
+		WillReturnError(sql.ErrConnDone)
+
+	export, err := s.repo.GetActive(s.ctx, "user-123")
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrServiceUnavailable)
+	assert.Nil(s.T(), export)
+}
+
+func (s *DataExportRepositoryTestSuite) TestListByUser_Success() {
+	rows := sqlmock.NewRows([]string{"id", "user_id", "status"}).
+		AddRow("export-2", "user-123", entity.DataExportReady).
+		AddRow("export-1", "user-123", entity.DataExportExpired)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "data_exports" WHERE user_id = $1 ORDER BY created_at DESC`)).
+		WithArgs("user-123").
+		WillReturnRows(rows)
+
+	exports, err := s.repo.ListByUser(s.ctx, "user-123")
+
+	assert.NoError(s.T(), err)
+	require.Len(s.T(), exports, 2)
+	assert.Equal(s.T(), "export-2", exports[0].ID)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *DataExportRepositoryTestSuite) TestUpdate_Success() {
+	export := &entity.DataExport{ID: "export-1", Status: entity.DataExportFailed}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "data_exports" SET "status"=$1,"file_key"=$2 WHERE "id" = $3`)).
+		WithArgs(entity.DataExportFailed, "", "export-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.Update(s.ctx, export, "status", "file_key")
+
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *DataExportRepositoryTestSuite) TestUpdate_NotFound() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "data_exports"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	err := s.repo.Update(s.ctx, &entity.DataExport{ID: "export-1"}, "status")
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrDataExportNotFound)
+}
+
+func (s *DataExportRepositoryTestSuite) TestListExpired_Success() {
+	before := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "status", "file_key"}).
+		AddRow("export-1", entity.DataExportReady, "export-1.zip")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "data_exports" WHERE status = $1 AND expires_at < $2 ORDER BY expires_at LIMIT $3`)).
+		WithArgs(entity.DataExportReady, before, 50).
+		WillReturnRows(rows)
+
+	exports, err := s.repo.ListExpired(s.ctx, before, 50)
+
+	assert.NoError(s.T(), err)
+	require.Len(s.T(), exports, 1)
+	assert.Equal(s.T(), "export-1.zip", exports[0].FileKey)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
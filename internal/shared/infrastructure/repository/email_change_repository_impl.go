@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/domain/repository"
+	"app/internal/shared/infrastructure/database"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// emailChangeRepository implements repository.EmailChangeRepository interface
+type emailChangeRepository struct {
+	db *gorm.DB
+}
+
+// NewEmailChangeRepository creates a new email change repository
+func NewEmailChangeRepository(db *gorm.DB) repository.EmailChangeRepository {
+	return &emailChangeRepository{db: db}
+}
+
+// Create stores change, replacing any change pending for the same user
+func (r *emailChangeRepository) Create(ctx context.Context, change *entity.EmailChange) error {
+	err := database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", change.UserID).Delete(&entity.EmailChange{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
+	return translateError(err)
+}
+
+// GetByToken retrieves the change confirmed by the token with the given hash
+func (r *emailChangeRepository) GetByToken(ctx context.Context, tokenHash string) (*entity.EmailChange, error) {
+	return r.getBy(ctx, "token_hash = ?", tokenHash)
+}
+
+// GetByCancelToken retrieves the change cancelled by the token with the given hash
+func (r *emailChangeRepository) GetByCancelToken(ctx context.Context, cancelTokenHash string) (*entity.EmailChange, error) {
+	return r.getBy(ctx, "cancel_token_hash = ?", cancelTokenHash)
+}
+
+// GetByUser retrieves the change pending for the user
+func (r *emailChangeRepository) GetByUser(ctx context.Context, userID string) (*entity.EmailChange, error) {
+	return r.getBy(ctx, "user_id = ?", userID)
+}
+
+// getBy retrieves the change matching the condition
+func (r *emailChangeRepository) getBy(ctx context.Context, query string, args ...any) (*entity.EmailChange, error) {
+	var change entity.EmailChange
+	err := database.Conn(ctx, r.db).Where(query, args...).First(&change).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domainerror.ErrEmailChangeNotFound
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &change, nil
+}
+
+// Delete removes a change
+func (r *emailChangeRepository) Delete(ctx context.Context, id string) error {
+	if err := database.Conn(ctx, r.db).Where("id = ?", id).Delete(&entity.EmailChange{}).Error; err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// DeleteByUser removes the change pending for the user
+func (r *emailChangeRepository) DeleteByUser(ctx context.Context, userID string) (int, error) {
+	result := database.Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&entity.EmailChange{})
+	if result.Error != nil {
+		return 0, translateError(result.Error)
+	}
+	return int(result.RowsAffected), nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type EmailChangeRepositoryTestSuite struct {
+	suite.Suite
+	mock  sqlmock.Sqlmock
+	repo  *emailChangeRepository
+	ctx   context.Context
+	sqlDB *sql.DB
+}
+
+func (s *EmailChangeRepositoryTestSuite) SetupTest() {
+	var err error
+	s.sqlDB, s.mock, err = sqlmock.New()
+	require.NoError(s.T(), err)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		Conn:       s.sqlDB,
+		DriverName: "postgres",
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(s.T(), err)
+
+	s.repo = &emailChangeRepository{db: db}
+	s.ctx = context.Background()
+}
+
+func (s *EmailChangeRepositoryTestSuite) TearDownTest() {
+	s.sqlDB.Close()
+}
+
+func TestEmailChangeRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(EmailChangeRepositoryTestSuite))
+}
+
+func (s *EmailChangeRepositoryTestSuite) TestCreate_ReplacesPendingChange() {
+	change := entity.NewEmailChange(&entity.User{ID: "user-123", Email: "old@example.com"}, "new@example.com", time.Hour)
+	change.TokenHash = "token-hash"
+	change.CancelTokenHash = "cancel-hash"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM "email_changes" WHERE user_id = $1`)).
+		WithArgs("user-123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO "email_changes" ("id","user_id","old_email","new_email","token_hash","cancel_token_hash","expires_at","created_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`)).
+		WithArgs(change.ID, "user-123", "old@example.com", "new@example.com", "token-hash", "cancel-hash", change.ExpiresAt, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.Create(s.ctx, change)
+
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *EmailChangeRepositoryTestSuite) TestGetByToken_Success() {
+	rows := sqlmock.NewRows([]string{"id", "user_id", "old_email", "new_email", "token_hash"}).
+		AddRow("change-123", "user-123", "old@example.com", "new@example.com", "token-hash")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "email_changes" WHERE token_hash = $1 ORDER BY "email_changes"."id" LIMIT $2`)).
+		WithArgs("token-hash", 1).
+		WillReturnRows(rows)
+
+	change, err := s.repo.GetByToken(s.ctx, "token-hash")
+
+	assert.NoError(s.T(), err)
+	require.NotNil(s.T(), change)
+	assert.Equal(s.T(), "new@example.com", change.NewEmail)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *EmailChangeRepositoryTestSuite) TestGetByCancelToken_NotFound() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "email_changes" WHERE cancel_token_hash = $1`)).
+		WithArgs("cancel-hash", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	change, err := s.repo.GetByCancelToken(s.ctx, "cancel-hash")
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrEmailChangeNotFound)
+	assert.Nil(s.T(), change)
+}
+
+func (s *EmailChangeRepositoryTestSuite) TestGetByUser_Success() {
+	rows := sqlmock.NewRows([]string{"id", "user_id", "new_email"}).
+		AddRow("change-123", "user-123", "new@example.com")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "email_changes" WHERE user_id = $1`)).
+		WithArgs("user-123", 1).
+		WillReturnRows(rows)
+
+	change, err := s.repo.GetByUser(s.ctx, "user-123")
+
+	assert.NoError(s.T(), err)
+	require.NotNil(s.T(), change)
+	assert.Equal(s.T(), "new@example.com", change.NewEmail)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *EmailChangeRepositoryTestSuite) TestDelete_Success() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM "email_changes" WHERE id = $1`)).
+		WithArgs("change-123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.Delete(s.ctx, "change-123")
+
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *EmailChangeRepositoryTestSuite) TestDeleteByUser_Success() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM "email_changes" WHERE user_id = $1`)).
+		WithArgs("user-123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	deleted, err := s.repo.DeleteByUser(s.ctx, "user-123")
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, deleted)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	domainerror "app/internal/shared/domain/error"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// uniqueViolation is the PostgreSQL SQLSTATE for unique constraint violations
+const uniqueViolation = "23505"
+
+// conflictKeyRegex extracts the column from details like "Key (email)=(a@b.c) already exists."
+var conflictKeyRegex = regexp.MustCompile(`Key \(([^)]+)\)=`)
+
+// translateError converts database errors into domain errors: missing rows become
+// ErrUserNotFound, unique violations a ConflictError and everything else is wrapped
+// as ErrServiceUnavailable or ErrInternalServer
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domainerror.ErrUserNotFound
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return &domainerror.ConflictError{Field: conflictField(pgErr)}
+	}
+
+	if isUnavailable(err) {
+		return fmt.Errorf("%w: %w", domainerror.ErrServiceUnavailable, err)
+	}
+	return fmt.Errorf("%w: %w", domainerror.ErrInternalServer, err)
+}
+
+// conflictField returns the column behind a unique violation
+func conflictField(pgErr *pgconn.PgError) string {
+	// Canonical key and blind index columns report the field they are derived from
+	if match := conflictKeyRegex.FindStringSubmatch(pgErr.Detail); match != nil {
+		return strings.TrimSuffix(strings.TrimSuffix(match[1], "_normalized"), "_index")
+	}
+
+	// Fall back to the constraint name, e.g. idx_users_email or users_email_key
+	for _, field := range []string{"email", "username", "phone"} {
+		if strings.Contains(pgErr.ConstraintName, field) {
+			return field
+		}
+	}
+	return pgErr.ConstraintName
+}
+
+// isUnavailable reports whether err means the database could not be reached in time
+func isUnavailable(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		pgconn.Timeout(err) {
+		return true
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
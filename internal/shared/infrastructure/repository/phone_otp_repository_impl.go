@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/domain/repository"
+	"app/internal/shared/infrastructure/database"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// phoneOTPRepository implements repository.PhoneOTPRepository interface
+type phoneOTPRepository struct {
+	db *gorm.DB
+}
+
+// NewPhoneOTPRepository creates a new one-time password repository
+func NewPhoneOTPRepository(db *gorm.DB) repository.PhoneOTPRepository {
+	return &phoneOTPRepository{db: db}
+}
+
+// Create stores otp, replacing any code pending for the same user and purpose
+func (r *phoneOTPRepository) Create(ctx context.Context, otp *entity.PhoneOTP) error {
+	err := database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND purpose = ?", otp.UserID, otp.Purpose).Delete(&entity.PhoneOTP{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(otp).Error
+	})
+	return translateError(err)
+}
+
+// GetPending retrieves the code pending for the user and purpose
+func (r *phoneOTPRepository) GetPending(ctx context.Context, userID string, purpose entity.OTPPurpose) (*entity.PhoneOTP, error) {
+	var otp entity.PhoneOTP
+	err := database.Conn(ctx, r.db).Where("user_id = ? AND purpose = ?", userID, purpose).First(&otp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domainerror.ErrOTPNotFound
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &otp, nil
+}
+
+// ConsumeAttempt counts one verification attempt in a single conditional update,
+// so concurrent guesses cannot exceed maxAttempts
+func (r *phoneOTPRepository) ConsumeAttempt(ctx context.Context, id string, maxAttempts int) error {
+	result := database.Conn(ctx, r.db).
+		Model(&entity.PhoneOTP{}).
+		Where("id = ? AND attempts < ?", id, maxAttempts).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domainerror.ErrOTPAttemptsExceeded
+	}
+	return nil
+}
+
+// Delete removes a code
+func (r *phoneOTPRepository) Delete(ctx context.Context, id string) error {
+	if err := database.Conn(ctx, r.db).Where("id = ?", id).Delete(&entity.PhoneOTP{}).Error; err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// DeleteByUser removes every code pending for the user
+func (r *phoneOTPRepository) DeleteByUser(ctx context.Context, userID string) (int, error) {
+	result := database.Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&entity.PhoneOTP{})
+	if result.Error != nil {
+		return 0, translateError(result.Error)
+	}
+	return int(result.RowsAffected), nil
+}
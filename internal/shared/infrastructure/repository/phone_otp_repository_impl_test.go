@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type PhoneOTPRepositoryTestSuite struct {
+	suite.Suite
+	mock  sqlmock.Sqlmock
+	repo  *phoneOTPRepository
+	ctx   context.Context
+	sqlDB *sql.DB
+}
+
+func (s *PhoneOTPRepositoryTestSuite) SetupTest() {
+	var err error
+	s.sqlDB, s.mock, err = sqlmock.New()
+	require.NoError(s.T(), err)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		Conn:       s.sqlDB,
+		DriverName: "postgres",
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(s.T(), err)
+
+	s.repo = &phoneOTPRepository{db: db}
+	s.ctx = context.Background()
+}
+
+func (s *PhoneOTPRepositoryTestSuite) TearDownTest() {
+	s.sqlDB.Close()
+}
+
+func TestPhoneOTPRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PhoneOTPRepositoryTestSuite))
+}
+
+func (s *PhoneOTPRepositoryTestSuite) TestCreate_ReplacesPendingCode() {
+	otp := entity.NewPhoneOTP("user-123", "phone-index-1", entity.OTPPurposeVerifyPhone, time.Minute)
+	otp.CodeHash = "hash"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM "phone_otps" WHERE user_id = $1 AND purpose = $2`)).
+		WithArgs("user-123", entity.OTPPurposeVerifyPhone).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO "phone_otps" ("id","user_id","phone_index","purpose","code_hash","attempts","expires_at","created_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`)).
+		WithArgs(otp.ID, "user-123", "phone-index-1", entity.OTPPurposeVerifyPhone, "hash", 0, otp.ExpiresAt, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.Create(s.ctx, otp)
+
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *PhoneOTPRepositoryTestSuite) TestCreate_Error() {
+	otp := entity.NewPhoneOTP("user-123", "phone-index-1", entity.OTPPurposeLogin, time.Minute)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "phone_otps"`)).
+		WillReturnError(errors.New("database error"))
+	s.mock.ExpectRollback()
+
+	err := s.repo.Create(s.ctx, otp)
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrInternalServer)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *PhoneOTPRepositoryTestSuite) TestGetPending_Success() {
+	rows := sqlmock.NewRows([]string{"id", "user_id", "phone_index", "purpose", "code_hash", "attempts"}).
+		AddRow("otp-123", "user-123", "phone-index-1", "login", "hash", 2)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "phone_otps" WHERE user_id = $1 AND purpose = $2 ORDER BY "phone_otps"."id" LIMIT $3`)).
+		WithArgs("user-123", entity.OTPPurposeLogin, 1).
+		WillReturnRows(rows)
+
+	otp, err := s.repo.GetPending(s.ctx, "user-123", entity.OTPPurposeLogin)
+
+	assert.NoError(s.T(), err)
+	require.NotNil(s.T(), otp)
+	assert.Equal(s.T(), "otp-123", otp.ID)
+	assert.Equal(s.T(), 2, otp.Attempts)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *PhoneOTPRepositoryTestSuite) TestGetPending_NotFound() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "phone_otps"`)).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	otp, err := s.repo.GetPending(s.ctx, "user-123", entity.OTPPurposeLogin)
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrOTPNotFound)
+	assert.Nil(s.T(), otp)
+}
+
+func (s *PhoneOTPRepositoryTestSuite) TestConsumeAttempt_Success() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "phone_otps" SET "attempts"=attempts + 1 WHERE id = $1 AND attempts < $2`)).
+		WithArgs("otp-123", 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.ConsumeAttempt(s.ctx, "otp-123", 5)
+
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *PhoneOTPRepositoryTestSuite) TestConsumeAttempt_Exceeded() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "phone_otps" SET "attempts"=attempts + 1`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	err := s.repo.ConsumeAttempt(s.ctx, "otp-123", 5)
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrOTPAttemptsExceeded)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *PhoneOTPRepositoryTestSuite) TestDelete_Success() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM "phone_otps" WHERE id = $1`)).
+		WithArgs("otp-123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.Delete(s.ctx, "otp-123")
+
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *PhoneOTPRepositoryTestSuite) TestDeleteByUser_Success() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM "phone_otps" WHERE user_id = $1`)).
+		WithArgs("user-123").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	s.mock.ExpectCommit()
+
+	deleted, err := s.repo.DeleteByUser(s.ctx, "user-123")
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, deleted)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
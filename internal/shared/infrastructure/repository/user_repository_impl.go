@@ -2,9 +2,15 @@ package repository
 
 import (
 	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
 	"app/internal/shared/domain/repository"
+	"app/internal/shared/infrastructure/database"
 	"app/pkg"
 	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -21,67 +27,281 @@ func NewUserRepository(db *gorm.DB) repository.UserRepository {
 
 // Create creates a new user
 func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
-	result := r.db.WithContext(ctx).Create(user)
+	result := database.Conn(ctx, r.db).Create(user)
 	if result.Error != nil {
-		return result.Error
+		return translateError(result.Error)
 	}
 	return nil
 }
 
-// GetByID retrieves a user by ID (UUID string)
-func (r *userRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
+// GetByID retrieves a user by ID (UUID string), optionally selecting only the given columns
+func (r *userRepository) GetByID(ctx context.Context, id string, fields ...string) (*entity.User, error) {
 	var user entity.User
-	result := r.db.WithContext(ctx).Where("id = ?", id).First(&user)
+	query := database.Conn(ctx, r.db)
+	if len(fields) > 0 {
+		query = query.Select(fields)
+	}
+	result := query.Where("id = ?", id).First(&user)
 	if result.Error != nil {
-		return nil, result.Error
+		return nil, translateError(result.Error)
+	}
+	return &user, nil
+}
+
+// GetByIDWithDeleted retrieves a user by ID, soft-deleted ones included
+func (r *userRepository) GetByIDWithDeleted(ctx context.Context, id string) (*entity.User, error) {
+	var user entity.User
+	if err := database.Conn(ctx, r.db).Unscoped().Where("id = ?", id).First(&user).Error; err != nil {
+		return nil, translateError(err)
 	}
 	return &user, nil
 }
 
-// GetByEmail retrieves a user by email
+// GetByEmail retrieves a user by email, ignoring case
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var user entity.User
-	result := r.db.WithContext(ctx).Where("email = ?", email).First(&user)
+	result := database.Conn(ctx, r.db).Where("email_normalized = ?", entity.NormalizeEmail(email)).First(&user)
 	if result.Error != nil {
-		return nil, result.Error
+		return nil, translateError(result.Error)
 	}
 	return &user, nil
 }
 
-// GetByUsername retrieves a user by username
+// GetByUsername retrieves a user by username, ignoring case
 func (r *userRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
 	var user entity.User
-	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
-		return nil, err
+	if err := database.Conn(ctx, r.db).Where("username_normalized = ?", entity.NormalizeUsername(username)).First(&user).Error; err != nil {
+		return nil, translateError(err)
 	}
 	return &user, nil
 }
 
-// Update updates a user
-func (r *userRepository) Update(ctx context.Context, filter entity.FilterUser, user *entity.User) error {
-	result := r.db.WithContext(ctx).Where("deleted_at IS NULL AND id = ?", filter.ID).Updates(user)
+// GetByPhone retrieves the user whose verified phone matches, unverified numbers are ignored.
+// Phones are encrypted, so they are matched by their blind index
+func (r *userRepository) GetByPhone(ctx context.Context, phone string) (*entity.User, error) {
+	var user entity.User
+	query := database.Conn(ctx, r.db).Where("phone_index = ? AND phone_verified_at IS NOT NULL", database.BlindIndex("phone", phone))
+	if err := query.First(&user).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+// IsUsernameTaken reports whether another live user has a username with the same
+// skeleton, or still holds one as a former username
+func (r *userRepository) IsUsernameTaken(ctx context.Context, username, exceptUserID string) (bool, error) {
+	skeleton := entity.UsernameSkeleton(username)
+
+	var taken bool
+	err := database.Conn(ctx, r.db).Raw(
+		`SELECT EXISTS (SELECT 1 FROM users WHERE username_skeleton = ? AND id <> ? AND deleted_at IS NULL)
+		     OR EXISTS (SELECT 1 FROM username_history WHERE username_skeleton = ? AND user_id <> ? AND held_until > ?)`,
+		skeleton, exceptUserID, skeleton, exceptUserID, time.Now(),
+	).Row().Scan(&taken)
+	if err != nil {
+		return false, translateError(err)
+	}
+	return taken, nil
+}
+
+// Update updates a user. Without fields only non-zero values are written, with fields
+// exactly those columns are, zero values included. When filter.Version is set the update
+// only applies while the row still has that version and bumps it, returning
+// ErrVersionConflict otherwise
+func (r *userRepository) Update(ctx context.Context, filter entity.FilterUser, user *entity.User, fields ...string) error {
+	query := database.Conn(ctx, r.db).Where("deleted_at IS NULL AND id = ?", filter.ID)
+	if filter.Version > 0 {
+		query = query.Where("version = ?", filter.Version)
+		user.Version = filter.Version + 1
+	}
+	if len(fields) > 0 {
+		columns := append(fields[:len(fields):len(fields)], "updated_at")
+		if filter.Version > 0 {
+			columns = append(columns, "version")
+		}
+		query = query.Select(columns)
+	}
+
+	result := query.Updates(user)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if filter.Version > 0 && result.RowsAffected == 0 {
+		return domainerror.ErrVersionConflict
+	}
+	return nil
+}
+
+// UpdatePassword swaps the password hash of a user for a new one, provided nobody
+// changed it since it was read
+func (r *userRepository) UpdatePassword(ctx context.Context, id, currentHash, newHash string) error {
+	result := database.Conn(ctx, r.db).Model(&entity.User{}).
+		Where("id = ? AND password = ?", id, currentHash).
+		UpdateColumn("password", newHash)
 	if result.Error != nil {
-		return result.Error
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domainerror.ErrUserNotFound
 	}
 	return nil
 }
 
 // Delete deletes a user (soft delete)
 func (r *userRepository) Delete(ctx context.Context, id string) error {
-	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&entity.User{}).Error; err != nil {
-		return err
+	if err := database.Conn(ctx, r.db).Where("id = ?", id).Delete(&entity.User{}).Error; err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// GetDeletedByEmail retrieves a soft-deleted user by email, ignoring case
+func (r *userRepository) GetDeletedByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return r.getDeleted(ctx, "email_normalized = ?", entity.NormalizeEmail(email))
+}
+
+// GetDeletedByUsername retrieves a soft-deleted user by username, ignoring case
+func (r *userRepository) GetDeletedByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return r.getDeleted(ctx, "username_normalized = ?", entity.NormalizeUsername(username))
+}
+
+// getDeleted retrieves the soft-deleted user matching the condition
+func (r *userRepository) getDeleted(ctx context.Context, query string, args ...any) (*entity.User, error) {
+	var user entity.User
+	// Once identities are released several deleted users may share one, the
+	// most recently deleted is the one that can still be reactivated
+	err := database.Conn(ctx, r.db).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Where(query, args...).
+		Order("deleted_at DESC, id").
+		Take(&user).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+// ListDeleted retrieves soft-deleted users deleted before the given time, skipping anonymized ones
+func (r *userRepository) ListDeleted(ctx context.Context, before time.Time, offset, limit int) ([]*entity.User, error) {
+	var users []*entity.User
+	err := database.Conn(ctx, r.db).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ? AND status <> ?", before, entity.UserStatusAnonymized).
+		Order("deleted_at, id").
+		Offset(offset).
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return users, nil
+}
+
+// ListAnonymized retrieves anonymized users deleted before the given time
+func (r *userRepository) ListAnonymized(ctx context.Context, before time.Time, offset, limit int) ([]*entity.User, error) {
+	var users []*entity.User
+	err := database.Conn(ctx, r.db).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ? AND status = ?", before, entity.UserStatusAnonymized).
+		Order("deleted_at, id").
+		Offset(offset).
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return users, nil
+}
+
+// UpdateDeleted writes the given columns of a soft-deleted user, zero values included
+func (r *userRepository) UpdateDeleted(ctx context.Context, user *entity.User, fields ...string) error {
+	result := database.Conn(ctx, r.db).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Select(append(fields[:len(fields):len(fields)], "updated_at")).
+		Updates(user)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domainerror.ErrUserNotFound
+	}
+	return nil
+}
+
+// Restore undoes the soft delete of a user
+func (r *userRepository) Restore(ctx context.Context, id string) error {
+	result := database.Conn(ctx, r.db).Unscoped().Model(&entity.User{}).
+		Where("deleted_at IS NOT NULL AND id = ?", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domainerror.ErrUserNotFound
+	}
+	return nil
+}
+
+// Purge deletes a user permanently
+func (r *userRepository) Purge(ctx context.Context, id string) error {
+	if err := database.Conn(ctx, r.db).Unscoped().Where("id = ?", id).Delete(&entity.User{}).Error; err != nil {
+		return translateError(err)
 	}
 	return nil
 }
 
+// estimateThreshold is the row estimate below which an exact count is cheap enough to run instead
+const estimateThreshold = 100000
+
+// userWithTotal is a user row carrying the window count of all matching rows
+type userWithTotal struct {
+	entity.User
+	TotalCount int64 `gorm:"column:total_count"`
+}
+
 // List retrieves a list of users with pagination and filtering
-func (r *userRepository) List(ctx context.Context, filter entity.FilterUser) ([]*entity.User, int, error) {
-	// Build scopes for dynamic query construction
-	scopes := []func(db *gorm.DB) *gorm.DB{
-		// Soft delete filter - only get non-deleted records
-		func(db *gorm.DB) *gorm.DB {
-			return db.Where("deleted_at IS NULL")
-		},
+func (r *userRepository) List(ctx context.Context, filter entity.FilterUser) ([]*entity.User, entity.Total, error) {
+	scopes, unfiltered := userScopes(filter)
+
+	switch filter.Count {
+	case entity.CountNone:
+		users, err := r.find(ctx, filter, scopes)
+		if err != nil {
+			return nil, entity.Total{}, translateError(err)
+		}
+		return users, entity.Total{Count: entity.TotalUnknown}, nil
+	case entity.CountEstimated:
+		estimate, err := r.estimate(ctx, unfiltered, scopes)
+		if err != nil || estimate < estimateThreshold {
+			// Small tables, missing or skewed statistics - an exact count is cheap enough or needed
+			users, total, err := r.listWithTotal(ctx, filter, scopes)
+			return users, entity.Total{Count: total}, translateError(err)
+		}
+		users, err := r.find(ctx, filter, scopes)
+		if err != nil {
+			return nil, entity.Total{}, translateError(err)
+		}
+		return users, entity.Total{Count: estimate, Estimated: true}, nil
+	default:
+		users, total, err := r.listWithTotal(ctx, filter, scopes)
+		return users, entity.Total{Count: total}, translateError(err)
+	}
+}
+
+// liveUsers is the soft delete filter - only get non-deleted records
+func liveUsers(db *gorm.DB) *gorm.DB {
+	return db.Where("deleted_at IS NULL")
+}
+
+// deletedUsers only gets the soft-deleted records, including anonymized accounts
+func deletedUsers(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Where("deleted_at IS NOT NULL")
+}
+
+// userScopes builds the filter scopes for dynamic query construction. unfiltered
+// reports that they select every live user, so table statistics can stand for a count
+func userScopes(filter entity.FilterUser) (scopes []func(db *gorm.DB) *gorm.DB, unfiltered bool) {
+	base := liveUsers
+	if filter.Deleted {
+		base = deletedUsers
 	}
 
 	// Basic field filters
@@ -116,10 +336,11 @@ func (r *userRepository) List(ctx context.Context, filter entity.FilterUser) ([]
 		})
 	}
 
-	// Extended filters (add these columns to your User entity if needed)
+	// Extended filters (add these columns to your User entity if needed). Encrypted
+	// columns are matched by their blind index
 	if filter.Phone != nil {
 		scopes = append(scopes, func(db *gorm.DB) *gorm.DB {
-			return db.Where("phone = ?", *filter.Phone)
+			return db.Where("phone_index = ?", database.BlindIndex("phone", *filter.Phone))
 		})
 	}
 	if filter.Status != "" {
@@ -129,7 +350,7 @@ func (r *userRepository) List(ctx context.Context, filter entity.FilterUser) ([]
 	}
 	if filter.BirthDate != nil {
 		scopes = append(scopes, func(db *gorm.DB) *gorm.DB {
-			return db.Where("birth_date = ?", filter.BirthDate)
+			return db.Where("birth_date_index = ?", database.BlindIndex("birth_date", *filter.BirthDate))
 		})
 	}
 	if filter.Gender != "" {
@@ -160,21 +381,139 @@ func (r *userRepository) List(ctx context.Context, filter entity.FilterUser) ([]
 		})
 	}
 
-	// Query with pagination and filters
+	unfiltered = len(scopes) == 0 && !filter.Deleted
+	return append([]func(db *gorm.DB) *gorm.DB{base}, scopes...), unfiltered
+}
+
+// find retrieves a page of users without counting
+func (r *userRepository) find(ctx context.Context, filter entity.FilterUser, scopes []func(db *gorm.DB) *gorm.DB) ([]*entity.User, error) {
+	// Sparse fieldset - only select the requested columns
+	query := database.Conn(ctx, r.db)
+	if len(filter.Fields) > 0 {
+		query = query.Select(filter.Fields)
+	}
+
 	var users []*entity.User
-	err := r.db.WithContext(ctx).
+	err := query.
 		Scopes(pkg.Paginate(filter.Offset, filter.PerPage, r.db)).
 		Scopes(scopes...).
 		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// listWithTotal retrieves a page of users and the exact total in a single query using COUNT(*) OVER()
+func (r *userRepository) listWithTotal(ctx context.Context, filter entity.FilterUser, scopes []func(db *gorm.DB) *gorm.DB) ([]*entity.User, int, error) {
+	columns := "*"
+	if len(filter.Fields) > 0 {
+		quoted := make([]string, 0, len(filter.Fields))
+		for _, field := range filter.Fields {
+			quoted = append(quoted, r.db.Statement.Quote(field))
+		}
+		columns = strings.Join(quoted, ",")
+	}
+
+	var rows []*userWithTotal
+	err := database.Conn(ctx, r.db).
+		Model(&entity.User{}).
+		Select(columns + ", COUNT(*) OVER() AS total_count").
+		Scopes(pkg.Paginate(filter.Offset, filter.PerPage, r.db)).
+		Scopes(scopes...).
+		Find(&rows).Error
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// Get total count for pagination
+	// A page past the end carries no window count, fall back to a separate count
+	if len(rows) == 0 {
+		if filter.Offset == 0 {
+			return []*entity.User{}, 0, nil
+		}
+		total, err := r.count(ctx, scopes)
+		if err != nil {
+			return nil, 0, err
+		}
+		return []*entity.User{}, total, nil
+	}
+
+	users := make([]*entity.User, 0, len(rows))
+	for _, row := range rows {
+		user := row.User
+		users = append(users, &user)
+	}
+	return users, int(rows[0].TotalCount), nil
+}
+
+// count returns the exact number of users matching the scopes
+func (r *userRepository) count(ctx context.Context, scopes []func(db *gorm.DB) *gorm.DB) (int, error) {
 	var totalRows int64
-	if err := r.db.WithContext(ctx).Model(&entity.User{}).Scopes(scopes...).Count(&totalRows).Error; err != nil {
-		return nil, 0, err
+	if err := database.Conn(ctx, r.db).Model(&entity.User{}).Scopes(scopes...).Count(&totalRows).Error; err != nil {
+		return 0, err
+	}
+	return int(totalRows), nil
+}
+
+// deletedTolerance is the share of soft-deleted rows table statistics may count
+// before they overstate the live users too much to stand for a count
+const deletedTolerance = 0.01
+
+// estimate returns the planner's row estimate for the scopes, reading pg_class.reltuples
+// when the query is unfiltered and the EXPLAIN output otherwise
+func (r *userRepository) estimate(ctx context.Context, unfiltered bool, scopes []func(db *gorm.DB) *gorm.DB) (int, error) {
+	if !unfiltered {
+		return r.planRows(ctx, scopes)
 	}
 
-	return users, int(totalRows), nil
+	var reltuples float64
+	err := database.Conn(ctx, r.db).
+		Raw("SELECT reltuples FROM pg_class WHERE oid = ?::regclass", entity.User{}.TableName()).
+		Row().Scan(&reltuples)
+	if err != nil {
+		return 0, err
+	}
+	if reltuples < 0 {
+		// Never analyzed
+		return 0, errors.New("table statistics unavailable")
+	}
+
+	// Table statistics count soft-deleted rows too, which the list leaves out
+	deleted, err := r.planRows(ctx, []func(db *gorm.DB) *gorm.DB{deletedUsers})
+	if err != nil {
+		return 0, err
+	}
+	if float64(deleted) > reltuples*deletedTolerance {
+		return 0, errors.New("table statistics count too many deleted rows")
+	}
+	return int(reltuples) - deleted, nil
+}
+
+// planRows returns the rows the planner expects the scopes to select
+func (r *userRepository) planRows(ctx context.Context, scopes []func(db *gorm.DB) *gorm.DB) (int, error) {
+	stmt := r.db.Session(&gorm.Session{DryRun: true}).
+		Model(&entity.User{}).
+		Scopes(scopes...).
+		Find(&[]*entity.User{}).Statement
+
+	var plan string
+	err := database.Conn(ctx, r.db).
+		Raw("EXPLAIN (FORMAT JSON) "+stmt.SQL.String(), stmt.Vars...).
+		Row().Scan(&plan)
+	if err != nil {
+		return 0, err
+	}
+
+	var explain []struct {
+		Plan struct {
+			PlanRows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explain); err != nil {
+		return 0, err
+	}
+	if len(explain) == 0 {
+		return 0, errors.New("empty query plan")
+	}
+	return int(explain[0].Plan.PlanRows), nil
 }
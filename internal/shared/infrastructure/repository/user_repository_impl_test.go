@@ -2,13 +2,21 @@ package repository
 
 import (
 	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/infrastructure/database"
+	"app/pkg/crypto"
+	"bytes"
 	"context"
 	"database/sql"
+	"errors"
+	"net"
+	"os"
 	"regexp"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -19,11 +27,11 @@ import (
 
 type UserRepositoryTestSuite struct {
 	suite.Suite
-	db      *gorm.DB
-	mock    sqlmock.Sqlmock
-	repo    *userRepository
-	ctx     context.Context
-	sqlDB   *sql.DB
+	db    *gorm.DB
+	mock  sqlmock.Sqlmock
+	repo  *userRepository
+	ctx   context.Context
+	sqlDB *sql.DB
 }
 
 func (s *UserRepositoryTestSuite) SetupTest() {
@@ -40,6 +48,7 @@ func (s *UserRepositoryTestSuite) SetupTest() {
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	require.NoError(s.T(), err)
+	require.NoError(s.T(), database.EnableEncryption(s.db, testKeyring(s.T())))
 
 	s.repo = &userRepository{db: s.db}
 	s.ctx = context.Background()
@@ -49,6 +58,13 @@ func (s *UserRepositoryTestSuite) TearDownTest() {
 	s.sqlDB.Close()
 }
 
+// testKeyring returns a field encryption keyring with fixed keys
+func testKeyring(t *testing.T) *crypto.Keyring {
+	keyring, err := crypto.NewKeyring(map[string][]byte{"1": bytes.Repeat([]byte{1}, 32)}, "1", bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+	return keyring
+}
+
 func TestUserRepositoryTestSuite(t *testing.T) {
 	suite.Run(t, new(UserRepositoryTestSuite))
 }
@@ -66,21 +82,30 @@ func (s *UserRepositoryTestSuite) TestCreate_Success() {
 
 	s.mock.ExpectBegin()
 	s.mock.ExpectExec(regexp.QuoteMeta(
-		`INSERT INTO "users" ("id","email","username","password","first_name","last_name","phone","status","birth_date","gender","role","provider","is_active","created_at","updated_at","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)`)).
+		`INSERT INTO "users" ("id","email","username","email_normalized","username_normalized","username_skeleton","password","first_name","last_name","phone","phone_index","phone_verified_at","status","birth_date","birth_date_index","gender","role","provider","is_active","two_factor_enabled","version","token_version","created_at","updated_at","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25)`)).
 		WithArgs(
 			user.ID,
 			user.Email,
 			user.Username,
+			"test@example.com", // email_normalized (set by BeforeCreate)
+			"testuser",         // username_normalized (set by BeforeCreate)
+			"testuser",         // username_skeleton (set by BeforeCreate)
 			user.Password,
 			user.FirstName,
 			user.LastName,
 			nil,      // phone
+			nil,      // phone_index
+			nil,      // phone_verified_at
 			"active", // status (default value)
 			nil,      // birth_date
+			nil,      // birth_date_index
 			"",       // gender
 			"user",   // role (default value)
 			"",       // provider
 			user.IsActive,
+			false, // two_factor_enabled (default value)
+			1,     // version (default value)
+			0,     // token_version
 			sqlmock.AnyArg(),
 			sqlmock.AnyArg(),
 			nil,
@@ -113,7 +138,111 @@ func (s *UserRepositoryTestSuite) TestCreate_Error() {
 
 	err := s.repo.Create(s.ctx, user)
 
-	assert.Error(s.T(), err)
+	assert.ErrorIs(s.T(), err, domainerror.ErrServiceUnavailable)
+}
+
+func (s *UserRepositoryTestSuite) TestCreate_UniqueViolation() {
+	user := &entity.User{
+		ID:        "user-123",
+		Email:     "test@example.com",
+		Username:  "testuser",
+		Password:  "hashedpassword",
+		FirstName: "Test",
+		LastName:  "User",
+		IsActive:  true,
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO "users"`)).
+		WillReturnError(&pgconn.PgError{
+			Code:           "23505",
+			ConstraintName: "idx_users_username",
+			Detail:         "Key (username)=(testuser) already exists.",
+		})
+	s.mock.ExpectRollback()
+
+	err := s.repo.Create(s.ctx, user)
+
+	var conflict *domainerror.ConflictError
+	require.ErrorAs(s.T(), err, &conflict)
+	assert.Equal(s.T(), "username", conflict.Field)
+	assert.ErrorIs(s.T(), err, domainerror.ErrUserAlreadyExists)
+}
+
+func (s *UserRepositoryTestSuite) TestCreate_CanonicalEmailConflict() {
+	user := &entity.User{
+		ID:       "user-123",
+		Email:    "Bob@X.com",
+		Username: "bob",
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO "users"`)).
+		WillReturnError(&pgconn.PgError{
+			Code:           "23505",
+			ConstraintName: "idx_users_email_normalized",
+			Detail:         "Key (email_normalized)=(bob@x.com) already exists.",
+		})
+	s.mock.ExpectRollback()
+
+	err := s.repo.Create(s.ctx, user)
+
+	// Reported as the email it was derived from
+	var conflict *domainerror.ConflictError
+	require.ErrorAs(s.T(), err, &conflict)
+	assert.Equal(s.T(), "email", conflict.Field)
+}
+
+func (s *UserRepositoryTestSuite) TestCreate_UniqueViolationWithoutDetail() {
+	user := &entity.User{
+		ID:       "user-123",
+		Email:    "test@example.com",
+		Username: "testuser",
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO "users"`)).
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"})
+	s.mock.ExpectRollback()
+
+	err := s.repo.Create(s.ctx, user)
+
+	var conflict *domainerror.ConflictError
+	require.ErrorAs(s.T(), err, &conflict)
+	assert.Equal(s.T(), "email", conflict.Field)
+}
+
+func (s *UserRepositoryTestSuite) TestCreate_ConcurrentRegistrationConflict() {
+	user := entity.NewUser("test@example.com", "TestUser", "hashedpassword", "Test", "User")
+	transactor := database.NewTransactor(s.db)
+
+	// The lookalike check passes, then a registration committed meanwhile trips
+	// the unique index and the whole unit of work is rolled back
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS`)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	s.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "users"`)).
+		WillReturnError(&pgconn.PgError{
+			Code:           "23505",
+			ConstraintName: "idx_users_username_normalized",
+			Detail:         "Key (username_normalized)=(testuser) already exists.",
+		})
+	s.mock.ExpectRollback()
+
+	err := transactor.WithinTransaction(s.ctx, func(ctx context.Context) error {
+		if _, err := s.repo.IsUsernameTaken(ctx, user.Username, user.ID); err != nil {
+			return err
+		}
+		return s.repo.Create(ctx, user)
+	})
+
+	var conflict *domainerror.ConflictError
+	require.ErrorAs(s.T(), err, &conflict)
+	assert.Equal(s.T(), &domainerror.ConflictError{Field: "username"}, conflict)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
 }
 
 func (s *UserRepositoryTestSuite) TestGetByID_Success() {
@@ -147,7 +276,36 @@ func (s *UserRepositoryTestSuite) TestGetByID_NotFound() {
 
 	user, err := s.repo.GetByID(s.ctx, userID)
 
-	assert.Error(s.T(), err)
+	assert.ErrorIs(s.T(), err, domainerror.ErrUserNotFound)
+	assert.Nil(s.T(), user)
+}
+
+func (s *UserRepositoryTestSuite) TestGetByID_ConnectionError() {
+	userID := "user-123"
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "users" WHERE id = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
+		WithArgs(userID, 1).
+		WillReturnError(&net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")})
+
+	user, err := s.repo.GetByID(s.ctx, userID)
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrServiceUnavailable)
+	assert.NotErrorIs(s.T(), err, domainerror.ErrUserNotFound)
+	assert.Nil(s.T(), user)
+}
+
+func (s *UserRepositoryTestSuite) TestGetByID_QueryError() {
+	userID := "user-123"
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "users" WHERE id = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
+		WithArgs(userID, 1).
+		WillReturnError(&pgconn.PgError{Code: "42703", Message: "column does not exist"})
+
+	user, err := s.repo.GetByID(s.ctx, userID)
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrInternalServer)
 	assert.Nil(s.T(), user)
 }
 
@@ -159,7 +317,7 @@ func (s *UserRepositoryTestSuite) TestGetByEmail_Success() {
 		AddRow("user-123", email, "testuser", "hashedpassword", "Test", "User", true, now, now, nil)
 
 	s.mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT * FROM "users" WHERE email = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
+		`SELECT * FROM "users" WHERE email_normalized = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
 		WithArgs(email, 1).
 		WillReturnRows(rows)
 
@@ -171,17 +329,35 @@ func (s *UserRepositoryTestSuite) TestGetByEmail_Success() {
 	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
 }
 
+func (s *UserRepositoryTestSuite) TestGetByEmail_IgnoresCase() {
+	rows := sqlmock.NewRows([]string{"id", "email"}).
+		AddRow("user-123", "Test@Example.com")
+
+	// Looked up by the normalized email
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "users" WHERE email_normalized = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
+		WithArgs("test@example.com", 1).
+		WillReturnRows(rows)
+
+	user, err := s.repo.GetByEmail(s.ctx, " TEST@example.COM ")
+
+	assert.NoError(s.T(), err)
+	require.NotNil(s.T(), user)
+	assert.Equal(s.T(), "user-123", user.ID)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
 func (s *UserRepositoryTestSuite) TestGetByEmail_NotFound() {
 	email := "nonexistent@example.com"
 
 	s.mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT * FROM "users" WHERE email = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
+		`SELECT * FROM "users" WHERE email_normalized = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
 		WithArgs(email, 1).
 		WillReturnError(gorm.ErrRecordNotFound)
 
 	user, err := s.repo.GetByEmail(s.ctx, email)
 
-	assert.Error(s.T(), err)
+	assert.ErrorIs(s.T(), err, domainerror.ErrUserNotFound)
 	assert.Nil(s.T(), user)
 }
 
@@ -193,7 +369,7 @@ func (s *UserRepositoryTestSuite) TestGetByUsername_Success() {
 		AddRow("user-123", "test@example.com", username, "hashedpassword", "Test", "User", true, now, now, nil)
 
 	s.mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT * FROM "users" WHERE username = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
+		`SELECT * FROM "users" WHERE username_normalized = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
 		WithArgs(username, 1).
 		WillReturnRows(rows)
 
@@ -209,16 +385,72 @@ func (s *UserRepositoryTestSuite) TestGetByUsername_NotFound() {
 	username := "nonexistentuser"
 
 	s.mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT * FROM "users" WHERE username = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
+		`SELECT * FROM "users" WHERE username_normalized = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
 		WithArgs(username, 1).
 		WillReturnError(gorm.ErrRecordNotFound)
 
 	user, err := s.repo.GetByUsername(s.ctx, username)
 
-	assert.Error(s.T(), err)
+	assert.ErrorIs(s.T(), err, domainerror.ErrUserNotFound)
+	assert.Nil(s.T(), user)
+}
+
+func (s *UserRepositoryTestSuite) TestGetByPhone_Success() {
+	phone := "+6281234567890"
+
+	rows := sqlmock.NewRows([]string{"id", "email", "username", "phone", "phone_verified_at"}).
+		AddRow("user-123", "test@example.com", "testuser", phone, time.Now())
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "users" WHERE (phone_index = $1 AND phone_verified_at IS NOT NULL) AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
+		WithArgs(database.BlindIndex("phone", phone), 1).
+		WillReturnRows(rows)
+
+	user, err := s.repo.GetByPhone(s.ctx, phone)
+
+	assert.NoError(s.T(), err)
+	require.NotNil(s.T(), user)
+	assert.Equal(s.T(), "user-123", user.ID)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestGetByPhone_NotFound() {
+	phone := "+6281234567890"
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "users" WHERE (phone_index = $1 AND phone_verified_at IS NOT NULL) AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
+		WithArgs(database.BlindIndex("phone", phone), 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	user, err := s.repo.GetByPhone(s.ctx, phone)
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrUserNotFound)
 	assert.Nil(s.T(), user)
 }
 
+func (s *UserRepositoryTestSuite) TestIsUsernameTaken() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT EXISTS (SELECT 1 FROM users WHERE username_skeleton = $1 AND id <> $2 AND deleted_at IS NULL)`)).
+		WithArgs("admin", "user-123", "admin", "user-123", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	taken, err := s.repo.IsUsernameTaken(s.ctx, "\u0410dmin", "user-123")
+
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), taken)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestIsUsernameTaken_Error() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS`)).
+		WillReturnError(sql.ErrConnDone)
+
+	taken, err := s.repo.IsUsernameTaken(s.ctx, "testuser", "")
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrServiceUnavailable)
+	assert.False(s.T(), taken)
+}
+
 func (s *UserRepositoryTestSuite) TestUpdate_Success() {
 	user := &entity.User{
 		ID:        "user-123",
@@ -271,6 +503,83 @@ func (s *UserRepositoryTestSuite) TestUpdate_Error() {
 	assert.Error(s.T(), err)
 }
 
+func (s *UserRepositoryTestSuite) TestUpdate_Versioned() {
+	user := &entity.User{
+		ID:        "user-123",
+		FirstName: "Updated",
+		Version:   3,
+	}
+
+	filter := entity.FilterUser{
+		ID:      "user-123",
+		Version: 3,
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "users" SET "first_name"=$1,"version"=$2,"updated_at"=$3 WHERE (deleted_at IS NULL AND id = $4) AND version = $5 AND "users"."deleted_at" IS NULL AND "id" = $6`)).
+		WithArgs(user.FirstName, 4, sqlmock.AnyArg(), filter.ID, 3, user.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.Update(s.ctx, filter, user)
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 4, user.Version)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestUpdate_WritesZeroValues() {
+	user := &entity.User{
+		ID:        "user-123",
+		FirstName: "Updated",
+		Version:   3,
+	}
+
+	filter := entity.FilterUser{
+		ID:      "user-123",
+		Version: 3,
+	}
+
+	// Phone and gender were cleared - they are written even though they are zero,
+	// and so is the blind index of the phone
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "users" SET "first_name"=$1,"phone"=$2,"phone_index"=$3,"gender"=$4,"version"=$5,"updated_at"=$6 WHERE (deleted_at IS NULL AND id = $7) AND version = $8 AND "users"."deleted_at" IS NULL AND "id" = $9`)).
+		WithArgs(user.FirstName, nil, nil, "", 4, sqlmock.AnyArg(), filter.ID, 3, user.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.Update(s.ctx, filter, user, "first_name", "phone", "gender")
+
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestUpdate_VersionConflict() {
+	user := &entity.User{
+		ID:        "user-123",
+		FirstName: "Updated",
+		Version:   3,
+	}
+
+	filter := entity.FilterUser{
+		ID:      "user-123",
+		Version: 3,
+	}
+
+	// Another writer already bumped the version - nothing matches
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	err := s.repo.Update(s.ctx, filter, user)
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrVersionConflict)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
 func (s *UserRepositoryTestSuite) TestDelete_Success() {
 	userID := "user-123"
 
@@ -302,6 +611,34 @@ func (s *UserRepositoryTestSuite) TestDelete_Error() {
 	assert.Error(s.T(), err)
 }
 
+func (s *UserRepositoryTestSuite) TestDelete_WithinTransaction() {
+	userID := "user-123"
+	transactor := database.NewTransactor(s.db)
+
+	// Both statements share the transaction from the context instead of opening their own
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "users" SET "deleted_at"=$1 WHERE id = $2 AND "users"."deleted_at" IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), userID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "users" WHERE id = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
+		WithArgs(userID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.mock.ExpectRollback()
+
+	err := transactor.WithinTransaction(s.ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, userID); err != nil {
+			return err
+		}
+		_, err := s.repo.GetByID(ctx, userID)
+		return err
+	})
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrUserNotFound)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
 func (s *UserRepositoryTestSuite) TestList_Success() {
 	now := time.Now()
 	filter := entity.FilterUser{
@@ -309,25 +646,21 @@ func (s *UserRepositoryTestSuite) TestList_Success() {
 		PerPage: 10,
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "email", "username", "password", "first_name", "last_name", "is_active", "created_at", "updated_at", "deleted_at"}).
-		AddRow("user-1", "user1@example.com", "user1", "hashedpassword", "User", "One", true, now, now, nil).
-		AddRow("user-2", "user2@example.com", "user2", "hashedpassword", "User", "Two", true, now, now, nil)
+	rows := sqlmock.NewRows([]string{"id", "email", "username", "password", "first_name", "last_name", "is_active", "created_at", "updated_at", "deleted_at", "total_count"}).
+		AddRow("user-1", "user1@example.com", "user1", "hashedpassword", "User", "One", true, now, now, nil, 7).
+		AddRow("user-2", "user2@example.com", "user2", "hashedpassword", "User", "Two", true, now, now, nil, 7)
 
 	s.mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT * FROM "users" WHERE deleted_at IS NULL AND "users"."deleted_at" IS NULL ORDER BY created_at DESC LIMIT $1 OFFSET $2`)).
+		`SELECT *, COUNT(*) OVER() AS total_count FROM "users" WHERE deleted_at IS NULL AND "users"."deleted_at" IS NULL ORDER BY created_at DESC LIMIT $1 OFFSET $2`)).
 		WithArgs(filter.PerPage, filter.Offset).
 		WillReturnRows(rows)
 
-	countRows := sqlmock.NewRows([]string{"count"}).AddRow(2)
-	s.mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT count(*) FROM "users" WHERE deleted_at IS NULL AND "users"."deleted_at" IS NULL`)).
-		WillReturnRows(countRows)
-
 	users, totalRows, err := s.repo.List(s.ctx, filter)
 
 	assert.NoError(s.T(), err)
 	assert.Len(s.T(), users, 2)
-	assert.Equal(s.T(), 2, totalRows)
+	assert.Equal(s.T(), "user-2", users[1].ID)
+	assert.Equal(s.T(), entity.Total{Count: 7}, totalRows)
 	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
 }
 
@@ -337,15 +670,37 @@ func (s *UserRepositoryTestSuite) TestList_Empty() {
 		PerPage: 10,
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "email", "username", "password", "first_name", "last_name", "is_active", "created_at", "updated_at", "deleted_at"})
+	rows := sqlmock.NewRows([]string{"id", "email", "username", "password", "first_name", "last_name", "is_active", "created_at", "updated_at", "deleted_at", "total_count"})
 
 	// When offset is 0, GORM doesn't add OFFSET to the query
 	s.mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT * FROM "users" WHERE deleted_at IS NULL AND "users"."deleted_at" IS NULL ORDER BY created_at DESC LIMIT $1`)).
+		`SELECT *, COUNT(*) OVER() AS total_count FROM "users" WHERE deleted_at IS NULL AND "users"."deleted_at" IS NULL ORDER BY created_at DESC LIMIT $1`)).
 		WithArgs(filter.PerPage).
 		WillReturnRows(rows)
 
-	countRows := sqlmock.NewRows([]string{"count"}).AddRow(0)
+	users, totalRows, err := s.repo.List(s.ctx, filter)
+
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), users)
+	assert.Equal(s.T(), entity.Total{Count: 0}, totalRows)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestList_PastLastPage() {
+	filter := entity.FilterUser{
+		Offset:  20,
+		PerPage: 10,
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "total_count"})
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT *, COUNT(*) OVER() AS total_count FROM "users" WHERE deleted_at IS NULL AND "users"."deleted_at" IS NULL ORDER BY created_at DESC LIMIT $1 OFFSET $2`)).
+		WithArgs(filter.PerPage, filter.Offset).
+		WillReturnRows(rows)
+
+	// The window count is lost on an empty page, so a separate count runs
+	countRows := sqlmock.NewRows([]string{"count"}).AddRow(12)
 	s.mock.ExpectQuery(regexp.QuoteMeta(
 		`SELECT count(*) FROM "users" WHERE deleted_at IS NULL AND "users"."deleted_at" IS NULL`)).
 		WillReturnRows(countRows)
@@ -354,7 +709,222 @@ func (s *UserRepositoryTestSuite) TestList_Empty() {
 
 	assert.NoError(s.T(), err)
 	assert.Empty(s.T(), users)
-	assert.Equal(s.T(), 0, totalRows)
+	assert.Equal(s.T(), entity.Total{Count: 12}, totalRows)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestList_WithoutTotal() {
+	now := time.Now()
+	filter := entity.FilterUser{
+		Offset:  0,
+		PerPage: 10,
+		Count:   entity.CountNone,
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "email", "username", "password", "first_name", "last_name", "is_active", "created_at", "updated_at", "deleted_at"}).
+		AddRow("user-1", "user1@example.com", "user1", "hashedpassword", "User", "One", true, now, now, nil)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "users" WHERE deleted_at IS NULL AND "users"."deleted_at" IS NULL ORDER BY created_at DESC LIMIT $1`)).
+		WithArgs(filter.PerPage).
+		WillReturnRows(rows)
+
+	users, totalRows, err := s.repo.List(s.ctx, filter)
+
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), users, 1)
+	assert.Equal(s.T(), entity.Total{Count: entity.TotalUnknown}, totalRows)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestList_EstimatedUnfiltered() {
+	now := time.Now()
+	filter := entity.FilterUser{
+		Offset:  0,
+		PerPage: 10,
+		Count:   entity.CountEstimated,
+	}
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT reltuples FROM pg_class WHERE oid = $1::regclass`)).
+		WithArgs("users").
+		WillReturnRows(sqlmock.NewRows([]string{"reltuples"}).AddRow(250000.0))
+	// A handful of soft-deleted rows, left out of the estimate
+	s.mock.ExpectQuery(regexp.QuoteMeta(`EXPLAIN (FORMAT JSON) SELECT * FROM "users" WHERE deleted_at IS NOT NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).AddRow(`[{"Plan": {"Plan Rows": 120}}]`))
+
+	rows := sqlmock.NewRows([]string{"id", "email", "username", "password", "first_name", "last_name", "is_active", "created_at", "updated_at", "deleted_at"}).
+		AddRow("user-1", "user1@example.com", "user1", "hashedpassword", "User", "One", true, now, now, nil)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "users" WHERE deleted_at IS NULL AND "users"."deleted_at" IS NULL ORDER BY created_at DESC LIMIT $1`)).
+		WithArgs(filter.PerPage).
+		WillReturnRows(rows)
+
+	users, totalRows, err := s.repo.List(s.ctx, filter)
+
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), users, 1)
+	assert.Equal(s.T(), entity.Total{Count: 249880, Estimated: true}, totalRows)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestList_EstimatedManyDeletedCountsExactly() {
+	filter := entity.FilterUser{
+		Offset:  0,
+		PerPage: 10,
+		Count:   entity.CountEstimated,
+	}
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT reltuples FROM pg_class WHERE oid = $1::regclass`)).
+		WithArgs("users").
+		WillReturnRows(sqlmock.NewRows([]string{"reltuples"}).AddRow(250000.0))
+	// Table statistics would overstate the live users by a fifth
+	s.mock.ExpectQuery(regexp.QuoteMeta(`EXPLAIN (FORMAT JSON) SELECT * FROM "users" WHERE deleted_at IS NOT NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).AddRow(`[{"Plan": {"Plan Rows": 50000}}]`))
+
+	rows := sqlmock.NewRows([]string{"id", "total_count"}).AddRow("user-1", 200000)
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT *, COUNT(*) OVER() AS total_count FROM "users" WHERE deleted_at IS NULL AND "users"."deleted_at" IS NULL ORDER BY created_at DESC LIMIT $1`)).
+		WithArgs(filter.PerPage).
+		WillReturnRows(rows)
+
+	_, totalRows, err := s.repo.List(s.ctx, filter)
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), entity.Total{Count: 200000}, totalRows)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestList_EstimateUnavailableCountsExactly() {
+	filter := entity.FilterUser{
+		Offset:  0,
+		PerPage: 10,
+		Count:   entity.CountEstimated,
+	}
+
+	// No statistics to read, e.g. the table was never analyzed
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT reltuples FROM pg_class WHERE oid = $1::regclass`)).
+		WithArgs("users").
+		WillReturnError(sql.ErrNoRows)
+
+	rows := sqlmock.NewRows([]string{"id", "total_count"}).AddRow("user-1", 150000)
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT *, COUNT(*) OVER() AS total_count FROM "users" WHERE deleted_at IS NULL AND "users"."deleted_at" IS NULL ORDER BY created_at DESC LIMIT $1`)).
+		WithArgs(filter.PerPage).
+		WillReturnRows(rows)
+
+	_, totalRows, err := s.repo.List(s.ctx, filter)
+
+	assert.NoError(s.T(), err)
+	// The total was counted, not estimated, though an estimate was asked for
+	assert.Equal(s.T(), entity.Total{Count: 150000}, totalRows)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestList_EstimatedFilteredUsesExplain() {
+	now := time.Now()
+	filter := entity.FilterUser{
+		Role:    "admin",
+		Offset:  0,
+		PerPage: 10,
+		Count:   entity.CountEstimated,
+	}
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`EXPLAIN (FORMAT JSON) SELECT * FROM "users" WHERE deleted_at IS NULL AND role = $1 AND "users"."deleted_at" IS NULL`)).
+		WithArgs("admin").
+		WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).AddRow(`[{"Plan": {"Node Type": "Seq Scan", "Plan Rows": 180000}}]`))
+
+	rows := sqlmock.NewRows([]string{"id", "email", "username", "password", "first_name", "last_name", "is_active", "created_at", "updated_at", "deleted_at"}).
+		AddRow("user-1", "user1@example.com", "user1", "hashedpassword", "User", "One", true, now, now, nil)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "users" WHERE deleted_at IS NULL AND role = $1 AND "users"."deleted_at" IS NULL ORDER BY created_at DESC LIMIT $2`)).
+		WithArgs("admin", filter.PerPage).
+		WillReturnRows(rows)
+
+	users, totalRows, err := s.repo.List(s.ctx, filter)
+
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), users, 1)
+	assert.Equal(s.T(), entity.Total{Count: 180000, Estimated: true}, totalRows)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestList_EstimatedSmallTableCountsExactly() {
+	filter := entity.FilterUser{
+		Offset:  0,
+		PerPage: 10,
+		Count:   entity.CountEstimated,
+	}
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT reltuples FROM pg_class WHERE oid = $1::regclass`)).
+		WithArgs("users").
+		WillReturnRows(sqlmock.NewRows([]string{"reltuples"}).AddRow(42.0))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`EXPLAIN (FORMAT JSON) SELECT * FROM "users" WHERE deleted_at IS NOT NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).AddRow(`[{"Plan": {"Plan Rows": 0}}]`))
+
+	rows := sqlmock.NewRows([]string{"id", "total_count"}).AddRow("user-1", 42)
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT *, COUNT(*) OVER() AS total_count FROM "users" WHERE deleted_at IS NULL AND "users"."deleted_at" IS NULL ORDER BY created_at DESC LIMIT $1`)).
+		WithArgs(filter.PerPage).
+		WillReturnRows(rows)
+
+	users, totalRows, err := s.repo.List(s.ctx, filter)
+
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), users, 1)
+	assert.Equal(s.T(), entity.Total{Count: 42}, totalRows)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestList_Deleted() {
+	now := time.Now()
+	filter := entity.FilterUser{
+		PerPage: 10,
+		Deleted: true,
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "email", "deleted_at", "total_count"}).
+		AddRow("user-1", "user1@example.com", now, 1)
+
+	// The soft delete clause is dropped in favour of its opposite
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT *, COUNT(*) OVER() AS total_count FROM "users" WHERE deleted_at IS NOT NULL ORDER BY created_at DESC LIMIT $1`)).
+		WithArgs(filter.PerPage).
+		WillReturnRows(rows)
+
+	users, totalRows, err := s.repo.List(s.ctx, filter)
+
+	assert.NoError(s.T(), err)
+	require.Len(s.T(), users, 1)
+	assert.True(s.T(), users[0].DeletedAt.Valid)
+	assert.Equal(s.T(), entity.Total{Count: 1}, totalRows)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestList_DeletedEstimatedUsesExplain() {
+	filter := entity.FilterUser{
+		PerPage: 10,
+		Deleted: true,
+		Count:   entity.CountEstimated,
+	}
+
+	// Table statistics count live rows too, so the plan is asked instead
+	s.mock.ExpectQuery(regexp.QuoteMeta(`EXPLAIN (FORMAT JSON) SELECT * FROM "users" WHERE deleted_at IS NOT NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).AddRow(`[{"Plan": {"Plan Rows": 250000}}]`))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE deleted_at IS NOT NULL ORDER BY created_at DESC LIMIT $1`)).
+		WithArgs(filter.PerPage).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, totalRows, err := s.repo.List(s.ctx, filter)
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), entity.Total{Count: 250000, Estimated: true}, totalRows)
 	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
 }
 
@@ -366,7 +936,7 @@ func (s *UserRepositoryTestSuite) TestList_Error() {
 
 	// When offset is 0, GORM doesn't add OFFSET to the query
 	s.mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT * FROM "users" WHERE deleted_at IS NULL AND "users"."deleted_at" IS NULL ORDER BY created_at DESC LIMIT $1`)).
+		`SELECT *, COUNT(*) OVER() AS total_count FROM "users" WHERE deleted_at IS NULL AND "users"."deleted_at" IS NULL ORDER BY created_at DESC LIMIT $1`)).
 		WithArgs(filter.PerPage).
 		WillReturnError(sql.ErrConnDone)
 
@@ -374,5 +944,316 @@ func (s *UserRepositoryTestSuite) TestList_Error() {
 
 	assert.Error(s.T(), err)
 	assert.Nil(s.T(), users)
-	assert.Equal(s.T(), 0, totalRows)
+	assert.Equal(s.T(), entity.Total{Count: 0}, totalRows)
+}
+
+func (s *UserRepositoryTestSuite) TestGetByID_SelectFields() {
+	userID := "user-123"
+
+	rows := sqlmock.NewRows([]string{"id", "username"}).
+		AddRow(userID, "testuser")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT "id","username" FROM "users" WHERE id = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2`)).
+		WithArgs(userID, 1).
+		WillReturnRows(rows)
+
+	user, err := s.repo.GetByID(s.ctx, userID, "id", "username")
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "testuser", user.Username)
+	assert.Empty(s.T(), user.Email)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestList_SelectFields() {
+	filter := entity.FilterUser{
+		Fields:  []string{"id", "first_name"},
+		Offset:  0,
+		PerPage: 10,
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "first_name", "total_count"}).
+		AddRow("user-1", "User", 1)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT "id","first_name", COUNT(*) OVER() AS total_count FROM "users" WHERE deleted_at IS NULL AND "users"."deleted_at" IS NULL ORDER BY created_at DESC LIMIT $1`)).
+		WithArgs(filter.PerPage).
+		WillReturnRows(rows)
+
+	users, totalRows, err := s.repo.List(s.ctx, filter)
+
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), users, 1)
+	assert.Equal(s.T(), "User", users[0].FirstName)
+	assert.Equal(s.T(), entity.Total{Count: 1}, totalRows)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+// The benchmarks below compare the total count strategies against a real database
+// with a populated users table, e.g.
+//
+//	BENCH_DATABASE_DSN="host=localhost user=postgres password=password dbname=app sslmode=disable" \
+//	go test -run '^$' -bench BenchmarkList ./internal/shared/infrastructure/repository/
+func setupBenchmarkRepository(b *testing.B) *userRepository {
+	dsn := os.Getenv("BENCH_DATABASE_DSN")
+	if dsn == "" {
+		b.Skip("BENCH_DATABASE_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(b, err)
+
+	return &userRepository{db: db}
+}
+
+func benchmarkList(b *testing.B, count entity.CountMode) {
+	repo := setupBenchmarkRepository(b)
+	ctx := context.Background()
+	filter := entity.FilterUser{Offset: 100, PerPage: 20, Count: count}
+
+	for b.Loop() {
+		if _, _, err := repo.List(ctx, filter); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkList_SeparateCount(b *testing.B) {
+	repo := setupBenchmarkRepository(b)
+	ctx := context.Background()
+	filter := entity.FilterUser{Offset: 100, PerPage: 20}
+	scopes, _ := userScopes(filter)
+
+	for b.Loop() {
+		if _, err := repo.find(ctx, filter, scopes); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := repo.count(ctx, scopes); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkList_WindowCount(b *testing.B) {
+	benchmarkList(b, entity.CountExact)
+}
+
+func BenchmarkList_EstimatedCount(b *testing.B) {
+	benchmarkList(b, entity.CountEstimated)
+}
+
+func BenchmarkList_WithoutTotal(b *testing.B) {
+	benchmarkList(b, entity.CountNone)
+}
+
+func (s *UserRepositoryTestSuite) TestGetDeletedByEmail_Success() {
+	rows := sqlmock.NewRows([]string{"id", "email", "deleted_at"}).
+		AddRow("user-123", "test@example.com", time.Now())
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "users" WHERE deleted_at IS NOT NULL AND email_normalized = $1 ORDER BY deleted_at DESC, id LIMIT $2`)).
+		WithArgs("test@example.com", 1).
+		WillReturnRows(rows)
+
+	user, err := s.repo.GetDeletedByEmail(s.ctx, "Test@Example.com")
+
+	assert.NoError(s.T(), err)
+	require.NotNil(s.T(), user)
+	assert.True(s.T(), user.DeletedAt.Valid)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestGetDeletedByUsername_NotFound() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "users" WHERE deleted_at IS NOT NULL AND username_normalized = $1 ORDER BY deleted_at DESC, id LIMIT $2`)).
+		WithArgs("testuser", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	user, err := s.repo.GetDeletedByUsername(s.ctx, "TestUser")
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrUserNotFound)
+	assert.Nil(s.T(), user)
+}
+
+func (s *UserRepositoryTestSuite) TestListDeleted_Success() {
+	before := time.Now().Add(-time.Hour)
+	rows := sqlmock.NewRows([]string{"id", "deleted_at"}).
+		AddRow("user-1", before.Add(-time.Hour)).
+		AddRow("user-2", before.Add(-time.Minute))
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "users" WHERE deleted_at IS NOT NULL AND deleted_at < $1 AND status <> $2 ORDER BY deleted_at, id LIMIT $3`)).
+		WithArgs(before, entity.UserStatusAnonymized, 100).
+		WillReturnRows(rows)
+
+	users, err := s.repo.ListDeleted(s.ctx, before, 0, 100)
+
+	assert.NoError(s.T(), err)
+	require.Len(s.T(), users, 2)
+	assert.Equal(s.T(), "user-1", users[0].ID)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestListDeleted_Offset() {
+	before := time.Now().Add(-time.Hour)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "users" WHERE deleted_at IS NOT NULL AND deleted_at < $1 AND status <> $2 ORDER BY deleted_at, id LIMIT $3 OFFSET $4`)).
+		WithArgs(before, entity.UserStatusAnonymized, 100, 200).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	users, err := s.repo.ListDeleted(s.ctx, before, 200, 100)
+
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), users)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestGetByIDWithDeleted_Success() {
+	rows := sqlmock.NewRows([]string{"id", "email", "deleted_at"}).
+		AddRow("user-123", "test@example.com", time.Now())
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "users" WHERE id = $1 ORDER BY "users"."id" LIMIT $2`)).
+		WithArgs("user-123", 1).
+		WillReturnRows(rows)
+
+	user, err := s.repo.GetByIDWithDeleted(s.ctx, "user-123")
+
+	assert.NoError(s.T(), err)
+	require.NotNil(s.T(), user)
+	assert.True(s.T(), user.DeletedAt.Valid)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestGetByIDWithDeleted_NotFound() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id = $1`)).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	user, err := s.repo.GetByIDWithDeleted(s.ctx, "user-123")
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrUserNotFound)
+	assert.Nil(s.T(), user)
+}
+
+func (s *UserRepositoryTestSuite) TestListDeleted_Error() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE deleted_at IS NOT NULL`)).
+		WillReturnError(sql.ErrConnDone)
+
+	users, err := s.repo.ListDeleted(s.ctx, time.Now(), 0, 100)
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrServiceUnavailable)
+	assert.Nil(s.T(), users)
+}
+
+func (s *UserRepositoryTestSuite) TestListAnonymized_Success() {
+	before := time.Now().Add(-time.Hour)
+	rows := sqlmock.NewRows([]string{"id", "status"}).
+		AddRow("user-1", entity.UserStatusAnonymized)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "users" WHERE deleted_at IS NOT NULL AND deleted_at < $1 AND status = $2 ORDER BY deleted_at, id LIMIT $3`)).
+		WithArgs(before, entity.UserStatusAnonymized, 100).
+		WillReturnRows(rows)
+
+	users, err := s.repo.ListAnonymized(s.ctx, before, 0, 100)
+
+	assert.NoError(s.T(), err)
+	require.Len(s.T(), users, 1)
+	assert.Equal(s.T(), "user-1", users[0].ID)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestUpdateDeleted_Success() {
+	user := &entity.User{ID: "user-123", FirstName: "Deleted", Phone: nil}
+
+	// Zero values are written and the soft delete is kept
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "users" SET "first_name"=$1,"phone"=$2,"phone_index"=$3,"updated_at"=$4 WHERE deleted_at IS NOT NULL AND "id" = $5`)).
+		WithArgs("Deleted", nil, nil, sqlmock.AnyArg(), "user-123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.UpdateDeleted(s.ctx, user, "first_name", "phone")
+
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestUpdateDeleted_NotDeleted() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	err := s.repo.UpdateDeleted(s.ctx, &entity.User{ID: "user-123"}, "first_name")
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrUserNotFound)
+}
+
+func (s *UserRepositoryTestSuite) TestUpdatePassword_Success() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "users" SET "password"=$1 WHERE (id = $2 AND password = $3) AND "users"."deleted_at" IS NULL`)).
+		WithArgs("new-hash", "user-123", "old-hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.UpdatePassword(s.ctx, "user-123", "old-hash", "new-hash")
+
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestUpdatePassword_ChangedMeanwhile() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "password"=$1`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	err := s.repo.UpdatePassword(s.ctx, "user-123", "old-hash", "new-hash")
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrUserNotFound)
+}
+
+func (s *UserRepositoryTestSuite) TestRestore_Success() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "users" SET "deleted_at"=$1,"updated_at"=$2 WHERE deleted_at IS NOT NULL AND id = $3`)).
+		WithArgs(nil, sqlmock.AnyArg(), "user-123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.Restore(s.ctx, "user-123")
+
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UserRepositoryTestSuite) TestRestore_NotDeleted() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "deleted_at"=$1`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	err := s.repo.Restore(s.ctx, "user-123")
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrUserNotFound)
+}
+
+func (s *UserRepositoryTestSuite) TestPurge_Success() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "users" WHERE id = $1`)).
+		WithArgs("user-123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.Purge(s.ctx, "user-123")
+
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
 }
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/domain/repository"
+	"app/internal/shared/infrastructure/database"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// usernameHistoryRepository implements repository.UsernameHistoryRepository interface
+type usernameHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewUsernameHistoryRepository creates a new username history repository
+func NewUsernameHistoryRepository(db *gorm.DB) repository.UsernameHistoryRepository {
+	return &usernameHistoryRepository{db: db}
+}
+
+// Create records a former username
+func (r *usernameHistoryRepository) Create(ctx context.Context, history *entity.UsernameHistory) error {
+	if err := database.Conn(ctx, r.db).Create(history).Error; err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// GetLatest retrieves the user's most recent username change
+func (r *usernameHistoryRepository) GetLatest(ctx context.Context, userID string) (*entity.UsernameHistory, error) {
+	var history entity.UsernameHistory
+	err := database.Conn(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("changed_at DESC").
+		First(&history).Error
+	return r.result(&history, err)
+}
+
+// GetHeld retrieves the entry still holding username, ignoring case
+func (r *usernameHistoryRepository) GetHeld(ctx context.Context, username string) (*entity.UsernameHistory, error) {
+	var history entity.UsernameHistory
+	err := database.Conn(ctx, r.db).
+		Where("username_normalized = ? AND held_until > ?", entity.NormalizeUsername(username), time.Now()).
+		Order("changed_at DESC").
+		First(&history).Error
+	return r.result(&history, err)
+}
+
+// ListByUser retrieves all former usernames of the user, most recent first
+func (r *usernameHistoryRepository) ListByUser(ctx context.Context, userID string) ([]*entity.UsernameHistory, error) {
+	var history []*entity.UsernameHistory
+	if err := database.Conn(ctx, r.db).Where("user_id = ?", userID).Order("changed_at DESC").Find(&history).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return history, nil
+}
+
+// DeleteByUser removes all former usernames of the user
+func (r *usernameHistoryRepository) DeleteByUser(ctx context.Context, userID string) (int, error) {
+	result := database.Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&entity.UsernameHistory{})
+	if result.Error != nil {
+		return 0, translateError(result.Error)
+	}
+	return int(result.RowsAffected), nil
+}
+
+// result translates the outcome of a single entry lookup
+func (r *usernameHistoryRepository) result(history *entity.UsernameHistory, err error) (*entity.UsernameHistory, error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domainerror.ErrUsernameHistoryNotFound
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return history, nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"app/internal/shared/domain/entity"
+	domainerror "app/internal/shared/domain/error"
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type UsernameHistoryRepositoryTestSuite struct {
+	suite.Suite
+	mock  sqlmock.Sqlmock
+	repo  *usernameHistoryRepository
+	ctx   context.Context
+	sqlDB *sql.DB
+}
+
+func (s *UsernameHistoryRepositoryTestSuite) SetupTest() {
+	var err error
+	s.sqlDB, s.mock, err = sqlmock.New()
+	require.NoError(s.T(), err)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		Conn:       s.sqlDB,
+		DriverName: "postgres",
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(s.T(), err)
+
+	s.repo = &usernameHistoryRepository{db: db}
+	s.ctx = context.Background()
+}
+
+func (s *UsernameHistoryRepositoryTestSuite) TearDownTest() {
+	s.sqlDB.Close()
+}
+
+func TestUsernameHistoryRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(UsernameHistoryRepositoryTestSuite))
+}
+
+func (s *UsernameHistoryRepositoryTestSuite) TestCreate_Success() {
+	history := entity.NewUsernameHistory(&entity.User{ID: "user-123", Username: "OldName"}, time.Hour)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO "username_history" ("id","user_id","username","username_normalized","username_skeleton","changed_at","held_until") VALUES ($1,$2,$3,$4,$5,$6,$7)`)).
+		WithArgs(history.ID, "user-123", "OldName", "oldname", "oldname", history.ChangedAt, history.HeldUntil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.Create(s.ctx, history)
+
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UsernameHistoryRepositoryTestSuite) TestGetLatest_Success() {
+	rows := sqlmock.NewRows([]string{"id", "user_id", "username", "changed_at"}).
+		AddRow("history-1", "user-123", "oldname", time.Now())
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "username_history" WHERE user_id = $1 ORDER BY changed_at DESC,"username_history"."id" LIMIT $2`)).
+		WithArgs("user-123", 1).
+		WillReturnRows(rows)
+
+	history, err := s.repo.GetLatest(s.ctx, "user-123")
+
+	assert.NoError(s.T(), err)
+	require.NotNil(s.T(), history)
+	assert.Equal(s.T(), "oldname", history.Username)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UsernameHistoryRepositoryTestSuite) TestGetLatest_NotFound() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "username_history"`)).
+		WithArgs("user-123", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	history, err := s.repo.GetLatest(s.ctx, "user-123")
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrUsernameHistoryNotFound)
+	assert.Nil(s.T(), history)
+}
+
+func (s *UsernameHistoryRepositoryTestSuite) TestGetHeld_MatchesNormalizedName() {
+	rows := sqlmock.NewRows([]string{"id", "user_id", "username"}).
+		AddRow("history-1", "user-123", "OldName")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "username_history" WHERE username_normalized = $1 AND held_until > $2 ORDER BY changed_at DESC,"username_history"."id" LIMIT $3`)).
+		WithArgs("oldname", sqlmock.AnyArg(), 1).
+		WillReturnRows(rows)
+
+	history, err := s.repo.GetHeld(s.ctx, " OLDNAME ")
+
+	assert.NoError(s.T(), err)
+	require.NotNil(s.T(), history)
+	assert.Equal(s.T(), "user-123", history.UserID)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UsernameHistoryRepositoryTestSuite) TestGetHeld_Error() {
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "username_history"`)).
+		WillReturnError(sql.ErrConnDone)
+
+	history, err := s.repo.GetHeld(s.ctx, "oldname")
+
+	assert.ErrorIs(s.T(), err, domainerror.ErrServiceUnavailable)
+	assert.Nil(s.T(), history)
+}
+
+func (s *UsernameHistoryRepositoryTestSuite) TestDeleteByUser_Success() {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "username_history" WHERE user_id = $1`)).
+		WithArgs("user-123").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	s.mock.ExpectCommit()
+
+	deleted, err := s.repo.DeleteByUser(s.ctx, "user-123")
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, deleted)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *UsernameHistoryRepositoryTestSuite) TestListByUser_Success() {
+	rows := sqlmock.NewRows([]string{"id", "user_id", "username"}).
+		AddRow("history-2", "user-123", "SecondName").
+		AddRow("history-1", "user-123", "FirstName")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "username_history" WHERE user_id = $1 ORDER BY changed_at DESC`)).
+		WithArgs("user-123").
+		WillReturnRows(rows)
+
+	history, err := s.repo.ListByUser(s.ctx, "user-123")
+
+	assert.NoError(s.T(), err)
+	require.Len(s.T(), history, 2)
+	assert.Equal(s.T(), "SecondName", history[0].Username)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
@@ -0,0 +1,21 @@
+package seed
+
+// Names generated users are given, common Indonesian and English ones
+var (
+	maleNames = []string{
+		"Adi", "Agus", "Ahmad", "Andi", "Arif", "Bayu", "Budi", "Dedi", "Dimas", "Eko",
+		"Fajar", "Hendra", "Irfan", "Joko", "Rizki", "Taufik", "Wahyu", "Yusuf",
+		"Daniel", "David", "James", "John", "Michael", "Ryan", "Thomas", "William",
+	}
+	femaleNames = []string{
+		"Ayu", "Dewi", "Fitri", "Indah", "Intan", "Lestari", "Maya", "Nur", "Putri", "Rina",
+		"Sari", "Siti", "Sri", "Wulan", "Yuni", "Ratna", "Dian", "Nadia",
+		"Anna", "Emily", "Emma", "Grace", "Laura", "Olivia", "Sarah", "Sophia",
+	}
+	lastNames = []string{
+		"Santoso", "Wijaya", "Saputra", "Hidayat", "Pratama", "Kusuma", "Nugroho", "Setiawan",
+		"Gunawan", "Siregar", "Nasution", "Lubis", "Sitompul", "Halim", "Tanoto", "Wibowo",
+		"Hartono", "Susanto", "Rahman", "Hakim", "Brown", "Clark", "Johnson", "Miller",
+		"Smith", "Taylor", "Walker", "Wilson",
+	}
+)
@@ -0,0 +1,241 @@
+package seed
+
+import (
+	"app/internal/shared/domain/entity"
+	"app/pkg/crypto"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sequences of generated users, numbered independently
+const (
+	sequenceUsers = "users"
+	sequenceBulk  = "bulk"
+)
+
+// namespace derives the IDs of seeded users, so a re-run finds the rows it inserted
+var namespace = uuid.MustParse("5d1c0f2e-6a8b-4f3e-9c47-2b8e1a0d7f63")
+
+// Report summarizes a Run, rows already there are skipped
+type Report struct {
+	AdminCreated bool
+	Users        Count
+	Bulk         Count
+	// Passwords drawn for a spec leaving them blank, set when the account was
+	// created or users were inserted
+	AdminPassword string
+	Password      string
+}
+
+// Count tells how many rows of a sequence were inserted and skipped
+type Count struct {
+	Inserted int64
+	Skipped  int64
+}
+
+// Run seeds the admin account and the users of spec. Every user is derived from
+// the seed and its position alone, with a deterministic ID, and inserted with ON
+// CONFLICT (id) DO NOTHING: re-running inserts nothing, raising a count only adds
+// the missing users, while a user colliding with another account's email or
+// username fails the run. Generated users share one password hash made with
+// params, passwords the spec leaves blank are drawn at random and reported.
+// progress, when set, is called after each bulk batch
+func Run(ctx context.Context, db *gorm.DB, spec *Spec, params crypto.PasswordParams, progress func(done, total int)) (*Report, error) {
+	report := &Report{}
+
+	if spec.Admin != nil {
+		password, generated, err := resolvePassword(spec.Admin.Password)
+		if err != nil {
+			return report, err
+		}
+		hash, err := crypto.HashPasswordWithParams(password, params)
+		if err != nil {
+			return report, err
+		}
+		admin := entity.NewUser(spec.Admin.Email, spec.Admin.Username, hash, spec.Admin.FirstName, spec.Admin.LastName)
+		admin.ID = uuid.NewSHA1(namespace, []byte("admin:"+admin.EmailNormalized)).String()
+		admin.Role = entity.UserRoleAdmin
+		admin.Status = entity.UserStatusActive
+
+		inserted, err := insert(ctx, db, []*entity.User{admin})
+		if err != nil {
+			return report, fmt.Errorf("admin: %w", err)
+		}
+		report.AdminCreated = inserted == 1
+		if report.AdminCreated && generated {
+			report.AdminPassword = password
+		}
+	}
+
+	password, generated, err := resolvePassword(spec.Password)
+	if err != nil {
+		return report, err
+	}
+	hash, err := crypto.HashPasswordWithParams(password, params)
+	if err != nil {
+		return report, err
+	}
+	generator := NewGenerator(spec, hash)
+
+	sequences := []struct {
+		name  string
+		total int
+		count *Count
+	}{
+		{sequenceUsers, spec.Users.Count, &report.Users},
+		{sequenceBulk, spec.Bulk.Count, &report.Bulk},
+	}
+	for _, sequence := range sequences {
+		for from := 0; from < sequence.total; from += spec.BatchSize {
+			to := min(from+spec.BatchSize, sequence.total)
+			users := make([]*entity.User, 0, to-from)
+			for i := from; i < to; i++ {
+				users = append(users, generator.User(sequence.name, i))
+			}
+
+			inserted, err := insert(ctx, db, users)
+			if err != nil {
+				return report, fmt.Errorf("%s %d-%d: %w", sequence.name, from, to-1, err)
+			}
+			sequence.count.Inserted += inserted
+			sequence.count.Skipped += int64(len(users)) - inserted
+			if progress != nil && sequence.name == sequenceBulk {
+				progress(to, sequence.total)
+			}
+		}
+	}
+	if generated && report.Users.Inserted+report.Bulk.Inserted > 0 {
+		report.Password = password
+	}
+	return report, nil
+}
+
+// resolvePassword returns the password of the spec, or a random one when it is blank
+func resolvePassword(spec string) (string, bool, error) {
+	if spec != "" {
+		return spec, false, nil
+	}
+	generated, err := crypto.GenerateToken()
+	return generated, true, err
+}
+
+// insert inserts users in one statement, skipping those seeded already, and
+// returns how many were inserted. Only the ID is a conflict target, a collision
+// on the email or username indexes is an error rather than a silent skip. A
+// single statement needs no transaction around it
+func insert(ctx context.Context, db *gorm.DB, users []*entity.User) (int64, error) {
+	result := db.WithContext(ctx).Session(&gorm.Session{SkipDefaultTransaction: true}).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "id"}}, DoNothing: true}).
+		Create(users)
+	return result.RowsAffected, result.Error
+}
+
+// Generator derives users from a spec
+type Generator struct {
+	spec         *Spec
+	passwordHash string
+	now          time.Time
+}
+
+// NewGenerator creates a generator of users of spec having passwordHash
+func NewGenerator(spec *Spec, passwordHash string) *Generator {
+	return &Generator{spec: spec, passwordHash: passwordHash, now: time.Now()}
+}
+
+// User returns user i of a sequence. It only depends on the seed, the sequence
+// and i, so user 7 is the same whatever the count
+func (g *Generator) User(sequence string, i int) *entity.User {
+	// The ID seeds every other value
+	id := uuid.NewSHA1(namespace, []byte(fmt.Sprintf("%d:%s:%d", g.spec.Seed, sequence, i)))
+	rng := rand.New(rand.NewPCG(binary.BigEndian.Uint64(id[:8]), binary.BigEndian.Uint64(id[8:])))
+	users := g.spec.Users
+
+	gender := pick(rng, users.Genders)
+	var firstName string
+	switch gender {
+	case "male":
+		firstName = maleNames[rng.IntN(len(maleNames))]
+	case "female":
+		firstName = femaleNames[rng.IntN(len(femaleNames))]
+	default:
+		if n := rng.IntN(len(maleNames) + len(femaleNames)); n < len(maleNames) {
+			firstName = maleNames[n]
+		} else {
+			firstName = femaleNames[n-len(maleNames)]
+		}
+	}
+	lastName := lastNames[rng.IntN(len(lastNames))]
+
+	username := generatedUsername(firstName, lastName, sequence, i)
+
+	user := entity.NewUser(username+"@"+users.EmailDomain, username, g.passwordHash, firstName, lastName)
+	user.ID = id.String()
+	user.Gender = gender
+	user.Role = pick(rng, users.Roles)
+	user.Provider = pick(rng, users.Providers)
+	user.Status = entity.UserStatusActive
+	user.IsActive = rng.Float64() >= users.InactiveRatio
+
+	first := time.Date(users.BirthYears[0], time.January, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(users.BirthYears[1]+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	birthDate := first.AddDate(0, 0, rng.IntN(int(last.Sub(first).Hours()/24)))
+	user.BirthDate = &birthDate
+
+	if rng.Float64() < users.PhoneRatio {
+		phone := fmt.Sprintf("+628%d%08d", 1+rng.IntN(9), rng.IntN(100_000_000))
+		user.Phone = &phone
+	}
+
+	user.CreatedAt = g.now.Add(-time.Duration(rng.Int64N(int64(users.CreatedWithin)))).Truncate(time.Second)
+	user.UpdatedAt = user.CreatedAt
+	return user
+}
+
+// maxUsernameLength is the longest username registration accepts
+const maxUsernameLength = 20
+
+// generatedUsername returns the username of user i of a sequence, the names
+// shortened so it passes registration: the position keeps it unique and bulk
+// users are told apart by a b before it, e.g. siti.nasution7 and siti.nasu.b7
+func generatedUsername(firstName, lastName, sequence string, i int) string {
+	suffix := strconv.Itoa(i)
+	if sequence != sequenceUsers {
+		suffix = "." + sequence[:1] + suffix
+	}
+	name := strings.ToLower(firstName + "." + lastName)
+	if room := maxUsernameLength - len(suffix); len(name) > room {
+		name = strings.TrimRight(name[:room], ".")
+	}
+	return name + suffix
+}
+
+// pick returns one of the values of weights with a chance proportional to its
+// weight, in a stable order so the same rng draws the same value
+func pick(rng *rand.Rand, weights map[string]int) string {
+	values := make([]string, 0, len(weights))
+	total := 0
+	for value, weight := range weights {
+		values = append(values, value)
+		total += weight
+	}
+	slices.Sort(values)
+
+	n := rng.IntN(total)
+	for _, value := range values {
+		if n < weights[value] {
+			return value
+		}
+		n -= weights[value]
+	}
+	return values[len(values)-1]
+}
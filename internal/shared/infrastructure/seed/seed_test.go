@@ -0,0 +1,268 @@
+package seed
+
+import (
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	"app/internal/shared/infrastructure/database"
+	"app/pkg/crypto"
+	"bytes"
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testParams keep hashing cheap in tests
+var testParams = crypto.PasswordParams{Algorithm: crypto.AlgorithmBcrypt, BcryptCost: 4}
+
+type SeedTestSuite struct {
+	suite.Suite
+	db    *gorm.DB
+	mock  sqlmock.Sqlmock
+	ctx   context.Context
+	sqlDB *sql.DB
+}
+
+func (s *SeedTestSuite) SetupTest() {
+	var err error
+	s.sqlDB, s.mock, err = sqlmock.New()
+	require.NoError(s.T(), err)
+
+	s.db, err = gorm.Open(postgres.New(postgres.Config{
+		Conn:       s.sqlDB,
+		DriverName: "postgres",
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(s.T(), err)
+
+	// Phone numbers and birth dates are encrypted on insert
+	keyring, err := crypto.NewKeyring(map[string][]byte{"1": bytes.Repeat([]byte{1}, 32)}, "1", bytes.Repeat([]byte{9}, 32))
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), database.EnableEncryption(s.db, keyring))
+
+	s.ctx = context.Background()
+}
+
+func (s *SeedTestSuite) TearDownTest() {
+	s.sqlDB.Close()
+}
+
+func TestSeedTestSuite(t *testing.T) {
+	suite.Run(t, new(SeedTestSuite))
+}
+
+const insertUsers = `INSERT INTO "users"`
+
+func (s *SeedTestSuite) TestRun() {
+	spec, err := ParseSpec([]byte(`
+seed: 7
+batch_size: 2
+admin: {email: admin@example.com, username: devadmin, password: admin12345}
+users: {count: 3}
+bulk: {count: 2}
+`))
+	require.NoError(s.T(), err)
+
+	// The admin already exists, one generated user too
+	s.mock.ExpectExec(regexp.QuoteMeta(insertUsers) + `.* ON CONFLICT \("id"\) DO NOTHING$`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectExec(regexp.QuoteMeta(insertUsers) + `.*\),\(.* ON CONFLICT \("id"\) DO NOTHING$`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(insertUsers)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(insertUsers) + `.*\),\(.*`).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	var progress []int
+	report, err := Run(s.ctx, s.db, spec, testParams, func(done, total int) {
+		progress = append(progress, done, total)
+	})
+
+	require.NoError(s.T(), err)
+	// The spec sets no password for the users, one was drawn
+	assert.Len(s.T(), report.Password, 43)
+	report.Password = ""
+	assert.Equal(s.T(), &Report{
+		AdminCreated: false,
+		Users:        Count{Inserted: 2, Skipped: 1},
+		Bulk:         Count{Inserted: 2},
+	}, report)
+	assert.Equal(s.T(), []int{2, 2}, progress)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *SeedTestSuite) TestRun_DrawsAdminPassword() {
+	spec, err := ParseSpec([]byte(`
+admin: {email: admin@example.com, username: devadmin}
+password: secret123
+`))
+	require.NoError(s.T(), err)
+
+	s.mock.ExpectExec(regexp.QuoteMeta(insertUsers)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	report, err := Run(s.ctx, s.db, spec, testParams, nil)
+
+	require.NoError(s.T(), err)
+	assert.True(s.T(), report.AdminCreated)
+	assert.Len(s.T(), report.AdminPassword, 43)
+	// The users password comes from the spec and is not reported
+	assert.Empty(s.T(), report.Password)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *SeedTestSuite) TestRun_IdentityCollision() {
+	spec, err := ParseSpec([]byte(`users: {count: 1}`))
+	require.NoError(s.T(), err)
+
+	// Someone registered the generated username, the user is not silently skipped
+	s.mock.ExpectExec(regexp.QuoteMeta(insertUsers)).
+		WillReturnError(&pgconn.PgError{Code: "23505", Message: `duplicate key value violates unique constraint "idx_users_username_normalized"`})
+
+	report, err := Run(s.ctx, s.db, spec, testParams, nil)
+
+	assert.ErrorContains(s.T(), err, "idx_users_username_normalized")
+	assert.Equal(s.T(), Count{}, report.Users)
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func (s *SeedTestSuite) TestRun_Error() {
+	spec, err := ParseSpec([]byte(`users: {count: 1}`))
+	require.NoError(s.T(), err)
+
+	s.mock.ExpectExec(regexp.QuoteMeta(insertUsers)).
+		WillReturnError(sql.ErrConnDone)
+
+	_, err = Run(s.ctx, s.db, spec, testParams, nil)
+
+	assert.ErrorIs(s.T(), err, sql.ErrConnDone)
+	assert.ErrorContains(s.T(), err, "users 0-0")
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
+func TestGenerator_Deterministic(t *testing.T) {
+	spec, err := ParseSpec([]byte(`
+seed: 42
+users:
+  genders: {male: 1, female: 1, "": 1}
+  roles: {user: 3, moderator: 1}
+  providers: {"": 1, google: 1}
+  phone_ratio: 0.5
+`))
+	require.NoError(t, err)
+	first := NewGenerator(spec, "hash")
+	second := NewGenerator(spec, "hash")
+	second.now = first.now
+
+	for i := 0; i < 50; i++ {
+		a, b := first.User(sequenceUsers, i), second.User(sequenceUsers, i)
+		assert.Equal(t, a, b)
+	}
+
+	// Another seed or sequence gives other users
+	other := *spec
+	other.Seed = 43
+	user := first.User(sequenceUsers, 0)
+	assert.NotEqual(t, user.ID, NewGenerator(&other, "hash").User(sequenceUsers, 0).ID)
+	assert.NotEqual(t, user.ID, first.User(sequenceBulk, 0).ID)
+	assert.Regexp(t, `\.b0$`, first.User(sequenceBulk, 0).Username)
+}
+
+func TestGeneratedUsername(t *testing.T) {
+	assert.Equal(t, "siti.nasution7", generatedUsername("Siti", "Nasution", sequenceUsers, 7))
+	assert.Equal(t, "siti.nasution.b7", generatedUsername("Siti", "Nasution", sequenceBulk, 7))
+	// Long names are shortened, never leaving a separator before the suffix
+	assert.Equal(t, "michael.johns1234567", generatedUsername("Michael", "Johnson", sequenceUsers, 1234567))
+	assert.Equal(t, "michael.jo.b99999999", generatedUsername("Michael", "Johnson", sequenceBulk, maxCount-1))
+
+	// Every name passes registration at the largest positions
+	for _, first := range append(maleNames, femaleNames...) {
+		for _, last := range lastNames {
+			for _, sequence := range []string{sequenceUsers, sequenceBulk} {
+				for _, i := range []int{0, 42, maxCount - 1} {
+					username := generatedUsername(first, last, sequence, i)
+					assert.LessOrEqual(t, len(username), maxUsernameLength, username)
+					assert.True(t, constants.IsValidUsername(username), username)
+				}
+			}
+		}
+	}
+}
+
+func TestGenerator_FollowsSpec(t *testing.T) {
+	spec, err := ParseSpec([]byte(`
+users:
+  email_domain: test.local
+  genders: {female: 1}
+  roles: {moderator: 1}
+  providers: {github: 1}
+  inactive_ratio: 1
+  phone_ratio: 1
+  birth_years: [1990, 1990]
+  created_within: 24h
+`))
+	require.NoError(t, err)
+	generator := NewGenerator(spec, "hash")
+
+	for i := 0; i < 20; i++ {
+		user := generator.User(sequenceUsers, i)
+
+		assert.Contains(t, femaleNames, user.FirstName)
+		assert.Equal(t, user.Username+"@test.local", user.Email)
+		assert.Equal(t, entity.NormalizeUsername(user.Username), user.UsernameNormalized)
+		assert.Equal(t, "moderator", user.Role)
+		assert.Equal(t, "github", user.Provider)
+		assert.False(t, user.IsActive)
+		require.NotNil(t, user.Phone)
+		assert.Regexp(t, `^\+628\d{9}$`, *user.Phone)
+		require.NotNil(t, user.BirthDate)
+		assert.Equal(t, 1990, user.BirthDate.Year())
+		assert.WithinDuration(t, generator.now, user.CreatedAt, 24*time.Hour)
+		assert.Equal(t, "hash", user.Password)
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	spec, err := ParseSpec([]byte(`admin: {email: a@example.com, username: root1, password: secret123}`))
+
+	require.NoError(t, err)
+	assert.Empty(t, spec.Password)
+	assert.Equal(t, 1000, spec.BatchSize)
+	assert.Equal(t, "example.com", spec.Users.EmailDomain)
+	assert.Equal(t, []int{1960, 2006}, spec.Users.BirthYears)
+	assert.Equal(t, "Admin", spec.Admin.FirstName)
+
+	for yaml, message := range map[string]string{
+		`admin: {email: a@example.com}`:      "admin needs",
+		`users: {count: -1}`:                 "cannot be negative",
+		`bulk: {count: 200000000}`:           "cannot exceed",
+		`users: {roles: {admin: 1}}`:         "roles cannot include admin",
+		`users: {roles: {user: 0}}`:          "roles weights add up to 0",
+		`users: {phone_ratio: 2}`:            "between 0 and 1",
+		`users: {birth_years: [2000, 1990]}`: "birth_years",
+		`batch_size: 5000`:                   "batch_size",
+		`users: [`:                           "parse seed spec",
+	} {
+		_, err := ParseSpec([]byte(yaml))
+		assert.ErrorContains(t, err, message, yaml)
+	}
+
+	assert.NoError(t, spec.CheckAdmin([]string{"admin"}))
+	assert.ErrorContains(t, spec.CheckAdmin([]string{"ROOT1"}), "USERNAME_RESERVED")
+	spec.Admin.Username = "the.administrator.account"
+	assert.ErrorContains(t, spec.CheckAdmin(nil), "not a valid username")
+
+	assert.ErrorContains(t, spec.CheckGenders([]string{"female"}), `gender "male"`)
+	assert.NoError(t, spec.CheckGenders([]string{"male", "female"}))
+}
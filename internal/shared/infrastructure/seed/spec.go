@@ -0,0 +1,162 @@
+package seed
+
+import (
+	"app/internal/shared/constants"
+	"app/internal/shared/domain/entity"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec describes the fixtures to seed, see seeds/dev.yaml
+type Spec struct {
+	Seed      uint64     `yaml:"seed"`       // Same seed, same users
+	Password  string     `yaml:"password"`   // Password of every generated user, random when blank
+	BatchSize int        `yaml:"batch_size"` // Users per INSERT
+	Admin     *AdminSpec `yaml:"admin"`
+	Users     UsersSpec  `yaml:"users"`
+	Bulk      BulkSpec   `yaml:"bulk"`
+}
+
+// AdminSpec is the admin account to create, the only user given the admin role
+type AdminSpec struct {
+	Email     string `yaml:"email"`
+	Username  string `yaml:"username"`
+	Password  string `yaml:"password"` // Random when blank
+	FirstName string `yaml:"first_name"`
+	LastName  string `yaml:"last_name"`
+}
+
+// UsersSpec shapes the generated users. Genders, roles and providers map values
+// to relative weights, an empty value leaves the field blank
+type UsersSpec struct {
+	Count         int            `yaml:"count"`
+	EmailDomain   string         `yaml:"email_domain"`
+	Genders       map[string]int `yaml:"genders"`
+	Roles         map[string]int `yaml:"roles"`
+	Providers     map[string]int `yaml:"providers"`
+	InactiveRatio float64        `yaml:"inactive_ratio"` // Share of deactivated users
+	PhoneRatio    float64        `yaml:"phone_ratio"`    // Share of users with a phone number
+	BirthYears    []int          `yaml:"birth_years"`    // First and last year of birth
+	CreatedWithin time.Duration  `yaml:"created_within"` // How far back registrations are spread
+}
+
+// BulkSpec adds users for load testing, generated like the others in a sequence
+// of their own so the fixtures stay as they are
+type BulkSpec struct {
+	Count int `yaml:"count"`
+}
+
+// LoadSpec reads a YAML spec, filling in defaults for what it leaves out
+func LoadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSpec(data)
+}
+
+// ParseSpec parses a YAML spec, filling in defaults for what it leaves out
+func ParseSpec(data []byte) (*Spec, error) {
+	spec := &Spec{}
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("parse seed spec: %w", err)
+	}
+
+	if spec.Users.EmailDomain == "" {
+		spec.Users.EmailDomain = "example.com"
+	}
+	if len(spec.Users.Genders) == 0 {
+		spec.Users.Genders = map[string]int{"male": 1, "female": 1}
+	}
+	if len(spec.Users.Roles) == 0 {
+		spec.Users.Roles = map[string]int{"user": 1}
+	}
+	if len(spec.Users.Providers) == 0 {
+		spec.Users.Providers = map[string]int{"": 1}
+	}
+	if len(spec.Users.BirthYears) == 0 {
+		spec.Users.BirthYears = []int{1960, 2006}
+	}
+	if spec.Users.CreatedWithin == 0 {
+		spec.Users.CreatedWithin = 2 * 365 * 24 * time.Hour
+	}
+	if spec.BatchSize == 0 {
+		spec.BatchSize = 1000
+	}
+	if spec.Admin != nil && spec.Admin.FirstName == "" {
+		spec.Admin.FirstName, spec.Admin.LastName = "Admin", "User"
+	}
+	return spec, spec.validate()
+}
+
+// maxCount bounds the users of a sequence, whose position must fit in a username
+const maxCount = 100_000_000
+
+// validate rejects specs the generator cannot follow
+func (s *Spec) validate() error {
+	if s.Admin != nil && (s.Admin.Email == "" || s.Admin.Username == "") {
+		return errors.New("admin needs an email and a username")
+	}
+	if s.Users.Count < 0 || s.Bulk.Count < 0 {
+		return errors.New("user counts cannot be negative")
+	}
+	if s.Users.Count > maxCount || s.Bulk.Count > maxCount {
+		return fmt.Errorf("user counts cannot exceed %d", maxCount)
+	}
+	if _, ok := s.Users.Roles[entity.UserRoleAdmin]; ok {
+		return errors.New("roles cannot include admin, the admin account is described by admin")
+	}
+	for name, weights := range map[string]map[string]int{"genders": s.Users.Genders, "roles": s.Users.Roles, "providers": s.Users.Providers} {
+		total := 0
+		for _, weight := range weights {
+			if weight < 0 {
+				return fmt.Errorf("%s weights cannot be negative", name)
+			}
+			total += weight
+		}
+		if total == 0 {
+			return fmt.Errorf("%s weights add up to 0", name)
+		}
+	}
+	if s.Users.InactiveRatio < 0 || s.Users.InactiveRatio > 1 || s.Users.PhoneRatio < 0 || s.Users.PhoneRatio > 1 {
+		return errors.New("ratios must be between 0 and 1")
+	}
+	if len(s.Users.BirthYears) != 2 || s.Users.BirthYears[0] > s.Users.BirthYears[1] {
+		return errors.New("birth_years must be a first and a last year")
+	}
+	// Batches over 2000 users exceed the 65535 parameters of a Postgres statement
+	if s.BatchSize < 1 || s.BatchSize > 2000 {
+		return errors.New("batch_size must be between 1 and 2000")
+	}
+	return nil
+}
+
+// CheckAdmin reports an admin username registration would refuse
+func (s *Spec) CheckAdmin(reserved []string) error {
+	if s.Admin == nil {
+		return nil
+	}
+	username := s.Admin.Username
+	if !constants.MinLength(username, 3) || !constants.MaxLength(username, maxUsernameLength) || !constants.IsValidUsername(username) {
+		return fmt.Errorf("admin username %q is not a valid username", username)
+	}
+	if constants.IsReservedUsername(username, reserved) {
+		return fmt.Errorf("admin username %q is in USERNAME_RESERVED", username)
+	}
+	return nil
+}
+
+// CheckGenders reports a gender the profile would not accept
+func (s *Spec) CheckGenders(accepted []string) error {
+	for gender := range s.Users.Genders {
+		if gender != "" && !slices.Contains(accepted, gender) {
+			return fmt.Errorf("gender %q is not in PROFILE_GENDERS", gender)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package sms
+
+import (
+	"app/internal/shared/domain/service"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NewSender creates the SMS sender for provider, unknown providers fall back to
+// the log sender
+func NewSender(provider string, logger *logrus.Logger) service.SMSSender {
+	switch provider {
+	case "memory":
+		return NewMemorySender()
+	default:
+		return NewLogSender(logger)
+	}
+}
+
+// logSender writes messages to the log instead of delivering them. Meant for
+// development only, the log then contains the codes
+type logSender struct {
+	logger *logrus.Logger
+}
+
+// NewLogSender creates an SMS sender that logs every message
+func NewLogSender(logger *logrus.Logger) service.SMSSender {
+	return &logSender{logger: logger}
+}
+
+// Send logs the message
+func (s *logSender) Send(ctx context.Context, phone, message string) error {
+	s.logger.WithField("phone", phone).Info("sms: ", message)
+	return nil
+}
+
+// Message is a text message recorded by MemorySender
+type Message struct {
+	Phone string
+	Body  string
+}
+
+// MemorySender keeps sent messages in memory so tests can read them back
+type MemorySender struct {
+	mu       sync.Mutex
+	messages []Message
+	err      error
+}
+
+// NewMemorySender creates an in-memory SMS sender
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+// Send records the message, or returns the error set with FailWith
+func (s *MemorySender) Send(ctx context.Context, phone, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return fmt.Errorf("send sms: %w", s.err)
+	}
+	s.messages = append(s.messages, Message{Phone: phone, Body: message})
+	return nil
+}
+
+// FailWith makes subsequent sends fail with err, nil restores delivery
+func (s *MemorySender) FailWith(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// Messages returns the messages sent so far
+func (s *MemorySender) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Last returns the latest message sent to phone
+func (s *MemorySender) Last(phone string) (Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if s.messages[i].Phone == phone {
+			return s.messages[i], true
+		}
+	}
+	return Message{}, false
+}
@@ -0,0 +1,46 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSender_Provider(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	assert.IsType(t, &MemorySender{}, NewSender("memory", logger))
+	assert.IsType(t, &logSender{}, NewSender("log", logger))
+	assert.IsType(t, &logSender{}, NewSender("unknown", logger))
+}
+
+func TestMemorySender_Last(t *testing.T) {
+	sender := NewMemorySender()
+	ctx := context.Background()
+
+	require.NoError(t, sender.Send(ctx, "+6281234567890", "first"))
+	require.NoError(t, sender.Send(ctx, "+6289876543210", "other"))
+	require.NoError(t, sender.Send(ctx, "+6281234567890", "second"))
+
+	msg, ok := sender.Last("+6281234567890")
+
+	assert.True(t, ok)
+	assert.Equal(t, "second", msg.Body)
+	assert.Len(t, sender.Messages(), 3)
+}
+
+func TestMemorySender_FailWith(t *testing.T) {
+	sender := NewMemorySender()
+	sender.FailWith(errors.New("gateway down"))
+
+	err := sender.Send(context.Background(), "+6281234567890", "code")
+
+	assert.Error(t, err)
+	assert.Empty(t, sender.Messages())
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	domainerror "app/internal/shared/domain/error"
+	"app/internal/shared/domain/service"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// NewStorage creates the file storage for provider, unknown providers fall back
+// to the local storage writing under dir
+func NewStorage(provider, dir string) service.FileStorage {
+	switch provider {
+	case "memory":
+		return NewMemoryStorage()
+	default:
+		return NewLocalStorage(dir)
+	}
+}
+
+// localStorage keeps files in a local directory. Files are readable by the
+// process owner only since they hold personal data
+type localStorage struct {
+	dir string
+}
+
+// NewLocalStorage creates a file storage writing under dir, created on first use
+func NewLocalStorage(dir string) service.FileStorage {
+	return &localStorage{dir: dir}
+}
+
+// Put writes the file, replacing any file with the same key
+func (s *localStorage) Put(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("%w: %v", domainerror.ErrServiceUnavailable, err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("%w: %v", domainerror.ErrServiceUnavailable, err)
+	}
+	return nil
+}
+
+// Get reads the file
+func (s *localStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, domainerror.ErrFileNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domainerror.ErrServiceUnavailable, err)
+	}
+	return data, nil
+}
+
+// Delete removes the file
+func (s *localStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %v", domainerror.ErrServiceUnavailable, err)
+	}
+	return nil
+}
+
+// path maps a key to its file, rejecting keys that would leave the directory
+func (s *localStorage) path(key string) (string, error) {
+	if key == "" || key != filepath.Base(key) || strings.HasPrefix(key, ".") {
+		return "", fmt.Errorf("%w: invalid file key %q", domainerror.ErrInvalidInput, key)
+	}
+	return filepath.Join(s.dir, key), nil
+}
+
+// MemoryStorage keeps files in memory so tests can read them back
+type MemoryStorage struct {
+	mu    sync.Mutex
+	files map[string][]byte
+	err   error
+}
+
+// NewMemoryStorage creates an in-memory file storage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{files: make(map[string][]byte)}
+}
+
+// Put stores the file, or returns the error set with FailWith
+func (s *MemoryStorage) Put(ctx context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.files[key] = append([]byte(nil), data...)
+	return nil
+}
+
+// Get returns the file
+func (s *MemoryStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.files[key]
+	if !ok {
+		return nil, domainerror.ErrFileNotFound
+	}
+	return data, nil
+}
+
+// Delete removes the file
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.files, key)
+	return nil
+}
+
+// FailWith makes every following Put return err, nil restores storing
+func (s *MemoryStorage) FailWith(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// Keys returns the keys of the stored files
+func (s *MemoryStorage) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.files))
+	for key := range s.files {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	domainerror "app/internal/shared/domain/error"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStorage_Provider(t *testing.T) {
+	assert.IsType(t, &MemoryStorage{}, NewStorage("memory", ""))
+	assert.IsType(t, &localStorage{}, NewStorage("local", t.TempDir()))
+	assert.IsType(t, &localStorage{}, NewStorage("unknown", t.TempDir()))
+}
+
+func TestLocalStorage_RoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "exports")
+	store := NewLocalStorage(dir)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "export.zip", []byte("data")))
+
+	data, err := store.Get(ctx, "export.zip")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+
+	// Readable by the owner only
+	info, err := os.Stat(filepath.Join(dir, "export.zip"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	require.NoError(t, store.Delete(ctx, "export.zip"))
+	_, err = store.Get(ctx, "export.zip")
+	assert.ErrorIs(t, err, domainerror.ErrFileNotFound)
+
+	// Deleting again is not an error
+	assert.NoError(t, store.Delete(ctx, "export.zip"))
+}
+
+func TestLocalStorage_RejectsKeysOutsideDir(t *testing.T) {
+	store := NewLocalStorage(t.TempDir())
+	ctx := context.Background()
+
+	for _, key := range []string{"", "../secret", "nested/file.zip", ".hidden", ".."} {
+		assert.ErrorIs(t, store.Put(ctx, key, []byte("x")), domainerror.ErrInvalidInput, key)
+	}
+}
+
+func TestMemoryStorage_FailWith(t *testing.T) {
+	store := NewMemoryStorage()
+	ctx := context.Background()
+	store.FailWith(errors.New("disk full"))
+
+	assert.Error(t, store.Put(ctx, "export.zip", []byte("data")))
+	assert.Empty(t, store.Keys())
+
+	store.FailWith(nil)
+	assert.NoError(t, store.Put(ctx, "export.zip", []byte("data")))
+	assert.Equal(t, []string{"export.zip"}, store.Keys())
+}
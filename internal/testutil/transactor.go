@@ -0,0 +1,12 @@
+// Package testutil holds fakes shared by the unit tests of several features
+package testutil
+
+import "context"
+
+// InlineTransactor runs the unit of work directly, without a database
+type InlineTransactor struct{}
+
+// WithinTransaction calls fn with ctx as it is
+func (InlineTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -1,7 +1,9 @@
+-- The original users table, later migrations add the columns of entity.User up to
+-- its current shape. Apply them in order with go run ./cmd/api migrate up
 CREATE EXTENSION IF NOT EXISTS "pgcrypto";
 
 CREATE TABLE IF NOT EXISTS users (
-    id VARCHAR(36) PRIMARY KEY DEFAULT VARCHAR(36),
+    id VARCHAR(36) PRIMARY KEY DEFAULT gen_random_uuid()::text,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP,
@@ -11,7 +13,6 @@ CREATE TABLE IF NOT EXISTS users (
     first_name VARCHAR(100) NOT NULL,
     last_name VARCHAR(100) NOT NULL,
     is_active BOOLEAN DEFAULT true
-    
 );
 
 CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_users_phone;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS provider,
+    DROP COLUMN IF EXISTS role,
+    DROP COLUMN IF EXISTS gender,
+    DROP COLUMN IF EXISTS birth_date,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS phone;
@@ -0,0 +1,9 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS phone VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS status VARCHAR(50) DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS birth_date DATE,
+    ADD COLUMN IF NOT EXISTS gender VARCHAR(10),
+    ADD COLUMN IF NOT EXISTS role VARCHAR(50) DEFAULT 'user',
+    ADD COLUMN IF NOT EXISTS provider VARCHAR(50);
+
+CREATE INDEX IF NOT EXISTS idx_users_phone ON users(phone);
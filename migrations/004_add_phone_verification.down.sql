@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS phone_otps;
+
+DROP INDEX IF EXISTS idx_users_phone_verified;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS two_factor_enabled,
+    DROP COLUMN IF EXISTS phone_verified_at;
@@ -0,0 +1,20 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS phone_verified_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS two_factor_enabled BOOLEAN DEFAULT false;
+
+-- A phone number can be verified by one account only
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone_verified ON users(phone)
+    WHERE phone_verified_at IS NOT NULL AND deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS phone_otps (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    phone VARCHAR(20) NOT NULL,
+    purpose VARCHAR(20) NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_phone_otps_user_purpose ON phone_otps(user_id, purpose);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_users_username_normalized;
+DROP INDEX IF EXISTS idx_users_email_normalized;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS username_normalized,
+    DROP COLUMN IF EXISTS email_normalized;
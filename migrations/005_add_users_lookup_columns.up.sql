@@ -0,0 +1,12 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_normalized VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS username_normalized VARCHAR(100);
+
+-- Backfill the case-insensitive lookup keys of existing users
+UPDATE users
+SET email_normalized = LOWER(TRIM(email)),
+    username_normalized = LOWER(TRIM(username))
+WHERE email_normalized IS NULL OR username_normalized IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_email_normalized ON users(email_normalized);
+CREATE INDEX IF NOT EXISTS idx_users_username_normalized ON users(username_normalized);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_username_normalized;
+DROP INDEX IF EXISTS idx_users_email_normalized;
+
+CREATE INDEX IF NOT EXISTS idx_users_email_normalized ON users(email_normalized);
+CREATE INDEX IF NOT EXISTS idx_users_username_normalized ON users(username_normalized);
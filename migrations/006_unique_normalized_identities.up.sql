@@ -0,0 +1,13 @@
+-- Recompute the canonical keys with Unicode normalization. Provider rules such as
+-- EMAIL_IGNORE_GMAIL_DOTS are applied by the backfill command (cmd/backfill),
+-- run it first to find users whose keys collide: the unique indexes below
+-- cannot be created until those are resolved
+UPDATE users
+SET email_normalized = LOWER(NORMALIZE(TRIM(email), NFKC)),
+    username_normalized = LOWER(NORMALIZE(TRIM(username), NFKC));
+
+DROP INDEX IF EXISTS idx_users_email_normalized;
+DROP INDEX IF EXISTS idx_users_username_normalized;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_normalized ON users(email_normalized);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_normalized ON users(username_normalized);
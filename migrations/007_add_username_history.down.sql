@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS username_history;
+
+DROP INDEX IF EXISTS idx_users_username_skeleton;
+
+ALTER TABLE users DROP COLUMN IF EXISTS username_skeleton;
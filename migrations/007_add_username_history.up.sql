@@ -0,0 +1,19 @@
+-- Lookalike key of the username, see canonical.Skeleton. It cannot be computed
+-- in SQL: run the backfill command (cmd/backfill) after this migration
+ALTER TABLE users ADD COLUMN IF NOT EXISTS username_skeleton VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_users_username_skeleton ON users(username_skeleton);
+
+CREATE TABLE IF NOT EXISTS username_history (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    username VARCHAR(100) NOT NULL,
+    username_normalized VARCHAR(100) NOT NULL,
+    username_skeleton VARCHAR(100) NOT NULL,
+    changed_at TIMESTAMP NOT NULL,
+    held_until TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_username_history_user_id ON username_history(user_id);
+CREATE INDEX IF NOT EXISTS idx_username_history_username_normalized ON username_history(username_normalized);
+CREATE INDEX IF NOT EXISTS idx_username_history_username_skeleton ON username_history(username_skeleton);
@@ -0,0 +1,18 @@
+-- Embedded in access tokens, incrementing it revokes every token issued before
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS email_changes (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    old_email VARCHAR(255) NOT NULL,
+    new_email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    cancel_token_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A user has at most one pending change
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_changes_user_id ON email_changes(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_changes_token_hash ON email_changes(token_hash);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_changes_cancel_token_hash ON email_changes(cancel_token_hash);
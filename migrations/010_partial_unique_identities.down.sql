@@ -0,0 +1,10 @@
+-- Fails while a deleted user shares an email or username with another user,
+-- purge or anonymize those first
+DROP INDEX IF EXISTS idx_users_username_normalized;
+DROP INDEX IF EXISTS idx_users_email_normalized;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_normalized ON users(email_normalized);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_normalized ON users(username_normalized);
+
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
@@ -0,0 +1,11 @@
+-- Emails and usernames are only unique among live users, so soft-deleting an
+-- account releases them for new sign-ups. A deleted account whose identity was
+-- taken in the meantime can no longer be restored or reactivated as is
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+
+DROP INDEX IF EXISTS idx_users_email_normalized;
+DROP INDEX IF EXISTS idx_users_username_normalized;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_normalized ON users(email_normalized) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_normalized ON users(username_normalized) WHERE deleted_at IS NULL;
@@ -0,0 +1,13 @@
+-- Decrypt the values first with go run ./cmd/reencrypt -decrypt, the casts below
+-- fail on ciphertext
+DROP INDEX IF EXISTS idx_users_birth_date_index;
+DROP INDEX IF EXISTS idx_users_phone_verified;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS birth_date_index,
+    DROP COLUMN IF EXISTS phone_index,
+    ALTER COLUMN birth_date TYPE DATE USING birth_date::date,
+    ALTER COLUMN phone TYPE VARCHAR(20);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone_verified ON users(phone)
+    WHERE phone_verified_at IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,16 @@
+-- Phone numbers and birth dates are stored AES-GCM encrypted by the application,
+-- with an HMAC blind index for equality lookups. Existing plaintext values stay
+-- readable; run make reencrypt-fields (go run ./cmd/reencrypt) right after this
+-- migration to encrypt them and fill the indexes, lookups by phone miss them until then
+DROP INDEX IF EXISTS idx_users_phone_verified;
+
+ALTER TABLE users
+    ALTER COLUMN phone TYPE TEXT,
+    ALTER COLUMN birth_date TYPE TEXT USING TO_CHAR(birth_date, 'YYYY-MM-DD'),
+    ADD COLUMN IF NOT EXISTS phone_index VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS birth_date_index VARCHAR(64);
+
+-- A phone number can be verified by one account only
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone_verified ON users(phone_index)
+    WHERE phone_verified_at IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_birth_date_index ON users(birth_date_index);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_data_exports_user_id_active;
@@ -0,0 +1,13 @@
+-- A user has at most one pending or running export, so concurrent requests
+-- cannot queue duplicate jobs. Older duplicates left by such races are failed
+-- first, the most recent one stays active
+UPDATE data_exports SET status = 'failed'
+WHERE (status = 'pending' OR status = 'running')
+  AND id NOT IN (
+      SELECT DISTINCT ON (user_id) id FROM data_exports
+      WHERE status = 'pending' OR status = 'running'
+      ORDER BY user_id, created_at DESC, id
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_exports_user_id_active ON data_exports(user_id)
+    WHERE status = 'pending' OR status = 'running';
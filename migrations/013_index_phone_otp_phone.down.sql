@@ -0,0 +1,5 @@
+DELETE FROM phone_otps;
+
+ALTER TABLE phone_otps
+    DROP COLUMN IF EXISTS phone_index,
+    ADD COLUMN IF NOT EXISTS phone VARCHAR(20) NOT NULL;
@@ -0,0 +1,8 @@
+-- One-time passwords keep the blind index of the number they were sent to
+-- rather than the number itself. Pending codes are short-lived and dropped,
+-- users simply ask for a new one
+DELETE FROM phone_otps;
+
+ALTER TABLE phone_otps
+    DROP COLUMN IF EXISTS phone,
+    ADD COLUMN IF NOT EXISTS phone_index VARCHAR(64) NOT NULL;
@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations, so the binary applies them
+// without the files at hand
+package migrations
+
+import "embed"
+
+// FS holds the <version>_<name>.up.sql and .down.sql files
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,98 @@
+// Package canonical reduces emails and usernames to the key they are unique by,
+// so variants differing in case, Unicode form or surrounding spaces collide
+package canonical
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Options holds the provider-specific email rules
+type Options struct {
+	IgnoreGmailDots bool // Drop dots from Gmail local parts, which Gmail ignores
+}
+
+// gmailDomains are the domains delivering to the same Gmail mailboxes
+var gmailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+}
+
+// options are the email rules Email applies, see Configure
+var options Options
+
+// Configure sets the email rules Email applies. It is called once at startup,
+// before any email is canonicalized: changing the rules later would change the
+// keys of stored rows
+func Configure(opts Options) {
+	options = opts
+}
+
+// Email canonicalizes an email with the rules set by Configure
+func Email(email string) string {
+	return EmailWith(email, options)
+}
+
+// EmailWith trims, NFKC-normalizes and lowercases an email, then applies opts
+func EmailWith(email string, opts Options) string {
+	email = Username(email)
+
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 || !opts.IgnoreGmailDots {
+		return email
+	}
+
+	local, domain := email[:at], email[at+1:]
+	if !gmailDomains[domain] {
+		return email
+	}
+	return strings.ReplaceAll(local, ".", "") + "@gmail.com"
+}
+
+// Username trims, NFKC-normalizes and lowercases a username. NFKC folds
+// compatibility characters such as fullwidth letters into their plain form
+func Username(username string) string {
+	return strings.ToLower(norm.NFKC.String(strings.TrimSpace(username)))
+}
+
+// confusables maps letters of other scripts that render like a Latin letter to
+// the letter they imitate, both cases, following the prototypes of the Unicode
+// TR39 confusables table. Only cross-script lookalikes are listed: ASCII pairs
+// such as "1" and "l" or "rn" and "m" are told apart, or ordinary names would
+// collide
+var confusables = map[rune]rune{
+	// Cyrillic
+	'А': 'A', 'В': 'B', 'Е': 'E', 'Ѕ': 'S', 'І': 'I', 'Ј': 'J', 'К': 'K', 'М': 'M',
+	'Н': 'H', 'О': 'O', 'Р': 'P', 'С': 'C', 'Т': 'T', 'Х': 'X', 'Ү': 'Y', 'Ԛ': 'Q', 'Ԝ': 'W',
+	'а': 'a', 'е': 'e', 'і': 'i', 'ј': 'j', 'о': 'o', 'р': 'p', 'с': 'c', 'ѕ': 's',
+	'у': 'y', 'х': 'x', 'һ': 'h', 'ӏ': 'l', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w',
+	// Greek
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M',
+	'Ν': 'N', 'Ο': 'O', 'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X',
+	'α': 'a', 'γ': 'y', 'ι': 'i', 'ν': 'v', 'ο': 'o', 'ρ': 'p', 'υ': 'u',
+}
+
+// Skeleton reduces a username to the form it is visually confused by: accents
+// and separators are dropped, lookalike letters of other scripts replaced by the
+// Latin one they imitate, then case is folded. Lookalikes are folded first so an
+// uppercase letter maps by its own shape rather than its lowercase one. Two
+// usernames with the same skeleton look alike, e.g. "admin", "Admin", "аdmin"
+// (Cyrillic а) and "ad.min"
+func Skeleton(username string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(strings.TrimSpace(username)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining accent
+		case r == '.' || r == '_' || r == '-':
+		default:
+			if c, ok := confusables[r]; ok {
+				r = c
+			}
+			b.WriteRune(r)
+		}
+	}
+	return strings.ToLower(b.String())
+}
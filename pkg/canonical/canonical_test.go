@@ -0,0 +1,84 @@
+package canonical
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"lowercases", "JohnDoe", "johndoe"},
+		{"trims", "  johndoe\t", "johndoe"},
+		{"folds fullwidth letters", "Ｊｏｈｎ", "john"},
+		{"composes accents", "jose\u0301", "jos\u00e9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Username(tt.input))
+		})
+	}
+}
+
+func TestEmailWith(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     Options
+		expected string
+	}{
+		{"lowercases", " Bob@X.com ", Options{}, "bob@x.com"},
+		{"keeps gmail dots by default", "J.Doe@gmail.com", Options{}, "j.doe@gmail.com"},
+		{"drops gmail dots", "J.Doe@gmail.com", Options{IgnoreGmailDots: true}, "jdoe@gmail.com"},
+		{"maps googlemail", "j.doe@GoogleMail.com", Options{IgnoreGmailDots: true}, "jdoe@gmail.com"},
+		{"keeps other domains", "j.doe@example.com", Options{IgnoreGmailDots: true}, "j.doe@example.com"},
+		{"no at sign", "j.doe", Options{IgnoreGmailDots: true}, "j.doe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EmailWith(tt.input, tt.opts))
+		})
+	}
+}
+
+func TestEmail_Configured(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{}) })
+
+	assert.Equal(t, "j.doe@gmail.com", Email("j.doe@gmail.com"))
+
+	Configure(Options{IgnoreGmailDots: true})
+	assert.Equal(t, "jdoe@gmail.com", Email("j.doe@gmail.com"))
+}
+
+func TestSkeleton_Confusables(t *testing.T) {
+	for _, username := range []string{"admin", "Admin", "ADMIN", "аdmin", "ad.min", "ádmin", "αdmιn", "АDМІN"} {
+		t.Run(username, func(t *testing.T) {
+			assert.Equal(t, Skeleton("admin"), Skeleton(username))
+		})
+	}
+}
+
+func TestSkeleton_Distinct(t *testing.T) {
+	// Distinct ASCII names never collide, however alike they look
+	pairs := [][2]string{
+		{"alice", "bob"},
+		{"admin", "admins"},
+		{"kim", "klm"},
+		{"barn", "bam"},
+		{"olivia", "ollvla"},
+		{"vvill", "will"},
+		{"adm1n", "admin"},
+		{"j0hn", "john"},
+	}
+	for _, pair := range pairs {
+		t.Run(pair[0]+"/"+pair[1], func(t *testing.T) {
+			assert.NotEqual(t, Skeleton(pair[0]), Skeleton(pair[1]))
+		})
+	}
+}
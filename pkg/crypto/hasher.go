@@ -0,0 +1,198 @@
+package crypto
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrHasherBusy is returned right away when the queue of a PasswordHasher is full
+	ErrHasherBusy = errors.New("password hasher queue is full")
+	// ErrHasherTimeout is returned when no worker picked a call up within the queue timeout
+	ErrHasherTimeout = errors.New("password hasher queue timeout")
+	// ErrHasherClosed is returned by a closed PasswordHasher
+	ErrHasherClosed = errors.New("password hasher is closed")
+)
+
+// HasherConfig sizes the worker pool of a PasswordHasher
+type HasherConfig struct {
+	Workers      int           // Hashes computed at once, runtime.NumCPU() when 0
+	QueueSize    int           // Calls waiting for a worker, further calls fail with ErrHasherBusy
+	QueueTimeout time.Duration // How long a call waits for a worker, 0 waits as long as its context
+}
+
+// HasherStats is a snapshot of the activity of a PasswordHasher
+type HasherStats struct {
+	Workers   int    `json:"workers"`
+	QueueSize int    `json:"queue_size"`
+	Queued    int64  `json:"queued"`    // Calls waiting for a worker
+	Running   int64  `json:"running"`   // Calls being computed
+	Completed uint64 `json:"completed"` // Calls computed
+	Rejected  uint64 `json:"rejected"`  // Calls failed with ErrHasherBusy
+	TimedOut  uint64 `json:"timed_out"` // Calls failed with ErrHasherTimeout
+	Canceled  uint64 `json:"canceled"`  // Calls whose context ended first
+	// Average time completed calls waited for a worker and took to compute
+	AvgWait time.Duration `json:"avg_wait_ns" swaggertype:"integer"`
+	AvgWork time.Duration `json:"avg_work_ns" swaggertype:"integer"`
+}
+
+// PasswordHasher hashes and verifies passwords on a bounded pool of workers, so
+// bursts of logins queue up instead of taking every CPU. Calls beyond the queue
+// fail fast with ErrHasherBusy, queued calls give up after the queue timeout or
+// when their context ends
+type PasswordHasher struct {
+	params  PasswordParams
+	cfg     HasherConfig
+	workers chan struct{} // One token per busy worker
+	quit    chan struct{}
+	closing sync.Once
+
+	queued, running                         atomic.Int64
+	completed, rejected, timedOut, canceled atomic.Uint64
+	waitTotal, workTotal                    atomic.Int64
+}
+
+// NewPasswordHasher creates a pool hashing new passwords with params
+func NewPasswordHasher(params PasswordParams, cfg HasherConfig) *PasswordHasher {
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.QueueSize < 0 {
+		cfg.QueueSize = 0
+	}
+
+	return &PasswordHasher{
+		params:  params,
+		cfg:     cfg,
+		workers: make(chan struct{}, cfg.Workers),
+		quit:    make(chan struct{}),
+	}
+}
+
+// Params returns the parameters new hashes are made with
+func (h *PasswordHasher) Params() PasswordParams {
+	return h.params
+}
+
+// Hash hashes a password with the parameters of the hasher
+func (h *PasswordHasher) Hash(ctx context.Context, password string) (string, error) {
+	var hash string
+	var err error
+	if poolErr := h.do(ctx, func() { hash, err = HashPasswordWithParams(password, h.params) }); poolErr != nil {
+		return "", poolErr
+	}
+	return hash, err
+}
+
+// Verify verifies a password against its hash, see VerifyPassword
+func (h *PasswordHasher) Verify(ctx context.Context, hashedPassword, password string) error {
+	var err error
+	if poolErr := h.do(ctx, func() { err = VerifyPassword(hashedPassword, password) }); poolErr != nil {
+		return poolErr
+	}
+	return err
+}
+
+// NeedsRehash reports whether a hash was made with other parameters than those of
+// the hasher, see NeedsRehash
+func (h *PasswordHasher) NeedsRehash(hashedPassword string) bool {
+	return NeedsRehash(hashedPassword, h.params)
+}
+
+// Stats returns a snapshot of the pool activity
+func (h *PasswordHasher) Stats() HasherStats {
+	stats := HasherStats{
+		Workers:   h.cfg.Workers,
+		QueueSize: h.cfg.QueueSize,
+		Queued:    h.queued.Load(),
+		Running:   h.running.Load(),
+		Completed: h.completed.Load(),
+		Rejected:  h.rejected.Load(),
+		TimedOut:  h.timedOut.Load(),
+		Canceled:  h.canceled.Load(),
+	}
+	if stats.Completed > 0 {
+		stats.AvgWait = time.Duration(h.waitTotal.Load() / int64(stats.Completed))
+		stats.AvgWork = time.Duration(h.workTotal.Load() / int64(stats.Completed))
+	}
+	return stats
+}
+
+// Close makes later calls fail with ErrHasherClosed, calls in progress complete
+func (h *PasswordHasher) Close() {
+	h.closing.Do(func() { close(h.quit) })
+}
+
+// do runs fn on a worker and waits for it. Once a worker picked fn up, fn runs to
+// completion even if the caller gives up, it still occupies the worker
+func (h *PasswordHasher) do(ctx context.Context, fn func()) error {
+	select {
+	case <-h.quit:
+		return ErrHasherClosed
+	default:
+	}
+
+	enqueued := time.Now()
+	if err := h.acquire(ctx); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	h.running.Add(1)
+	go func() {
+		start := time.Now()
+		fn()
+		h.workTotal.Add(int64(time.Since(start)))
+		h.waitTotal.Add(int64(start.Sub(enqueued)))
+		h.completed.Add(1)
+		h.running.Add(-1)
+		<-h.workers
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		h.canceled.Add(1)
+		return ctx.Err()
+	}
+}
+
+// acquire takes a free worker, waiting in the queue when none is
+func (h *PasswordHasher) acquire(ctx context.Context) error {
+	select {
+	case h.workers <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if h.queued.Add(1) > int64(h.cfg.QueueSize) {
+		h.queued.Add(-1)
+		h.rejected.Add(1)
+		return ErrHasherBusy
+	}
+	defer h.queued.Add(-1)
+
+	var timeout <-chan time.Time
+	if h.cfg.QueueTimeout > 0 {
+		timer := time.NewTimer(h.cfg.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case h.workers <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		h.canceled.Add(1)
+		return ctx.Err()
+	case <-timeout:
+		h.timedOut.Add(1)
+		return ErrHasherTimeout
+	}
+}
@@ -0,0 +1,178 @@
+package crypto
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// occupy blocks the single worker of h until the returned func is called
+func occupy(t *testing.T, h *PasswordHasher) func() {
+	release := make(chan struct{})
+	go func() { _ = h.do(context.Background(), func() { <-release }) }()
+	require.Eventually(t, func() bool { return h.Stats().Running == 1 }, time.Second, time.Millisecond)
+	return func() { close(release) }
+}
+
+func TestPasswordHasher_HashAndVerify(t *testing.T) {
+	h := NewPasswordHasher(testParams, HasherConfig{Workers: 2, QueueSize: 4})
+	defer h.Close()
+	ctx := context.Background()
+
+	hash, err := h.Hash(ctx, "testPassword123")
+	require.NoError(t, err)
+
+	assert.NoError(t, h.Verify(ctx, hash, "testPassword123"))
+	assert.ErrorIs(t, h.Verify(ctx, hash, "wrongPassword"), ErrPasswordMismatch)
+	assert.False(t, h.NeedsRehash(hash))
+	stats := h.Stats()
+	assert.Equal(t, uint64(3), stats.Completed)
+	assert.Positive(t, stats.AvgWork)
+}
+
+func TestPasswordHasher_BoundsConcurrency(t *testing.T) {
+	h := NewPasswordHasher(testParams, HasherConfig{Workers: 2, QueueSize: 16})
+	defer h.Close()
+
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := h.do(context.Background(), func() {
+				n := running.Add(1)
+				for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+				}
+				time.Sleep(2 * time.Millisecond)
+				running.Add(-1)
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), peak.Load())
+	assert.Equal(t, uint64(16), h.Stats().Completed)
+}
+
+func TestPasswordHasher_RejectsWhenQueueFull(t *testing.T) {
+	h := NewPasswordHasher(testParams, HasherConfig{Workers: 1, QueueSize: 1})
+	defer h.Close()
+	release := occupy(t, h)
+
+	// One call fits in the queue, the next one fails fast
+	queued := make(chan error, 1)
+	go func() {
+		_, err := h.Hash(context.Background(), "testPassword123")
+		queued <- err
+	}()
+	require.Eventually(t, func() bool { return h.Stats().Queued == 1 }, time.Second, time.Millisecond)
+
+	_, err := h.Hash(context.Background(), "testPassword123")
+	assert.ErrorIs(t, err, ErrHasherBusy)
+	assert.Equal(t, uint64(1), h.Stats().Rejected)
+
+	release()
+	assert.NoError(t, <-queued)
+}
+
+func TestPasswordHasher_QueueTimeout(t *testing.T) {
+	h := NewPasswordHasher(testParams, HasherConfig{Workers: 1, QueueSize: 1, QueueTimeout: 10 * time.Millisecond})
+	defer h.Close()
+	release := occupy(t, h)
+	defer release()
+
+	err := h.Verify(context.Background(), "$2a$04$invalid", "testPassword123")
+
+	assert.ErrorIs(t, err, ErrHasherTimeout)
+	stats := h.Stats()
+	assert.Equal(t, uint64(1), stats.TimedOut)
+	assert.Zero(t, stats.Queued)
+}
+
+func TestPasswordHasher_ContextCanceled(t *testing.T) {
+	h := NewPasswordHasher(testParams, HasherConfig{Workers: 1, QueueSize: 1})
+	defer h.Close()
+	release := occupy(t, h)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := h.Hash(ctx, "testPassword123")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, uint64(1), h.Stats().Canceled)
+
+	// The abandoned call is skipped, not computed
+	release()
+	hash, err := h.Hash(context.Background(), "testPassword123")
+	require.NoError(t, err)
+	assert.NotEmpty(t, hash)
+	assert.Equal(t, uint64(2), h.Stats().Completed)
+}
+
+func TestPasswordHasher_Closed(t *testing.T) {
+	h := NewPasswordHasher(testParams, HasherConfig{Workers: 1})
+	h.Close()
+	h.Close()
+
+	_, err := h.Hash(context.Background(), "testPassword123")
+
+	assert.ErrorIs(t, err, ErrHasherClosed)
+}
+
+// BenchmarkHashPassword_Inline hashes on the calling goroutines, as many at once as
+// there are callers
+func BenchmarkHashPassword_Inline(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := HashPassword("testPassword123"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkPasswordHasher hashes on a pool of one worker per CPU, compare the
+// throughput and latency with BenchmarkHashPassword_Inline under -cpu 1,4,16
+func BenchmarkPasswordHasher(b *testing.B) {
+	h := NewPasswordHasher(DefaultPasswordParams, HasherConfig{QueueSize: 1024})
+	defer h.Close()
+	ctx := context.Background()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := h.Hash(ctx, "testPassword123"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	stats := h.Stats()
+	b.ReportMetric(float64(stats.AvgWait.Microseconds()), "wait-µs/op")
+	b.ReportMetric(float64(stats.AvgWork.Microseconds()), "work-µs/op")
+}
+
+// BenchmarkPasswordHasher_Verify measures verification, what every login pays
+func BenchmarkPasswordHasher_Verify(b *testing.B) {
+	h := NewPasswordHasher(DefaultPasswordParams, HasherConfig{QueueSize: 1024})
+	defer h.Close()
+	ctx := context.Background()
+	hash, err := h.Hash(ctx, "testPassword123")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := h.Verify(ctx, hash, "testPassword123"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ciphertextPrefix marks values written by Keyring.Encrypt, values without it
+// are plaintext stored before encryption was enabled
+const ciphertextPrefix = "enc:"
+
+// Keyring encrypts values with AES-256-GCM under its primary key and decrypts
+// them with whichever key they were written with, so keys can be rotated. A
+// separate key computes blind indexes
+type Keyring struct {
+	keys     map[string]cipher.AEAD
+	primary  string
+	indexKey []byte
+}
+
+// NewKeyring creates a keyring from 32-byte keys by ID. primary is the ID of the
+// key new values are encrypted with
+func NewKeyring(keys map[string][]byte, primary string, indexKey []byte) (*Keyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("primary key %q is not in the keyring", primary)
+	}
+	if len(indexKey) < 32 {
+		return nil, errors.New("index key must be at least 32 bytes")
+	}
+
+	aeads := make(map[string]cipher.AEAD, len(keys))
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key ID %q", id)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("key %q must be 32 bytes", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		aeads[id] = aead
+	}
+
+	return &Keyring{keys: aeads, primary: primary, indexKey: indexKey}, nil
+}
+
+// ParseKeys parses a comma-separated list of id:base64 keys, returning the keys
+// by ID and the IDs in the order listed
+func ParseKeys(list string) (map[string][]byte, []string, error) {
+	keys := map[string][]byte{}
+	var ids []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, nil, fmt.Errorf("key %q is not in id:base64 form", item)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		if _, ok := keys[id]; ok {
+			return nil, nil, fmt.Errorf("key %q is listed twice", id)
+		}
+		keys[id] = key
+		ids = append(ids, id)
+	}
+	return keys, ids, nil
+}
+
+// Encrypt encrypts plaintext under the primary key as enc:<key ID>:<base64>.
+// aad is authenticated but not stored, the same aad must be given to Decrypt.
+// Stored values should use FieldAAD so they cannot be moved between cells
+func (k *Keyring) Encrypt(plaintext, aad []byte) (string, error) {
+	aead := k.keys[k.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, aad)
+	return ciphertextPrefix + k.primary + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value returned by Encrypt with the key it names
+func (k *Keyring) Decrypt(ciphertext string, aad []byte) ([]byte, error) {
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(ciphertext, ciphertextPrefix), ":")
+	if !ok || !IsEncrypted(ciphertext) {
+		return nil, errors.New("malformed ciphertext")
+	}
+	aead, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", id)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("malformed ciphertext: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("malformed ciphertext")
+	}
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], aad)
+}
+
+// FieldAAD returns the additional data binding a value to the table, column and
+// primary key of the cell it is stored in, so a ciphertext copied to another
+// column or another row fails to decrypt
+func FieldAAD(table, column, id string) []byte {
+	aad := make([]byte, 0, len(table)+len(column)+len(id)+2)
+	aad = append(aad, table...)
+	aad = append(aad, 0)
+	aad = append(aad, column...)
+	aad = append(aad, 0)
+	return append(aad, id...)
+}
+
+// NeedsRotation reports whether value is plaintext or was encrypted under a key
+// other than the primary one
+func (k *Keyring) NeedsRotation(value string) bool {
+	return !strings.HasPrefix(value, ciphertextPrefix+k.primary+":")
+}
+
+// BlindIndex returns a deterministic HMAC-SHA256 of plaintext, hex encoded, so
+// encrypted values can be looked up by equality without being decrypted.
+// domain separates the indexes of different columns
+func (k *Keyring) BlindIndex(domain string, plaintext []byte) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(domain))
+	mac.Write([]byte{0})
+	mac.Write(plaintext)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsEncrypted reports whether value was returned by Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, ciphertextPrefix)
+}
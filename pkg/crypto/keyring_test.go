@@ -0,0 +1,126 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyring(t *testing.T, primary string) *Keyring {
+	keyring, err := NewKeyring(map[string][]byte{
+		"1": bytes.Repeat([]byte{1}, 32),
+		"2": bytes.Repeat([]byte{2}, 32),
+	}, primary, bytes.Repeat([]byte{9}, 32))
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	keyring := newTestKeyring(t, "1")
+
+	ciphertext, err := keyring.Encrypt([]byte("+6281234567890"), []byte("phone"))
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(ciphertext, "enc:1:"))
+	assert.NotContains(t, ciphertext, "6281234567890")
+	plaintext, err := keyring.Decrypt(ciphertext, []byte("phone"))
+	require.NoError(t, err)
+	assert.Equal(t, "+6281234567890", string(plaintext))
+
+	// Every encryption uses a fresh nonce
+	again, err := keyring.Encrypt([]byte("+6281234567890"), []byte("phone"))
+	require.NoError(t, err)
+	assert.NotEqual(t, ciphertext, again)
+}
+
+func TestKeyring_DecryptRejectsTampering(t *testing.T) {
+	keyring := newTestKeyring(t, "1")
+	ciphertext, err := keyring.Encrypt([]byte("1990-01-31"), []byte("birth_date"))
+	require.NoError(t, err)
+
+	// Moved to another column
+	_, err = keyring.Decrypt(ciphertext, []byte("phone"))
+	assert.Error(t, err)
+
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(ciphertext, "enc:1:"))
+	require.NoError(t, err)
+	sealed[len(sealed)-1] ^= 1
+	_, err = keyring.Decrypt("enc:1:"+base64.RawStdEncoding.EncodeToString(sealed), []byte("birth_date"))
+	assert.Error(t, err)
+
+	_, err = keyring.Decrypt("enc:3:AAAA", []byte("birth_date"))
+	assert.ErrorContains(t, err, "unknown key")
+	_, err = keyring.Decrypt("1990-01-31", []byte("birth_date"))
+	assert.Error(t, err)
+}
+
+func TestFieldAAD(t *testing.T) {
+	assert.Equal(t, []byte("users\x00phone\x00user-1"), FieldAAD("users", "phone", "user-1"))
+	// The separators keep shifted boundaries apart
+	assert.NotEqual(t, FieldAAD("users", "phone", "1"), FieldAAD("users", "phone1", ""))
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	old := newTestKeyring(t, "1")
+	ciphertext, err := old.Encrypt([]byte("+6281234567890"), nil)
+	require.NoError(t, err)
+
+	rotated := newTestKeyring(t, "2")
+
+	// Values under the former primary key still decrypt, but are due for rotation
+	plaintext, err := rotated.Decrypt(ciphertext, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "+6281234567890", string(plaintext))
+	assert.True(t, rotated.NeedsRotation(ciphertext))
+	assert.True(t, rotated.NeedsRotation("+6281234567890"))
+
+	reencrypted, err := rotated.Encrypt(plaintext, nil)
+	require.NoError(t, err)
+	assert.False(t, rotated.NeedsRotation(reencrypted))
+}
+
+func TestKeyring_BlindIndex(t *testing.T) {
+	keyring := newTestKeyring(t, "1")
+	rotated := newTestKeyring(t, "2")
+
+	index := keyring.BlindIndex("phone", []byte("+6281234567890"))
+
+	assert.Regexp(t, `^[0-9a-f]{64}$`, index)
+	// Deterministic and independent of the encryption keys
+	assert.Equal(t, index, rotated.BlindIndex("phone", []byte("+6281234567890")))
+	assert.NotEqual(t, index, keyring.BlindIndex("phone", []byte("+6281234567891")))
+	assert.NotEqual(t, index, keyring.BlindIndex("birth_date", []byte("+6281234567890")))
+}
+
+func TestNewKeyring_Invalid(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	indexKey := bytes.Repeat([]byte{9}, 32)
+
+	_, err := NewKeyring(map[string][]byte{"1": key}, "2", indexKey)
+	assert.Error(t, err)
+	_, err = NewKeyring(map[string][]byte{"1": key[:16]}, "1", indexKey)
+	assert.Error(t, err)
+	_, err = NewKeyring(map[string][]byte{"a:b": key}, "a:b", indexKey)
+	assert.Error(t, err)
+	_, err = NewKeyring(map[string][]byte{"1": key}, "1", indexKey[:8])
+	assert.Error(t, err)
+}
+
+func TestParseKeys(t *testing.T) {
+	keys, ids, err := ParseKeys("2024:" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)) + ", 2025:" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32)))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024", "2025"}, ids)
+	assert.Len(t, keys["2025"], 32)
+
+	_, _, err = ParseKeys("no-separator")
+	assert.Error(t, err)
+	_, _, err = ParseKeys("1:not base64!")
+	assert.Error(t, err)
+	_, _, err = ParseKeys("1:AAAA,1:AAAA")
+	assert.Error(t, err)
+}
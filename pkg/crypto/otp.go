@@ -0,0 +1,37 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"math/big"
+	"strings"
+)
+
+// OTPLength is the number of digits in a one-time password
+const OTPLength = 6
+
+// GenerateOTP returns a random numeric code of the given number of digits
+func GenerateOTP(digits int) (string, error) {
+	var b strings.Builder
+	for i := 0; i < digits; i++ {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(byte('0' + n.Int64()))
+	}
+	return b.String(), nil
+}
+
+// HashOTP hashes a one-time password salted with salt (e.g. the code's ID)
+func HashOTP(code, salt string) string {
+	sum := sha256.Sum256([]byte(salt + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyOTP reports whether code matches hash in constant time
+func VerifyOTP(hash, code, salt string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(HashOTP(code, salt))) == 1
+}
@@ -0,0 +1,34 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateOTP_Digits(t *testing.T) {
+	code, err := GenerateOTP(OTPLength)
+
+	require.NoError(t, err)
+	assert.Len(t, code, OTPLength)
+	assert.Regexp(t, `^[0-9]+$`, code)
+}
+
+func TestVerifyOTP_Success(t *testing.T) {
+	hash := HashOTP("123456", "otp-123")
+
+	assert.True(t, VerifyOTP(hash, "123456", "otp-123"))
+}
+
+func TestVerifyOTP_WrongCode(t *testing.T) {
+	hash := HashOTP("123456", "otp-123")
+
+	assert.False(t, VerifyOTP(hash, "654321", "otp-123"))
+}
+
+func TestVerifyOTP_WrongSalt(t *testing.T) {
+	hash := HashOTP("123456", "otp-123")
+
+	assert.False(t, VerifyOTP(hash, "123456", "otp-456"))
+}
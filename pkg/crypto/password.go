@@ -1,15 +1,58 @@
 package crypto
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // DefaultCost is the default bcrypt cost
 const DefaultCost = bcrypt.DefaultCost
 
-// HashPassword hashes a password using bcrypt with default cost
+// Password hashing algorithms
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+var (
+	// ErrPasswordMismatch is returned when a password does not match its hash
+	ErrPasswordMismatch = errors.New("password does not match")
+	// ErrUnsupportedHash is returned for hashes of an unknown format
+	ErrUnsupportedHash = errors.New("unsupported password hash")
+)
+
+// PasswordParams selects the algorithm and cost new password hashes are made with
+type PasswordParams struct {
+	Algorithm   string // AlgorithmArgon2id or AlgorithmBcrypt
+	Memory      uint32 // Argon2id memory in KiB
+	Iterations  uint32 // Argon2id passes over the memory
+	Parallelism uint8  // Argon2id lanes
+	SaltLength  uint32 // Argon2id salt bytes
+	KeyLength   uint32 // Argon2id hash bytes
+	BcryptCost  int
+}
+
+// DefaultPasswordParams are the OWASP recommended Argon2id parameters
+var DefaultPasswordParams = PasswordParams{
+	Algorithm:   AlgorithmArgon2id,
+	Memory:      19 * 1024,
+	Iterations:  2,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+	BcryptCost:  DefaultCost,
+}
+
+// HashPassword hashes a password with DefaultPasswordParams
 func HashPassword(password string) (string, error) {
-	return HashPasswordWithCost(password, DefaultCost)
+	return HashPasswordWithParams(password, DefaultPasswordParams)
 }
 
 // HashPasswordWithCost hashes a password using bcrypt with custom cost
@@ -21,13 +64,119 @@ func HashPasswordWithCost(password string, cost int) (string, error) {
 	return string(hashedPassword), nil
 }
 
-// VerifyPassword verifies a password against its hash
+// HashPasswordWithParams hashes a password with the algorithm and cost of params.
+// Argon2id hashes are PHC strings recording their parameters,
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<hash>, bcrypt
+// hashes are in their usual $2a$<cost>$ form. Unlike bcrypt, which rejects
+// passwords over 72 bytes, Argon2id takes the whole password into account
+func HashPasswordWithParams(password string, params PasswordParams) (string, error) {
+	switch params.Algorithm {
+	case AlgorithmArgon2id:
+		if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 || params.SaltLength == 0 || params.KeyLength == 0 {
+			return "", errors.New("invalid argon2id parameters")
+		}
+		salt := make([]byte, params.SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+		return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s", AlgorithmArgon2id, argon2.Version,
+			params.Memory, params.Iterations, params.Parallelism,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	case AlgorithmBcrypt:
+		return HashPasswordWithCost(password, params.BcryptCost)
+	}
+	return "", fmt.Errorf("unknown password hashing algorithm %q", params.Algorithm)
+}
+
+// VerifyPassword verifies a password against its hash, whichever algorithm made it.
+// It returns ErrPasswordMismatch when the password is wrong
 func VerifyPassword(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	if isBcrypt(hashedPassword) {
+		err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) || errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			return ErrPasswordMismatch
+		}
+		return err
+	}
+
+	hash, err := parseArgon2id(hashedPassword)
+	if err != nil {
+		return err
+	}
+	key := argon2.IDKey([]byte(password), hash.salt, hash.params.Iterations, hash.params.Memory, hash.params.Parallelism, hash.params.KeyLength)
+	if subtle.ConstantTimeCompare(key, hash.key) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
 }
 
 // CheckPasswordHash is an alias for VerifyPassword that returns bool
 func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	return VerifyPassword(hash, password) == nil
+}
+
+// NeedsRehash reports whether a hash was made with another algorithm or cost than
+// params, so it should be replaced once the password is known. Unreadable hashes
+// need one too
+func NeedsRehash(hashedPassword string, params PasswordParams) bool {
+	switch params.Algorithm {
+	case AlgorithmArgon2id:
+		hash, err := parseArgon2id(hashedPassword)
+		if err != nil {
+			return true
+		}
+		return hash.params.Memory != params.Memory ||
+			hash.params.Iterations != params.Iterations ||
+			hash.params.Parallelism != params.Parallelism ||
+			hash.params.SaltLength != params.SaltLength ||
+			hash.params.KeyLength != params.KeyLength
+	case AlgorithmBcrypt:
+		cost, err := bcrypt.Cost([]byte(hashedPassword))
+		return err != nil || cost != params.BcryptCost
+	}
+	return false
+}
+
+// argon2idHash is a parsed Argon2id PHC string
+type argon2idHash struct {
+	params PasswordParams
+	salt   []byte
+	key    []byte
+}
+
+// parseArgon2id parses a hash returned by HashPasswordWithParams for Argon2id
+func parseArgon2id(hashedPassword string) (*argon2idHash, error) {
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != AlgorithmArgon2id {
+		return nil, ErrUnsupportedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("%w: argon2id version %q", ErrUnsupportedHash, parts[2])
+	}
+	hash := &argon2idHash{params: PasswordParams{Algorithm: AlgorithmArgon2id}}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &hash.params.Memory, &hash.params.Iterations, &hash.params.Parallelism); err != nil {
+		return nil, fmt.Errorf("%w: argon2id parameters %q", ErrUnsupportedHash, parts[3])
+	}
+	if hash.params.Memory == 0 || hash.params.Iterations == 0 || hash.params.Parallelism == 0 {
+		return nil, fmt.Errorf("%w: argon2id parameters %q", ErrUnsupportedHash, parts[3])
+	}
+
+	var err error
+	if hash.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedHash, err)
+	}
+	if hash.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(hash.key) == 0 {
+		return nil, fmt.Errorf("%w: malformed argon2id hash", ErrUnsupportedHash)
+	}
+	hash.params.SaltLength = uint32(len(hash.salt))
+	hash.params.KeyLength = uint32(len(hash.key))
+	return hash, nil
+}
+
+// isBcrypt reports whether a hash is in the bcrypt $2a$, $2b$ or $2y$ form
+func isBcrypt(hashedPassword string) bool {
+	return strings.HasPrefix(hashedPassword, "$2a$") || strings.HasPrefix(hashedPassword, "$2b$") || strings.HasPrefix(hashedPassword, "$2y$")
 }
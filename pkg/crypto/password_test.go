@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -79,3 +80,90 @@ func TestCheckPasswordHash_False(t *testing.T) {
 
 	assert.False(t, result)
 }
+
+// testParams are cheap Argon2id parameters for tests
+var testParams = PasswordParams{Algorithm: AlgorithmArgon2id, Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestHashPassword_Argon2idFormat(t *testing.T) {
+	hashedPassword, err := HashPassword("testPassword123")
+
+	require.NoError(t, err)
+	assert.Regexp(t, `^\$argon2id\$v=19\$m=19456,t=2,p=1\$[A-Za-z0-9+/]{22}\$[A-Za-z0-9+/]{43}$`, hashedPassword)
+	assert.NoError(t, VerifyPassword(hashedPassword, "testPassword123"))
+}
+
+func TestHashPasswordWithParams_Argon2id(t *testing.T) {
+	hashedPassword, err := HashPasswordWithParams("testPassword123", testParams)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(hashedPassword, "$argon2id$v=19$m=64,t=1,p=1$"))
+	assert.NoError(t, VerifyPassword(hashedPassword, "testPassword123"))
+	assert.ErrorIs(t, VerifyPassword(hashedPassword, "wrongPassword"), ErrPasswordMismatch)
+
+	// Salted, the same password hashes differently every time
+	again, err := HashPasswordWithParams("testPassword123", testParams)
+	require.NoError(t, err)
+	assert.NotEqual(t, hashedPassword, again)
+}
+
+func TestHashPasswordWithParams_LongPassword(t *testing.T) {
+	long := strings.Repeat("a", 72)
+
+	hashedPassword, err := HashPasswordWithParams(long+"b", testParams)
+	require.NoError(t, err)
+
+	// Bytes past the 72nd count, unlike with bcrypt
+	assert.NoError(t, VerifyPassword(hashedPassword, long+"b"))
+	assert.ErrorIs(t, VerifyPassword(hashedPassword, long+"c"), ErrPasswordMismatch)
+}
+
+func TestHashPasswordWithParams_Invalid(t *testing.T) {
+	_, err := HashPasswordWithParams("testPassword123", PasswordParams{Algorithm: "md5"})
+	assert.Error(t, err)
+
+	_, err = HashPasswordWithParams("testPassword123", PasswordParams{Algorithm: AlgorithmArgon2id})
+	assert.Error(t, err)
+}
+
+func TestVerifyPassword_Bcrypt(t *testing.T) {
+	hashedPassword, err := HashPasswordWithParams("testPassword123", PasswordParams{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost})
+	require.NoError(t, err)
+
+	assert.NoError(t, VerifyPassword(hashedPassword, "testPassword123"))
+	assert.ErrorIs(t, VerifyPassword(hashedPassword, "wrongPassword"), ErrPasswordMismatch)
+	assert.ErrorIs(t, VerifyPassword(hashedPassword, strings.Repeat("a", 73)), ErrPasswordMismatch)
+}
+
+func TestVerifyPassword_Malformed(t *testing.T) {
+	for _, hashedPassword := range []string{
+		"",
+		"plaintext",
+		"$argon2i$v=19$m=64,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$aGFzaA",
+		"$argon2id$v=16$m=64,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=0,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$",
+		"$argon2id$v=19$m=64,t=1,p=1$not base64$aGFzaA",
+	} {
+		assert.ErrorIs(t, VerifyPassword(hashedPassword, "testPassword123"), ErrUnsupportedHash, hashedPassword)
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	argon2id, err := HashPasswordWithParams("testPassword123", testParams)
+	require.NoError(t, err)
+	bcryptHash, err := HashPasswordWithCost("testPassword123", bcrypt.MinCost)
+	require.NoError(t, err)
+
+	stronger := testParams
+	stronger.Memory = 128
+	bcryptParams := PasswordParams{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost}
+
+	assert.False(t, NeedsRehash(argon2id, testParams))
+	assert.True(t, NeedsRehash(argon2id, stronger))
+	assert.True(t, NeedsRehash(bcryptHash, testParams))
+	assert.True(t, NeedsRehash("malformed", testParams))
+
+	assert.False(t, NeedsRehash(bcryptHash, bcryptParams))
+	assert.True(t, NeedsRehash(bcryptHash, PasswordParams{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost + 1}))
+	assert.True(t, NeedsRehash(argon2id, bcryptParams))
+}
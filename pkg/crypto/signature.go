@@ -0,0 +1,21 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// Sign returns an HMAC-SHA256 signature of message under secret, URL-safe so it
+// can be put in a link
+func Sign(secret, message string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether signature was made by Sign for message under
+// secret, in constant time
+func VerifySignature(secret, message, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, message)), []byte(signature))
+}
@@ -0,0 +1,17 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign_Verify(t *testing.T) {
+	signature := Sign("secret", "export-1:1700000000")
+
+	assert.Regexp(t, `^[A-Za-z0-9_-]{43}$`, signature)
+	assert.True(t, VerifySignature("secret", "export-1:1700000000", signature))
+	assert.False(t, VerifySignature("secret", "export-1:1700000001", signature))
+	assert.False(t, VerifySignature("other-secret", "export-1:1700000000", signature))
+	assert.False(t, VerifySignature("secret", "export-1:1700000000", ""))
+}
@@ -0,0 +1,25 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateToken_Unique(t *testing.T) {
+	first, err := GenerateToken()
+	require.NoError(t, err)
+	second, err := GenerateToken()
+	require.NoError(t, err)
+
+	assert.Len(t, first, 43)
+	assert.Regexp(t, `^[A-Za-z0-9_-]+$`, first)
+	assert.NotEqual(t, first, second)
+}
+
+func TestHashToken_Deterministic(t *testing.T) {
+	assert.Equal(t, HashToken("token"), HashToken("token"))
+	assert.NotEqual(t, HashToken("token"), HashToken("other"))
+	assert.Len(t, HashToken("token"), 64)
+}
@@ -0,0 +1,26 @@
+package pkg
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FormatETag renders a resource version as a strong entity tag
+func FormatETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ParseETag returns the version carried by an entity tag produced by FormatETag.
+// Weak tags are rejected since If-Match requires a strong comparison
+func ParseETag(tag string) (int, bool) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
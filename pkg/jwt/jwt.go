@@ -10,17 +10,19 @@ import (
 
 // Claims represents JWT claims
 type Claims struct {
-	UserID   string `json:"user_id"`
-	Email    string `json:"email"`
-	Username string `json:"username"`
+	UserID       string `json:"user_id"`
+	Email        string `json:"email"`
+	Username     string `json:"username"`
+	TokenVersion int    `json:"token_version"` // The token is revoked once the user's version moved past it
 	jwt.RegisteredClaims
 }
 
 // UserPayload represents user data for token generation
 type UserPayload struct {
-	ID       string
-	Email    string
-	Username string
+	ID           string
+	Email        string
+	Username     string
+	TokenVersion int
 }
 
 // GenerateToken generates a JWT token for the given user payload
@@ -31,9 +33,10 @@ func GenerateToken(user UserPayload) (string, error) {
 // GenerateTokenWithExpiry generates a JWT token with custom expiry duration
 func GenerateTokenWithExpiry(user UserPayload, expiry time.Duration) (string, error) {
 	claims := &Claims{
-		UserID:   user.ID,
-		Email:    user.Email,
-		Username: user.Username,
+		UserID:       user.ID,
+		Email:        user.Email,
+		Username:     user.Username,
+		TokenVersion: user.TokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
@@ -46,9 +46,10 @@ func TestGenerateTokenWithExpiry_Success(t *testing.T) {
 func TestValidateToken_Success(t *testing.T) {
 	secret := "test-secret-key"
 	user := UserPayload{
-		ID:       "user-123",
-		Email:    "test@example.com",
-		Username: "testuser",
+		ID:           "user-123",
+		Email:        "test@example.com",
+		Username:     "testuser",
+		TokenVersion: 2,
 	}
 
 	token, err := GenerateToken(user)
@@ -60,6 +61,7 @@ func TestValidateToken_Success(t *testing.T) {
 	assert.Equal(t, user.ID, claims.UserID)
 	assert.Equal(t, user.Email, claims.Email)
 	assert.Equal(t, user.Username, claims.Username)
+	assert.Equal(t, user.TokenVersion, claims.TokenVersion)
 }
 
 func TestValidateToken_InvalidToken(t *testing.T) {
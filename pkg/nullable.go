@@ -0,0 +1,21 @@
+package pkg
+
+import "encoding/json"
+
+// Nullable is a JSON field that tells an absent value apart from an explicit
+// null, as needed to apply RFC 7396 merge patches
+type Nullable[T any] struct {
+	Value T
+	Set   bool // The field was present in the document
+	Null  bool // The field was explicitly null
+}
+
+// UnmarshalJSON is only called for fields present in the document
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &n.Value)
+}
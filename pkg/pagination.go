@@ -50,8 +50,27 @@ func TotalPage(totalRows, perPage int) int {
 }
 
 type PaginationResponse struct {
-	Page      int `json:"page"`
-	PerPage   int `json:"per_page"`
-	TotalPage int `json:"total_page"`
-	TotalData int `json:"total_data"`
+	Page      int  `json:"page"`
+	PerPage   int  `json:"per_page"`
+	TotalPage *int `json:"total_page,omitempty"` // Omitted when the total was not counted
+	TotalData *int `json:"total_data,omitempty"` // Omitted when the total was not counted
+	Estimated bool `json:"estimated,omitempty"`  // Totals may come from planner statistics
+	HasNext   bool `json:"has_next"`
+}
+
+// NewPaginationResponse builds pagination metadata, leaving the totals out when total is negative
+func NewPaginationResponse(pagination *Pagination, total int) PaginationResponse {
+	response := PaginationResponse{
+		Page:    pagination.Page,
+		PerPage: pagination.PerPage,
+	}
+	if total < 0 {
+		return response
+	}
+
+	totalPage := TotalPage(total, pagination.PerPage)
+	response.TotalPage = &totalPage
+	response.TotalData = &total
+	response.HasNext = pagination.Page < totalPage
+	return response
 }
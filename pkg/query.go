@@ -0,0 +1,22 @@
+package pkg
+
+import "strings"
+
+// SplitQueryList splits a comma-separated query value into trimmed, unique items
+func SplitQueryList(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	items := make([]string, 0)
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		items = append(items, item)
+	}
+	return items
+}